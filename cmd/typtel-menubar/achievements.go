@@ -0,0 +1,49 @@
+//go:build darwin
+// +build darwin
+
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+	"github.com/aayushbajaj/typing-telemetry/internal/notify"
+)
+
+// achievementInterval is how often achievements are checked for unlocking
+const achievementInterval = time.Minute
+
+// watchAchievements unlocks achievements as they're met, recording when and
+// notifying each
+func watchAchievements() {
+	ticker := time.NewTicker(achievementInterval)
+	defer ticker.Stop()
+
+	for {
+		newly, err := store.UnlockAchievements(units().Feet, time.Now())
+		if err != nil {
+			log.Printf("Failed to unlock achievements: %v", err)
+		}
+		for _, a := range newly {
+			log.Printf("Achievement unlocked: %s", a.Name)
+			notifyAchievement(a)
+		}
+		<-ticker.C
+	}
+}
+
+// notifyAchievement announces an unlocked achievement with the break
+// reminder notifier
+func notifyAchievement(a achievements.Achievement) {
+	n, err := notify.NewNotifier(store.Config().Breaks, os.Stdout)
+	if err != nil {
+		log.Printf("Failed to notify achievement: %v", err)
+		return
+	}
+	msg := a.Icon + " " + a.Name + ": " + a.Description
+	if err := n.Notify(notify.Notification{Kind: achievements.NotificationKind, Title: "Achievement unlocked", Message: msg}); err != nil {
+		log.Printf("Failed to notify achievement: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+//go:build darwin
+// +build darwin
+
+package main
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/breaks"
+	"github.com/aayushbajaj/typing-telemetry/internal/notify"
+)
+
+// Break reminders, scheduled from the keystroke and mouse stream
+var (
+	breakScheduler *breaks.Scheduler // nil while reminders are off
+	breakNotifier  notify.Notifier
+	breakMutex     sync.Mutex
+)
+
+// breakTickInterval is how often an idle stretch is checked for being a
+// long enough break
+const breakTickInterval = time.Second
+
+// startBreaks turns on break reminders if they're enabled
+func startBreaks() {
+	cfg := store.Config().Breaks
+	rules := breaks.Rules(cfg)
+	if !cfg.Enabled || len(rules) == 0 {
+		log.Println("Break reminders are disabled")
+		return
+	}
+	n, err := notify.NewNotifier(cfg, os.Stdout)
+	if err != nil {
+		log.Printf("Break reminders are disabled: %v", err)
+		return
+	}
+	breakNotifier = n
+	breakScheduler = breaks.NewScheduler(rules)
+	log.Printf("Break reminders on, notifying with %s", cfg.Notifier)
+
+	go func() {
+		ticker := time.NewTicker(breakTickInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			breakMutex.Lock()
+			events := breakScheduler.Tick(now)
+			breakMutex.Unlock()
+			handleBreakEvents(events)
+		}
+	}()
+}
+
+// recordActivity feeds keyboard or mouse activity to the break scheduler
+func recordActivity(t time.Time, keystrokes int) {
+	if breakScheduler == nil {
+		return
+	}
+	breakMutex.Lock()
+	events := breakScheduler.Activity(t, keystrokes)
+	breakMutex.Unlock()
+	handleBreakEvents(events)
+}
+
+// handleBreakEvents sends reminders for breaks that are due and stores the
+// ones taken or skipped
+func handleBreakEvents(events []breaks.Event) {
+	for _, e := range events {
+		if e.Type == breaks.Due {
+			n := breaks.Reminder(e.Rule)
+			go func() {
+				if err := breakNotifier.Notify(n); err != nil {
+					log.Printf("Failed to send break reminder: %v", err)
+				}
+			}()
+			continue
+		}
+		if err := store.RecordBreakOutcome(e); err != nil {
+			log.Printf("Failed to record break: %v", err)
+		}
+	}
+}
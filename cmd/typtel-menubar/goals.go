@@ -0,0 +1,116 @@
+//go:build darwin
+// +build darwin
+
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+	"github.com/aayushbajaj/typing-telemetry/internal/goals"
+	"github.com/aayushbajaj/typing-telemetry/internal/notify"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// Today's goal progress as of the last check, to notify as it changes
+var (
+	goalMutex   sync.Mutex
+	goalDate    string         // day goalTargets are for
+	goalTargets []goals.Target // nil until the first check
+	goalsMet    bool
+)
+
+// goalRefresh is how long the goal status is reused between menu bar title
+// updates, as working out the streak reads every day's totals
+const goalRefresh = time.Minute
+
+// The goal status last worked out and the goals last recorded
+var (
+	goalCacheMutex  sync.Mutex
+	goalCache       *storage.GoalStatus
+	goalCacheAt     time.Time
+	goalCacheFor    config.Goals // goals in effect when goalCache was worked out
+	goalsRecordedOn string       // day goalsRecorded were recorded for
+	goalsRecorded   config.Goals
+)
+
+// loadGoals records the goals in effect once a day and whenever they change,
+// so later changes don't rewrite today's, and returns today's progress,
+// notifying as goals are met
+func loadGoals() (*storage.GoalStatus, error) {
+	cfg := store.Config().Goals
+	today := store.Today()
+
+	goalCacheMutex.Lock()
+	defer goalCacheMutex.Unlock()
+
+	if goalsRecordedOn != today || goalsRecorded != cfg {
+		if err := store.RecordGoals(today, cfg); err != nil {
+			log.Printf("Failed to record goals: %v", err)
+		} else {
+			goalsRecordedOn, goalsRecorded = today, cfg
+		}
+	}
+	if goalCache != nil && goalCache.Date == today && goalCacheFor == cfg && time.Since(goalCacheAt) < goalRefresh {
+		return goalCache, nil
+	}
+
+	g, err := store.GetGoalStatus(units().Feet)
+	if err != nil {
+		return nil, err
+	}
+	goalCache, goalCacheAt, goalCacheFor = g, time.Now(), cfg
+	if msg := goalMessage(g); msg != "" && cfg.Notify {
+		notifyGoals(msg)
+	}
+	return g, nil
+}
+
+// goalMessage describes what changed since the last check, or returns ""
+func goalMessage(g *storage.GoalStatus) string {
+	goalMutex.Lock()
+	defer goalMutex.Unlock()
+
+	// The first check of a day only sets the baseline
+	if g.Date != goalDate {
+		goalDate, goalTargets, goalsMet = g.Date, g.Targets, g.Streak.TodayMet
+		return ""
+	}
+
+	var lines []string
+	for _, t := range goals.Changed(goalTargets, g.Targets) {
+		lines = append(lines, goals.Message(t, units().FormatFeet))
+	}
+	if g.Streak.TodayMet && !goalsMet {
+		lines = append(lines, "All of today's goals met: 🔥 "+streakDays(g.Streak.Current)+" in a row!")
+	}
+	goalTargets, goalsMet = g.Targets, g.Streak.TodayMet
+	return strings.Join(lines, " ")
+}
+
+// notifyGoals sends goal progress with the break reminder notifier
+func notifyGoals(msg string) {
+	n, err := notify.NewNotifier(store.Config().Breaks, os.Stdout)
+	if err != nil {
+		log.Printf("Failed to notify goal progress: %v", err)
+		return
+	}
+	go func() {
+		if err := n.Notify(notify.Notification{Kind: goals.NotificationKind, Title: "Daily goals", Message: msg}); err != nil {
+			log.Printf("Failed to notify goal progress: %v", err)
+		}
+	}()
+}
+
+// streakDays formats a streak length, e.g. "12 days"
+func streakDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return strconv.Itoa(n) + " days"
+}
@@ -0,0 +1,94 @@
+//go:build darwin
+// +build darwin
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/aayushbajaj/typing-telemetry/internal/leaderboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+const (
+	// leaderboardSlots is how many entries each leaderboard submenu lists
+	leaderboardSlots = 10
+
+	// leaderboardInterval is how often the leaderboards are refreshed; some
+	// are counted from every recorded keystroke, so not on every update
+	leaderboardInterval = time.Minute
+)
+
+// boardMenu is the submenu of one leaderboard
+type boardMenu struct {
+	board leaderboard.Board
+	items []*systray.MenuItem
+}
+
+var boardMenus []boardMenu
+
+// buildLeaderboardMenu adds a submenu for every leaderboard
+func buildLeaderboardMenu() {
+	mLeaderboards := systray.AddMenuItem("🏆 Leaderboards", "Your best days, hours, sessions and typing tests")
+	for _, b := range leaderboard.All {
+		parent := mLeaderboards.AddSubMenuItem(b.Icon+" "+b.Name, b.Description)
+		header := parent.AddSubMenuItem(b.Description, "")
+		header.Disable()
+
+		menu := boardMenu{board: b}
+		for i := 0; i < leaderboardSlots; i++ {
+			item := parent.AddSubMenuItem("", "")
+			item.Disable()
+			item.Hide()
+			menu.items = append(menu.items, item)
+		}
+		boardMenus = append(boardMenus, menu)
+
+		if b.ID == leaderboard.Stillness.ID {
+			mPage := parent.AddSubMenuItem("Open in Browser", "Show the stillness leaderboard page")
+			go func() {
+				for range mPage.ClickedCh {
+					showLeaderboard()
+				}
+			}()
+		}
+	}
+}
+
+// watchLeaderboards keeps the leaderboard submenus up to date
+func watchLeaderboards() {
+	ticker := time.NewTicker(leaderboardInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, menu := range boardMenus {
+			updateBoardMenu(menu)
+		}
+		<-ticker.C
+	}
+}
+
+func updateBoardMenu(menu boardMenu) {
+	entries, err := store.GetLeaderboard(menu.board, storage.LeaderboardOptions{Limit: leaderboardSlots})
+	if err != nil {
+		log.Printf("Failed to get %s leaderboard: %v", menu.board.ID, err)
+	}
+
+	for i, item := range menu.items {
+		if i >= len(entries) {
+			item.Hide()
+			continue
+		}
+		e := entries[i]
+		medal := ""
+		if e.Rank <= 3 {
+			medal = report.Medal(e.Rank) + " "
+		}
+		item.SetTitle(fmt.Sprintf("%s#%d: %s - %s", medal, e.Rank, report.EntryLabel(e), units().FormatLeaderboardValue(menu.board.Metric, e.Value)))
+		item.Show()
+	}
+}
@@ -103,18 +103,23 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
-	"sort"
-	"strings"
 	"sync"
 	"syscall"
 	"time"
 	"unsafe"
 
 	"fyne.io/systray"
+	"github.com/aayushbajaj/typing-telemetry/internal/devicesync"
 	"github.com/aayushbajaj/typing-telemetry/internal/inertia"
 	"github.com/aayushbajaj/typing-telemetry/internal/keylogger"
+	"github.com/aayushbajaj/typing-telemetry/internal/metrics"
 	"github.com/aayushbajaj/typing-telemetry/internal/mousetracker"
+	"github.com/aayushbajaj/typing-telemetry/internal/paths"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/speed"
+	"github.com/aayushbajaj/typing-telemetry/internal/statusbar"
 	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	"github.com/aayushbajaj/typing-telemetry/internal/words"
 )
 
 var (
@@ -123,42 +128,58 @@ var (
 	menuTitleMutex sync.Mutex
 )
 
+// Live typing speed, measured from the keystroke stream
+var (
+	typingSpeed      speed.Estimator
+	typingSpeedMutex sync.Mutex
+)
+
+// syncPushInterval is how often changes are written to the sync folder
+const syncPushInterval = 5 * time.Minute
+
+// retentionInterval is how often old keystrokes are pruned
+const retentionInterval = time.Hour
+
 // Version is set at build time via ldflags: -X main.Version=$(VERSION)
 var Version = "dev"
 
 // Menu item references for dynamic updates
 var (
-	mTodayKeystrokes    *systray.MenuItem
-	mTodayMouse         *systray.MenuItem
-	mWeekKeystrokes     *systray.MenuItem
-	mWeekMouse          *systray.MenuItem
-	mShowKeystrokes     *systray.MenuItem
-	mShowWords          *systray.MenuItem
-	mShowClicks         *systray.MenuItem
-	mShowDistance       *systray.MenuItem
-	mDistanceFeet       *systray.MenuItem
-	mDistanceCars       *systray.MenuItem
-	mDistanceFields     *systray.MenuItem
-	mMouseTracking      *systray.MenuItem
-	mInertiaEnabled     *systray.MenuItem
-	mInertiaUltraFast   *systray.MenuItem
-	mInertiaVeryFast    *systray.MenuItem
-	mInertiaFast        *systray.MenuItem
-	mInertiaMedium      *systray.MenuItem
-	mInertiaSlow        *systray.MenuItem
-	mThreshold100       *systray.MenuItem
-	mThreshold150       *systray.MenuItem
-	mThreshold200       *systray.MenuItem
-	mThreshold250       *systray.MenuItem
-	mThreshold350       *systray.MenuItem
-	mAccelRate025       *systray.MenuItem
-	mAccelRate050       *systray.MenuItem
-	mAccelRate100       *systray.MenuItem
-	mAccelRate150       *systray.MenuItem
-	mAccelRate200       *systray.MenuItem
-	leaderboardItems    []*systray.MenuItem
-	mLeaderboardHeader  *systray.MenuItem
-	leaderboardSubmenus *systray.MenuItem
+	mTodayKeystrokes  *systray.MenuItem
+	mTodayMouse       *systray.MenuItem
+	mTodaySpeed       *systray.MenuItem
+	mWeekKeystrokes   *systray.MenuItem
+	mWeekMouse        *systray.MenuItem
+	mShowKeystrokes   *systray.MenuItem
+	mShowWords        *systray.MenuItem
+	mShowClicks       *systray.MenuItem
+	mShowDistance     *systray.MenuItem
+	mShowWPM          *systray.MenuItem
+	mShowGoals        *systray.MenuItem
+	mDistanceFeet     *systray.MenuItem
+	mDistanceCars     *systray.MenuItem
+	mDistanceFields   *systray.MenuItem
+	mMouseTracking    *systray.MenuItem
+	mInertiaEnabled   *systray.MenuItem
+	mInertiaUltraFast *systray.MenuItem
+	mInertiaVeryFast  *systray.MenuItem
+	mInertiaFast      *systray.MenuItem
+	mInertiaMedium    *systray.MenuItem
+	mInertiaSlow      *systray.MenuItem
+	mThreshold100     *systray.MenuItem
+	mThreshold150     *systray.MenuItem
+	mThreshold200     *systray.MenuItem
+	mThreshold250     *systray.MenuItem
+	mThreshold350     *systray.MenuItem
+	mAccelRate025     *systray.MenuItem
+	mAccelRate050     *systray.MenuItem
+	mAccelRate100     *systray.MenuItem
+	mAccelRate150     *systray.MenuItem
+	mAccelRate200     *systray.MenuItem
+	mDayStartMidnight *systray.MenuItem
+	mDayStart2am      *systray.MenuItem
+	mDayStart4am      *systray.MenuItem
+	mDayStart6am      *systray.MenuItem
 )
 
 func init() {
@@ -201,28 +222,73 @@ func main() {
 	}
 	defer store.Close()
 
+	// Settings saved from the menu override the config file
+	if path, err := paths.ConfigPath(); err != nil {
+		log.Printf("Failed to find config file: %v", err)
+	} else if err := store.LoadConfigFile(path); err != nil {
+		log.Printf("Ignoring config file: %v", err)
+	}
+	if err := store.ApplyDayBounds(); err != nil {
+		log.Printf("Failed to re-bucket keystrokes into days: %v", err)
+	}
+
+	// Remind to take breaks, if set up
+	startBreaks()
+
 	// Start keylogger in background
 	keystrokeChan, err := keylogger.Start()
 	if err != nil {
 		log.Fatalf("Failed to start keylogger: %v", err)
 	}
 	defer keylogger.Stop()
+	metrics.RegisterDropped(metrics.SourceKeylogger, keylogger.Dropped)
 
 	// Process keystrokes in background
 	go func() {
-		for keycode := range keystrokeChan {
-			if err := store.RecordKeystroke(keycode); err != nil {
+		var detector words.Detector
+		for k := range keystrokeChan {
+			start := time.Now()
+			err := store.RecordChordAt(k.Keycode, k.Modifiers, k.Time)
+			metrics.KeystrokeWrite.ObserveSince(start)
+			if err != nil {
 				log.Printf("Failed to record keystroke: %v", err)
+			} else {
+				metrics.Keystrokes.Inc()
+			}
+			typingSpeedMutex.Lock()
+			burst, ok := typingSpeed.Press(k.Time, k.Keycode, k.Modifiers)
+			typingSpeedMutex.Unlock()
+			if ok {
+				recordTypingBurst(burst)
 			}
-			if isWordBoundary(keycode) {
-				date := time.Now().Format("2006-01-02")
-				if err := store.IncrementWordCount(date); err != nil {
+			recordActivity(k.Time, 1)
+			if detector.Press(k.Keycode, k.Modifiers) {
+				start := time.Now()
+				err := store.IncrementWordCountAt(k.Time)
+				metrics.WordWrite.ObserveSince(start)
+				if err != nil {
 					log.Printf("Failed to increment word count: %v", err)
+				} else {
+					metrics.Words.Inc()
 				}
 			}
 		}
 	}()
 
+	// Store the burst being typed once typing pauses
+	go func() {
+		ticker := time.NewTicker(speed.Pause)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			typingSpeedMutex.Lock()
+			burst, ok := typingSpeed.Flush(now)
+			typingSpeedMutex.Unlock()
+			if ok {
+				recordTypingBurst(burst)
+			}
+		}
+	}()
+
 	// Start mouse tracker if enabled
 	mouseTrackingEnabled := store.IsMouseTrackingEnabled()
 	if mouseTrackingEnabled {
@@ -231,34 +297,47 @@ func main() {
 			log.Printf("Warning: Failed to start mouse tracker: %v", err)
 		} else {
 			defer mousetracker.Stop()
+			metrics.RegisterDropped(metrics.SourceMouseMovement, mousetracker.DroppedMovements)
+			metrics.RegisterDropped(metrics.SourceMouseClick, mousetracker.DroppedClicks)
 
 			pos := mousetracker.GetCurrentPosition()
-			date := time.Now().Format("2006-01-02")
-			if err := store.SetMidnightPosition(date, pos.X, pos.Y); err != nil {
+			if err := store.SetMidnightPosition(store.Today(), pos.X, pos.Y); err != nil {
 				log.Printf("Failed to set midnight position: %v", err)
 			}
 
 			go func() {
-				currentDate := time.Now().Format("2006-01-02")
+				currentDate := store.Today()
 				for movement := range mouseChan {
-					newDate := time.Now().Format("2006-01-02")
+					newDate := store.Today()
 					if newDate != currentDate {
 						currentDate = newDate
 						if err := store.SetMidnightPosition(currentDate, movement.X, movement.Y); err != nil {
 							log.Printf("Failed to set midnight position: %v", err)
 						}
 					}
-					if err := store.RecordMouseMovement(movement.X, movement.Y, movement.Distance); err != nil {
+					start := time.Now()
+					err := store.RecordMouseMovement(movement.X, movement.Y, movement.Distance)
+					metrics.MouseMoveWrite.ObserveSince(start)
+					if err != nil {
 						log.Printf("Failed to record mouse movement: %v", err)
+					} else {
+						metrics.MouseDistance.Add(movement.Distance)
 					}
+					recordActivity(time.Now(), 0)
 				}
 			}()
 
 			go func() {
 				for range clickChan {
-					if err := store.RecordMouseClick(); err != nil {
+					start := time.Now()
+					err := store.RecordMouseClick()
+					metrics.ClickWrite.ObserveSince(start)
+					if err != nil {
 						log.Printf("Failed to record mouse click: %v", err)
+					} else {
+						metrics.MouseClicks.Inc()
 					}
+					recordActivity(time.Now(), 0)
 				}
 			}()
 		}
@@ -285,6 +364,41 @@ func main() {
 		log.Println("Inertia is disabled")
 	}
 
+	// Push changes to the shared sync folder, if one is set up
+	go func() {
+		ticker := time.NewTicker(syncPushInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if dir := store.SyncDir(); dir != "" {
+				if _, err := devicesync.Push(store, dir); err != nil {
+					log.Printf("Failed to push sync changes: %v", err)
+				}
+			}
+		}
+	}()
+
+	// Fold keystrokes older than retention.keystroke_days into totals
+	go func() {
+		for {
+			if n, err := store.PruneKeystrokes(); err != nil {
+				log.Printf("Failed to prune keystrokes: %v", err)
+			} else if n > 0 {
+				log.Printf("Pruned %d keystrokes past the retention period", n)
+			}
+			time.Sleep(retentionInterval)
+		}
+	}()
+
+	// Serve Prometheus metrics, if a listen address is set up
+	if addr := store.MetricsAddr(); addr != "" {
+		go func() {
+			log.Printf("Serving metrics at http://%s/metrics", addr)
+			if err := metrics.Default.ListenAndServe(addr); err != nil {
+				log.Printf("Metrics listener stopped: %v", err)
+			}
+		}()
+	}
+
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -300,6 +414,20 @@ func main() {
 	systray.Run(onReady, onExit)
 }
 
+// recordTypingBurst stores a burst of typing measured by typingSpeed
+func recordTypingBurst(b speed.Burst) {
+	if err := store.RecordTypingBurst(b); err != nil {
+		log.Printf("Failed to record typing burst: %v", err)
+	}
+}
+
+// currentWPM returns the live typing speed, or 0 before any has been measured
+func currentWPM() float64 {
+	typingSpeedMutex.Lock()
+	defer typingSpeedMutex.Unlock()
+	return typingSpeed.Current()
+}
+
 func onReady() {
 	// Set initial title
 	systray.SetTitle("⌨️")
@@ -308,6 +436,9 @@ func onReady() {
 	// Build the menu structure
 	buildMenu()
 
+	go watchAchievements()
+	go watchLeaderboards()
+
 	// Start update loop
 	go func() {
 		// Initial update after a short delay
@@ -335,6 +466,8 @@ func buildMenu() {
 	mTodayKeystrokes.Disable()
 	mTodayMouse = systray.AddMenuItem("Today: 🖱️ -- clicks, -- distance", "")
 	mTodayMouse.Disable()
+	mTodaySpeed = systray.AddMenuItem("Today: -- WPM typing speed", "Median and 90th percentile over bursts of continuous typing")
+	mTodaySpeed.Disable()
 
 	systray.AddSeparator()
 
@@ -354,21 +487,8 @@ func buildMenu() {
 		}
 	}()
 
-	// Leaderboard submenu
-	leaderboardSubmenus = systray.AddMenuItem("🏆 Stillness Leaderboard", "Days with least mouse movement")
-	mLeaderboardHeader = leaderboardSubmenus.AddSubMenuItem("🧘 Days You Didn't Move The Mouse", "")
-	mLeaderboardHeader.Disable()
-	// Pre-allocate leaderboard slots
-	for i := 0; i < 10; i++ {
-		item := leaderboardSubmenus.AddSubMenuItem("", "")
-		item.Hide()
-		leaderboardItems = append(leaderboardItems, item)
-	}
-	go func() {
-		for range leaderboardSubmenus.ClickedCh {
-			showLeaderboard()
-		}
-	}()
+	// Leaderboard submenus
+	buildLeaderboardMenu()
 
 	systray.AddSeparator()
 
@@ -385,6 +505,8 @@ func buildMenu() {
 	mShowWords = mSettings.AddSubMenuItemCheckbox("Show Words", "", settings.ShowWords)
 	mShowClicks = mSettings.AddSubMenuItemCheckbox("Show Mouse Clicks", "", settings.ShowClicks)
 	mShowDistance = mSettings.AddSubMenuItemCheckbox("Show Mouse Distance", "", settings.ShowDistance)
+	mShowWPM = mSettings.AddSubMenuItemCheckbox("Show Typing Speed", "", settings.ShowWPM)
+	mShowGoals = mSettings.AddSubMenuItemCheckbox("Show Goal Progress", "", settings.ShowGoals)
 
 	// Distance Unit submenu
 	mDistanceUnit := mSettings.AddSubMenuItem("   Distance Unit", "")
@@ -402,6 +524,14 @@ func buildMenu() {
 	mouseTrackingEnabled := store.IsMouseTrackingEnabled()
 	mMouseTracking = mSettings.AddSubMenuItemCheckbox("Enable Mouse Distance", "", mouseTrackingEnabled)
 
+	// Day boundary submenu
+	mDayStart := mSettings.AddSubMenuItem("   Day Starts At", "When a new day begins for daily totals")
+	dayStartHour := store.DayStartHour()
+	mDayStartMidnight = mDayStart.AddSubMenuItemCheckbox("Midnight (default)", "", dayStartHour == 0)
+	mDayStart2am = mDayStart.AddSubMenuItemCheckbox("2am", "", dayStartHour == 2)
+	mDayStart4am = mDayStart.AddSubMenuItemCheckbox("4am (night owl)", "", dayStartHour == 4)
+	mDayStart6am = mDayStart.AddSubMenuItemCheckbox("6am", "", dayStartHour == 6)
+
 	mSettings.AddSubMenuItem("", "").Disable() // Separator
 
 	// Inertia section
@@ -514,6 +644,28 @@ func handleSettingsClicks() {
 			}
 			updateMenuBarTitle()
 
+		case <-mShowWPM.ClickedCh:
+			s := store.GetMenubarSettings()
+			s.ShowWPM = !s.ShowWPM
+			store.SaveMenubarSettings(s)
+			if s.ShowWPM {
+				mShowWPM.Check()
+			} else {
+				mShowWPM.Uncheck()
+			}
+			updateMenuBarTitle()
+
+		case <-mShowGoals.ClickedCh:
+			s := store.GetMenubarSettings()
+			s.ShowGoals = !s.ShowGoals
+			store.SaveMenubarSettings(s)
+			if s.ShowGoals {
+				mShowGoals.Check()
+			} else {
+				mShowGoals.Uncheck()
+			}
+			updateMenuBarTitle()
+
 		case <-mDistanceFeet.ClickedCh:
 			store.SetDistanceUnit(storage.DistanceUnitFeet)
 			mDistanceFeet.Check()
@@ -547,6 +699,18 @@ func handleSettingsClicks() {
 				"Restart the app for changes to take effect.",
 				[]string{"OK"})
 
+		case <-mDayStartMidnight.ClickedCh:
+			setDayStartHour(0)
+
+		case <-mDayStart2am.ClickedCh:
+			setDayStartHour(2)
+
+		case <-mDayStart4am.ClickedCh:
+			setDayStartHour(4)
+
+		case <-mDayStart6am.ClickedCh:
+			setDayStartHour(6)
+
 		case <-mInertiaEnabled.ClickedCh:
 			s := store.GetInertiaSettings()
 			newEnabled := !s.Enabled
@@ -646,6 +810,32 @@ func handleSettingsClicks() {
 	}
 }
 
+func setDayStartHour(hour int) {
+	if err := store.SetDayStartHour(hour); err != nil {
+		log.Printf("Failed to set day start hour: %v", err)
+	}
+	updateDayStartChecks(store.DayStartHour())
+	updateMenuBarTitle()
+	updateStatsDisplay()
+}
+
+func updateDayStartChecks(hour int) {
+	mDayStartMidnight.Uncheck()
+	mDayStart2am.Uncheck()
+	mDayStart4am.Uncheck()
+	mDayStart6am.Uncheck()
+	switch hour {
+	case 0:
+		mDayStartMidnight.Check()
+	case 2:
+		mDayStart2am.Check()
+	case 4:
+		mDayStart4am.Check()
+	case 6:
+		mDayStart6am.Check()
+	}
+}
+
 func updateInertiaSpeedChecks(speed string) {
 	mInertiaUltraFast.Uncheck()
 	mInertiaVeryFast.Uncheck()
@@ -725,31 +915,14 @@ func updateMenuBarTitle() {
 		setMenuTitle("⌨️ --")
 		return
 	}
-
-	settings := store.GetMenubarSettings()
 	mouseStats, _ := store.GetTodayMouseStats()
 
-	var parts []string
-
-	if settings.ShowKeystrokes {
-		parts = append(parts, fmt.Sprintf("⌨️%s", formatAbsolute(stats.Keystrokes)))
-	}
-	if settings.ShowWords {
-		parts = append(parts, fmt.Sprintf("%sw", formatAbsolute(stats.Words)))
-	}
-	if settings.ShowClicks && mouseStats != nil {
-		parts = append(parts, fmt.Sprintf("🖱️%s", formatAbsolute(mouseStats.ClickCount)))
-	}
-	if settings.ShowDistance && mouseStats != nil && mouseStats.TotalDistance > 0 {
-		parts = append(parts, formatDistance(mouseStats.TotalDistance))
+	show := store.GetMenubarSettings()
+	status := statusbar.New(stats, mouseStats, currentWPM(), units(), show)
+	if g, err := loadGoals(); err == nil {
+		status.SetGoals(g, show)
 	}
-
-	title := "⌨️"
-	if len(parts) > 0 {
-		title = strings.Join(parts, " | ")
-	}
-
-	setMenuTitle(title)
+	setMenuTitle(status.Title)
 }
 
 func setMenuTitle(title string) {
@@ -800,44 +973,19 @@ func updateStatsDisplay() {
 		todayClicks = mouseStats.ClickCount
 	}
 
-	// Update menu items
-	mTodayKeystrokes.SetTitle(fmt.Sprintf("Today: %s keystrokes (%s words)", formatAbsolute(keystrokeCount), formatAbsolute(todayWords)))
-	mTodayMouse.SetTitle(fmt.Sprintf("Today: 🖱️ %s clicks, %s distance", formatAbsolute(todayClicks), formatDistance(todayMouseDistance)))
-	mWeekKeystrokes.SetTitle(fmt.Sprintf("This Week: %s keystrokes (%s words)", formatAbsolute(weekKeystrokes), formatAbsolute(weekWords)))
-	mWeekMouse.SetTitle(fmt.Sprintf("This Week: 🖱️ %s clicks, %s distance", formatAbsolute(weekClicks), formatDistance(weekMouseDistance)))
-
-	// Update leaderboard
-	updateLeaderboard()
-}
-
-func updateLeaderboard() {
-	entries, err := store.GetMouseLeaderboard(10)
-	if err != nil || len(entries) == 0 {
-		for _, item := range leaderboardItems {
-			item.Hide()
+	todaySpeed := "Today: -- WPM typing speed"
+	if stats != nil {
+		if sp, err := store.GetSpeed(stats.Date, stats.Date); err == nil && sp.Bursts > 0 {
+			todaySpeed = fmt.Sprintf("Today: %.0f WPM typing speed (p90 %.0f)", sp.MedianWPM, sp.P90WPM)
 		}
-		return
 	}
 
-	for i, item := range leaderboardItems {
-		if i < len(entries) {
-			entry := entries[i]
-			t, _ := time.Parse("2006-01-02", entry.Date)
-			medal := ""
-			switch entry.Rank {
-			case 1:
-				medal = "🥇 "
-			case 2:
-				medal = "🥈 "
-			case 3:
-				medal = "🥉 "
-			}
-			item.SetTitle(fmt.Sprintf("%s#%d: %s - %s", medal, entry.Rank, t.Format("Jan 2, 2006"), formatDistance(entry.TotalDistance)))
-			item.Show()
-		} else {
-			item.Hide()
-		}
-	}
+	// Update menu items
+	mTodayKeystrokes.SetTitle(fmt.Sprintf("Today: %s keystrokes (%s words)", report.FormatAbsolute(keystrokeCount), report.FormatAbsolute(todayWords)))
+	mTodayMouse.SetTitle(fmt.Sprintf("Today: 🖱️ %s clicks, %s distance", report.FormatAbsolute(todayClicks), units().FormatDistance(todayMouseDistance)))
+	mTodaySpeed.SetTitle(todaySpeed)
+	mWeekKeystrokes.SetTitle(fmt.Sprintf("This Week: %s keystrokes (%s words)", report.FormatAbsolute(weekKeystrokes), report.FormatAbsolute(weekWords)))
+	mWeekMouse.SetTitle(fmt.Sprintf("This Week: 🖱️ %s clicks, %s distance", report.FormatAbsolute(weekClicks), units().FormatDistance(weekMouseDistance)))
 }
 
 func showAbout() {
@@ -925,65 +1073,12 @@ func openFileNative(path string) bool {
 }
 
 func getLogDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	logDir := filepath.Join(home, ".local", "share", "typtel", "logs")
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return "", err
-	}
-	return logDir, nil
+	return paths.LogDir()
 }
 
-func formatAbsolute(n int64) string {
-	s := fmt.Sprintf("%d", n)
-	if n < 0 {
-		return s
-	}
-
-	result := ""
-	for i, c := range s {
-		if i > 0 && (len(s)-i)%3 == 0 {
-			result += ","
-		}
-		result += string(c)
-	}
-	return result
-}
-
-func formatDistance(pixels float64) string {
-	feet := mousetracker.PixelsToFeet(pixels)
-
-	unit := store.GetDistanceUnit()
-	switch unit {
-	case storage.DistanceUnitCars:
-		cars := feet / 15.0
-		if cars >= 1000 {
-			return fmt.Sprintf("%.1fk cars", cars/1000)
-		} else if cars >= 1 {
-			return fmt.Sprintf("%.0f cars", cars)
-		}
-		return fmt.Sprintf("%.1f cars", cars)
-
-	case storage.DistanceUnitFrisbee:
-		fields := feet / 330.0
-		if fields >= 100 {
-			return fmt.Sprintf("%.0f fields", fields)
-		} else if fields >= 1 {
-			return fmt.Sprintf("%.1f fields", fields)
-		}
-		return fmt.Sprintf("%.2f fields", fields)
-
-	default:
-		if feet >= 5280 {
-			return fmt.Sprintf("%.1fmi", feet/5280)
-		} else if feet >= 1 {
-			return fmt.Sprintf("%.0fft", feet)
-		}
-		inches := feet * 12
-		return fmt.Sprintf("%.0fin", inches)
-	}
+// units reports distances in the user's chosen unit at the display's real PPI
+func units() report.Units {
+	return report.Units{PPI: mousetracker.GetAveragePPI(), Distance: store.GetDistanceUnit()}
 }
 
 func showLeaderboard() {
@@ -994,7 +1089,7 @@ func showLeaderboard() {
 			}
 		}()
 
-		htmlPath, err := generateLeaderboardHTML()
+		htmlPath, err := report.SaveLeaderboard(store, units())
 		if err != nil {
 			log.Printf("Failed to generate leaderboard: %v", err)
 			showAlertDialog("Error", fmt.Sprintf("Failed to generate leaderboard: %v", err), []string{"OK"})
@@ -1008,155 +1103,6 @@ func showLeaderboard() {
 	}()
 }
 
-func generateLeaderboardHTML() (string, error) {
-	entries, err := store.GetMouseLeaderboard(30)
-	if err != nil {
-		return "", err
-	}
-
-	var rows strings.Builder
-	for _, entry := range entries {
-		t, _ := time.Parse("2006-01-02", entry.Date)
-		medal := ""
-		switch entry.Rank {
-		case 1:
-			medal = "🥇"
-		case 2:
-			medal = "🥈"
-		case 3:
-			medal = "🥉"
-		default:
-			medal = fmt.Sprintf("#%d", entry.Rank)
-		}
-		rows.WriteString(fmt.Sprintf(`
-			<tr>
-				<td class="rank">%s</td>
-				<td class="date">%s</td>
-				<td class="distance">%s</td>
-			</tr>`, medal, t.Format("Monday, Jan 2, 2006"), formatDistance(entry.TotalDistance)))
-	}
-
-	html := fmt.Sprintf(`<!DOCTYPE html>
-<html>
-<head>
-    <title>Typtel - Stillness Leaderboard</title>
-    <style>
-        * { margin: 0; padding: 0; box-sizing: border-box; }
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-            background: linear-gradient(135deg, #1a1a2e 0%%, #16213e 100%%);
-            color: #eee;
-            min-height: 100vh;
-            padding: 30px;
-        }
-        h1 {
-            text-align: center;
-            margin-bottom: 10px;
-            font-size: 2.5em;
-            background: linear-gradient(90deg, #ff6b6b, #feca57);
-            -webkit-background-clip: text;
-            -webkit-text-fill-color: transparent;
-        }
-        .subtitle {
-            text-align: center;
-            color: #888;
-            margin-bottom: 30px;
-            font-size: 1.1em;
-        }
-        .leaderboard-container {
-            max-width: 800px;
-            margin: 0 auto;
-            background: rgba(255,255,255,0.05);
-            border-radius: 16px;
-            padding: 25px;
-            backdrop-filter: blur(10px);
-            border: 1px solid rgba(255,255,255,0.1);
-        }
-        table {
-            width: 100%%;
-            border-collapse: collapse;
-        }
-        th {
-            text-align: left;
-            padding: 15px;
-            border-bottom: 2px solid rgba(255,255,255,0.2);
-            color: #888;
-            font-size: 0.9em;
-            text-transform: uppercase;
-        }
-        td {
-            padding: 15px;
-            border-bottom: 1px solid rgba(255,255,255,0.05);
-        }
-        tr:hover {
-            background: rgba(255,255,255,0.05);
-        }
-        .rank {
-            font-size: 1.5em;
-            width: 80px;
-        }
-        .date {
-            color: #aaa;
-        }
-        .distance {
-            text-align: right;
-            font-weight: bold;
-            background: linear-gradient(90deg, #00d2ff, #3a7bd5);
-            -webkit-background-clip: text;
-            -webkit-text-fill-color: transparent;
-        }
-        .explanation {
-            margin-top: 30px;
-            padding: 20px;
-            background: rgba(255,255,255,0.03);
-            border-radius: 10px;
-            color: #888;
-            font-size: 0.9em;
-            line-height: 1.6;
-        }
-    </style>
-</head>
-<body>
-    <h1>🧘 Stillness Leaderboard</h1>
-    <p class="subtitle">Days You Didn't Move The Mouse (Much)</p>
-
-    <div class="leaderboard-container">
-        <table>
-            <thead>
-                <tr>
-                    <th>Rank</th>
-                    <th>Date</th>
-                    <th>Distance</th>
-                </tr>
-            </thead>
-            <tbody>
-                %s
-            </tbody>
-        </table>
-
-        <div class="explanation">
-            <strong>What is this?</strong><br>
-            This leaderboard tracks the days when you moved your mouse the least. Less mouse movement
-            could indicate focused keyboard work, reading, or meditation sessions. The distance is
-            calculated as the total Euclidean distance your cursor traveled throughout the day,
-            converted to approximate real-world measurements in feet (assuming ~100 DPI display).
-        </div>
-    </div>
-</body>
-</html>`, rows.String())
-
-	dataDir, err := getLogDir()
-	if err != nil {
-		return "", err
-	}
-	htmlPath := filepath.Join(dataDir, "leaderboard.html")
-	if err := os.WriteFile(htmlPath, []byte(html), 0644); err != nil {
-		return "", err
-	}
-
-	return htmlPath, nil
-}
-
 func openCharts() {
 	go func() {
 		defer func() {
@@ -1165,7 +1111,7 @@ func openCharts() {
 			}
 		}()
 
-		htmlPath, err := generateChartsHTML()
+		htmlPath, err := report.SaveCharts(store, units(), report.ChartOptions{})
 		if err != nil {
 			log.Printf("Failed to generate charts: %v", err)
 			showAlertDialog("Error", fmt.Sprintf("Failed to generate charts: %v", err), []string{"OK"})
@@ -1178,521 +1124,3 @@ func openCharts() {
 		}
 	}()
 }
-
-func generateChartsHTML() (string, error) {
-	prepareChartData := func(days int) (labels, keystrokeData, wordData []string, mouseDataFeet []float64, totalKeystrokes, totalWords int64, totalMouseDistance float64, heatmapHTML string, err error) {
-		histStats, err := store.GetHistoricalStats(days)
-		if err != nil {
-			return nil, nil, nil, nil, 0, 0, 0, "", err
-		}
-
-		mouseStats, err := store.GetMouseHistoricalStats(days)
-		if err != nil {
-			return nil, nil, nil, nil, 0, 0, 0, "", err
-		}
-
-		hourlyData, err := store.GetAllHourlyStatsForDays(days)
-		if err != nil {
-			return nil, nil, nil, nil, 0, 0, 0, "", err
-		}
-
-		for i, stat := range histStats {
-			t, _ := time.Parse("2006-01-02", stat.Date)
-			labels = append(labels, fmt.Sprintf("'%s'", t.Format("Jan 2")))
-			keystrokeData = append(keystrokeData, fmt.Sprintf("%d", stat.Keystrokes))
-			wordData = append(wordData, fmt.Sprintf("%d", stat.Words))
-			totalKeystrokes += stat.Keystrokes
-			totalWords += stat.Words
-
-			if i < len(mouseStats) {
-				feet := mousetracker.PixelsToFeet(mouseStats[i].TotalDistance)
-				mouseDataFeet = append(mouseDataFeet, feet)
-				totalMouseDistance += mouseStats[i].TotalDistance
-			} else {
-				mouseDataFeet = append(mouseDataFeet, 0)
-			}
-		}
-
-		heatmapHTML = generateHeatmapHTML(hourlyData, days)
-		return
-	}
-
-	weeklyLabels, weeklyKeystrokes, weeklyWords, weeklyMouseFeet, weeklyTotalKeys, weeklyTotalWords, weeklyTotalMouse, weeklyHeatmap, err := prepareChartData(7)
-	if err != nil {
-		return "", err
-	}
-
-	monthlyLabels, monthlyKeystrokes, monthlyWords, monthlyMouseFeet, monthlyTotalKeys, monthlyTotalWords, monthlyTotalMouse, monthlyHeatmap, err := prepareChartData(30)
-	if err != nil {
-		return "", err
-	}
-
-	formatMouseData := func(feetData []float64, divisor float64) string {
-		var result []string
-		for _, f := range feetData {
-			result = append(result, fmt.Sprintf("%.2f", f/divisor))
-		}
-		return strings.Join(result, ",")
-	}
-
-	weeklyMouseFeetStr := formatMouseData(weeklyMouseFeet, 1.0)
-	weeklyMouseCarsStr := formatMouseData(weeklyMouseFeet, 15.0)
-	weeklyMouseFieldsStr := formatMouseData(weeklyMouseFeet, 330.0)
-	monthlyMouseFeetStr := formatMouseData(monthlyMouseFeet, 1.0)
-	monthlyMouseCarsStr := formatMouseData(monthlyMouseFeet, 15.0)
-	monthlyMouseFieldsStr := formatMouseData(monthlyMouseFeet, 330.0)
-
-	html := fmt.Sprintf(`<!DOCTYPE html>
-<html>
-<head>
-    <title>Typtel - Typing Statistics</title>
-    <script src="https://cdn.jsdelivr.net/npm/chart.js"></script>
-    <style>
-        * { margin: 0; padding: 0; box-sizing: border-box; }
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-            background: linear-gradient(135deg, #1a1a2e 0%%, #16213e 100%%);
-            color: #eee;
-            min-height: 100vh;
-            padding: 30px;
-        }
-        h1 {
-            text-align: center;
-            margin-bottom: 10px;
-            font-size: 2.5em;
-            background: linear-gradient(90deg, #00d2ff, #3a7bd5);
-            -webkit-background-clip: text;
-            -webkit-text-fill-color: transparent;
-        }
-        .controls {
-            display: flex;
-            justify-content: center;
-            gap: 20px;
-            margin-bottom: 30px;
-        }
-        .control-group {
-            display: flex;
-            align-items: center;
-            gap: 10px;
-        }
-        .control-group label {
-            color: #888;
-            font-size: 0.9em;
-        }
-        select {
-            background: rgba(255,255,255,0.1);
-            border: 1px solid rgba(255,255,255,0.2);
-            border-radius: 8px;
-            color: #eee;
-            padding: 8px 16px;
-            font-size: 0.9em;
-            cursor: pointer;
-        }
-        select:hover {
-            background: rgba(255,255,255,0.15);
-        }
-        .charts-container {
-            display: grid;
-            grid-template-columns: 1fr 1fr;
-            gap: 30px;
-            max-width: 1400px;
-            margin: 0 auto 40px;
-        }
-        .chart-box {
-            background: rgba(255,255,255,0.05);
-            border-radius: 16px;
-            padding: 25px;
-            backdrop-filter: blur(10px);
-            border: 1px solid rgba(255,255,255,0.1);
-        }
-        .chart-box h2 {
-            margin-bottom: 20px;
-            font-size: 1.3em;
-            color: #aaa;
-        }
-        .heatmap-container {
-            max-width: 1400px;
-            margin: 0 auto;
-        }
-        .heatmap-box {
-            background: rgba(255,255,255,0.05);
-            border-radius: 16px;
-            padding: 25px;
-            backdrop-filter: blur(10px);
-            border: 1px solid rgba(255,255,255,0.1);
-        }
-        .heatmap-box h2 {
-            margin-bottom: 20px;
-            font-size: 1.3em;
-            color: #aaa;
-        }
-        .heatmap {
-            display: flex;
-            flex-direction: column;
-            gap: 3px;
-        }
-        .heatmap-row {
-            display: flex;
-            align-items: center;
-            gap: 3px;
-        }
-        .heatmap-label {
-            width: 70px;
-            font-size: 11px;
-            color: #888;
-            text-align: right;
-            padding-right: 10px;
-        }
-        .heatmap-cell {
-            width: 20px;
-            height: 20px;
-            border-radius: 3px;
-            transition: transform 0.2s;
-        }
-        .heatmap-cell:hover {
-            transform: scale(1.3);
-            z-index: 10;
-        }
-        .hour-labels {
-            display: flex;
-            gap: 3px;
-            margin-left: 80px;
-            margin-bottom: 5px;
-        }
-        .hour-label {
-            width: 20px;
-            font-size: 10px;
-            color: #666;
-            text-align: center;
-        }
-        .legend {
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            gap: 8px;
-            margin-top: 20px;
-        }
-        .legend-text { color: #666; font-size: 12px; }
-        .legend-box {
-            width: 15px;
-            height: 15px;
-            border-radius: 2px;
-        }
-        .stats-summary {
-            display: flex;
-            justify-content: center;
-            gap: 40px;
-            margin: 30px 0;
-        }
-        .stat-item {
-            text-align: center;
-        }
-        .stat-value {
-            font-size: 2.5em;
-            font-weight: bold;
-            background: linear-gradient(90deg, #00d2ff, #3a7bd5);
-            -webkit-background-clip: text;
-            -webkit-text-fill-color: transparent;
-        }
-        .stat-label {
-            color: #888;
-            font-size: 0.9em;
-        }
-    </style>
-</head>
-<body>
-    <h1>Typtel Statistics</h1>
-
-    <div class="controls">
-        <div class="control-group">
-            <label>Time Period:</label>
-            <select id="periodSelect" onchange="updateCharts()">
-                <option value="weekly">Weekly (7 days)</option>
-                <option value="monthly">Monthly (30 days)</option>
-            </select>
-        </div>
-        <div class="control-group">
-            <label>Distance Unit:</label>
-            <select id="unitSelect" onchange="updateCharts()">
-                <option value="feet">Feet</option>
-                <option value="cars">Car Lengths (~15ft)</option>
-                <option value="fields">Frisbee Fields (~330ft)</option>
-            </select>
-        </div>
-    </div>
-
-    <div class="stats-summary">
-        <div class="stat-item">
-            <div class="stat-value" id="totalKeystrokes">-</div>
-            <div class="stat-label">Total Keystrokes</div>
-        </div>
-        <div class="stat-item">
-            <div class="stat-value" id="totalWords">-</div>
-            <div class="stat-label">Words</div>
-        </div>
-        <div class="stat-item">
-            <div class="stat-value" id="avgKeystrokes">-</div>
-            <div class="stat-label">Avg Keystrokes/Day</div>
-        </div>
-        <div class="stat-item">
-            <div class="stat-value" id="totalMouse">-</div>
-            <div class="stat-label">Mouse Distance</div>
-        </div>
-    </div>
-
-    <div class="charts-container">
-        <div class="chart-box">
-            <h2>Keystrokes per Day</h2>
-            <canvas id="keystrokesChart"></canvas>
-        </div>
-        <div class="chart-box">
-            <h2>Words per Day</h2>
-            <canvas id="wordsChart"></canvas>
-        </div>
-    </div>
-
-    <div class="charts-container">
-        <div class="chart-box" style="grid-column: span 2;">
-            <h2 id="mouseChartTitle">Mouse Distance per Day</h2>
-            <canvas id="mouseChart"></canvas>
-        </div>
-    </div>
-
-    <div class="heatmap-container">
-        <div class="heatmap-box">
-            <h2>Activity Heatmap (Hourly)</h2>
-            <div class="hour-labels">
-                %s
-            </div>
-            <div class="heatmap" id="heatmapContainer">
-            </div>
-            <div class="legend">
-                <span class="legend-text">Less</span>
-                <div class="legend-box" style="background: #1a1a2e;"></div>
-                <div class="legend-box" style="background: #2d4a3e;"></div>
-                <div class="legend-box" style="background: #3d6b4f;"></div>
-                <div class="legend-box" style="background: #5a9a6f;"></div>
-                <div class="legend-box" style="background: #7bc96f;"></div>
-                <span class="legend-text">More</span>
-            </div>
-        </div>
-    </div>
-
-    <script>
-        const data = {
-            weekly: {
-                labels: [%s],
-                keystrokes: [%s],
-                words: [%s],
-                mouse: { feet: [%s], cars: [%s], fields: [%s] },
-                totalKeystrokes: %d,
-                totalWords: %d,
-                totalMouseFeet: %.2f,
-                days: 7,
-                heatmap: `+"`%s`"+`
-            },
-            monthly: {
-                labels: [%s],
-                keystrokes: [%s],
-                words: [%s],
-                mouse: { feet: [%s], cars: [%s], fields: [%s] },
-                totalKeystrokes: %d,
-                totalWords: %d,
-                totalMouseFeet: %.2f,
-                days: 30,
-                heatmap: `+"`%s`"+`
-            }
-        };
-
-        const unitLabels = { feet: 'feet', cars: 'car lengths', fields: 'frisbee fields' };
-
-        let keystrokesChart, wordsChart, mouseChart;
-
-        const chartConfig = {
-            responsive: true,
-            plugins: { legend: { display: false } },
-            scales: {
-                y: { beginAtZero: true, grid: { color: 'rgba(255,255,255,0.1)' }, ticks: { color: '#888' } },
-                x: { grid: { display: false }, ticks: { color: '#888' } }
-            }
-        };
-
-        function formatNumber(n) {
-            if (n >= 1000000) return (n/1000000).toFixed(1) + 'M';
-            if (n >= 1000) return (n/1000).toFixed(1) + 'K';
-            return n.toString();
-        }
-
-        function formatDistance(feet) {
-            if (feet >= 5280) return (feet/5280).toFixed(2) + ' mi';
-            return feet.toFixed(0) + ' ft';
-        }
-
-        function updateCharts() {
-            const period = document.getElementById('periodSelect').value;
-            const unit = document.getElementById('unitSelect').value;
-            const d = data[period];
-
-            document.getElementById('totalKeystrokes').textContent = formatNumber(d.totalKeystrokes);
-            document.getElementById('totalWords').textContent = formatNumber(d.totalWords);
-            document.getElementById('avgKeystrokes').textContent = formatNumber(Math.round(d.totalKeystrokes / d.days));
-            document.getElementById('totalMouse').textContent = formatDistance(d.totalMouseFeet);
-
-            document.getElementById('mouseChartTitle').textContent = 'Mouse Distance per Day (' + unitLabels[unit] + ')';
-
-            if (keystrokesChart) keystrokesChart.destroy();
-            if (wordsChart) wordsChart.destroy();
-            if (mouseChart) mouseChart.destroy();
-
-            keystrokesChart = new Chart(document.getElementById('keystrokesChart'), {
-                type: 'bar',
-                data: {
-                    labels: d.labels,
-                    datasets: [{ data: d.keystrokes, backgroundColor: 'rgba(0, 210, 255, 0.6)', borderColor: 'rgba(0, 210, 255, 1)', borderWidth: 1, borderRadius: 4 }]
-                },
-                options: chartConfig
-            });
-
-            wordsChart = new Chart(document.getElementById('wordsChart'), {
-                type: 'line',
-                data: {
-                    labels: d.labels,
-                    datasets: [{ data: d.words, borderColor: 'rgba(122, 201, 111, 1)', backgroundColor: 'rgba(122, 201, 111, 0.2)', fill: true, tension: 0.4, pointRadius: 4, pointBackgroundColor: 'rgba(122, 201, 111, 1)' }]
-                },
-                options: chartConfig
-            });
-
-            mouseChart = new Chart(document.getElementById('mouseChart'), {
-                type: 'bar',
-                data: {
-                    labels: d.labels,
-                    datasets: [{ data: d.mouse[unit], backgroundColor: 'rgba(255, 107, 107, 0.6)', borderColor: 'rgba(255, 107, 107, 1)', borderWidth: 1, borderRadius: 4 }]
-                },
-                options: chartConfig
-            });
-
-            document.getElementById('heatmapContainer').innerHTML = d.heatmap;
-        }
-
-        updateCharts();
-    </script>
-</body>
-</html>`,
-		generateHourLabels(),
-		strings.Join(weeklyLabels, ","),
-		strings.Join(weeklyKeystrokes, ","),
-		strings.Join(weeklyWords, ","),
-		weeklyMouseFeetStr,
-		weeklyMouseCarsStr,
-		weeklyMouseFieldsStr,
-		weeklyTotalKeys,
-		weeklyTotalWords,
-		mousetracker.PixelsToFeet(float64(weeklyTotalMouse)),
-		weeklyHeatmap,
-		strings.Join(monthlyLabels, ","),
-		strings.Join(monthlyKeystrokes, ","),
-		strings.Join(monthlyWords, ","),
-		monthlyMouseFeetStr,
-		monthlyMouseCarsStr,
-		monthlyMouseFieldsStr,
-		monthlyTotalKeys,
-		monthlyTotalWords,
-		mousetracker.PixelsToFeet(float64(monthlyTotalMouse)),
-		monthlyHeatmap,
-	)
-
-	dataDir, err := getLogDir()
-	if err != nil {
-		return "", err
-	}
-	htmlPath := filepath.Join(dataDir, "charts.html")
-	if err := os.WriteFile(htmlPath, []byte(html), 0644); err != nil {
-		return "", err
-	}
-
-	return htmlPath, nil
-}
-
-func generateHourLabels() string {
-	var labels []string
-	for h := 0; h < 24; h++ {
-		if h%3 == 0 {
-			labels = append(labels, fmt.Sprintf(`<div class="hour-label">%d</div>`, h))
-		} else {
-			labels = append(labels, `<div class="hour-label"></div>`)
-		}
-	}
-	return strings.Join(labels, "\n                ")
-}
-
-func generateHeatmapHTML(hourlyData map[string][]HourlyStats, days int) string {
-	var maxVal int64 = 1
-	for _, hours := range hourlyData {
-		for _, h := range hours {
-			if h.Keystrokes > maxVal {
-				maxVal = h.Keystrokes
-			}
-		}
-	}
-
-	dates := make([]string, 0, len(hourlyData))
-	for date := range hourlyData {
-		dates = append(dates, date)
-	}
-	sort.Strings(dates)
-
-	var rows []string
-	for _, date := range dates {
-		hours := hourlyData[date]
-		t, _ := time.Parse("2006-01-02", date)
-		dateLabel := t.Format("Mon Jan 2")
-
-		var cells []string
-		for _, h := range hours {
-			color := getHeatmapColor(h.Keystrokes, maxVal)
-			title := fmt.Sprintf("%s %d:00 - %d keystrokes", dateLabel, h.Hour, h.Keystrokes)
-			cells = append(cells, fmt.Sprintf(
-				`<div class="heatmap-cell" style="background: %s;" title="%s"></div>`,
-				color, title,
-			))
-		}
-
-		rows = append(rows, fmt.Sprintf(
-			`<div class="heatmap-row"><div class="heatmap-label">%s</div>%s</div>`,
-			dateLabel,
-			strings.Join(cells, ""),
-		))
-	}
-
-	return strings.Join(rows, "\n                ")
-}
-
-func getHeatmapColor(value, max int64) string {
-	if value == 0 {
-		return "#1a1a2e"
-	}
-	ratio := float64(value) / float64(max)
-	if ratio < 0.25 {
-		return "#2d4a3e"
-	} else if ratio < 0.5 {
-		return "#3d6b4f"
-	} else if ratio < 0.75 {
-		return "#5a9a6f"
-	}
-	return "#7bc96f"
-}
-
-type HourlyStats = storage.HourlyStats
-
-func isWordBoundary(keycode int) bool {
-	switch keycode {
-	case 49: // Space
-		return true
-	case 36: // Return/Enter
-		return true
-	case 48: // Tab
-		return true
-	default:
-		return false
-	}
-}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var achievementsCmd = &cobra.Command{
+	Use:   "achievements",
+	Short: "Show unlocked achievements and progress towards the rest",
+	Long: `Show achievements: milestones in your typing history such as a million
+keystrokes, a 100 WPM typing test, a week of barely touching the mouse, a
+30-day goal streak or a night owl session.
+
+The menu bar app unlocks achievements as they're met and records when; this
+command also records any met since it last checked.
+
+Examples:
+  typtel achievements
+  typtel achievements --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showAchievements()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(achievementsCmd)
+}
+
+func showAchievements() error {
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	feet := report.Units{}.Feet
+	if _, err := store.UnlockAchievements(feet, time.Now()); err != nil {
+		return err
+	}
+	statuses, err := store.GetAchievements(feet)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return printJSON("achievements", statuses)
+	}
+
+	unlocked := 0
+	for _, s := range statuses {
+		if s.Unlocked {
+			unlocked++
+		}
+	}
+	fmt.Printf("🏆 Achievements (%d of %d)\n", unlocked, len(statuses))
+	fmt.Println("────────────────────")
+	for _, s := range statuses {
+		fmt.Printf("%s %-14s %-22s %s\n", achievementIcon(s), s.Name, achievementState(s), s.Description)
+	}
+	return nil
+}
+
+// achievementIcon shows locked achievements with a padlock
+func achievementIcon(s achievements.Status) string {
+	if s.Unlocked {
+		return s.Icon
+	}
+	return "🔒"
+}
+
+// achievementState says when an achievement was unlocked, or how far along
+// it is
+func achievementState(s achievements.Status) string {
+	switch {
+	case s.UnlockedAt != nil:
+		return "unlocked " + s.UnlockedAt.Local().Format("Jan 2, 2006")
+	case s.Unlocked:
+		return "unlocked"
+	case s.Value == nil:
+		return "not started"
+	}
+	return fmt.Sprintf("%.0f%% there", s.Progress*100)
+}
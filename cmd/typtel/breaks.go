@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/breaks"
+	"github.com/aayushbajaj/typing-telemetry/internal/notify"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	"github.com/aayushbajaj/typing-telemetry/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+// Flags for breaks command
+var breaksDays int
+
+var breaksCmd = &cobra.Command{
+	Use:   "breaks",
+	Short: "Show how often break reminders were taken or skipped",
+	Long: `Show how often break reminders were heeded.
+
+With breaks.enabled set, the menu bar app watches keyboard and mouse
+activity and reminds you to rest:
+
+  break        breaks.break_minutes away after breaks.break_after_minutes
+               of activity without one (5 every 50 by default)
+  micro-pause  breaks.micropause_seconds off the keyboard after
+               breaks.micropause_keystrokes without one (off by default)
+
+Any pause of that length resets the count, reminded or not. A reminder
+counts as taken if the pause follows, and as skipped if you're still going
+when the pause would have been over. Reminders go to breaks.notifier:
+desktop, bell or webhook (POSTing JSON to breaks.webhook_url). Changes take
+effect the next time the menu bar app starts.
+
+Examples:
+  typtel config set breaks.enabled true
+  typtel config set breaks.micropause_keystrokes 300
+  typtel breaks --days 30
+  typtel breaks test`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showBreaks(breaksDays)
+	},
+}
+
+var breaksTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test reminder with the configured notifier",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return testBreakNotifier()
+	},
+}
+
+func init() {
+	breaksCmd.Flags().IntVarP(&breaksDays, "days", "d", 7, "Number of days to include, ending today")
+	breaksCmd.AddCommand(breaksTestCmd)
+	rootCmd.AddCommand(breaksCmd)
+}
+
+// breaksReport is the output of 'typtel breaks --json'
+type breaksReport struct {
+	Range   reportRange          `json:"range"`
+	Enabled bool                 `json:"enabled"`
+	Rules   []breakRule          `json:"rules"`
+	Stats   []storage.BreakStats `json:"stats"` // kinds with any reminders
+}
+
+// breakRule is a break rule in 'typtel breaks --json'
+type breakRule struct {
+	Kind          breaks.Kind `json:"kind"`
+	ActiveMinutes float64     `json:"active_minutes,omitempty"`
+	Keystrokes    int         `json:"keystrokes,omitempty"`
+	LengthSeconds float64     `json:"length_seconds"`
+}
+
+func showBreaks(days int) error {
+	if days < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	today, _ := time.Parse("2006-01-02", store.Today())
+	r := stats.Range{From: today.AddDate(0, 0, -(days - 1)), To: today}
+	counts, err := store.GetBreakStats(r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("failed to get breaks: %w", err)
+	}
+
+	cfg := store.Config().Breaks
+	rep := breaksReport{Range: newReportRange(r), Enabled: cfg.Enabled, Rules: []breakRule{}, Stats: []storage.BreakStats{}}
+	for _, rule := range breaks.Rules(cfg) {
+		rep.Rules = append(rep.Rules, breakRule{
+			Kind:          rule.Kind,
+			ActiveMinutes: rule.Active.Minutes(),
+			Keystrokes:    rule.Keystrokes,
+			LengthSeconds: rule.Length.Seconds(),
+		})
+	}
+	rep.Stats = append(rep.Stats, counts...)
+
+	if jsonOutput {
+		return printJSON("breaks", rep)
+	}
+
+	fmt.Println("🧘 Breaks")
+	fmt.Println("────────────────────")
+	fmt.Println(formatRange(r))
+	fmt.Println()
+	if !cfg.Enabled {
+		fmt.Println("Break reminders are off; turn them on with 'typtel config set breaks.enabled true'")
+	} else {
+		for _, rule := range breaks.Rules(cfg) {
+			fmt.Println(breaks.Reminder(rule).Title + ": " + describeRule(rule))
+		}
+	}
+	if len(counts) == 0 {
+		fmt.Println("No break reminders yet")
+		return nil
+	}
+
+	fmt.Println()
+	for _, c := range counts {
+		fmt.Printf("%-12s %4d taken  %4d skipped  (%.0f%% taken)\n", kindLabel(c.Kind), c.Taken, c.Skipped, c.TakenRate()*100)
+	}
+	return nil
+}
+
+// describeRule says when a rule's pause is due
+func describeRule(r breaks.Rule) string {
+	if r.Keystrokes > 0 {
+		return fmt.Sprintf("%s off the keyboard after %d keystrokes without one", r.Length, r.Keystrokes)
+	}
+	return fmt.Sprintf("%s away after %s of activity without one", formatMinutes(r.Length.Minutes()), formatMinutes(r.Active.Minutes()))
+}
+
+// kindLabel names a kind of break for display
+func kindLabel(k breaks.Kind) string {
+	if k == breaks.KindMicroPause {
+		return "Micro-pauses"
+	}
+	return "Breaks"
+}
+
+func testBreakNotifier() error {
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	cfg := store.Config().Breaks
+	n, err := notify.NewNotifier(cfg, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if err := n.Notify(notify.Notification{
+		Kind:    string(breaks.KindBreak),
+		Title:   "typtel",
+		Message: "Break reminders will look like this.",
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("Sent a test reminder with the %s notifier\n", cfg.Notifier)
+	return nil
+}
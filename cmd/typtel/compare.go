@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	"github.com/aayushbajaj/typing-telemetry/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+// Flags for stats compare
+var comparePeriod string
+
+var statsCompareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare this week, month or year with the previous one",
+	Long: `Compare the current period so far with the same stretch of the previous
+one, e.g. Monday to today of this week and of last week.
+
+Changes marked * are notable: the daily values differ by more than ordinary
+day-to-day variation (Welch's t-test, |t| >= 2).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showComparison(comparePeriod)
+	},
+}
+
+func init() {
+	statsCompareCmd.Flags().StringVarP(&comparePeriod, "period", "p", stats.PeriodWeek, "Period to compare: week, month or year")
+	statsCmd.AddCommand(statsCompareCmd)
+}
+
+// periodSeries holds the daily values of one period, oldest first
+type periodSeries struct {
+	keystrokes  []float64
+	words       []float64
+	clicks      []float64
+	mouse       []float64 // pixels
+	activeHours []float64
+	hours       [24]int64 // keystrokes per hour of day over the whole period
+}
+
+func loadPeriodSeries(store *storage.Store, r stats.Range) (*periodSeries, error) {
+	from, to := r.From.Format("2006-01-02"), r.To.Format("2006-01-02")
+
+	n := r.Days()
+	s := &periodSeries{
+		keystrokes:  make([]float64, n),
+		words:       make([]float64, n),
+		clicks:      make([]float64, n),
+		mouse:       make([]float64, n),
+		activeHours: make([]float64, n),
+	}
+	index := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		index[r.From.AddDate(0, 0, i).Format("2006-01-02")] = i
+	}
+
+	daily, err := store.GetDailyRange(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily stats: %w", err)
+	}
+	for _, d := range daily {
+		if i, ok := index[d.Date]; ok {
+			s.keystrokes[i] = float64(d.Keystrokes)
+			s.words[i] = float64(d.Words)
+		}
+	}
+
+	mouse, err := store.GetMouseRange(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mouse stats: %w", err)
+	}
+	for _, m := range mouse {
+		if i, ok := index[m.Date]; ok {
+			s.clicks[i] = float64(m.ClickCount)
+			s.mouse[i] = m.TotalDistance
+		}
+	}
+
+	hourly, err := store.GetHourlyRange(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hourly stats: %w", err)
+	}
+	for _, h := range hourly {
+		i, ok := index[h.Date]
+		if !ok || h.Hour < 0 || h.Hour > 23 || h.Keystrokes == 0 {
+			continue
+		}
+		s.activeHours[i]++
+		s.hours[h.Hour] += h.Keystrokes
+	}
+
+	return s, nil
+}
+
+func showComparison(period string) error {
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	today, _ := time.Parse("2006-01-02", store.Today())
+	current, previous, err := stats.PeriodRanges(period, today)
+	if err != nil {
+		return err
+	}
+
+	cur, err := loadPeriodSeries(store, current)
+	if err != nil {
+		return err
+	}
+	prev, err := loadPeriodSeries(store, previous)
+	if err != nil {
+		return err
+	}
+
+	prevPeak, prevCount := stats.FindPeakHour(prev.hours[:])
+	curPeak, curCount := stats.FindPeakHour(cur.hours[:])
+	comparisons := []stats.Comparison{
+		stats.Compare("keystrokes", prev.keystrokes, cur.keystrokes),
+		stats.Compare("words", prev.words, cur.words),
+		stats.Compare("clicks", prev.clicks, cur.clicks),
+		stats.Compare("mouse_distance_px", prev.mouse, cur.mouse),
+		stats.Compare("active_hours", prev.activeHours, cur.activeHours),
+	}
+
+	if jsonOutput {
+		r := compareReport{
+			Period:   period,
+			Current:  newReportRange(current),
+			Previous: newReportRange(previous),
+			Metrics:  comparisons,
+		}
+		if prevCount > 0 {
+			r.PeakHour.Previous = &prevPeak
+		}
+		if curCount > 0 {
+			r.PeakHour.Current = &curPeak
+		}
+		return printJSON("stats compare", r)
+	}
+
+	units := report.Units{Distance: store.GetDistanceUnit()}
+	count := func(v float64) string { return formatNum(int64(math.Round(v))) }
+	rows := []struct {
+		label  string
+		format func(float64) string
+	}{
+		{"Keystrokes", count},
+		{"Words", count},
+		{"Clicks", count},
+		{"Mouse", units.FormatDistance},
+		{"Active hours", func(v float64) string { return fmt.Sprintf("%.0fh", v) }},
+	}
+
+	prevLabel, curLabel := "Last "+period, "This "+period
+	fmt.Printf("📊 %s vs %s\n", curLabel, strings.ToLower(prevLabel))
+	fmt.Println("────────────────────")
+	fmt.Printf("%s: %s\n", curLabel, formatRange(current))
+	fmt.Printf("%s: %s\n\n", prevLabel, formatRange(previous))
+
+	fmt.Printf("%-13s %12s %12s   %s\n", "", prevLabel, curLabel, "Change")
+	var notable bool
+	for i, c := range comparisons {
+		row := rows[i]
+		change := formatChange(c, row.format)
+		if c.Notable {
+			change += " *"
+			notable = true
+		}
+		fmt.Printf("%-13s %12s %12s   %s\n", row.label, row.format(c.Previous), row.format(c.Current), change)
+	}
+
+	fmt.Printf("%-13s %12s %12s   %s\n", "Peak hour", formatPeakHour(prevPeak, prevCount), formatPeakHour(curPeak, curCount), formatPeakShift(prevPeak, prevCount, curPeak, curCount))
+
+	if notable {
+		fmt.Println("\n* notable: daily values changed beyond day-to-day variation")
+	}
+	return nil
+}
+
+// compareReport is the output of 'typtel stats compare --json'. Mouse
+// distance is in pixels.
+type compareReport struct {
+	Period   string             `json:"period"`
+	Current  reportRange        `json:"current"`
+	Previous reportRange        `json:"previous"`
+	Metrics  []stats.Comparison `json:"metrics"`
+	PeakHour struct {
+		Previous *int `json:"previous"` // null without activity
+		Current  *int `json:"current"`
+	} `json:"peak_hour"`
+}
+
+// reportRange is an inclusive range of days in JSON output
+type reportRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Days int    `json:"days"`
+}
+
+func newReportRange(r stats.Range) reportRange {
+	return reportRange{From: r.From.Format("2006-01-02"), To: r.To.Format("2006-01-02"), Days: r.Days()}
+}
+
+// formatRange shows an inclusive range of days
+func formatRange(r stats.Range) string {
+	if r.Days() == 1 {
+		return r.From.Format("Mon Jan 2, 2006")
+	}
+	return fmt.Sprintf("%s – %s (%d days)", r.From.Format("Mon Jan 2"), r.To.Format("Mon Jan 2, 2006"), r.Days())
+}
+
+// formatChange shows the absolute and percentage change with an arrow for
+// its direction
+func formatChange(c stats.Comparison, format func(float64) string) string {
+	if c.Change == 0 {
+		return "no change"
+	}
+
+	arrow, sign := "▲", "+"
+	if c.Change < 0 {
+		arrow, sign = "▼", "-"
+	}
+	s := fmt.Sprintf("%s %s%s", arrow, sign, format(math.Abs(c.Change)))
+	if c.HasPercent {
+		s += fmt.Sprintf(" (%+.1f%%)", c.Percent)
+	} else {
+		s += " (new)"
+	}
+	return s
+}
+
+func formatPeakHour(hour int, count int64) string {
+	if count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%02d:00", hour)
+}
+
+// formatPeakShift shows how far the busiest hour moved, taking the shorter
+// way around the clock
+func formatPeakShift(prevHour int, prevCount int64, curHour int, curCount int64) string {
+	if prevCount == 0 || curCount == 0 {
+		return ""
+	}
+	shift := (curHour - prevHour + 24) % 24
+	if shift > 12 {
+		shift -= 24
+	}
+	switch {
+	case shift == 0:
+		return "no change"
+	case shift > 0:
+		return fmt.Sprintf("%dh later", shift)
+	default:
+		return fmt.Sprintf("%dh earlier", -shift)
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// Where a setting's value comes from, lowest precedence first
+const (
+	sourceDefault = "default"
+	sourceFile    = "file"
+	sourceDB      = "db"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change settings",
+	Long: `Show and change typtel's settings.
+
+Settings are resolved in layers, each overriding the one before:
+  1. built-in defaults
+  2. the config file: --config, $TYPTEL_CONFIG, $TYPTEL_DATA_DIR/config.toml,
+     $XDG_CONFIG_HOME/typtel/config.toml or ~/.config/typtel/config.toml
+  3. values saved in the database by the menu bar app or 'typtel config set'
+
+'typtel config unset' removes a saved value so the config file applies again.
+
+Examples:
+  typtel config list
+  typtel config set mouse.distance_unit cars
+  typtel config unset mouse.distance_unit
+  typtel config edit`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every setting with its value and where it comes from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listConfig()
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a setting's value",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return getConfig(args[0])
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Save a setting in the database, overriding the config file",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setConfig(args[0], args[1])
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a saved setting so the config file or default applies",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return unsetConfig(args[0])
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in $EDITOR",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editConfig()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configEditCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func lookupKey(name string) (config.Key, error) {
+	k, ok := config.Lookup(name)
+	if !ok {
+		return config.Key{}, fmt.Errorf("unknown setting %q; valid settings: %s", name, strings.Join(config.Names(), ", "))
+	}
+	return k, nil
+}
+
+// configEntry is one setting in 'typtel config list'
+type configEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // "default", "file" or "db"
+}
+
+func listConfig() error {
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	overrides, err := store.ConfigOverrides()
+	if err != nil {
+		return fmt.Errorf("failed to read settings: %w", err)
+	}
+	file := store.ConfigFile()
+	cfg := store.Config()
+
+	entries := make([]configEntry, len(config.Keys))
+	var invalid []string
+	for i, k := range config.Keys {
+		source := sourceDefault
+		if file.Sets(k.Name) {
+			source = sourceFile
+		}
+		if val, ok := overrides[k.Name]; ok {
+			if scratch := cfg; k.Set(&scratch, val) == nil {
+				source = sourceDB
+			} else {
+				invalid = append(invalid, fmt.Sprintf("%s = %q", k.Name, val))
+			}
+		}
+		entries[i] = configEntry{Key: k.Name, Value: k.Get(cfg), Source: source}
+	}
+
+	if jsonOutput {
+		return printJSON("config list", struct {
+			File     string        `json:"file"`
+			Settings []configEntry `json:"settings"`
+		}{file.Path, entries})
+	}
+
+	fmt.Printf("Config file: %s", file.Path)
+	if _, err := os.Stat(file.Path); err != nil {
+		fmt.Print(" (not created)")
+	}
+	fmt.Println()
+	fmt.Println()
+
+	width := 0
+	for _, e := range entries {
+		width = max(width, len(e.Key))
+	}
+	for _, e := range entries {
+		fmt.Printf("%-*s  %-12s %s\n", width, e.Key, e.Value, e.Source)
+	}
+
+	if len(invalid) > 0 {
+		fmt.Printf("\nIgnored invalid saved values: %s\n", strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+func getConfig(name string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	fmt.Println(k.Get(store.Config()))
+	return nil
+}
+
+func setConfig(name, value string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+	cfg := config.Default()
+	if err := k.Set(&cfg, value); err != nil {
+		return err
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	before := store.Config().Goals
+	if err := store.SetSetting(k.Setting, k.Get(cfg)); err != nil {
+		return fmt.Errorf("failed to save %s: %w", k.Name, err)
+	}
+	if err := recordGoalChange(store, before); err != nil {
+		return err
+	}
+	fmt.Printf("%s = %s\n", k.Name, k.Get(cfg))
+	if k.Restart {
+		fmt.Println("Restart the menu bar app to apply.")
+	}
+	return nil
+}
+
+func unsetConfig(name string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	before := store.Config().Goals
+	if err := store.DeleteSetting(k.Setting); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", k.Name, err)
+	}
+	if err := recordGoalChange(store, before); err != nil {
+		return err
+	}
+
+	source := sourceDefault
+	if store.ConfigFile().Sets(k.Name) {
+		source = sourceFile
+	}
+	fmt.Printf("%s = %s (%s)\n", k.Name, k.Get(store.Config()), source)
+	if k.Restart {
+		fmt.Println("Restart the menu bar app to apply.")
+	}
+	return nil
+}
+
+// recordGoalChange adds a change of goals to the goal history, so it applies
+// from today on and earlier streaks keep the goals they were met against
+func recordGoalChange(store *storage.Store, before config.Goals) error {
+	after := store.Config().Goals
+	if after.Notify = before.Notify; after == before {
+		return nil
+	}
+	if err := store.RecordGoalChange(store.Today(), before, after); err != nil {
+		return fmt.Errorf("failed to record goal history: %w", err)
+	}
+	return nil
+}
+
+func editConfig() error {
+	path, err := configFilePath()
+	if err != nil {
+		return fmt.Errorf("failed to find config file: %w", err)
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(config.Template()), 0644); err != nil {
+			return fmt.Errorf("failed to create config file: %w", err)
+		}
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// $EDITOR may carry arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", editor, err)
+	}
+
+	file, err := config.Load(path)
+	if err != nil {
+		return fmt.Errorf("config file is invalid, run 'typtel config edit' to fix it: %w", err)
+	}
+
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	overrides, err := store.ConfigOverrides()
+	if err != nil {
+		return fmt.Errorf("failed to read settings: %w", err)
+	}
+	var shadowed []string
+	for _, name := range config.Names() {
+		if _, ok := overrides[name]; ok && file.Sets(name) {
+			shadowed = append(shadowed, name)
+		}
+	}
+	if len(shadowed) > 0 {
+		fmt.Printf("These settings are saved in the database and override the file: %s\n", strings.Join(shadowed, ", "))
+		fmt.Println("Run 'typtel config unset <key>' to use the file's value.")
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/leaderboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// Flags for leaderboard command
+var (
+	leaderboardLimit       int
+	leaderboardWords       int
+	leaderboardPunctuation bool
+)
+
+var leaderboardCmd = &cobra.Command{
+	Use:   "leaderboard [board]",
+	Short: "Rank your days, hours, sessions and typing tests",
+	Long: `Rank your best days, hours, sessions and typing tests. Boards:
+
+` + leaderboardList() + `
+Without a board, shows the days with the least mouse movement.
+
+Examples:
+  typtel leaderboard
+  typtel leaderboard keystrokes -n 5
+  typtel leaderboard test-wpm -w 25 -p
+  typtel leaderboard focus --json`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: leaderboard.IDs(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return showLeaderboard(leaderboardLimit)
+		}
+		b, err := leaderboard.Get(args[0])
+		if err != nil {
+			return err
+		}
+		opts := storage.LeaderboardOptions{Limit: leaderboardLimit}
+		if cmd.Flags().Changed("words") || cmd.Flags().Changed("punctuation") {
+			if b.Metric != leaderboard.TestWPM {
+				return fmt.Errorf("--words and --punctuation only apply to typing test boards")
+			}
+			opts.Mode = &storage.TypingTestMode{WordCount: leaderboardWords, Punctuation: leaderboardPunctuation}
+		}
+		return showBoard(b, opts)
+	},
+}
+
+func init() {
+	leaderboardCmd.Flags().IntVarP(&leaderboardLimit, "limit", "n", report.LeaderboardSize, "Number of entries to show")
+	leaderboardCmd.Flags().IntVarP(&leaderboardWords, "words", "w", 25, "Only typing tests of this many words")
+	leaderboardCmd.Flags().BoolVarP(&leaderboardPunctuation, "punctuation", "p", false, "Only typing tests with punctuation")
+	rootCmd.AddCommand(leaderboardCmd)
+}
+
+// leaderboardList describes each board for the help text
+func leaderboardList() string {
+	var b strings.Builder
+	for _, board := range leaderboard.All {
+		fmt.Fprintf(&b, "  %-14s %s\n", board.ID, board.Description)
+	}
+	return b.String()
+}
+
+// showLeaderboard prints the stillness board, as JSON in the form it has
+// always had
+func showLeaderboard(limit int) error {
+	if !jsonOutput {
+		return showBoard(leaderboard.Stillness, storage.LeaderboardOptions{Limit: limit})
+	}
+
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	entries, err := store.GetMouseLeaderboard(limit)
+	if err != nil {
+		return fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+	if entries == nil {
+		entries = []storage.MouseLeaderboardEntry{}
+	}
+	return printJSON("leaderboard", entries)
+}
+
+// boardReport is a leaderboard and its entries, best first
+type boardReport struct {
+	leaderboard.Board
+	Entries []storage.LeaderboardEntry `json:"entries"`
+}
+
+func showBoard(b leaderboard.Board, opts storage.LeaderboardOptions) error {
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	entries, err := store.GetLeaderboard(b, opts)
+	if err != nil {
+		return fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+
+	if jsonOutput {
+		if entries == nil {
+			entries = []storage.LeaderboardEntry{}
+		}
+		return printJSON("leaderboard "+b.ID, boardReport{Board: b, Entries: entries})
+	}
+
+	fmt.Printf("🏆 %s Leaderboard\n", b.Name)
+	fmt.Println(b.Description)
+	fmt.Println("────────────────────")
+	if len(entries) == 0 {
+		fmt.Println("Nothing to rank yet")
+		return nil
+	}
+
+	units := report.Units{Distance: store.GetDistanceUnit()}
+	for _, e := range entries {
+		medal := fmt.Sprintf("%-4s", report.Medal(e.Rank))
+		if e.Rank <= 3 {
+			medal = report.Medal(e.Rank) + "  " // medals are two columns wide
+		}
+		fmt.Printf("%s %-42s %s\n", medal, report.EntryLabel(e), units.FormatLeaderboardValue(b.Metric, e.Value))
+	}
+	return nil
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
+	"runtime"
 	"strings"
 	"time"
 
+	"github.com/aayushbajaj/typing-telemetry/internal/corrections"
+	"github.com/aayushbajaj/typing-telemetry/internal/export"
+	"github.com/aayushbajaj/typing-telemetry/internal/goals"
+	"github.com/aayushbajaj/typing-telemetry/internal/importer"
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/paths"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/statusbar"
 	"github.com/aayushbajaj/typing-telemetry/internal/storage"
 	"github.com/aayushbajaj/typing-telemetry/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,9 +25,31 @@ import (
 )
 
 var (
+	// Global flags
+	dbPath     string
+	configPath string
+
+	// Flags for today command
+	todayFormat string
+
 	// Flags for test command
 	testFile      string
 	testWordCount int
+
+	// Flags for view command
+	viewFrom   string
+	viewTo     string
+	viewLayout string
+
+	// Flags for export and import commands
+	exportFormat string
+	exportFrom   string
+	exportTo     string
+	exportTables string
+	exportOutput string
+	importFormat string
+	importSource string
+	importList   bool
 )
 
 var rootCmd = &cobra.Command{
@@ -41,8 +72,28 @@ var statsCmd = &cobra.Command{
 var todayCmd = &cobra.Command{
 	Use:   "today",
 	Short: "Show today's keystroke count (for menu bar)",
+	Long: `Show today's totals for status bars and scripts.
+
+--format takes a preset or a Go template. Presets:
+  plain      Keystroke count only (default)
+  waybar     JSON with text, tooltip and class ("idle" or "active")
+  polybar    The menu bar title
+  i3blocks   The menu bar title, then the short keystroke count
+  tmux       The menu bar title, escaped for tmux
+
+The title shows what the menu bar app's Menu Bar Display settings select.
+--json prints today's stats as JSON instead and ignores --format.
+
+Template fields: .Date .Keystrokes .Words .Clicks .DistancePixels .Distance
+.Title .Tooltip .Class
+Template functions: abs (12,345), short (12.3K), json, tmux
+
+Examples:
+  typtel today --format waybar
+  typtel today --format '{{abs .Keystrokes}} keys, {{.Distance}}'
+  set -g status-right '#(typtel today --format tmux)'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return showToday()
+		return showToday(todayFormat)
 	},
 }
 
@@ -65,19 +116,102 @@ var viewCmd = &cobra.Command{
 	Use:     "v",
 	Aliases: []string{"view", "charts"},
 	Short:   "View typing statistics charts in browser",
+	Long: `Open charts of keystrokes, words and mouse distance, a calendar of the
+last year, an hourly heatmap and a keyboard coloured by key frequency in the
+browser.
+
+The page offers weekly, monthly and yearly periods and a date picker for any
+range within the last year. --from and --to add a range of any length,
+including hourly detail for ranges up to 62 days. --layout draws the keyboard
+as ANSI or ISO instead of the keyboard.layout setting.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return viewCharts()
 	},
 }
 
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export telemetry as CSV, JSON or NDJSON",
+	Long: `Export daily, hourly, mouse, per-key and typing test data.
+
+Tables: daily, hourly, mouse, keys, tests. CSV writes one file per table, so
+exporting several tables as CSV needs --output to name a directory.
+
+Examples:
+  typtel export --format json > typtel.json
+  typtel export --format ndjson --from 2024-01-01 --to 2024-01-31 -o jan.ndjson
+  typtel export --format csv --tables daily > daily.csv
+  typtel export --format csv -o typtel-export/`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExport()
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file|dir>",
+	Short: "Import telemetry from typtel, Monkeytype or WhatPulse",
+	Long: `Import history into the database. Supported sources:
+
+  export      A file or CSV directory written by 'typtel export'
+  typtel-db   Another machine's typtel.db
+  monkeytype  Monkeytype results export (CSV or JSON) into typing test history
+  whatpulse   WhatPulse keys-per-day CSV into daily keystroke totals
+
+The source is detected from the file unless --source is given. Imported data
+is tagged with its source, and days that already have data are summed rather
+than overwritten. Importing an export into an empty database reproduces it
+exactly.
+
+Examples:
+  typtel import typtel.json
+  typtel import ~/Downloads/results.csv             # Monkeytype
+  typtel import --source whatpulse pulses.csv
+  typtel import /Volumes/laptop/typtel.db
+  typtel import --list                              # Show past imports`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if importList {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if importList {
+			return showImportLog()
+		}
+		return runImport(args[0])
+	},
+}
+
 func init() {
+	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "", "Path to the typtel database (default: $TYPTEL_DATA_DIR/typtel.db or ~/.local/share/typtel/typtel.db)")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to the config file (default: $TYPTEL_CONFIG or ~/.config/typtel/config.toml)")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Print reports as a versioned JSON document")
+
 	testCmd.Flags().StringVarP(&testFile, "file", "f", "", "Path to text file with words/passages")
 	testCmd.Flags().IntVarP(&testWordCount, "words", "w", 25, "Number of words in the test")
 
+	todayCmd.Flags().StringVarP(&todayFormat, "format", "f", statusbar.DefaultPreset, "Preset ("+strings.Join(statusbar.PresetNames(), ", ")+") or Go template")
+
+	viewCmd.Flags().StringVar(&viewFrom, "from", "", "First date of a custom range (YYYY-MM-DD)")
+	viewCmd.Flags().StringVar(&viewTo, "to", "", "Last date of a custom range (YYYY-MM-DD, default: today)")
+	viewCmd.Flags().StringVar(&viewLayout, "layout", "", "Keyboard layout of the key heatmap: "+strings.Join(keyboard.Layouts, " or ")+" (default: keyboard.layout setting)")
+
+	exportCmd.Flags().StringVar(&exportFormat, "format", export.FormatJSON, "Output format: csv, json or ndjson")
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "First date to export (YYYY-MM-DD)")
+	exportCmd.Flags().StringVar(&exportTo, "to", "", "Last date to export (YYYY-MM-DD)")
+	exportCmd.Flags().StringVar(&exportTables, "tables", "", "Comma-separated tables to export (default: all)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file, or directory for CSV (default: stdout)")
+
+	importCmd.Flags().StringVar(&importFormat, "format", "", "Format of a typtel export: csv, json or ndjson (default: from file extension)")
+	importCmd.Flags().StringVar(&importSource, "source", "", "Source: export, typtel-db, monkeytype or whatpulse (default: detect)")
+	importCmd.Flags().BoolVar(&importList, "list", false, "List previous imports")
+
 	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(todayCmd)
 	rootCmd.AddCommand(testCmd)
 	rootCmd.AddCommand(viewCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
 }
 
 func main() {
@@ -87,8 +221,57 @@ func main() {
 	}
 }
 
+// openStore opens the database selected by --db, falling back to the default location
+func openStore() (*storage.Store, error) {
+	var store *storage.Store
+	var err error
+	if dbPath != "" {
+		store, err = storage.NewWithPath(dbPath)
+	} else {
+		store, err = storage.New()
+	}
+	return withConfigFile(store, err)
+}
+
+// openStoreReadOnly opens the database for reporting commands, which only read
+// and so must not compete with the menubar daemon for the write lock
+func openStoreReadOnly() (*storage.Store, error) {
+	var store *storage.Store
+	var err error
+	if dbPath != "" {
+		store, err = storage.OpenReadOnly(dbPath)
+	} else {
+		store, err = storage.NewReadOnly()
+	}
+	return withConfigFile(store, err)
+}
+
+// withConfigFile loads the config file into a freshly opened store
+func withConfigFile(store *storage.Store, err error) (*storage.Store, error) {
+	if err != nil {
+		return nil, err
+	}
+	path, err := configFilePath()
+	if err == nil {
+		err = store.LoadConfigFile(path)
+	}
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return store, nil
+}
+
+// configFilePath returns --config or the default config file location
+func configFilePath() (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	return paths.ConfigPath()
+}
+
 func runTUI() error {
-	store, err := storage.New()
+	store, err := openStore()
 	if err != nil {
 		return fmt.Errorf("failed to open storage: %w", err)
 	}
@@ -109,7 +292,7 @@ func runTUI() error {
 }
 
 func runTypingTest() error {
-	store, err := storage.New()
+	store, err := openStore()
 	if err != nil {
 		return fmt.Errorf("failed to open storage: %w", err)
 	}
@@ -123,8 +306,86 @@ func runTypingTest() error {
 	return err
 }
 
+// statsReport is the output of 'typtel stats'
+type statsReport struct {
+	Today        *storage.DailyStats       `json:"today"`
+	TodayMouse   *storage.MouseDailyStats  `json:"today_mouse"`
+	Week         []storage.DailyStats      `json:"week"`
+	WeekMouse    []storage.MouseDailyStats `json:"week_mouse"`
+	WeekTotal    storage.DailyStats        `json:"week_total"`    // Date is empty
+	DailyAverage storage.DailyStats        `json:"daily_average"` // over the 7 days; Date is empty
+	TypingTest   storage.TypingTestStats   `json:"typing_test"`
+	Corrections  correctionsReport         `json:"corrections"`
+	Sessions     storage.DailySessions     `json:"sessions"` // today's active time (see 'typtel sessions')
+	Goals        *storage.GoalStatus       `json:"goals"`
+}
+
+// correctionsReport is the Backspace/Delete trend in 'typtel stats'
+type correctionsReport struct {
+	Today        corrections.Counts         `json:"today"`
+	Week         corrections.Counts         `json:"week"`          // the 7 days of Week
+	PreviousWeek corrections.Counts         `json:"previous_week"` // the 7 days before
+	Daily        []storage.DailyCorrections `json:"daily"`         // days of Week with any typing
+}
+
+func loadCorrections(store *storage.Store, week []storage.DailyStats) (correctionsReport, error) {
+	var r correctionsReport
+	from, to := week[0].Date, week[len(week)-1].Date
+	start, _ := time.Parse("2006-01-02", from)
+	prevFrom := start.AddDate(0, 0, -7).Format("2006-01-02")
+	prevTo := start.AddDate(0, 0, -1).Format("2006-01-02")
+
+	var err error
+	if r.Daily, err = store.GetDailyCorrectionsRange(from, to); err != nil {
+		return r, fmt.Errorf("failed to get corrections: %w", err)
+	}
+	for _, day := range r.Daily {
+		r.Week.Add(day.Counts)
+		if day.Date == to {
+			r.Today = day.Counts
+		}
+	}
+	if r.PreviousWeek, err = store.GetCorrections(prevFrom, prevTo); err != nil {
+		return r, fmt.Errorf("failed to get corrections: %w", err)
+	}
+	return r, nil
+}
+
+func printCorrections(r correctionsReport) {
+	if r.Week.Chars == 0 && r.Week.Corrections == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("✏️  Corrections (Backspace/Delete per character)")
+	fmt.Printf("Today:     %s\n", formatCorrections(r.Today))
+	week := formatCorrections(r.Week)
+	if r.PreviousWeek.Chars > 0 {
+		week += fmt.Sprintf(", %.1f%% the week before", r.PreviousWeek.Rate()*100)
+	}
+	fmt.Printf("This week: %s\n", week)
+
+	days := make([]string, len(r.Daily))
+	for i, day := range r.Daily {
+		t, _ := time.Parse("2006-01-02", day.Date)
+		days[i] = fmt.Sprintf("%s %.1f%%", t.Format("Mon"), day.Rate()*100)
+	}
+	fmt.Printf("By day:    %s\n", strings.Join(days, "  "))
+}
+
+func formatCorrections(c corrections.Counts) string {
+	return fmt.Sprintf("%.1f%% (%s corrections, %s)", c.Rate()*100, formatNum(c.Corrections), plural(int(c.Bursts), "burst"))
+}
+
+// plural counts n of noun, e.g. "1 break" or "3 breaks"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
 func showStats() error {
-	store, err := storage.New()
+	store, err := openStoreReadOnly()
 	if err != nil {
 		return fmt.Errorf("failed to open storage: %w", err)
 	}
@@ -140,26 +401,98 @@ func showStats() error {
 		return fmt.Errorf("failed to get week stats: %w", err)
 	}
 
-	var weekTotal int64
+	r := statsReport{Today: today, Week: week}
 	for _, day := range week {
-		weekTotal += day.Keystrokes
+		r.WeekTotal.Keystrokes += day.Keystrokes
+		r.WeekTotal.Words += day.Words
+	}
+	r.DailyAverage.Keystrokes = r.WeekTotal.Keystrokes / 7
+	r.DailyAverage.Words = r.WeekTotal.Words / 7
+	if r.Corrections, err = loadCorrections(store, week); err != nil {
+		return err
+	}
+	r.Sessions.Date = today.Date
+	activity, err := store.GetDailySessionsRange(today.Date, today.Date)
+	if err != nil {
+		return fmt.Errorf("failed to get sessions: %w", err)
+	}
+	if len(activity) > 0 {
+		r.Sessions = activity[0]
+	}
+	units := report.Units{Distance: store.GetDistanceUnit()}
+	if r.Goals, err = store.GetGoalStatus(units.Feet); err != nil {
+		return fmt.Errorf("failed to get goals: %w", err)
 	}
 
-	// Calculate week words from daily stats
-	var weekWords int64
-	for _, day := range week {
-		weekWords += day.Words
+	if jsonOutput {
+		if r.TodayMouse, err = store.GetTodayMouseStats(); err != nil {
+			return fmt.Errorf("failed to get today's mouse stats: %w", err)
+		}
+		if r.WeekMouse, err = store.GetWeekMouseStats(); err != nil {
+			return fmt.Errorf("failed to get week mouse stats: %w", err)
+		}
+		r.TypingTest = store.GetTypingTestStats()
+		return printJSON("stats", r)
 	}
 
 	fmt.Println("📊 Typing Statistics")
 	fmt.Println("────────────────────")
 	fmt.Printf("Today:     %s keystrokes (%s words)\n", formatNum(today.Keystrokes), formatNum(today.Words))
-	fmt.Printf("This week: %s keystrokes (%s words)\n", formatNum(weekTotal), formatNum(weekWords))
-	fmt.Printf("Daily avg: %s keystrokes (%s words)\n", formatNum(weekTotal/7), formatNum(weekWords/7))
+	fmt.Printf("This week: %s keystrokes (%s words)\n", formatNum(r.WeekTotal.Keystrokes), formatNum(r.WeekTotal.Words))
+	fmt.Printf("Daily avg: %s keystrokes (%s words)\n", formatNum(r.DailyAverage.Keystrokes), formatNum(r.DailyAverage.Words))
+	if s := r.Sessions; s.Sessions > 0 {
+		fmt.Printf("Active:    %s today in %s (longest %s, %s)\n", formatMinutes(s.ActiveMinutes),
+			plural(s.Sessions, "session"), formatMinutes(s.LongestMinutes), plural(s.Breaks, "break"))
+	}
+	printCorrections(r.Corrections)
+	printGoals(r.Goals, units)
 
 	return nil
 }
 
+func printGoals(g *storage.GoalStatus, units report.Units) {
+	if len(g.Targets) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("🎯 Goals")
+	for _, t := range g.Targets {
+		fmt.Printf("%-14s %s\n", goalLabels[t.Metric]+":", formatTarget(t, units))
+	}
+	streak := "none yet"
+	if g.Streak.Current > 0 {
+		streak = fmt.Sprintf("🔥 %d-day streak", g.Streak.Current)
+		if !g.Streak.TodayMet {
+			streak += ", meet today's goals to keep it"
+		}
+	}
+	fmt.Printf("%-14s %s (longest %d)\n", "Streak:", streak, g.Streak.Longest)
+}
+
+// goalLabels name each goal in 'typtel stats'
+var goalLabels = map[goals.Metric]string{
+	goals.Keystrokes:    "Keystrokes",
+	goals.Words:         "Words",
+	goals.TypingTests:   "Typing tests",
+	goals.MouseDistance: "Mouse",
+}
+
+// formatTarget shows progress towards a goal, e.g. "7,450 / 10,000 (75%)"
+func formatTarget(t goals.Target, units report.Units) string {
+	if t.Max {
+		s := fmt.Sprintf("%s of at most %s", units.FormatFeet(t.Value), units.FormatFeet(t.Goal))
+		if !t.Met {
+			s += ", over"
+		}
+		return s
+	}
+	s := fmt.Sprintf("%s / %s", report.FormatAbsolute(int64(t.Value)), report.FormatAbsolute(int64(t.Goal)))
+	if t.Met {
+		return s + " ✓"
+	}
+	return s + fmt.Sprintf(" (%.0f%%)", t.Value/t.Goal*100)
+}
+
 func formatNum(n int64) string {
 	if n >= 1000000 {
 		return fmt.Sprintf("%.1fM", float64(n)/1000000)
@@ -170,577 +503,186 @@ func formatNum(n int64) string {
 	return fmt.Sprintf("%d", n)
 }
 
-func showToday() error {
-	store, err := storage.New()
+// todayReport is the output of 'typtel today --json'
+type todayReport struct {
+	Today *storage.DailyStats      `json:"today"`
+	Mouse *storage.MouseDailyStats `json:"mouse"`
+	Speed storage.Speed            `json:"speed"` // over today's typing bursts
+}
+
+func showToday(format string) error {
+	tmpl, err := statusbar.Parse(format)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStoreReadOnly()
 	if err != nil {
 		return fmt.Errorf("failed to open storage: %w", err)
 	}
 	defer store.Close()
 
-	today, err := store.GetTodayStats()
-	if err != nil {
-		return fmt.Errorf("failed to get today's stats: %w", err)
+	if jsonOutput {
+		var r todayReport
+		if r.Today, err = store.GetTodayStats(); err != nil {
+			return fmt.Errorf("failed to get today's stats: %w", err)
+		}
+		if r.Mouse, err = store.GetTodayMouseStats(); err != nil {
+			return fmt.Errorf("failed to get today's mouse stats: %w", err)
+		}
+		if r.Speed, err = store.GetSpeed(r.Today.Date, r.Today.Date); err != nil {
+			return fmt.Errorf("failed to get today's typing speed: %w", err)
+		}
+		return printJSON("today", r)
 	}
 
-	// Output format suitable for menu bar scripts
-	fmt.Printf("%d\n", today.Keystrokes)
-	return nil
+	status, err := statusbar.Load(store, report.Units{Distance: store.GetDistanceUnit()})
+	if err != nil {
+		return err
+	}
+	// Render fully before writing, so a bad template leaves no partial line
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, status); err != nil {
+		return err
+	}
+	_, err = out.WriteTo(os.Stdout)
+	return err
 }
 
 func viewCharts() error {
-	store, err := storage.New()
+	store, err := openStoreReadOnly()
 	if err != nil {
 		return fmt.Errorf("failed to open storage: %w", err)
 	}
 	defer store.Close()
 
-	htmlPath, err := generateChartsHTML(store)
+	if viewTo != "" && viewFrom == "" {
+		return fmt.Errorf("--to needs --from")
+	}
+	htmlPath, err := report.SaveCharts(store, report.Units{}, report.ChartOptions{From: viewFrom, To: viewTo, Layout: viewLayout})
 	if err != nil {
 		return fmt.Errorf("failed to generate charts: %w", err)
 	}
 
 	fmt.Printf("Opening charts: %s\n", htmlPath)
-	return exec.Command("open", htmlPath).Start()
+	return openBrowser(htmlPath)
 }
 
-// DefaultPPI is the default pixels per inch if display info is unavailable
-const DefaultPPI = 100.0
-
-func pixelsToFeet(pixels float64) float64 {
-	inches := pixels / DefaultPPI
-	return inches / 12.0
+// openBrowser opens a file or URL with the platform's default handler
+func openBrowser(target string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target).Start()
+	default:
+		return exec.Command("xdg-open", target).Start()
+	}
 }
 
-func formatAbsolute(n int64) string {
-	s := fmt.Sprintf("%d", n)
-	if n < 0 {
-		return s
+func runExport() error {
+	tables, err := export.ParseTables(exportTables)
+	if err != nil {
+		return err
 	}
 
-	result := ""
-	for i, c := range s {
-		if i > 0 && (len(s)-i)%3 == 0 {
-			result += ","
-		}
-		result += string(c)
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
 	}
-	return result
-}
+	defer store.Close()
 
-func formatDistance(pixels float64) string {
-	feet := pixelsToFeet(pixels)
-	if feet >= 5280 {
-		return fmt.Sprintf("%.1fmi", feet/5280)
-	} else if feet >= 1 {
-		return fmt.Sprintf("%.0fft", feet)
+	ds, err := export.Collect(store, export.Options{From: exportFrom, To: exportTo, Tables: tables})
+	if err != nil {
+		return fmt.Errorf("failed to read telemetry: %w", err)
 	}
-	inches := feet * 12
-	return fmt.Sprintf("%.0fin", inches)
-}
 
-func generateChartsHTML(store *storage.Store) (string, error) {
-	prepareChartData := func(days int) (labels, keystrokeData, wordData []string, mouseDataFeet []float64, totalKeystrokes, totalWords int64, totalMouseDistance float64, heatmapHTML string, err error) {
-		histStats, err := store.GetHistoricalStats(days)
-		if err != nil {
-			return nil, nil, nil, nil, 0, 0, 0, "", err
-		}
-
-		mouseStats, err := store.GetMouseHistoricalStats(days)
-		if err != nil {
-			return nil, nil, nil, nil, 0, 0, 0, "", err
-		}
+	// Several CSV tables go to a directory, one file each
+	if exportFormat == export.FormatCSV && exportOutput != "" &&
+		(len(tables) != 1 || !strings.EqualFold(filepath.Ext(exportOutput), ".csv")) {
+		return export.WriteCSVDir(exportOutput, ds, tables)
+	}
 
-		hourlyData, err := store.GetAllHourlyStatsForDays(days)
+	out := os.Stdout
+	if exportOutput != "" {
+		f, err := os.Create(exportOutput)
 		if err != nil {
-			return nil, nil, nil, nil, 0, 0, 0, "", err
+			return fmt.Errorf("failed to create output: %w", err)
 		}
-
-		for i, stat := range histStats {
-			t, _ := time.Parse("2006-01-02", stat.Date)
-			labels = append(labels, fmt.Sprintf("'%s'", t.Format("Jan 2")))
-			keystrokeData = append(keystrokeData, fmt.Sprintf("%d", stat.Keystrokes))
-			wordData = append(wordData, fmt.Sprintf("%d", stat.Words))
-			totalKeystrokes += stat.Keystrokes
-			totalWords += stat.Words
-
-			if i < len(mouseStats) {
-				feet := pixelsToFeet(mouseStats[i].TotalDistance)
-				mouseDataFeet = append(mouseDataFeet, feet)
-				totalMouseDistance += mouseStats[i].TotalDistance
-			} else {
-				mouseDataFeet = append(mouseDataFeet, 0)
-			}
-		}
-
-		heatmapHTML = generateHeatmapHTML(hourlyData)
-		return
+		defer f.Close()
+		out = f
 	}
 
-	weeklyLabels, weeklyKeystrokes, weeklyWords, weeklyMouseFeet, weeklyTotalKeys, weeklyTotalWords, weeklyTotalMouse, weeklyHeatmap, err := prepareChartData(7)
-	if err != nil {
-		return "", err
-	}
+	return export.Write(out, ds, exportFormat, tables)
+}
 
-	monthlyLabels, monthlyKeystrokes, monthlyWords, monthlyMouseFeet, monthlyTotalKeys, monthlyTotalWords, monthlyTotalMouse, monthlyHeatmap, err := prepareChartData(30)
+func runImport(path string) error {
+	store, err := openStore()
 	if err != nil {
-		return "", err
+		return fmt.Errorf("failed to open storage: %w", err)
 	}
+	defer store.Close()
 
-	formatMouseData := func(feetData []float64, divisor float64) string {
-		var result []string
-		for _, f := range feetData {
-			result = append(result, fmt.Sprintf("%.2f", f/divisor))
+	if target, err := activeDBPath(); err == nil {
+		if same, _ := sameFile(path, target); same {
+			return fmt.Errorf("%s is the database being imported into", path)
 		}
-		return strings.Join(result, ",")
-	}
-
-	weeklyMouseFeetStr := formatMouseData(weeklyMouseFeet, 1.0)
-	weeklyMouseCarsStr := formatMouseData(weeklyMouseFeet, 15.0)
-	weeklyMouseFieldsStr := formatMouseData(weeklyMouseFeet, 330.0)
-	monthlyMouseFeetStr := formatMouseData(monthlyMouseFeet, 1.0)
-	monthlyMouseCarsStr := formatMouseData(monthlyMouseFeet, 15.0)
-	monthlyMouseFieldsStr := formatMouseData(monthlyMouseFeet, 330.0)
-
-	html := fmt.Sprintf(`<!DOCTYPE html>
-<html>
-<head>
-    <title>Typtel - Typing Statistics</title>
-    <script src="https://cdn.jsdelivr.net/npm/chart.js"></script>
-    <style>
-        * { margin: 0; padding: 0; box-sizing: border-box; }
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-            background: linear-gradient(135deg, #1a1a2e 0%%, #16213e 100%%);
-            color: #eee;
-            min-height: 100vh;
-            padding: 30px;
-        }
-        h1 {
-            text-align: center;
-            margin-bottom: 10px;
-            font-size: 2.5em;
-            background: linear-gradient(90deg, #00d2ff, #3a7bd5);
-            -webkit-background-clip: text;
-            -webkit-text-fill-color: transparent;
-        }
-        .controls {
-            display: flex;
-            justify-content: center;
-            gap: 20px;
-            margin-bottom: 30px;
-        }
-        .control-group {
-            display: flex;
-            align-items: center;
-            gap: 10px;
-        }
-        .control-group label {
-            color: #888;
-            font-size: 0.9em;
-        }
-        select {
-            background: rgba(255,255,255,0.1);
-            border: 1px solid rgba(255,255,255,0.2);
-            border-radius: 8px;
-            color: #eee;
-            padding: 8px 16px;
-            font-size: 0.9em;
-            cursor: pointer;
-        }
-        select:hover {
-            background: rgba(255,255,255,0.15);
-        }
-        .charts-container {
-            display: grid;
-            grid-template-columns: 1fr 1fr;
-            gap: 30px;
-            max-width: 1400px;
-            margin: 0 auto 40px;
-        }
-        .chart-box {
-            background: rgba(255,255,255,0.05);
-            border-radius: 16px;
-            padding: 25px;
-            backdrop-filter: blur(10px);
-            border: 1px solid rgba(255,255,255,0.1);
-        }
-        .chart-box h2 {
-            margin-bottom: 20px;
-            font-size: 1.3em;
-            color: #aaa;
-        }
-        .heatmap-container {
-            max-width: 1400px;
-            margin: 0 auto;
-        }
-        .heatmap-box {
-            background: rgba(255,255,255,0.05);
-            border-radius: 16px;
-            padding: 25px;
-            backdrop-filter: blur(10px);
-            border: 1px solid rgba(255,255,255,0.1);
-        }
-        .heatmap-box h2 {
-            margin-bottom: 20px;
-            font-size: 1.3em;
-            color: #aaa;
-        }
-        .heatmap {
-            display: flex;
-            flex-direction: column;
-            gap: 3px;
-        }
-        .heatmap-row {
-            display: flex;
-            align-items: center;
-            gap: 3px;
-        }
-        .heatmap-label {
-            width: 70px;
-            font-size: 11px;
-            color: #888;
-            text-align: right;
-            padding-right: 10px;
-        }
-        .heatmap-cell {
-            width: 20px;
-            height: 20px;
-            border-radius: 3px;
-            transition: transform 0.2s;
-        }
-        .heatmap-cell:hover {
-            transform: scale(1.3);
-            z-index: 10;
-        }
-        .hour-labels {
-            display: flex;
-            gap: 3px;
-            margin-left: 80px;
-            margin-bottom: 5px;
-        }
-        .hour-label {
-            width: 20px;
-            font-size: 10px;
-            color: #666;
-            text-align: center;
-        }
-        .legend {
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            gap: 8px;
-            margin-top: 20px;
-        }
-        .legend-text { color: #666; font-size: 12px; }
-        .legend-box {
-            width: 15px;
-            height: 15px;
-            border-radius: 2px;
-        }
-        .stats-summary {
-            display: flex;
-            justify-content: center;
-            gap: 40px;
-            margin: 30px 0;
-        }
-        .stat-item {
-            text-align: center;
-        }
-        .stat-value {
-            font-size: 2.5em;
-            font-weight: bold;
-            background: linear-gradient(90deg, #00d2ff, #3a7bd5);
-            -webkit-background-clip: text;
-            -webkit-text-fill-color: transparent;
-        }
-        .stat-label {
-            color: #888;
-            font-size: 0.9em;
-        }
-    </style>
-</head>
-<body>
-    <h1>Typtel Statistics</h1>
-
-    <div class="controls">
-        <div class="control-group">
-            <label>Time Period:</label>
-            <select id="periodSelect" onchange="updateCharts()">
-                <option value="weekly">Weekly (7 days)</option>
-                <option value="monthly">Monthly (30 days)</option>
-            </select>
-        </div>
-        <div class="control-group">
-            <label>Distance Unit:</label>
-            <select id="unitSelect" onchange="updateCharts()">
-                <option value="feet">Feet</option>
-                <option value="cars">Car Lengths (~15ft)</option>
-                <option value="fields">Frisbee Fields (~330ft)</option>
-            </select>
-        </div>
-    </div>
-
-    <div class="stats-summary">
-        <div class="stat-item">
-            <div class="stat-value" id="totalKeystrokes">-</div>
-            <div class="stat-label">Total Keystrokes</div>
-        </div>
-        <div class="stat-item">
-            <div class="stat-value" id="totalWords">-</div>
-            <div class="stat-label">Words</div>
-        </div>
-        <div class="stat-item">
-            <div class="stat-value" id="avgKeystrokes">-</div>
-            <div class="stat-label">Avg Keystrokes/Day</div>
-        </div>
-        <div class="stat-item">
-            <div class="stat-value" id="totalMouse">-</div>
-            <div class="stat-label">Mouse Distance</div>
-        </div>
-    </div>
-
-    <div class="charts-container">
-        <div class="chart-box">
-            <h2>Keystrokes per Day</h2>
-            <canvas id="keystrokesChart"></canvas>
-        </div>
-        <div class="chart-box">
-            <h2>Words per Day</h2>
-            <canvas id="wordsChart"></canvas>
-        </div>
-    </div>
-
-    <div class="charts-container">
-        <div class="chart-box" style="grid-column: span 2;">
-            <h2 id="mouseChartTitle">Mouse Distance per Day</h2>
-            <canvas id="mouseChart"></canvas>
-        </div>
-    </div>
-
-    <div class="heatmap-container">
-        <div class="heatmap-box">
-            <h2>Activity Heatmap (Hourly)</h2>
-            <div class="hour-labels">
-                %s
-            </div>
-            <div class="heatmap" id="heatmapContainer">
-            </div>
-            <div class="legend">
-                <span class="legend-text">Less</span>
-                <div class="legend-box" style="background: #1a1a2e;"></div>
-                <div class="legend-box" style="background: #2d4a3e;"></div>
-                <div class="legend-box" style="background: #3d6b4f;"></div>
-                <div class="legend-box" style="background: #5a9a6f;"></div>
-                <div class="legend-box" style="background: #7bc96f;"></div>
-                <span class="legend-text">More</span>
-            </div>
-        </div>
-    </div>
-
-    <script>
-        const data = {
-            weekly: {
-                labels: [%s],
-                keystrokes: [%s],
-                words: [%s],
-                mouse: { feet: [%s], cars: [%s], fields: [%s] },
-                totalKeystrokes: %d,
-                totalWords: %d,
-                totalMouseFeet: %.2f,
-                days: 7,
-                heatmap: `+"`%s`"+`
-            },
-            monthly: {
-                labels: [%s],
-                keystrokes: [%s],
-                words: [%s],
-                mouse: { feet: [%s], cars: [%s], fields: [%s] },
-                totalKeystrokes: %d,
-                totalWords: %d,
-                totalMouseFeet: %.2f,
-                days: 30,
-                heatmap: `+"`%s`"+`
-            }
-        };
-
-        const unitLabels = { feet: 'feet', cars: 'car lengths', fields: 'frisbee fields' };
-
-        let keystrokesChart, wordsChart, mouseChart;
-
-        const chartConfig = {
-            responsive: true,
-            plugins: { legend: { display: false } },
-            scales: {
-                y: { beginAtZero: true, grid: { color: 'rgba(255,255,255,0.1)' }, ticks: { color: '#888' } },
-                x: { grid: { display: false }, ticks: { color: '#888' } }
-            }
-        };
-
-        function formatNumber(n) {
-            if (n >= 1000000) return (n/1000000).toFixed(1) + 'M';
-            if (n >= 1000) return (n/1000).toFixed(1) + 'K';
-            return n.toString();
-        }
-
-        function formatDistanceJS(feet) {
-            if (feet >= 5280) return (feet/5280).toFixed(2) + ' mi';
-            return feet.toFixed(0) + ' ft';
-        }
-
-        function updateCharts() {
-            const period = document.getElementById('periodSelect').value;
-            const unit = document.getElementById('unitSelect').value;
-            const d = data[period];
-
-            document.getElementById('totalKeystrokes').textContent = formatNumber(d.totalKeystrokes);
-            document.getElementById('totalWords').textContent = formatNumber(d.totalWords);
-            document.getElementById('avgKeystrokes').textContent = formatNumber(Math.round(d.totalKeystrokes / d.days));
-            document.getElementById('totalMouse').textContent = formatDistanceJS(d.totalMouseFeet);
-
-            document.getElementById('mouseChartTitle').textContent = 'Mouse Distance per Day (' + unitLabels[unit] + ')';
-
-            if (keystrokesChart) keystrokesChart.destroy();
-            if (wordsChart) wordsChart.destroy();
-            if (mouseChart) mouseChart.destroy();
-
-            keystrokesChart = new Chart(document.getElementById('keystrokesChart'), {
-                type: 'bar',
-                data: {
-                    labels: d.labels,
-                    datasets: [{ data: d.keystrokes, backgroundColor: 'rgba(0, 210, 255, 0.6)', borderColor: 'rgba(0, 210, 255, 1)', borderWidth: 1, borderRadius: 4 }]
-                },
-                options: chartConfig
-            });
-
-            wordsChart = new Chart(document.getElementById('wordsChart'), {
-                type: 'line',
-                data: {
-                    labels: d.labels,
-                    datasets: [{ data: d.words, borderColor: 'rgba(122, 201, 111, 1)', backgroundColor: 'rgba(122, 201, 111, 0.2)', fill: true, tension: 0.4, pointRadius: 4, pointBackgroundColor: 'rgba(122, 201, 111, 1)' }]
-                },
-                options: chartConfig
-            });
-
-            mouseChart = new Chart(document.getElementById('mouseChart'), {
-                type: 'bar',
-                data: {
-                    labels: d.labels,
-                    datasets: [{ data: d.mouse[unit], backgroundColor: 'rgba(255, 107, 107, 0.6)', borderColor: 'rgba(255, 107, 107, 1)', borderWidth: 1, borderRadius: 4 }]
-                },
-                options: chartConfig
-            });
-
-            document.getElementById('heatmapContainer').innerHTML = d.heatmap;
-        }
-
-        updateCharts();
-    </script>
-</body>
-</html>`,
-		generateHourLabels(),
-		strings.Join(weeklyLabels, ","),
-		strings.Join(weeklyKeystrokes, ","),
-		strings.Join(weeklyWords, ","),
-		weeklyMouseFeetStr,
-		weeklyMouseCarsStr,
-		weeklyMouseFieldsStr,
-		weeklyTotalKeys,
-		weeklyTotalWords,
-		pixelsToFeet(weeklyTotalMouse),
-		weeklyHeatmap,
-		strings.Join(monthlyLabels, ","),
-		strings.Join(monthlyKeystrokes, ","),
-		strings.Join(monthlyWords, ","),
-		monthlyMouseFeetStr,
-		monthlyMouseCarsStr,
-		monthlyMouseFieldsStr,
-		monthlyTotalKeys,
-		monthlyTotalWords,
-		pixelsToFeet(monthlyTotalMouse),
-		monthlyHeatmap,
-	)
+	}
 
-	home, err := os.UserHomeDir()
+	result, err := importer.Run(store, path, importer.Options{Source: importSource, Format: importFormat})
 	if err != nil {
-		return "", err
-	}
-	dataDir := filepath.Join(home, ".local", "share", "typtel", "logs")
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return "", err
-	}
-	htmlPath := filepath.Join(dataDir, "charts.html")
-	if err := os.WriteFile(htmlPath, []byte(html), 0644); err != nil {
-		return "", err
+		return err
 	}
 
-	return htmlPath, nil
+	sum := result.Summary
+	fmt.Printf("Imported %d rows from %s (daily %d, hourly %d, mouse %d, keys %d, tests %d)\n",
+		sum.Total(), result.Source, sum.Daily, sum.Hourly, sum.Mouse, sum.Keys, sum.Tests)
+	return nil
 }
 
-func generateHourLabels() string {
-	var labels []string
-	for h := 0; h < 24; h++ {
-		if h%3 == 0 {
-			labels = append(labels, fmt.Sprintf(`<div class="hour-label">%d</div>`, h))
-		} else {
-			labels = append(labels, `<div class="hour-label"></div>`)
-		}
+func showImportLog() error {
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
 	}
-	return strings.Join(labels, "\n                ")
-}
+	defer store.Close()
 
-func generateHeatmapHTML(hourlyData map[string][]storage.HourlyStats) string {
-	var maxVal int64 = 1
-	for _, hours := range hourlyData {
-		for _, h := range hours {
-			if h.Keystrokes > maxVal {
-				maxVal = h.Keystrokes
-			}
+	log, err := store.GetImportLog()
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		if log == nil {
+			log = []storage.ImportRecord{}
 		}
+		return printJSON("import list", log)
 	}
-
-	dates := make([]string, 0, len(hourlyData))
-	for date := range hourlyData {
-		dates = append(dates, date)
+	if len(log) == 0 {
+		fmt.Println("No imports yet")
+		return nil
 	}
-	sort.Strings(dates)
-
-	var rows []string
-	for _, date := range dates {
-		hours := hourlyData[date]
-		t, _ := time.Parse("2006-01-02", date)
-		dateLabel := t.Format("Mon Jan 2")
-
-		var cells []string
-		for _, h := range hours {
-			color := getHeatmapColor(h.Keystrokes, maxVal)
-			title := fmt.Sprintf("%s %d:00 - %d keystrokes", dateLabel, h.Hour, h.Keystrokes)
-			cells = append(cells, fmt.Sprintf(
-				`<div class="heatmap-cell" style="background: %s;" title="%s"></div>`,
-				color, title,
-			))
-		}
-
-		rows = append(rows, fmt.Sprintf(
-			`<div class="heatmap-row"><div class="heatmap-label">%s</div>%s</div>`,
-			dateLabel,
-			strings.Join(cells, ""),
-		))
+	for _, r := range log {
+		fmt.Printf("%s  %-10s  %8s rows  %s\n", r.Timestamp.Format("2006-01-02 15:04"), r.Source, report.FormatAbsolute(int64(r.Rows)), r.Origin)
 	}
+	return nil
+}
 
-	return strings.Join(rows, "\n                ")
+// activeDBPath returns the database selected by --db or the default location
+func activeDBPath() (string, error) {
+	if dbPath != "" {
+		return dbPath, nil
+	}
+	return paths.DBPath()
 }
 
-func getHeatmapColor(value, max int64) string {
-	if value == 0 {
-		return "#1a1a2e"
+func sameFile(a, b string) (bool, error) {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false, err
 	}
-	ratio := float64(value) / float64(max)
-	if ratio < 0.25 {
-		return "#2d4a3e"
-	} else if ratio < 0.5 {
-		return "#3d6b4f"
-	} else if ratio < 0.75 {
-		return "#5a9a6f"
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false, err
 	}
-	return "#7bc96f"
+	return os.SameFile(ai, bi), nil
 }
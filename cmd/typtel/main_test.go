@@ -1,7 +1,22 @@
 package main
 
 import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+	"github.com/aayushbajaj/typing-telemetry/internal/corrections"
+	"github.com/aayushbajaj/typing-telemetry/internal/goals"
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/leaderboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/speed"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
 )
 
 func TestRootCmdExists(t *testing.T) {
@@ -99,6 +114,176 @@ func TestViewCmdExists(t *testing.T) {
 	}
 }
 
+func TestDBFlag(t *testing.T) {
+	flag := rootCmd.PersistentFlags().Lookup("db")
+	if flag == nil {
+		t.Fatal("rootCmd should have a persistent 'db' flag")
+	}
+	if flag.DefValue != "" {
+		t.Errorf("db flag default = %q, want empty", flag.DefValue)
+	}
+
+	// Every subcommand inherits the flag
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.InheritedFlags().Lookup("db") == nil {
+			t.Errorf("subcommand %q should inherit the 'db' flag", cmd.Name())
+		}
+	}
+}
+
+func TestOpenStoreUsesDBFlag(t *testing.T) {
+	orig := dbPath
+	defer func() { dbPath = orig }()
+
+	dbPath = filepath.Join(t.TempDir(), "alt.db")
+	store, err := openStore()
+	if err != nil {
+		t.Fatalf("openStore failed: %v", err)
+	}
+	store.Close()
+
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Errorf("Expected database at %s: %v", dbPath, err)
+	}
+}
+
+func TestExportImportCommands(t *testing.T) {
+	origDB, origFormat, origOutput := dbPath, exportFormat, exportOutput
+	defer func() { dbPath, exportFormat, exportOutput = origDB, origFormat, origOutput }()
+
+	dir := t.TempDir()
+	dbPath = filepath.Join(dir, "src.db")
+	store, err := openStore()
+	if err != nil {
+		t.Fatalf("openStore failed: %v", err)
+	}
+	store.AddDailyTotals("2024-01-01", 42, 7)
+	store.Close()
+
+	exportFormat = "ndjson"
+	exportOutput = filepath.Join(dir, "out.ndjson")
+	if err := runExport(); err != nil {
+		t.Fatalf("runExport failed: %v", err)
+	}
+
+	dbPath = filepath.Join(dir, "dst.db")
+	if err := runImport(exportOutput); err != nil {
+		t.Fatalf("runImport failed: %v", err)
+	}
+
+	store, err = openStore()
+	if err != nil {
+		t.Fatalf("openStore failed: %v", err)
+	}
+	defer store.Close()
+	stats, err := store.GetDayStats("2024-01-01")
+	if err != nil {
+		t.Fatalf("GetDayStats failed: %v", err)
+	}
+	if stats.Keystrokes != 42 || stats.Words != 7 {
+		t.Errorf("Imported day = %d keystrokes, %d words; want 42, 7", stats.Keystrokes, stats.Words)
+	}
+}
+
+func TestImportCmdFlags(t *testing.T) {
+	for _, name := range []string{"format", "source", "list"} {
+		if importCmd.Flags().Lookup(name) == nil {
+			t.Errorf("importCmd should have a %q flag", name)
+		}
+	}
+}
+
+func TestImportRefusesTargetDatabase(t *testing.T) {
+	useTempDB(t, nil)
+	if err := runImport(dbPath); err == nil {
+		t.Error("Expected error importing a database into itself")
+	}
+}
+
+func TestSyncSubcommands(t *testing.T) {
+	names := make(map[string]bool)
+	for _, cmd := range syncCmd.Commands() {
+		names[cmd.Name()] = true
+	}
+	for _, name := range []string{"init", "off", "push", "status", "stats"} {
+		if !names[name] {
+			t.Errorf("syncCmd should have subcommand %q", name)
+		}
+	}
+}
+
+func TestSyncInitAndStatus(t *testing.T) {
+	useTempDB(t, nil)
+	syncFolder := filepath.Join(t.TempDir(), "shared")
+
+	if err := runSyncInit(syncFolder); err != nil {
+		t.Fatalf("runSyncInit failed: %v", err)
+	}
+	if err := showSyncStatus(); err != nil {
+		t.Errorf("showSyncStatus failed: %v", err)
+	}
+	if err := showSyncStats(); err != nil {
+		t.Errorf("showSyncStats failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(syncFolder)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected one changeset file in %s, got %v (%v)", syncFolder, entries, err)
+	}
+
+	jsonOutput = true
+	out := captureStdout(t, showSyncStatus)
+	var status struct {
+		Data struct {
+			Devices []struct {
+				ID string `json:"id"`
+			} `json:"devices"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(out), &status); err != nil || len(status.Data.Devices) != 1 {
+		t.Errorf("Unexpected sync status JSON (%v):\n%s", err, out)
+	}
+	out = captureStdout(t, showSyncStats)
+	if !strings.Contains(out, `"command": "sync stats"`) || !strings.Contains(out, `"by_device"`) {
+		t.Errorf("Unexpected sync stats JSON:\n%s", out)
+	}
+}
+
+func TestFormatLastSeen(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{3 * time.Hour, "3h ago"},
+		{72 * time.Hour, "3d ago"},
+	}
+	for _, tt := range tests {
+		got := formatLastSeen(now.Add(-tt.ago), now)
+		if !strings.HasSuffix(got, "("+tt.want+")") {
+			t.Errorf("formatLastSeen(-%v) = %q, want suffix %q", tt.ago, got, tt.want)
+		}
+	}
+	if got := formatLastSeen(time.Time{}, now); got != "never" {
+		t.Errorf("formatLastSeen(zero) = %q, want 'never'", got)
+	}
+}
+
+func TestServeCmdFlags(t *testing.T) {
+	addr := serveCmd.Flags().Lookup("addr")
+	if addr == nil {
+		t.Fatal("serveCmd should have an 'addr' flag")
+	}
+	if addr.DefValue != "127.0.0.1:7777" {
+		t.Errorf("addr flag default = %q, want '127.0.0.1:7777'", addr.DefValue)
+	}
+	if serveCmd.Flags().Lookup("open") == nil {
+		t.Error("serveCmd should have an 'open' flag")
+	}
+}
+
 func TestRootCmdHasSubcommands(t *testing.T) {
 	commands := rootCmd.Commands()
 
@@ -108,7 +293,7 @@ func TestRootCmdHasSubcommands(t *testing.T) {
 		cmdNames[cmd.Use] = true
 	}
 
-	expectedCmds := []string{"stats", "today", "test", "v"}
+	expectedCmds := []string{"stats", "today", "test", "v", "export", "import <file|dir>", "sync", "serve"}
 	for _, name := range expectedCmds {
 		if !cmdNames[name] {
 			t.Errorf("rootCmd should have subcommand %q", name)
@@ -196,3 +381,589 @@ func TestFormatNumEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestStatsCompare(t *testing.T) {
+	var today, weekAgo string
+	useTempDB(t, func(store *storage.Store) {
+		today = store.Today()
+		d, _ := time.Parse("2006-01-02", today)
+		weekAgo = d.AddDate(0, 0, -7).Format("2006-01-02")
+		for _, day := range []struct {
+			date              string
+			keystrokes, words int64
+			clicks            int64
+		}{
+			{weekAgo, 1000, 200, 40},
+			{today, 1200, 150, 40},
+		} {
+			if err := store.AddDailyTotals(day.date, day.keystrokes, day.words); err != nil {
+				t.Fatalf("AddDailyTotals failed: %v", err)
+			}
+			if err := store.AddMouseTotals(day.date, 0, day.clicks, 0); err != nil {
+				t.Fatalf("AddMouseTotals failed: %v", err)
+			}
+		}
+		noon := time.Date(d.Year(), d.Month(), d.Day(), 12, 0, 0, 0, time.Local)
+		for _, at := range []time.Time{noon.AddDate(0, 0, -7), noon} {
+			if err := store.RecordKeystrokeAt(keyboard.KeyA, at); err != nil {
+				t.Fatalf("RecordKeystrokeAt failed: %v", err)
+			}
+		}
+	})
+
+	compare := func(period string) map[string]struct{ Previous, Current, Change float64 } {
+		t.Helper()
+		out := captureStdout(t, func() error { return showComparison(period) })
+		var doc struct {
+			Data struct {
+				Current struct{ To string } `json:"current"`
+				Metrics []struct {
+					Metric                    string
+					Previous, Current, Change float64
+				} `json:"metrics"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatalf("Output is not JSON: %v\n%s", err, out)
+		}
+		if doc.Data.Current.To != today {
+			t.Errorf("%s: current period ends %s, want %s", period, doc.Data.Current.To, today)
+		}
+		metrics := make(map[string]struct{ Previous, Current, Change float64 })
+		for _, m := range doc.Data.Metrics {
+			metrics[m.Metric] = struct{ Previous, Current, Change float64 }{m.Previous, m.Current, m.Change}
+		}
+		return metrics
+	}
+
+	// A week ago falls in last week's stretch and today in this week's
+	jsonOutput = true
+	week := compare("week")
+	if k := week["keystrokes"]; k.Previous != 1001 || k.Current != 1201 || k.Change != 200 {
+		t.Errorf("keystrokes = %+v, want 1001 -> 1201 (+200)", k)
+	}
+	if w := week["words"]; w.Previous != 200 || w.Current != 150 || w.Change != -50 {
+		t.Errorf("words = %+v, want 200 -> 150 (-50)", w)
+	}
+	if c := week["clicks"]; c.Change != 0 {
+		t.Errorf("clicks = %+v, want no change", c)
+	}
+	if h := week["active_hours"]; h.Previous != 1 || h.Current != 1 {
+		t.Errorf("active_hours = %+v, want 1 -> 1", h)
+	}
+	for _, period := range []string{"month", "year"} {
+		if k := compare(period)["keystrokes"]; k.Current < 1201 {
+			t.Errorf("%s keystrokes = %+v, want today's 1201 in the current period", period, k)
+		}
+	}
+
+	jsonOutput = false
+	out := captureStdout(t, func() error { return showComparison("week") })
+	for _, want := range []string{"This week vs last week", "▲ +200", "▼ -50", "no change"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+
+	if err := showComparison("fortnight"); err == nil {
+		t.Error("Expected an error for an unknown period")
+	}
+}
+
+func TestShortcuts(t *testing.T) {
+	useTempDB(t, func(store *storage.Store) {
+		for i := 0; i < 3; i++ {
+			store.RecordChord(keyboard.KeyS, keyboard.ModCommand)
+		}
+		store.RecordChord(keyboard.KeyTab, keyboard.ModControl|keyboard.ModShift)
+		for i := 0; i < 4; i++ {
+			store.RecordKeystroke(keyboard.KeyE)
+		}
+	})
+
+	out := captureStdout(t, func() error { return showShortcuts(7, 20) })
+	for _, want := range []string{"Cmd+S", "75.0%", "Ctrl+Shift+Tab", "4 shortcut presses, 50.0% of keystrokes"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+
+	jsonOutput = true
+	out = captureStdout(t, func() error { return showShortcuts(7, 1) })
+	var doc struct {
+		Data shortcutsReport `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("Output is not JSON: %v\n%s", err, out)
+	}
+	r := doc.Data
+	if r.Range.Days != 7 || r.Keystrokes != 8 || r.Presses != 4 {
+		t.Errorf("Unexpected totals: %s", out)
+	}
+	if len(r.Shortcuts) != 1 || r.Shortcuts[0].Chord != "Cmd+S" || r.Shortcuts[0].Modifiers != keyboard.ModCommand {
+		t.Errorf("Unexpected shortcuts: %s", out)
+	}
+
+	if err := showShortcuts(0, 20); err == nil {
+		t.Error("Expected an error for --days 0")
+	}
+}
+
+func TestRecountWords(t *testing.T) {
+	useTempDB(t, func(store *storage.Store) {
+		for _, code := range []int{keyboard.KeyG, keyboard.KeyO, keyboard.KeySpace, keyboard.KeySpace, keyboard.KeySpace} {
+			store.RecordKeystroke(code)
+		}
+		for i := 0; i < 3; i++ {
+			store.IncrementWordCount(store.Today())
+		}
+	})
+
+	out := captureStdout(t, recountWords)
+	if !strings.Contains(out, "corrected 1 from 3 to 1 words") {
+		t.Errorf("Unexpected output: %s", out)
+	}
+	out = captureStdout(t, recountWords)
+	if !strings.Contains(out, "already up to date") {
+		t.Errorf("Unexpected output on second run: %s", out)
+	}
+}
+
+func TestFormatPeakShift(t *testing.T) {
+	tests := []struct {
+		prev, cur int
+		want      string
+	}{
+		{10, 10, "no change"},
+		{10, 14, "4h later"},
+		{14, 10, "4h earlier"},
+		{22, 1, "3h later"},
+		{1, 22, "3h earlier"},
+	}
+	for _, tt := range tests {
+		if got := formatPeakShift(tt.prev, 1, tt.cur, 1); got != tt.want {
+			t.Errorf("formatPeakShift(%d, %d) = %q, want %q", tt.prev, tt.cur, got, tt.want)
+		}
+	}
+	if got := formatPeakShift(10, 0, 14, 1); got != "" {
+		t.Errorf("formatPeakShift without previous activity = %q, want empty", got)
+	}
+}
+
+func TestFormatTarget(t *testing.T) {
+	feet := report.Units{Distance: storage.DistanceUnitFeet}
+	cars := report.Units{Distance: storage.DistanceUnitCars}
+	tests := []struct {
+		target goals.Target
+		units  report.Units
+		want   string
+	}{
+		{goals.Target{Metric: goals.Keystrokes, Goal: 10000, Value: 7450}, feet, "7,450 / 10,000 (74%)"},
+		{goals.Target{Metric: goals.Words, Goal: 2000, Value: 2100, Met: true}, feet, "2,100 / 2,000 ✓"},
+		{goals.Target{Metric: goals.MouseDistance, Goal: 500, Value: 120, Max: true, Met: true}, feet, "120ft of at most 500ft"},
+		{goals.Target{Metric: goals.MouseDistance, Goal: 300, Value: 450, Max: true}, cars, "30 cars of at most 20 cars, over"},
+	}
+	for _, tt := range tests {
+		if got := formatTarget(tt.target, tt.units); got != tt.want {
+			t.Errorf("formatTarget(%+v) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMinutes(t *testing.T) {
+	tests := []struct {
+		minutes float64
+		want    string
+	}{
+		{0, "0m"},
+		{0.4, "0m"},
+		{48.6, "49m"},
+		{60, "1h 00m"},
+		{192.2, "3h 12m"},
+	}
+	for _, tt := range tests {
+		if got := formatMinutes(tt.minutes); got != tt.want {
+			t.Errorf("formatMinutes(%v) = %q, want %q", tt.minutes, got, tt.want)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	for n, want := range map[int]string{0: "0 breaks", 1: "1 break", 2: "2 breaks"} {
+		if got := plural(n, "break"); got != want {
+			t.Errorf("plural(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestMetricsOnOff(t *testing.T) {
+	useTempDB(t, nil)
+	status := func() (enabled bool, addr string) {
+		t.Helper()
+		jsonOutput = true
+		defer func() { jsonOutput = false }()
+		out := captureStdout(t, showMetricsStatus)
+		var doc struct {
+			Data struct {
+				Enabled bool   `json:"enabled"`
+				Addr    string `json:"addr"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatalf("Output is not JSON: %v\n%s", err, out)
+		}
+		return doc.Data.Enabled, doc.Data.Addr
+	}
+
+	if enabled, addr := status(); enabled || addr != "" {
+		t.Errorf("Metrics = %v at %q before they were turned on, want off", enabled, addr)
+	}
+
+	if err := setMetricsAddr("127.0.0.1:9100"); err != nil {
+		t.Fatalf("setMetricsAddr failed: %v", err)
+	}
+	if enabled, addr := status(); !enabled || addr != "127.0.0.1:9100" {
+		t.Errorf("Metrics = %v at %q, want on at 127.0.0.1:9100", enabled, addr)
+	}
+	if out := captureStdout(t, showMetricsStatus); !strings.Contains(out, "http://127.0.0.1:9100/metrics") {
+		t.Errorf("Unexpected status: %s", out)
+	}
+
+	// An invalid address leaves the setting alone
+	if err := setMetricsAddr("not an address"); err == nil {
+		t.Error("Expected an error for an invalid address")
+	}
+	if _, addr := status(); addr != "127.0.0.1:9100" {
+		t.Errorf("Addr = %q after an invalid address, want 127.0.0.1:9100", addr)
+	}
+
+	if err := setMetricsAddr(""); err != nil {
+		t.Fatalf("setMetricsAddr(\"\") failed: %v", err)
+	}
+	if enabled, addr := status(); enabled || addr != "" {
+		t.Errorf("Metrics = %v at %q after turning off, want off", enabled, addr)
+	}
+	if out := captureStdout(t, showMetricsStatus); out != "Metrics off\n" {
+		t.Errorf("Unexpected status: %q", out)
+	}
+}
+
+func TestTodayFormat(t *testing.T) {
+	useTempDB(t, nil)
+
+	if todayCmd.Flags().Lookup("format") == nil {
+		t.Fatal("todayCmd should have --format flag")
+	}
+	for _, format := range []string{"", "waybar", "i3blocks", "tmux", "{{.Title}}"} {
+		if err := showToday(format); err != nil {
+			t.Errorf("showToday(%q) failed: %v", format, err)
+		}
+	}
+	if err := showToday("{{.Missing}}"); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+// useTempDB points the commands at a fresh database, seeded by seed if it
+// isn't nil, with plain text output for the rest of the test
+func useTempDB(t *testing.T, seed func(store *storage.Store)) {
+	t.Helper()
+	origDB, origJSON := dbPath, jsonOutput
+	t.Cleanup(func() { dbPath, jsonOutput = origDB, origJSON })
+	dbPath = filepath.Join(t.TempDir(), "typtel.db")
+	jsonOutput = false
+
+	store, err := openStore()
+	if err != nil {
+		t.Fatalf("openStore failed: %v", err)
+	}
+	defer store.Close()
+	if seed != nil {
+		seed(store)
+	}
+}
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	orig := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe failed: %v", err)
+	}
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- b
+	}()
+
+	runErr := fn()
+	w.Close()
+	os.Stdout = orig
+	out := <-done
+	if runErr != nil {
+		t.Fatalf("command failed: %v", runErr)
+	}
+	return string(out)
+}
+
+func TestJSONOutput(t *testing.T) {
+	useTempDB(t, func(store *storage.Store) {
+		if err := store.AddDailyTotals(store.Today(), 1200, 240); err != nil {
+			t.Fatalf("AddDailyTotals failed: %v", err)
+		}
+		if err := store.AddMouseTotals(store.Today(), 5000, 12, 30); err != nil {
+			t.Fatalf("AddMouseTotals failed: %v", err)
+		}
+		now := time.Now()
+		if err := store.RecordTypingBurst(speed.Burst{Start: now, End: now.Add(12 * time.Second), Chars: 60}); err != nil {
+			t.Fatalf("RecordTypingBurst failed: %v", err)
+		}
+		for _, code := range []int{keyboard.KeyA, keyboard.KeyA, keyboard.KeyA, keyboard.KeyDelete} {
+			if err := store.RecordKeystrokeAt(code, now); err != nil {
+				t.Fatalf("RecordKeystrokeAt failed: %v", err)
+			}
+		}
+	})
+
+	if rootCmd.PersistentFlags().Lookup("json") == nil {
+		t.Fatal("rootCmd should have a persistent --json flag")
+	}
+	jsonOutput = true
+
+	tests := []struct {
+		command string
+		run     func() error
+		check   func(t *testing.T, data json.RawMessage)
+	}{
+		{"stats", showStats, func(t *testing.T, data json.RawMessage) {
+			var r struct {
+				Today       storage.DailyStats      `json:"today"`
+				TodayMouse  storage.MouseDailyStats `json:"today_mouse"`
+				Week        []storage.DailyStats    `json:"week"`
+				WeekTotal   storage.DailyStats      `json:"week_total"`
+				TypingTest  storage.TypingTestStats `json:"typing_test"`
+				Corrections struct {
+					Today corrections.Counts         `json:"today"`
+					Daily []storage.DailyCorrections `json:"daily"`
+				} `json:"corrections"`
+				Sessions storage.DailySessions `json:"sessions"`
+				Goals    storage.GoalStatus    `json:"goals"`
+			}
+			if err := json.Unmarshal(data, &r); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if r.Corrections.Today != (corrections.Counts{Chars: 3, Corrections: 1}) || len(r.Corrections.Daily) != 1 {
+				t.Errorf("Unexpected corrections: %s", data)
+			}
+			if r.Sessions.Sessions != 1 || r.Sessions.Date != r.Today.Date {
+				t.Errorf("Unexpected sessions: %s", data)
+			}
+			if r.Goals.Date != r.Today.Date || r.Goals.Targets == nil || r.Goals.Streak.Current != 0 {
+				t.Errorf("Unexpected goals: %s", data)
+			}
+			if r.Today.Keystrokes != 1204 || r.TodayMouse.ClickCount != 12 || r.WeekTotal.Words != 240 || len(r.Week) != 7 {
+				t.Errorf("Unexpected stats: %s", data)
+			}
+		}},
+		{"today", func() error { return showToday("") }, func(t *testing.T, data json.RawMessage) {
+			var r struct {
+				Today storage.DailyStats      `json:"today"`
+				Mouse storage.MouseDailyStats `json:"mouse"`
+				Speed storage.Speed           `json:"speed"`
+			}
+			if err := json.Unmarshal(data, &r); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if r.Today.Keystrokes != 1204 || r.Mouse.TotalDistance != 5000 || r.Speed.MedianWPM != 60 {
+				t.Errorf("Unexpected today: %s", data)
+			}
+		}},
+		{"sessions", func() error { return showSessions(3) }, func(t *testing.T, data json.RawMessage) {
+			var r struct {
+				IdleMinutes int                     `json:"idle_minutes"`
+				Days        []storage.DailySessions `json:"days"`
+			}
+			if err := json.Unmarshal(data, &r); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if r.IdleMinutes != 5 || len(r.Days) != 3 || r.Days[2].Sessions != 1 || r.Days[0].Sessions != 0 {
+				t.Errorf("Unexpected sessions: %s", data)
+			}
+		}},
+		{"breaks", func() error { return showBreaks(7) }, func(t *testing.T, data json.RawMessage) {
+			var r struct {
+				Enabled bool `json:"enabled"`
+				Rules   []struct {
+					Kind          string  `json:"kind"`
+					ActiveMinutes float64 `json:"active_minutes"`
+				} `json:"rules"`
+				Stats []storage.BreakStats `json:"stats"`
+			}
+			if err := json.Unmarshal(data, &r); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if r.Enabled || len(r.Rules) != 1 || r.Rules[0].ActiveMinutes != 50 || r.Stats == nil || len(r.Stats) != 0 {
+				t.Errorf("Unexpected breaks: %s", data)
+			}
+		}},
+		{"achievements", showAchievements, func(t *testing.T, data json.RawMessage) {
+			var r []struct {
+				ID       string `json:"id"`
+				Unlocked bool   `json:"unlocked"`
+			}
+			if err := json.Unmarshal(data, &r); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if len(r) != len(achievements.All) || r[0].ID != "keystrokes-1m" || r[0].Unlocked {
+				t.Errorf("Unexpected achievements: %s", data)
+			}
+		}},
+		{"stats compare", func() error { return showComparison("week") }, func(t *testing.T, data json.RawMessage) {
+			var r struct {
+				Period  string `json:"period"`
+				Metrics []struct {
+					Metric  string  `json:"metric"`
+					Current float64 `json:"current"`
+				} `json:"metrics"`
+			}
+			if err := json.Unmarshal(data, &r); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if r.Period != "week" || len(r.Metrics) != 5 || r.Metrics[0].Metric != "keystrokes" || r.Metrics[0].Current != 1204 {
+				t.Errorf("Unexpected comparison: %s", data)
+			}
+		}},
+		{"leaderboard", func() error { return showLeaderboard(5) }, func(t *testing.T, data json.RawMessage) {
+			var entries []storage.MouseLeaderboardEntry
+			if err := json.Unmarshal(data, &entries); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if len(entries) != 1 || entries[0].Rank != 1 || entries[0].TotalDistance != 5000 {
+				t.Errorf("Unexpected leaderboard: %s", data)
+			}
+		}},
+		{"leaderboard fewest-clicks", func() error {
+			b, _ := leaderboard.Get("fewest-clicks")
+			return showBoard(b, storage.LeaderboardOptions{Limit: 5})
+		}, func(t *testing.T, data json.RawMessage) {
+			var r struct {
+				ID      string                     `json:"id"`
+				Entries []storage.LeaderboardEntry `json:"entries"`
+			}
+			if err := json.Unmarshal(data, &r); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if r.ID != "fewest-clicks" || len(r.Entries) != 1 || r.Entries[0].Value != 12 {
+				t.Errorf("Unexpected leaderboard: %s", data)
+			}
+		}},
+		{"import list", showImportLog, func(t *testing.T, data json.RawMessage) {
+			if string(data) != "[]" {
+				t.Errorf("Expected an empty list, got %s", data)
+			}
+		}},
+		{"metrics status", showMetricsStatus, func(t *testing.T, data json.RawMessage) {
+			if !strings.Contains(string(data), `"enabled": false`) {
+				t.Errorf("Unexpected metrics status: %s", data)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			out := captureStdout(t, tt.run)
+			var doc struct {
+				Version int             `json:"version"`
+				Command string          `json:"command"`
+				Data    json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal([]byte(out), &doc); err != nil {
+				t.Fatalf("Output is not JSON: %v\n%s", err, out)
+			}
+			if doc.Version != jsonVersion || doc.Command != tt.command {
+				t.Errorf("version %d command %q, want %d %q", doc.Version, doc.Command, jsonVersion, tt.command)
+			}
+			tt.check(t, doc.Data)
+		})
+	}
+}
+
+func TestConfigCommands(t *testing.T) {
+	useTempDB(t, nil)
+	origConfig := configPath
+	defer func() { configPath = origConfig }()
+	configPath = filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("[mouse]\ndistance_unit = \"cars\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	sources := func() map[string]string {
+		out := captureStdout(t, listConfig)
+		got := map[string]string{}
+		for _, line := range strings.Split(out, "\n") {
+			if f := strings.Fields(line); len(f) == 3 {
+				got[f[0]] = f[1] + " " + f[2]
+			}
+		}
+		return got
+	}
+
+	list := sources()
+	if list["mouse.distance_unit"] != "cars file" {
+		t.Errorf("mouse.distance_unit = %q, want cars from the file", list["mouse.distance_unit"])
+	}
+	if list["menubar.show_words"] != "true default" {
+		t.Errorf("menubar.show_words = %q, want the default", list["menubar.show_words"])
+	}
+
+	if err := setConfig("mouse.distance_unit", "frisbee"); err != nil {
+		t.Fatalf("setConfig failed: %v", err)
+	}
+	if got := captureStdout(t, func() error { return getConfig("mouse.distance_unit") }); got != "frisbee\n" {
+		t.Errorf("getConfig = %q, want frisbee", got)
+	}
+	if list := sources(); list["mouse.distance_unit"] != "frisbee db" {
+		t.Errorf("mouse.distance_unit = %q, want frisbee from the database", list["mouse.distance_unit"])
+	}
+
+	for _, args := range [][2]string{
+		{"mouse.distance_unit", "parsecs"},
+		{"inertia.threshold_ms", "-5"},
+		{"mouse.units", "cars"},
+	} {
+		if err := setConfig(args[0], args[1]); err == nil {
+			t.Errorf("setConfig(%q, %q) should fail", args[0], args[1])
+		}
+	}
+
+	if err := unsetConfig("mouse.distance_unit"); err != nil {
+		t.Fatalf("unsetConfig failed: %v", err)
+	}
+	if got := captureStdout(t, func() error { return getConfig("mouse.distance_unit") }); got != "cars\n" {
+		t.Errorf("getConfig after unset = %q, want cars", got)
+	}
+
+	// Setting a goal records it from today on, after the goals before it
+	if err := setConfig("goals.keystrokes", "10000"); err != nil {
+		t.Fatalf("setConfig failed: %v", err)
+	}
+	store, err := openStoreReadOnly()
+	if err != nil {
+		t.Fatalf("openStoreReadOnly failed: %v", err)
+	}
+	history, err := store.GetGoalHistory()
+	store.Close()
+	if err != nil || len(history) != 2 || history[0].Goals.Keystrokes != 0 || history[1].Goals.Keystrokes != 10000 {
+		t.Errorf("Goal history = %+v, %v; want no goal until today", history, err)
+	}
+
+	// A broken config file stops commands rather than being ignored
+	if err := os.WriteFile(configPath, []byte("[mouse]\ndistance_unit = 3\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := openStoreReadOnly(); err == nil {
+		t.Error("expected an error for an invalid config file")
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+// Flags for metrics commands
+var metricsAddr string
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Expose capture daemon metrics to Prometheus",
+	Long: `Have the menu bar app serve Prometheus metrics at /metrics.
+
+Metrics:
+  typtel_keystrokes_total               Keystrokes recorded
+  typtel_words_total                    Words recorded
+  typtel_mouse_clicks_total             Mouse clicks recorded
+  typtel_mouse_distance_pixels_total    Mouse distance recorded, in pixels
+  typtel_dropped_events_total           Events dropped by a full capture channel, by source
+  typtel_db_write_duration_seconds      Database write latency histogram, by op
+
+Counters start from zero when the app starts. Changes take effect the next
+time the menu bar app starts.
+
+Examples:
+  typtel metrics on
+  typtel metrics on --addr 127.0.0.1:9100
+  curl http://127.0.0.1:9777/metrics`,
+}
+
+var metricsOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Serve metrics from the menu bar app",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setMetricsAddr(metricsAddr)
+	},
+}
+
+var metricsOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Stop serving metrics",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setMetricsAddr("")
+	},
+}
+
+var metricsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where metrics are served",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showMetricsStatus()
+	},
+}
+
+func init() {
+	metricsOnCmd.Flags().StringVar(&metricsAddr, "addr", metrics.DefaultAddr, "Address to listen on")
+
+	metricsCmd.AddCommand(metricsOnCmd)
+	metricsCmd.AddCommand(metricsOffCmd)
+	metricsCmd.AddCommand(metricsStatusCmd)
+	rootCmd.AddCommand(metricsCmd)
+}
+
+func setMetricsAddr(addr string) error {
+	if addr != "" {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid address %q: %w", addr, err)
+		}
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	if err := store.SetMetricsAddr(addr); err != nil {
+		return fmt.Errorf("failed to save metrics address: %w", err)
+	}
+	if addr == "" {
+		if file := store.MetricsAddr(); file != "" {
+			return fmt.Errorf("metrics.addr = %q is set in %s; remove it there to turn metrics off", file, store.ConfigFile().Path)
+		}
+		fmt.Println("Metrics off. Restart the menu bar app to apply.")
+	} else {
+		fmt.Printf("Metrics will be served at http://%s/metrics. Restart the menu bar app to apply.\n", addr)
+	}
+	return nil
+}
+
+func showMetricsStatus() error {
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	if jsonOutput {
+		addr := store.MetricsAddr()
+		return printJSON("metrics status", struct {
+			Enabled bool   `json:"enabled"`
+			Addr    string `json:"addr"`
+		}{addr != "", addr})
+	}
+
+	if addr := store.MetricsAddr(); addr != "" {
+		fmt.Printf("Metrics served at http://%s/metrics\n", addr)
+	} else {
+		fmt.Println("Metrics off")
+	}
+	return nil
+}
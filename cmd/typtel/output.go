@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// jsonVersion is the version of every --json document. Adding fields keeps
+// it; renaming, removing or changing the meaning of one bumps it.
+const jsonVersion = 1
+
+// jsonOutput is set by the global --json flag
+var jsonOutput bool
+
+// jsonDocument wraps the output of every reporting command under --json
+type jsonDocument struct {
+	Version int         `json:"version"`
+	Command string      `json:"command"` // e.g. "stats compare"
+	Data    interface{} `json:"data"`
+}
+
+// printJSON writes data as the versioned document for command
+func printJSON(command string, data interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonDocument{Version: jsonVersion, Command: command, Data: data})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var recountWordsCmd = &cobra.Command{
+	Use:   "recount-words",
+	Short: "Recount words from the recorded keystrokes",
+	Long: `Replay the recorded keystrokes through the word detector and correct each
+day's word count.
+
+Words are counted when Space, Return or Tab follows typed characters, so
+repeated spaces, indentation and Return on an empty prompt no longer count,
+and backspaced words are left out. Run it once to correct days recorded by
+older versions, which counted every Space, Return and Tab.
+
+Imported words are kept, and days whose keystrokes were pruned by
+retention.keystroke_days keep their totals.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return recountWords()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recountWordsCmd)
+}
+
+func recountWords() error {
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	result, err := store.RecountWords()
+	if err != nil {
+		return fmt.Errorf("failed to recount words: %w", err)
+	}
+
+	if jsonOutput {
+		return printJSON("recount-words", result)
+	}
+
+	if result.Changed == 0 {
+		fmt.Printf("Checked %d days; word counts are already up to date.\n", result.Days)
+		return nil
+	}
+	fmt.Printf("Checked %d days; corrected %d from %s to %s words.\n",
+		result.Days, result.Changed, formatNum(result.Before), formatNum(result.After))
+	return nil
+}
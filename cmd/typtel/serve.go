@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for serve command
+	serveAddr string
+	serveOpen bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a live dashboard and JSON API over HTTP",
+	Long: `Serve a live dashboard and a read-only JSON API.
+
+Endpoints:
+  /             Live dashboard
+  /api/today    Today's keystrokes, words, clicks and mouse distance
+  /api/daily    Daily keystrokes and words
+  /api/hourly   Keystrokes per hour
+  /api/mouse    Daily mouse distance, clicks and movements
+  /api/keys     Presses per key per day
+  /api/tests    Typing test results
+
+Date range parameters: from=YYYY-MM-DD, to=YYYY-MM-DD, or days=N (default 30).
+
+Examples:
+  typtel serve
+  typtel serve --addr 127.0.0.1:8080
+  curl 'http://127.0.0.1:7777/api/daily?from=2024-01-01&to=2024-01-31'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServe()
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", server.DefaultAddr, "Address to listen on")
+	serveCmd.Flags().BoolVar(&serveOpen, "open", false, "Open the dashboard in a browser")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe() error {
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	srv := &http.Server{
+		Addr:              serveAddr,
+		Handler:           server.New(store),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	url := "http://" + serveAddr
+	fmt.Printf("Serving dashboard at %s (Ctrl+C to stop)\n", url)
+	if serveOpen {
+		if err := openBrowser(url); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open browser: %v\n", err)
+		}
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	"github.com/aayushbajaj/typing-telemetry/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+// Flags for sessions command
+var sessionsDays int
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Show active time, focus sessions and breaks per day",
+	Long: `Show how long you were actually active each day.
+
+Keyboard and mouse activity is split into sessions: a session ends once
+nothing happens for sessions.idle_minutes (5 by default), and the next
+activity starts a new one. Time away from the computer never counts as
+active, and each gap between sessions is a break. Sessions count towards the
+day they started on.
+
+Examples:
+  typtel sessions
+  typtel sessions --days 30
+  typtel config set sessions.idle_minutes 10`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showSessions(sessionsDays)
+	},
+}
+
+func init() {
+	sessionsCmd.Flags().IntVarP(&sessionsDays, "days", "d", 7, "Number of days to include, ending today")
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+// sessionsReport is the output of 'typtel sessions --json'
+type sessionsReport struct {
+	Range       reportRange             `json:"range"`
+	IdleMinutes int                     `json:"idle_minutes"`
+	Days        []storage.DailySessions `json:"days"` // every day of the range, oldest first
+}
+
+func showSessions(days int) error {
+	if days < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	today, _ := time.Parse("2006-01-02", store.Today())
+	r := stats.Range{From: today.AddDate(0, 0, -(days - 1)), To: today}
+	from, to := r.From.Format("2006-01-02"), r.To.Format("2006-01-02")
+
+	daily, err := store.GetDailySessionsRange(from, to)
+	if err != nil {
+		return fmt.Errorf("failed to get sessions: %w", err)
+	}
+	byDate := make(map[string]storage.DailySessions, len(daily))
+	for _, d := range daily {
+		byDate[d.Date] = d
+	}
+
+	rep := sessionsReport{Range: newReportRange(r), IdleMinutes: store.Config().Sessions.IdleMinutes}
+	for d := r.From; !d.After(r.To); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		day, ok := byDate[date]
+		if !ok {
+			day.Date = date
+		}
+		rep.Days = append(rep.Days, day)
+	}
+
+	if jsonOutput {
+		return printJSON("sessions", rep)
+	}
+
+	fmt.Println("⏱️  Active Time")
+	fmt.Println("────────────────────")
+	fmt.Printf("%s, sessions end after %d idle minutes\n\n", formatRange(r), rep.IdleMinutes)
+	if len(daily) == 0 {
+		fmt.Println("No activity recorded yet")
+		return nil
+	}
+
+	fmt.Printf("%-15s %8s %9s %8s %7s\n", "Day", "Active", "Sessions", "Longest", "Breaks")
+	var active, longest float64
+	var sessions, breaks int
+	for _, d := range rep.Days {
+		t, _ := time.Parse("2006-01-02", d.Date)
+		fmt.Printf("%-15s %8s %9d %8s %7d\n", t.Format("Mon Jan 2"), formatMinutes(d.ActiveMinutes), d.Sessions, formatMinutes(d.LongestMinutes), d.Breaks)
+		active += d.ActiveMinutes
+		longest = math.Max(longest, d.LongestMinutes)
+		sessions += d.Sessions
+		breaks += d.Breaks
+	}
+	fmt.Printf("\n%s active in %s, %s a day on average; longest session %s, %s\n",
+		formatMinutes(active), plural(sessions, "session"), formatMinutes(active/float64(days)), formatMinutes(longest), plural(breaks, "break"))
+	return nil
+}
+
+// formatMinutes formats a duration in minutes, e.g. "3h 05m" or "48m"
+func formatMinutes(minutes float64) string {
+	m := int(math.Round(minutes))
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh %02dm", m/60, m%60)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	"github.com/aayushbajaj/typing-telemetry/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+// Flags for shortcuts command
+var (
+	shortcutsDays  int
+	shortcutsLimit int
+)
+
+var shortcutsCmd = &cobra.Command{
+	Use:   "shortcuts",
+	Short: "Show the most used keyboard shortcuts",
+	Long: `List the key combinations pressed most often, e.g. Cmd+S or Ctrl+R.
+
+A shortcut is any key pressed with Ctrl, Opt or Cmd held; Shift alone only
+counts alongside one of those. Keystrokes recorded before modifiers were
+tracked count as plain keys.
+
+Examples:
+  typtel shortcuts
+  typtel shortcuts --days 365 --limit 50`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showShortcuts(shortcutsDays, shortcutsLimit)
+	},
+}
+
+func init() {
+	shortcutsCmd.Flags().IntVarP(&shortcutsDays, "days", "d", 30, "Number of days to include, ending today")
+	shortcutsCmd.Flags().IntVarP(&shortcutsLimit, "limit", "n", 20, "Number of shortcuts to show")
+	rootCmd.AddCommand(shortcutsCmd)
+}
+
+// shortcutUsage is one shortcut in 'typtel shortcuts'
+type shortcutUsage struct {
+	Chord     string             `json:"chord"` // e.g. "Cmd+S"
+	Keycode   int                `json:"keycode"`
+	Modifiers keyboard.Modifiers `json:"modifiers"`
+	Count     int64              `json:"count"`
+	Percent   float64            `json:"percent"` // share of all shortcut presses
+}
+
+// shortcutsReport is the output of 'typtel shortcuts --json'
+type shortcutsReport struct {
+	Range      reportRange     `json:"range"`
+	Keystrokes int64           `json:"keystrokes"`
+	Presses    int64           `json:"shortcut_presses"`
+	Shortcuts  []shortcutUsage `json:"shortcuts"`
+}
+
+func showShortcuts(days, limit int) error {
+	if days < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	today, _ := time.Parse("2006-01-02", store.Today())
+	r := stats.Range{From: today.AddDate(0, 0, -(days - 1)), To: today}
+	from, to := r.From.Format("2006-01-02"), r.To.Format("2006-01-02")
+
+	counts, err := store.GetShortcuts(from, to)
+	if err != nil {
+		return fmt.Errorf("failed to get shortcuts: %w", err)
+	}
+	daily, err := store.GetDailyRange(from, to)
+	if err != nil {
+		return fmt.Errorf("failed to get daily stats: %w", err)
+	}
+
+	rep := shortcutsReport{Range: newReportRange(r), Shortcuts: []shortcutUsage{}}
+	for _, d := range daily {
+		rep.Keystrokes += d.Keystrokes
+	}
+	for _, c := range counts {
+		rep.Presses += c.Count
+	}
+	for _, c := range topShortcuts(counts, limit) {
+		rep.Shortcuts = append(rep.Shortcuts, shortcutUsage{
+			Chord:     keyboard.Chord(c.Keycode, c.Modifiers),
+			Keycode:   c.Keycode,
+			Modifiers: c.Modifiers,
+			Count:     c.Count,
+			Percent:   float64(c.Count) / float64(rep.Presses) * 100,
+		})
+	}
+
+	if jsonOutput {
+		return printJSON("shortcuts", rep)
+	}
+
+	fmt.Println("⌨️  Keyboard Shortcuts")
+	fmt.Println("────────────────────")
+	fmt.Println(formatRange(r))
+	fmt.Println()
+	if len(rep.Shortcuts) == 0 {
+		fmt.Println("No shortcuts recorded yet")
+		return nil
+	}
+
+	width := 0
+	for _, s := range rep.Shortcuts {
+		width = max(width, len([]rune(s.Chord)))
+	}
+	for i, s := range rep.Shortcuts {
+		fmt.Printf("%3d. %-*s %10s  %5.1f%%\n", i+1, width, s.Chord, formatNum(s.Count), s.Percent)
+	}
+
+	fmt.Printf("\n%s shortcut presses", formatNum(rep.Presses))
+	if rep.Keystrokes > 0 {
+		fmt.Printf(", %.1f%% of keystrokes", float64(rep.Presses)/float64(rep.Keystrokes)*100)
+	}
+	fmt.Println()
+	return nil
+}
+
+// topShortcuts returns the first limit shortcuts, or all of them when limit
+// isn't positive
+func topShortcuts(counts []storage.ShortcutCount, limit int) []storage.ShortcutCount {
+	if limit > 0 && len(counts) > limit {
+		return counts[:limit]
+	}
+	return counts
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/devicesync"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for sync commands
+	syncDeviceName string
+	syncDays       int
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Share telemetry between devices through a synced folder",
+	Long: `Share telemetry between devices through a folder kept in sync by
+Syncthing, Dropbox, iCloud Drive or similar.
+
+Each device appends its own changes to <device-id>.ndjson in the folder and
+reads everyone else's, so there are never conflicting edits. The menu bar
+app pushes changes every few minutes once sync is set up.`,
+}
+
+var syncInitCmd = &cobra.Command{
+	Use:   "init <dir>",
+	Short: "Start syncing through a shared folder",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSyncInit(args[0])
+	},
+}
+
+var syncOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Stop syncing (the shared folder is left untouched)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore()
+		if err != nil {
+			return fmt.Errorf("failed to open storage: %w", err)
+		}
+		defer store.Close()
+		return store.SetSyncDir("")
+	},
+}
+
+var syncPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Write this device's changes to the shared folder now",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSyncPush()
+	},
+}
+
+var syncStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show every device in the shared folder and when it was last seen",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showSyncStatus()
+	},
+}
+
+var syncStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show daily totals across all devices",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showSyncStats()
+	},
+}
+
+func init() {
+	syncInitCmd.Flags().StringVar(&syncDeviceName, "name", "", "Name other devices show for this one (default: hostname)")
+	syncStatsCmd.Flags().IntVarP(&syncDays, "days", "d", 7, "Number of days to show")
+
+	syncCmd.AddCommand(syncInitCmd)
+	syncCmd.AddCommand(syncOffCmd)
+	syncCmd.AddCommand(syncPushCmd)
+	syncCmd.AddCommand(syncStatusCmd)
+	syncCmd.AddCommand(syncStatsCmd)
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSyncInit(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create sync folder: %w", err)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	if syncDeviceName != "" {
+		if err := store.SetDeviceName(syncDeviceName); err != nil {
+			return err
+		}
+	}
+	if err := store.SetSyncDir(dir); err != nil {
+		return err
+	}
+
+	if _, err := devicesync.Push(store, dir); err != nil {
+		return err
+	}
+
+	id, err := store.DeviceID()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Syncing %s as %q through %s\n", id, store.DeviceName(), dir)
+	return nil
+}
+
+func runSyncPush() error {
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	dir := store.SyncDir()
+	if dir == "" {
+		return fmt.Errorf("sync is not set up; run 'typtel sync init <dir>' first")
+	}
+
+	cs, err := devicesync.Push(store, dir)
+	if err != nil {
+		return err
+	}
+	if cs == nil {
+		fmt.Println("Nothing new to push")
+		return nil
+	}
+
+	var keystrokes int64
+	for _, h := range cs.Hours {
+		keystrokes += h.Keystrokes
+	}
+	fmt.Printf("Pushed changeset %d (%s keystrokes)\n", cs.Seq, report.FormatAbsolute(keystrokes))
+	return nil
+}
+
+// openSyncView opens the database read-only and merges the shared folder
+func openSyncView() (*storage.Store, *devicesync.View, string, error) {
+	store, err := openStoreReadOnly()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to open storage: %w", err)
+	}
+
+	dir := store.SyncDir()
+	if dir == "" {
+		store.Close()
+		return nil, nil, "", fmt.Errorf("sync is not set up; run 'typtel sync init <dir>' first")
+	}
+
+	view, err := devicesync.Load(dir)
+	if err != nil {
+		store.Close()
+		return nil, nil, "", fmt.Errorf("failed to read sync folder: %w", err)
+	}
+
+	localID, _ := store.GetSetting(storage.SettingDeviceID)
+	return store, view, localID, nil
+}
+
+// syncStatusReport is the output of 'typtel sync status --json'
+type syncStatusReport struct {
+	Folder  string             `json:"folder"`
+	LocalID string             `json:"local_id"`
+	Devices []syncDeviceReport `json:"devices"`
+	Skipped int                `json:"skipped_changesets"`
+}
+
+type syncDeviceReport struct {
+	*devicesync.Device
+	Keystrokes int64 `json:"keystrokes"`
+}
+
+// syncStatsReport is the output of 'typtel sync stats --json'
+type syncStatsReport struct {
+	LocalID string                    `json:"local_id"`
+	Devices map[string]string         `json:"devices"` // names by device ID
+	Days    []devicesync.DayBreakdown `json:"days"`
+}
+
+func showSyncStatus() error {
+	store, view, localID, err := openSyncView()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if jsonOutput {
+		r := syncStatusReport{Folder: store.SyncDir(), LocalID: localID, Devices: []syncDeviceReport{}, Skipped: view.Skipped}
+		for _, d := range view.Devices() {
+			r.Devices = append(r.Devices, syncDeviceReport{Device: d, Keystrokes: d.TotalKeystrokes()})
+		}
+		return printJSON("sync status", r)
+	}
+
+	fmt.Println("🔄 Sync Status")
+	fmt.Println("────────────────────")
+	fmt.Printf("Folder: %s\n\n", store.SyncDir())
+
+	devices := view.Devices()
+	if len(devices) == 0 {
+		fmt.Println("No devices have pushed yet")
+		return nil
+	}
+
+	now := time.Now()
+	for _, d := range devices {
+		name := d.Name
+		if d.ID == localID {
+			name += " (this device)"
+		}
+		fmt.Printf("%-28s %s  last seen %s  %s keystrokes\n",
+			name, d.ID, formatLastSeen(d.LastSeen, now), report.FormatAbsolute(d.TotalKeystrokes()))
+	}
+
+	if view.Skipped > 0 {
+		fmt.Printf("\n%d unreadable changesets skipped (still syncing, or from a newer typtel)\n", view.Skipped)
+	}
+	return nil
+}
+
+func showSyncStats() error {
+	store, view, localID, err := openSyncView()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if syncDays < 1 {
+		syncDays = 1
+	}
+	history, err := store.GetHistoricalStats(syncDays)
+	if err != nil {
+		return err
+	}
+	dates := make([]string, len(history))
+	for i, day := range history {
+		dates[i] = day.Date
+	}
+
+	days, err := view.Breakdown(store, localID, dates)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		r := syncStatsReport{LocalID: localID, Devices: map[string]string{localID: store.DeviceName()}, Days: days}
+		for _, d := range view.Devices() {
+			if d.ID != localID {
+				r.Devices[d.ID] = d.Name
+			}
+		}
+		if r.Days == nil {
+			r.Days = []devicesync.DayBreakdown{}
+		}
+		return printJSON("sync stats", r)
+	}
+
+	// Columns: this device first, then the others by name
+	ids := []string{localID}
+	names := []string{store.DeviceName()}
+	for _, d := range view.Devices() {
+		if d.ID != localID {
+			ids = append(ids, d.ID)
+			names = append(names, d.Name)
+		}
+	}
+
+	fmt.Printf("%-12s", "Date")
+	for _, name := range names {
+		fmt.Printf(" %12.12s", name)
+	}
+	fmt.Printf(" %12s\n", "Total")
+
+	for _, day := range days {
+		fmt.Printf("%-12s", day.Date)
+		for _, id := range ids {
+			fmt.Printf(" %12s", formatNum(day.ByDevice[id].Keystrokes))
+		}
+		fmt.Printf(" %12s\n", formatNum(day.Total.Keystrokes))
+	}
+	return nil
+}
+
+// formatLastSeen renders a timestamp with how long ago it was
+func formatLastSeen(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	ago := now.Sub(t)
+	var rel string
+	switch {
+	case ago < time.Minute:
+		rel = "just now"
+	case ago < time.Hour:
+		rel = fmt.Sprintf("%dm ago", int(ago.Minutes()))
+	case ago < 48*time.Hour:
+		rel = fmt.Sprintf("%dh ago", int(ago.Hours()))
+	default:
+		rel = fmt.Sprintf("%dd ago", int(ago.Hours()/24))
+	}
+	return fmt.Sprintf("%s (%s)", t.Local().Format("2006-01-02 15:04"), rel)
+}
@@ -4,6 +4,7 @@ go 1.24.4
 
 require (
 	fyne.io/systray v1.12.0
+	github.com/BurntSushi/toml v1.5.0
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/lipgloss v1.1.0
 	github.com/mattn/go-sqlite3 v1.14.32
@@ -0,0 +1,114 @@
+// Package achievements awards badges for milestones in the typing history.
+//
+// Each badge unlocks when one measured stat, such as total keystrokes or the
+// best typing test, reaches its goal; the stillness badge instead asks for a
+// week of little mouse travel. Stats not measured yet, like a typing test
+// never taken, unlock nothing, and a badge once earned is kept along with
+// when it was earned.
+package achievements
+
+import "time"
+
+// NotificationKind marks unlocked achievements sent with internal/notify
+const NotificationKind = "achievement"
+
+// Stat names a measurement achievements are judged by
+type Stat string
+
+const (
+	TotalKeystrokes     Stat = "total_keystrokes"
+	BestTestWPM         Stat = "best_test_wpm"
+	StillestWeekFeet    Stat = "stillest_week_feet"    // least mouse travel over 7 tracked days in a row
+	LongestStreak       Stat = "longest_streak"        // days in a row daily goals were met
+	LongestNightSession Stat = "longest_night_session" // minutes of the longest session begun between midnight and 4am
+)
+
+// Stats are measured values by stat
+type Stats map[Stat]float64
+
+// Achievement is a milestone and the rule that unlocks it
+type Achievement struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Icon        string  `json:"icon"`
+	Description string  `json:"description"`
+	Stat        Stat    `json:"stat"`
+	Goal        float64 `json:"goal"`
+	AtMost      bool    `json:"at_most,omitempty"` // unlocked at or below Goal rather than at or above
+}
+
+// All lists every achievement in display order
+var All = []Achievement{
+	{ID: "keystrokes-1m", Name: "Millionaire", Icon: "⌨️", Description: "Type 1,000,000 keystrokes", Stat: TotalKeystrokes, Goal: 1000000},
+	{ID: "test-100wpm", Name: "Triple Digits", Icon: "⚡", Description: "Reach 100 WPM in a typing test", Stat: BestTestWPM, Goal: 100},
+	{ID: "still-week", Name: "Statue", Icon: "🗿", Description: "Move the mouse under 100 ft in a week", Stat: StillestWeekFeet, Goal: 100, AtMost: true},
+	{ID: "streak-30", Name: "On Fire", Icon: "🔥", Description: "Meet your daily goals 30 days in a row", Stat: LongestStreak, Goal: 30},
+	{ID: "night-owl", Name: "Night Owl", Icon: "🦉", Description: "Work a 30 minute session begun between midnight and 4am", Stat: LongestNightSession, Goal: 30},
+}
+
+// Met reports whether stats unlock a
+func (a Achievement) Met(stats Stats) bool {
+	v, ok := stats[a.Stat]
+	if !ok {
+		return false
+	}
+	if a.AtMost {
+		return v <= a.Goal
+	}
+	return v >= a.Goal
+}
+
+// Progress returns how close stats are to unlocking a, from 0 to 1
+func (a Achievement) Progress(stats Stats) float64 {
+	v, ok := stats[a.Stat]
+	switch {
+	case !ok:
+		return 0
+	case a.Met(stats):
+		return 1
+	case a.AtMost:
+		return a.Goal / v
+	case a.Goal <= 0:
+		return 0
+	}
+	return v / a.Goal
+}
+
+// Status is an achievement and whether it has been unlocked
+type Status struct {
+	Achievement
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlocked_at,omitempty"` // nil if not unlocked, or met but not recorded yet
+	Value      *float64   `json:"value,omitempty"`       // the stat as measured; nil before it has been
+	Progress   float64    `json:"progress"`              // 0 to 1
+}
+
+// Evaluate returns the status of every achievement given stats and the
+// unlock times recorded so far
+func Evaluate(stats Stats, unlocked map[string]time.Time) []Status {
+	statuses := make([]Status, len(All))
+	for i, a := range All {
+		s := Status{Achievement: a, Progress: a.Progress(stats)}
+		if v, ok := stats[a.Stat]; ok {
+			s.Value = &v
+		}
+		if t, ok := unlocked[a.ID]; ok {
+			s.Unlocked, s.UnlockedAt, s.Progress = true, &t, 1
+		} else {
+			s.Unlocked = a.Met(stats)
+		}
+		statuses[i] = s
+	}
+	return statuses
+}
+
+// Newly returns the achievements stats unlock that aren't in unlocked yet
+func Newly(stats Stats, unlocked map[string]time.Time) []Achievement {
+	var newly []Achievement
+	for _, a := range All {
+		if _, ok := unlocked[a.ID]; !ok && a.Met(stats) {
+			newly = append(newly, a)
+		}
+	}
+	return newly
+}
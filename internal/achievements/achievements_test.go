@@ -0,0 +1,81 @@
+package achievements
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMet(t *testing.T) {
+	most := Achievement{Stat: StillestWeekFeet, Goal: 100, AtMost: true}
+	least := Achievement{Stat: TotalKeystrokes, Goal: 1000}
+
+	tests := []struct {
+		name         string
+		a            Achievement
+		stats        Stats
+		want         bool
+		wantProgress float64
+	}{
+		{"not measured", least, Stats{}, false, 0},
+		{"short", least, Stats{TotalKeystrokes: 250}, false, 0.25},
+		{"reached", least, Stats{TotalKeystrokes: 1000}, true, 1},
+		{"not measured at most", most, Stats{}, false, 0},
+		{"under", most, Stats{StillestWeekFeet: 99}, true, 1},
+		{"over", most, Stats{StillestWeekFeet: 400}, false, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Met(tt.stats); got != tt.want {
+				t.Errorf("Met = %v, want %v", got, tt.want)
+			}
+			if got := tt.a.Progress(tt.stats); got != tt.wantProgress {
+				t.Errorf("Progress = %v, want %v", got, tt.wantProgress)
+			}
+		})
+	}
+}
+
+func TestAllHaveUniqueIDs(t *testing.T) {
+	seen := map[string]bool{}
+	for _, a := range All {
+		if a.ID == "" || a.Name == "" || a.Description == "" || a.Stat == "" {
+			t.Errorf("Incomplete achievement %+v", a)
+		}
+		if seen[a.ID] {
+			t.Errorf("Duplicate achievement ID %q", a.ID)
+		}
+		seen[a.ID] = true
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	at := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	stats := Stats{TotalKeystrokes: 2000000, BestTestWPM: 80, LongestStreak: 30}
+	unlocked := map[string]time.Time{"keystrokes-1m": at}
+
+	statuses := Evaluate(stats, unlocked)
+	if len(statuses) != len(All) {
+		t.Fatalf("Evaluate returned %d statuses, want %d", len(statuses), len(All))
+	}
+	byID := map[string]Status{}
+	for _, s := range statuses {
+		byID[s.ID] = s
+	}
+	if s := byID["keystrokes-1m"]; !s.Unlocked || s.UnlockedAt == nil || !s.UnlockedAt.Equal(at) {
+		t.Errorf("keystrokes-1m = %+v, want unlocked at %v", s, at)
+	}
+	if s := byID["streak-30"]; !s.Unlocked || s.UnlockedAt != nil {
+		t.Errorf("streak-30 = %+v, want met but not recorded", s)
+	}
+	if s := byID["test-100wpm"]; s.Unlocked || s.Progress != 0.8 || s.Value == nil || *s.Value != 80 {
+		t.Errorf("test-100wpm = %+v, want 80%% of the way", s)
+	}
+	if s := byID["night-owl"]; s.Unlocked || s.Value != nil {
+		t.Errorf("night-owl = %+v, want unmeasured", s)
+	}
+
+	newly := Newly(stats, unlocked)
+	if len(newly) != 1 || newly[0].ID != "streak-30" {
+		t.Errorf("Newly = %+v, want only streak-30", newly)
+	}
+}
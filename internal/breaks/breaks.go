@@ -0,0 +1,175 @@
+// Package breaks schedules rest breaks to head off repetitive strain.
+//
+// A Rule asks for a pause of Length once activity has gone on too long
+// without one: either a stretch of Active time (a break, e.g. 5 minutes
+// every 50) or a number of Keystrokes (a micro-pause, e.g. 10 seconds every
+// 300 keystrokes). Any pause of at least Length resets a rule, asked for or
+// not. Once a rule is due the Scheduler reports it so a reminder can be
+// sent. The break counts as taken if a pause of Length follows, and as
+// skipped if activity is still going Length after the reminder.
+package breaks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+	"github.com/aayushbajaj/typing-telemetry/internal/notify"
+)
+
+// Kind names a type of break
+type Kind string
+
+const (
+	KindBreak      Kind = "break"      // after a stretch of activity
+	KindMicroPause Kind = "micropause" // after a run of keystrokes
+)
+
+// Rule says when a pause is due and how long it should be
+type Rule struct {
+	Kind       Kind
+	Active     time.Duration // activity before the pause is due, or 0
+	Keystrokes int           // keystrokes before the pause is due, or 0
+	Length     time.Duration
+}
+
+// Rules returns the rules turned on in c
+func Rules(c config.Breaks) []Rule {
+	var rules []Rule
+	if c.BreakAfter > 0 {
+		rules = append(rules, Rule{
+			Kind:   KindBreak,
+			Active: time.Duration(c.BreakAfter) * time.Minute,
+			Length: time.Duration(c.BreakLength) * time.Minute,
+		})
+	}
+	if c.MicroPauseKeystrokes > 0 {
+		rules = append(rules, Rule{
+			Kind:       KindMicroPause,
+			Keystrokes: c.MicroPauseKeystrokes,
+			Length:     time.Duration(c.MicroPauseLength) * time.Second,
+		})
+	}
+	return rules
+}
+
+// EventType says what happened to a rule
+type EventType int
+
+const (
+	Due     EventType = iota // time to send a reminder
+	Taken                    // a pause of the rule's length followed the reminder
+	Skipped                  // activity carried on past the reminder
+)
+
+// Event is a change in a rule's state. At is when the reminder was due for
+// Due and Skipped, and when the pause began for Taken.
+type Event struct {
+	Rule Rule
+	Type EventType
+	At   time.Time
+}
+
+// ruleState tracks one rule's run of activity since its last pause
+type ruleState struct {
+	Rule
+	start time.Time // first activity since the last long enough pause
+	keys  int
+	due   bool
+	dueAt time.Time
+}
+
+// Scheduler watches activity and says when breaks are due, taken and
+// skipped. It isn't safe for concurrent use.
+type Scheduler struct {
+	rules   []ruleState
+	last    time.Time // latest activity
+	started bool
+}
+
+// NewScheduler returns a scheduler for rules
+func NewScheduler(rules []Rule) *Scheduler {
+	s := &Scheduler{rules: make([]ruleState, len(rules))}
+	for i, r := range rules {
+		s.rules[i].Rule = r
+	}
+	return s
+}
+
+// Activity records keyboard or mouse activity at t with the number of
+// keystrokes it carried
+func (s *Scheduler) Activity(t time.Time, keystrokes int) []Event {
+	var events []Event
+	for i := range s.rules {
+		r := &s.rules[i]
+		switch {
+		case !s.started:
+			r.start = t
+		case t.Sub(s.last) >= r.Length:
+			if r.due {
+				events = append(events, Event{r.Rule, Taken, s.last})
+			}
+			r.start, r.keys, r.due = t, 0, false
+		case r.due && t.Sub(r.dueAt) > r.Length:
+			events = append(events, Event{r.Rule, Skipped, r.dueAt})
+			r.start, r.keys, r.due = t, 0, false
+		}
+
+		r.keys += keystrokes
+		if !r.due && r.overdue(t) {
+			r.due, r.dueAt = true, t
+			events = append(events, Event{r.Rule, Due, t})
+		}
+	}
+	if !s.started || t.After(s.last) {
+		s.last = t
+	}
+	s.started = true
+	return events
+}
+
+// Tick reports breaks that have been taken by now, without waiting for the
+// next activity
+func (s *Scheduler) Tick(now time.Time) []Event {
+	var events []Event
+	for i := range s.rules {
+		r := &s.rules[i]
+		if r.due && now.Sub(s.last) >= r.Length {
+			events = append(events, Event{r.Rule, Taken, s.last})
+			r.due = false
+		}
+	}
+	return events
+}
+
+// overdue reports whether the activity up to t calls for a pause
+func (r *ruleState) overdue(t time.Time) bool {
+	if r.Active > 0 && t.Sub(r.start) >= r.Active {
+		return true
+	}
+	return r.Keystrokes > 0 && r.keys >= r.Keystrokes
+}
+
+// Reminder returns the notification asking for a pause under r
+func Reminder(r Rule) notify.Notification {
+	if r.Kind == KindMicroPause {
+		return notify.Notification{
+			Kind:    string(r.Kind),
+			Title:   "Micro-pause",
+			Message: fmt.Sprintf("%d keystrokes without a rest. Take your hands off the keyboard for %s.", r.Keystrokes, r.Length),
+		}
+	}
+	return notify.Notification{
+		Kind:    string(r.Kind),
+		Title:   "Time for a break",
+		Message: fmt.Sprintf("%s of activity. Step away for %s.", formatMinutes(r.Active), formatMinutes(r.Length)),
+	}
+}
+
+// formatMinutes formats a whole number of minutes, e.g. "50 minutes"
+func formatMinutes(d time.Duration) string {
+	if m := int(d.Minutes()); m != 1 {
+		return fmt.Sprintf("%d minutes", m)
+	}
+	return "1 minute"
+}
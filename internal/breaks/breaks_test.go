@@ -0,0 +1,171 @@
+package breaks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+)
+
+var (
+	fiftyMinutes = Rule{Kind: KindBreak, Active: 50 * time.Minute, Length: 5 * time.Minute}
+	microPause   = Rule{Kind: KindMicroPause, Keystrokes: 5, Length: 10 * time.Second}
+)
+
+// activity is keystrokes at an offset from the start of a test
+type activity struct {
+	at   time.Duration
+	keys int
+}
+
+// every returns activity with keys keystrokes each step from start to end
+func every(start, end, step time.Duration, keys int) []activity {
+	var a []activity
+	for at := start; at <= end; at += step {
+		a = append(a, activity{at, keys})
+	}
+	return a
+}
+
+func TestScheduler(t *testing.T) {
+	base := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		activity []activity
+		tick     time.Duration // Tick at this offset afterwards, if set
+		want     []Event
+	}{
+		{
+			name:     "short stretch",
+			rule:     fiftyMinutes,
+			activity: every(0, 40*time.Minute, time.Minute, 1),
+		},
+		{
+			name:     "pauses reset the stretch",
+			rule:     fiftyMinutes,
+			activity: append(every(0, 30*time.Minute, time.Minute, 1), every(36*time.Minute, 70*time.Minute, time.Minute, 1)...),
+		},
+		{
+			name:     "break taken",
+			rule:     fiftyMinutes,
+			activity: append(every(0, 51*time.Minute, time.Minute, 1), activity{57 * time.Minute, 1}),
+			want: []Event{
+				{fiftyMinutes, Due, base.Add(50 * time.Minute)},
+				{fiftyMinutes, Taken, base.Add(51 * time.Minute)},
+			},
+		},
+		{
+			name:     "break skipped, then due again",
+			rule:     fiftyMinutes,
+			activity: every(0, 110*time.Minute, time.Minute, 1),
+			want: []Event{
+				{fiftyMinutes, Due, base.Add(50 * time.Minute)},
+				{fiftyMinutes, Skipped, base.Add(50 * time.Minute)},
+				{fiftyMinutes, Due, base.Add(106 * time.Minute)},
+			},
+		},
+		{
+			name:     "break taken while idle",
+			rule:     fiftyMinutes,
+			activity: every(0, 50*time.Minute, time.Minute, 1),
+			tick:     56 * time.Minute,
+			want: []Event{
+				{fiftyMinutes, Due, base.Add(50 * time.Minute)},
+				{fiftyMinutes, Taken, base.Add(50 * time.Minute)},
+			},
+		},
+		{
+			name:     "mouse activity counts towards a break",
+			rule:     fiftyMinutes,
+			activity: every(0, 50*time.Minute, time.Minute, 0),
+			want:     []Event{{fiftyMinutes, Due, base.Add(50 * time.Minute)}},
+		},
+		{
+			name:     "micro-pause due",
+			rule:     microPause,
+			activity: every(0, 4*time.Second, time.Second, 1),
+			want:     []Event{{microPause, Due, base.Add(4 * time.Second)}},
+		},
+		{
+			name:     "short gaps don't reset keystrokes",
+			rule:     microPause,
+			activity: append(every(0, 2*time.Second, time.Second, 1), every(11*time.Second, 12*time.Second, time.Second, 1)...),
+			want: []Event{
+				{microPause, Due, base.Add(12 * time.Second)},
+			},
+		},
+		{
+			name:     "mouse movement doesn't add keystrokes",
+			rule:     microPause,
+			activity: append(every(0, 3*time.Second, time.Second, 1), every(4*time.Second, 9*time.Second, time.Second, 0)...),
+		},
+		{
+			name:     "micro-pause taken",
+			rule:     microPause,
+			activity: append(every(0, 4*time.Second, time.Second, 1), activity{20 * time.Second, 1}),
+			want: []Event{
+				{microPause, Due, base.Add(4 * time.Second)},
+				{microPause, Taken, base.Add(4 * time.Second)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler([]Rule{tt.rule})
+			var got []Event
+			for _, a := range tt.activity {
+				got = append(got, s.Activity(base.Add(a.at), a.keys)...)
+			}
+			if tt.tick > 0 {
+				got = append(got, s.Tick(base.Add(tt.tick))...)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("events = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("event %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTickBeforeBreakIsLongEnough(t *testing.T) {
+	base := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	s := NewScheduler([]Rule{fiftyMinutes})
+	for _, a := range every(0, 50*time.Minute, time.Minute, 1) {
+		s.Activity(base.Add(a.at), a.keys)
+	}
+	if got := s.Tick(base.Add(53 * time.Minute)); len(got) != 0 {
+		t.Errorf("Tick three minutes into a five minute break = %+v, want nothing", got)
+	}
+}
+
+func TestRules(t *testing.T) {
+	c := config.Default().Breaks
+	rules := Rules(c)
+	if len(rules) != 1 || rules[0] != fiftyMinutes {
+		t.Errorf("Default rules = %+v, want a 5 minute break every 50", rules)
+	}
+
+	c.BreakAfter, c.MicroPauseKeystrokes = 0, 300
+	rules = Rules(c)
+	want := Rule{Kind: KindMicroPause, Keystrokes: 300, Length: 10 * time.Second}
+	if len(rules) != 1 || rules[0] != want {
+		t.Errorf("Rules = %+v, want only %+v", rules, want)
+	}
+}
+
+func TestReminder(t *testing.T) {
+	if got := Reminder(fiftyMinutes); !strings.Contains(got.Message, "50 minutes") || !strings.Contains(got.Message, "5 minutes") {
+		t.Errorf("Break reminder = %+v", got)
+	}
+	if got := Reminder(microPause); got.Kind != string(KindMicroPause) || !strings.Contains(got.Message, "10s") {
+		t.Errorf("Micro-pause reminder = %+v", got)
+	}
+}
@@ -0,0 +1,363 @@
+/*
+ * typtel chart renderer.
+ *
+ * A small, dependency-free stand-in for the part of the Chart.js API that
+ * typtel's pages use, so chart pages work without network access:
+ *
+ *   const chart = new Chart(canvas, {
+ *       type: 'bar' | 'line',
+ *       data: { labels: [...], datasets: [{ label, data: [...],
+ *               backgroundColor, borderColor, borderWidth, borderRadius,
+ *               fill, tension, pointRadius, pointBackgroundColor }] },
+ *       options: { scales: { x: { ticks: { color } },
+ *                            y: { grid: { color }, ticks: { color } } } }
+ *   });
+ *   chart.data.labels = [...]; chart.update();
+ *   chart.destroy();
+ *
+ * Bar charts draw the first dataset; line charts draw every dataset, leaving
+ * gaps at null values. Charts fill their container's width at a 2:1 aspect
+ * ratio, redraw on resize and show the hovered values.
+ */
+(function (global) {
+    'use strict';
+
+    var FONT = '12px -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif';
+    var TICK_COUNT = 5;
+    var PAD = 8;
+
+    function get(obj, path, fallback) {
+        for (var i = 0; i < path.length; i++) {
+            if (obj == null) return fallback;
+            obj = obj[path[i]];
+        }
+        return obj == null ? fallback : obj;
+    }
+
+    // niceStep rounds max / count up to 1, 2, 2.5 or 5 times a power of ten
+    function niceStep(max, count) {
+        var raw = max / count;
+        var mag = Math.pow(10, Math.floor(Math.log10(raw)));
+        var steps = [1, 2, 2.5, 5, 10];
+        for (var i = 0; i < steps.length; i++) {
+            if (raw <= steps[i] * mag) return steps[i] * mag;
+        }
+        return 10 * mag;
+    }
+
+    function formatTick(n) {
+        if (Math.abs(n) >= 1000000) return +(n / 1000000).toFixed(1) + 'M';
+        if (Math.abs(n) >= 1000) return +(n / 1000).toFixed(1) + 'K';
+        return String(+n.toFixed(2));
+    }
+
+    function Chart(canvas, config) {
+        if (canvas._typtelChart) canvas._typtelChart.destroy();
+        canvas._typtelChart = this;
+
+        this.canvas = canvas;
+        this.ctx = canvas.getContext('2d');
+        this.config = config;
+        this.data = config.data || { labels: [], datasets: [] };
+        this.options = config.options || {};
+        this.hover = -1;
+
+        var self = this;
+        this._onResize = function () { self.draw(); };
+        this._onMove = function (e) { self._hoverAt(e); };
+        this._onLeave = function () { self.hover = -1; self.draw(); };
+        global.addEventListener('resize', this._onResize);
+        canvas.addEventListener('mousemove', this._onMove);
+        canvas.addEventListener('mouseleave', this._onLeave);
+
+        this.draw();
+    }
+
+    Chart.prototype.update = function () {
+        this.draw();
+    };
+
+    Chart.prototype.destroy = function () {
+        global.removeEventListener('resize', this._onResize);
+        this.canvas.removeEventListener('mousemove', this._onMove);
+        this.canvas.removeEventListener('mouseleave', this._onLeave);
+        this.ctx.setTransform(1, 0, 0, 1, 0, 0);
+        this.ctx.clearRect(0, 0, this.canvas.width, this.canvas.height);
+        if (this.canvas._typtelChart === this) this.canvas._typtelChart = null;
+    };
+
+    // resize matches the canvas to its container and the screen's pixel density
+    Chart.prototype._resize = function () {
+        var canvas = this.canvas;
+        var parent = canvas.parentNode;
+        var width = 300;
+        // Collapse first so the old size can't hold a shrinking container open
+        canvas.style.width = '0px';
+        if (parent && parent.clientWidth) {
+            var style = global.getComputedStyle(parent);
+            width = parent.clientWidth - parseFloat(style.paddingLeft) - parseFloat(style.paddingRight);
+        }
+        width = Math.max(Math.floor(width), 100);
+        var height = Math.round(width / 2);
+        var ratio = global.devicePixelRatio || 1;
+
+        canvas.style.display = 'block';
+        canvas.style.width = width + 'px';
+        canvas.style.height = height + 'px';
+        canvas.width = width * ratio;
+        canvas.height = height * ratio;
+        this.ctx.setTransform(ratio, 0, 0, ratio, 0, 0);
+        this.width = width;
+        this.height = height;
+    };
+
+    // _datasets returns the datasets drawn: the first for bar charts, all of
+    // them for line charts
+    Chart.prototype._datasets = function () {
+        var datasets = this.data.datasets || [];
+        if (this.config.type !== 'line') datasets = datasets.slice(0, 1);
+        return datasets;
+    };
+
+    // _series returns each drawn dataset's values, with null for missing ones
+    Chart.prototype._series = function () {
+        return this._datasets().map(function (ds) {
+            return (ds.data || []).map(function (v) {
+                return v == null || v === '' || isNaN(v) ? null : Number(v);
+            });
+        });
+    };
+
+    Chart.prototype.draw = function () {
+        this._resize();
+
+        var ctx = this.ctx;
+        var labels = this.data.labels || [];
+        var datasets = this._datasets();
+        var series = this._series();
+        var n = labels.length;
+        var all = [0];
+        series.forEach(function (values) {
+            n = Math.max(n, values.length);
+            values.forEach(function (v) { if (v !== null) all.push(v); });
+        });
+
+        ctx.clearRect(0, 0, this.width, this.height);
+        ctx.font = FONT;
+
+        // Y axis: zero to a rounded maximum
+        var max = Math.max.apply(null, all);
+        var step = max > 0 ? niceStep(max, TICK_COUNT) : 1;
+        var top = Math.ceil(max / step) * step || step;
+        var ticks = [];
+        for (var t = 0; t <= top + step / 2; t += step) ticks.push(t);
+
+        var axisWidth = 0;
+        ticks.forEach(function (t) {
+            axisWidth = Math.max(axisWidth, ctx.measureText(formatTick(t)).width);
+        });
+
+        var plot = {
+            left: Math.ceil(axisWidth) + PAD * 2,
+            top: PAD,
+            right: this.width - PAD,
+            bottom: this.height - 24
+        };
+        plot.width = plot.right - plot.left;
+        plot.height = plot.bottom - plot.top;
+        this.plot = plot;
+        this.count = n;
+
+        var y = function (v) { return plot.bottom - (v / top) * plot.height; };
+        var slot = n > 0 ? plot.width / n : plot.width;
+        var x = function (i) { return plot.left + slot * (i + 0.5); };
+
+        // Grid and y labels
+        var gridColor = get(this.options, ['scales', 'y', 'grid', 'color'], 'rgba(0,0,0,0.1)');
+        var yTickColor = get(this.options, ['scales', 'y', 'ticks', 'color'], '#666');
+        ctx.textAlign = 'right';
+        ctx.textBaseline = 'middle';
+        ticks.forEach(function (t) {
+            var ty = Math.round(y(t)) + 0.5;
+            ctx.strokeStyle = gridColor;
+            ctx.lineWidth = 1;
+            ctx.beginPath();
+            ctx.moveTo(plot.left, ty);
+            ctx.lineTo(plot.right, ty);
+            ctx.stroke();
+            ctx.fillStyle = yTickColor;
+            ctx.fillText(formatTick(t), plot.left - PAD, ty);
+        });
+
+        // X labels, thinned out so they never overlap
+        var xTickColor = get(this.options, ['scales', 'x', 'ticks', 'color'], '#666');
+        var widest = 0;
+        labels.forEach(function (l) { widest = Math.max(widest, ctx.measureText(String(l)).width); });
+        var every = Math.max(1, Math.ceil((widest + PAD) / slot));
+        ctx.textAlign = 'center';
+        ctx.textBaseline = 'top';
+        ctx.fillStyle = xTickColor;
+        for (var i = 0; i < labels.length; i += every) {
+            ctx.fillText(String(labels[i]), x(i), plot.bottom + 6);
+        }
+
+        var self = this;
+        if (this.config.type === 'line') {
+            datasets.forEach(function (ds, i) { self._drawLine(ds, series[i], x, y); });
+        } else if (datasets.length > 0) {
+            this._drawBars(datasets[0], series[0].map(function (v) { return v || 0; }), slot, x, y);
+        }
+
+        if (this.hover >= 0 && this.hover < n) {
+            var hover = this.hover;
+            var values = series.map(function (s) { return s[hover]; });
+            var highest = Math.max.apply(null, values.map(function (v) { return v || 0; }).concat([0]));
+            this._drawTooltip(labels[hover], datasets, values, x(hover), y(highest));
+        }
+    };
+
+    Chart.prototype._drawBars = function (ds, values, slot, x, y) {
+        var ctx = this.ctx;
+        var plot = this.plot;
+        var barWidth = Math.max(1, slot * 0.8);
+        var radius = ds.borderRadius || 0;
+        var fill = ds.backgroundColor || 'rgba(0,0,0,0.1)';
+        var hover = this.hover;
+
+        values.forEach(function (v, i) {
+            var left = x(i) - barWidth / 2;
+            var barTop = y(v);
+            var h = plot.bottom - barTop;
+            if (h <= 0) return;
+            var r = Math.min(radius, barWidth / 2, h);
+
+            ctx.beginPath();
+            ctx.moveTo(left, plot.bottom);
+            ctx.lineTo(left, barTop + r);
+            ctx.arcTo(left, barTop, left + r, barTop, r);
+            ctx.lineTo(left + barWidth - r, barTop);
+            ctx.arcTo(left + barWidth, barTop, left + barWidth, barTop + r, r);
+            ctx.lineTo(left + barWidth, plot.bottom);
+            ctx.closePath();
+
+            ctx.globalAlpha = hover === -1 || hover === i ? 1 : 0.7;
+            ctx.fillStyle = fill;
+            ctx.fill();
+            if (ds.borderWidth) {
+                ctx.lineWidth = ds.borderWidth;
+                ctx.strokeStyle = ds.borderColor || fill;
+                ctx.stroke();
+            }
+            ctx.globalAlpha = 1;
+        });
+    };
+
+    Chart.prototype._drawLine = function (ds, values, x, y) {
+        // Runs of points between null values
+        var runs = [];
+        var run = null;
+        values.forEach(function (v, i) {
+            if (v === null) {
+                run = null;
+                return;
+            }
+            if (!run) runs.push(run = []);
+            run.push([x(i), y(v), i]);
+        });
+        var self = this;
+        runs.forEach(function (points) { self._drawRun(ds, points); });
+    };
+
+    // _drawRun draws one unbroken stretch of a line dataset
+    Chart.prototype._drawRun = function (ds, points) {
+        var ctx = this.ctx;
+        var plot = this.plot;
+        var tension = ds.tension || 0;
+
+        // Cardinal spline through the points, straight when tension is 0
+        var path = function () {
+            ctx.moveTo(points[0][0], points[0][1]);
+            for (var i = 0; i < points.length - 1; i++) {
+                var p0 = points[Math.max(i - 1, 0)];
+                var p1 = points[i];
+                var p2 = points[i + 1];
+                var p3 = points[Math.min(i + 2, points.length - 1)];
+                var k = tension / 2;
+                var c1y = Math.min(p1[1] + (p2[1] - p0[1]) * k, plot.bottom);
+                var c2y = Math.min(p2[1] - (p3[1] - p1[1]) * k, plot.bottom);
+                ctx.bezierCurveTo(
+                    p1[0] + (p2[0] - p0[0]) * k, c1y,
+                    p2[0] - (p3[0] - p1[0]) * k, c2y,
+                    p2[0], p2[1]);
+            }
+        };
+
+        var stroke = ds.borderColor || '#666';
+        if (ds.fill) {
+            ctx.beginPath();
+            path();
+            ctx.lineTo(points[points.length - 1][0], plot.bottom);
+            ctx.lineTo(points[0][0], plot.bottom);
+            ctx.closePath();
+            ctx.fillStyle = ds.backgroundColor || 'rgba(0,0,0,0.1)';
+            ctx.fill();
+        }
+
+        ctx.beginPath();
+        path();
+        ctx.lineWidth = ds.borderWidth || 2;
+        ctx.strokeStyle = stroke;
+        ctx.stroke();
+
+        var radius = ds.pointRadius == null ? 3 : ds.pointRadius;
+        var hover = this.hover;
+        ctx.fillStyle = ds.pointBackgroundColor || stroke;
+        points.forEach(function (p) {
+            var r = p[2] === hover ? Math.max(radius, 3) + 2 : radius;
+            if (r <= 0) return;
+            ctx.beginPath();
+            ctx.arc(p[0], p[1], r, 0, Math.PI * 2);
+            ctx.fill();
+        });
+    };
+
+    // _drawTooltip labels the hovered values, naming each dataset when there
+    // are several
+    Chart.prototype._drawTooltip = function (label, datasets, values, px, py) {
+        var ctx = this.ctx;
+        var parts = values.map(function (v, i) {
+            var value = v === null || v === undefined ? '-' : Number(v).toLocaleString();
+            return values.length > 1 && datasets[i].label ? datasets[i].label + ' ' + value : value;
+        });
+        var text = (label == null ? '' : label + ': ') + parts.join(', ');
+        var w = ctx.measureText(text).width + PAD * 2;
+        var h = 24;
+        var left = Math.min(Math.max(px - w / 2, 0), this.width - w);
+        var top = Math.max(py - h - PAD, 0);
+
+        ctx.fillStyle = 'rgba(0,0,0,0.8)';
+        ctx.beginPath();
+        ctx.rect(left, top, w, h);
+        ctx.fill();
+        ctx.fillStyle = '#fff';
+        ctx.textAlign = 'left';
+        ctx.textBaseline = 'middle';
+        ctx.fillText(text, left + PAD, top + h / 2);
+    };
+
+    Chart.prototype._hoverAt = function (e) {
+        var rect = this.canvas.getBoundingClientRect();
+        var mx = e.clientX - rect.left;
+        var plot = this.plot;
+        var i = -1;
+        if (this.count > 0 && mx >= plot.left && mx <= plot.right) {
+            i = Math.min(Math.floor((mx - plot.left) / (plot.width / this.count)), this.count - 1);
+        }
+        if (i !== this.hover) {
+            this.hover = i;
+            this.draw();
+        }
+    };
+
+    global.Chart = Chart;
+})(window);
@@ -0,0 +1,17 @@
+// Package charts embeds the script that draws typtel's HTML charts, so chart
+// pages render without fetching anything from the network.
+package charts
+
+import _ "embed"
+
+// Script is the chart renderer. It defines a global Chart class covering the
+// subset of the Chart.js API used by typtel's pages.
+//
+//go:embed chart.js
+var Script string
+
+// ScriptTag returns Script wrapped in a <script> element, for inlining into
+// standalone HTML files.
+func ScriptTag() string {
+	return "<script>\n" + Script + "</script>"
+}
@@ -0,0 +1,26 @@
+package charts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	if !strings.Contains(Script, "global.Chart = Chart") {
+		t.Error("Script does not define Chart")
+	}
+	for _, want := range []string{"prototype.update", "prototype.destroy"} {
+		if !strings.Contains(Script, want) {
+			t.Errorf("Script is missing %s", want)
+		}
+	}
+	// Inlined scripts end at the first closing tag
+	if strings.Contains(strings.ToLower(Script), "</script") {
+		t.Error("Script must not contain a closing script tag")
+	}
+
+	tag := ScriptTag()
+	if !strings.HasPrefix(tag, "<script>") || !strings.HasSuffix(tag, "</script>") {
+		t.Errorf("ScriptTag() = %.40q..., want a script element", tag)
+	}
+}
@@ -0,0 +1,266 @@
+// Package config defines typtel's settings and loads them from a TOML file.
+//
+// Settings are resolved in layers, each overriding the one before:
+//
+//  1. built-in defaults (Default)
+//  2. the config file (see paths.ConfigPath), e.g. ~/.config/typtel/config.toml
+//  3. overrides in the database's settings table, written by the menu bar
+//     app and 'typtel config set'
+//
+// 'typtel config unset' removes a database override so the file applies again.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+// Distance unit options
+const (
+	DistanceUnitFeet    = "feet"    // feet/miles (default)
+	DistanceUnitCars    = "cars"    // average car length ~15ft
+	DistanceUnitFrisbee = "frisbee" // ultimate frisbee field ~330ft
+)
+
+// DistanceUnits lists the valid distance units
+var DistanceUnits = []string{DistanceUnitFeet, DistanceUnitCars, DistanceUnitFrisbee}
+
+// Inertia max speed options (capped at what terminals/editors can handle)
+const (
+	InertiaSpeedUltraFast = "ultra_fast" // Cap at ~140 keys/sec (pushing limits)
+	InertiaSpeedVeryFast  = "very_fast"  // Cap at ~125 keys/sec
+	InertiaSpeedFast      = "fast"       // Cap at ~83 keys/sec
+	InertiaSpeedMedium    = "medium"     // Cap at ~50 keys/sec
+	InertiaSpeedSlow      = "slow"       // Cap at ~20 keys/sec
+)
+
+// InertiaSpeeds lists the valid inertia max speeds, fastest first
+var InertiaSpeeds = []string{InertiaSpeedUltraFast, InertiaSpeedVeryFast, InertiaSpeedFast, InertiaSpeedMedium, InertiaSpeedSlow}
+
+// Notifiers for break reminders and goal notifications
+const (
+	NotifierDesktop = "desktop" // macOS or Linux desktop notification (default)
+	NotifierBell    = "bell"    // terminal bell and message on the menu bar app's output
+	NotifierWebhook = "webhook" // JSON POSTed to breaks.webhook_url
+)
+
+// Notifiers lists the valid notifiers for break reminders and goals
+var Notifiers = []string{NotifierDesktop, NotifierBell, NotifierWebhook}
+
+// Config is the full set of user settings
+type Config struct {
+	Menubar    Menubar    `toml:"menubar"`
+	Mouse      Mouse      `toml:"mouse"`
+	Inertia    Inertia    `toml:"inertia"`
+	Keyboard   Keyboard   `toml:"keyboard"`
+	TypingTest TypingTest `toml:"typing_test"`
+	Days       Days       `toml:"days"`
+	Retention  Retention  `toml:"retention"`
+	Sessions   Sessions   `toml:"sessions"`
+	Breaks     Breaks     `toml:"breaks"`
+	Goals      Goals      `toml:"goals"`
+	Metrics    Metrics    `toml:"metrics"`
+}
+
+// Menubar is what to show in the menu bar title
+type Menubar struct {
+	ShowKeystrokes bool `toml:"show_keystrokes"`
+	ShowWords      bool `toml:"show_words"`
+	ShowClicks     bool `toml:"show_clicks"`
+	ShowDistance   bool `toml:"show_distance"`
+	ShowWPM        bool `toml:"show_wpm"`   // live typing speed
+	ShowGoals      bool `toml:"show_goals"` // progress towards goals and the streak
+}
+
+// Mouse configures mouse tracking and how distance is shown
+type Mouse struct {
+	Tracking     bool   `toml:"tracking"`      // takes effect when the menu bar app restarts
+	DistanceUnit string `toml:"distance_unit"` // one of DistanceUnits
+}
+
+// Inertia configures key repeat acceleration
+type Inertia struct {
+	Enabled   bool    `toml:"enabled"`
+	MaxSpeed  string  `toml:"max_speed"`    // one of InertiaSpeeds
+	Threshold int     `toml:"threshold_ms"` // ms before acceleration starts
+	AccelRate float64 `toml:"accel_rate"`   // acceleration multiplier
+}
+
+// Keyboard configures the key frequency heatmap
+type Keyboard struct {
+	Layout string `toml:"layout"` // one of keyboard.Layouts
+}
+
+// TypingTest configures the typing test
+type TypingTest struct {
+	Theme string `toml:"theme"` // unknown themes fall back to the default
+}
+
+// Days sets where one day of activity ends and the next begins
+type Days struct {
+	Timezone  string `toml:"timezone"`   // IANA zone name; empty follows the system clock
+	StartHour int    `toml:"start_hour"` // hour (0-23) at which a new day begins
+}
+
+// Retention limits how long individual keystrokes are kept
+type Retention struct {
+	// KeystrokeDays is how many days of individual keystrokes to keep; older
+	// ones are folded into hourly and per-key totals. 0 keeps them forever.
+	KeystrokeDays int `toml:"keystroke_days"`
+}
+
+// Sessions controls how activity is split into sessions (see
+// internal/sessions)
+type Sessions struct {
+	IdleMinutes int `toml:"idle_minutes"` // minutes without activity that end a session
+}
+
+// Breaks schedules rest break reminders (see internal/breaks)
+type Breaks struct {
+	Enabled              bool   `toml:"enabled"`
+	BreakAfter           int    `toml:"break_after_minutes"`   // minutes of activity before a break; 0 turns breaks off
+	BreakLength          int    `toml:"break_minutes"`         // length of a break
+	MicroPauseKeystrokes int    `toml:"micropause_keystrokes"` // keystrokes before a micro-pause; 0 turns them off
+	MicroPauseLength     int    `toml:"micropause_seconds"`    // length of a micro-pause
+	Notifier             string `toml:"notifier"`              // one of Notifiers
+	WebhookURL           string `toml:"webhook_url"`           // for NotifierWebhook
+}
+
+// Goals are daily targets (see internal/goals); 0 leaves a goal unset
+type Goals struct {
+	Keystrokes   int     `toml:"keystrokes"`
+	Words        int     `toml:"words"`
+	TypingTests  int     `toml:"typing_tests"`
+	MaxMouseFeet float64 `toml:"max_mouse_feet"` // a ceiling on mouse distance
+	Notify       bool    `toml:"notify"`         // notify as goals are met, with breaks.notifier
+}
+
+// Metrics configures the Prometheus endpoint (see internal/metrics)
+type Metrics struct {
+	Addr string `toml:"addr"` // host:port the menu bar app serves on; empty is off
+}
+
+// Default returns the built-in settings
+func Default() Config {
+	return Config{
+		Menubar: Menubar{
+			ShowKeystrokes: true,
+			ShowWords:      true,
+			ShowGoals:      true,
+		},
+		Mouse: Mouse{
+			Tracking:     true,
+			DistanceUnit: DistanceUnitFeet,
+		},
+		Inertia: Inertia{
+			MaxSpeed:  InertiaSpeedFast,
+			Threshold: 200,
+			AccelRate: 1.0,
+		},
+		Keyboard: Keyboard{
+			Layout: keyboard.DefaultLayout,
+		},
+		TypingTest: TypingTest{
+			Theme: "default",
+		},
+		Sessions: Sessions{
+			IdleMinutes: 5,
+		},
+		Breaks: Breaks{
+			BreakAfter:       50,
+			BreakLength:      5,
+			MicroPauseLength: 10,
+			Notifier:         NotifierDesktop,
+		},
+		Goals: Goals{
+			Notify: true,
+		},
+	}
+}
+
+// Validate reports every setting that is out of range
+func (c Config) Validate() error {
+	var errs []error
+	for _, k := range Keys {
+		scratch := c
+		if err := k.Set(&scratch, k.Get(c)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// File is a loaded config file
+type File struct {
+	Path   string
+	Config Config // defaults with the file's settings applied
+
+	defined map[string]bool // key names the file sets
+}
+
+// Sets reports whether the file sets the named key
+func (f *File) Sets(name string) bool {
+	return f != nil && f.defined[name]
+}
+
+// Load reads the config file at path over the defaults. A missing file is not
+// an error: it yields the defaults.
+func Load(path string) (*File, error) {
+	f := &File{Path: path, Config: Default(), defined: map[string]bool{}}
+
+	md, err := toml.DecodeFile(path, &f.Config)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		names := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			names[i] = key.String()
+		}
+		return nil, fmt.Errorf("%s: unknown settings: %s", path, strings.Join(names, ", "))
+	}
+	for _, k := range Keys {
+		if md.IsDefined(strings.Split(k.Name, ".")...) {
+			f.defined[k.Name] = true
+		}
+	}
+
+	if err := f.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Template is the commented config file 'typtel config edit' starts from,
+// listing every setting at its default
+func Template() string {
+	var b strings.Builder
+	b.WriteString("# typtel settings. Values set from the menu bar or with 'typtel config set'\n")
+	b.WriteString("# override this file; 'typtel config unset <key>' removes an override.\n")
+
+	def := Default()
+	section := ""
+	for _, k := range Keys {
+		sec, name, _ := strings.Cut(k.Name, ".")
+		if sec != section {
+			section = sec
+			fmt.Fprintf(&b, "\n[%s]\n", sec)
+		}
+		help := k.Help
+		if k.Restart {
+			help += "; applies when the menu bar app restarts"
+		}
+		fmt.Fprintf(&b, "# %s\n", help)
+		fmt.Fprintf(&b, "# %s = %s\n", name, k.tomlValue(def))
+	}
+	return b.String()
+}
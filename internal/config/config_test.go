@@ -0,0 +1,198 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	f, err := Load(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if f.Config != Default() {
+		t.Errorf("Config = %+v, want defaults", f.Config)
+	}
+	if f.Sets("mouse.distance_unit") {
+		t.Error("missing file should set nothing")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := writeFile(t, `
+[menubar]
+show_clicks = true
+
+[mouse]
+distance_unit = "cars"
+
+[inertia]
+accel_rate = 2
+threshold_ms = 150
+
+[retention]
+keystroke_days = 90
+`)
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	want := Default()
+	want.Menubar.ShowClicks = true
+	want.Mouse.DistanceUnit = DistanceUnitCars
+	want.Inertia.AccelRate = 2
+	want.Inertia.Threshold = 150
+	want.Retention.KeystrokeDays = 90
+	if f.Config != want {
+		t.Errorf("Config = %+v, want %+v", f.Config, want)
+	}
+
+	if !f.Sets("mouse.distance_unit") || !f.Sets("retention.keystroke_days") {
+		t.Error("Sets should report keys in the file")
+	}
+	if f.Sets("menubar.show_words") {
+		t.Error("Sets should not report keys left at their default")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"syntax", "[mouse\n", "failed to parse"},
+		{"unknown key", "[mouse]\nunits = \"cars\"\n", "unknown settings: mouse.units"},
+		{"bad choice", "[mouse]\ndistance_unit = \"parsecs\"\n", "mouse.distance_unit: want one of feet, cars, frisbee"},
+		{"out of range", "[inertia]\nthreshold_ms = 0\n", "inertia.threshold_ms: must be between 1 and 5000"},
+		{"wrong type", "[menubar]\nshow_words = \"yes\"\n", "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeFile(t, tt.content))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"menubar.show_clicks", "true", "true", false},
+		{"menubar.show_clicks", "maybe", "", true},
+		{"mouse.distance_unit", "frisbee", "frisbee", false},
+		{"mouse.distance_unit", "miles", "", true},
+		{"inertia.max_speed", "slow", "slow", false},
+		{"inertia.threshold_ms", "350", "350", false},
+		{"inertia.threshold_ms", "1.5", "", true},
+		{"inertia.accel_rate", "1.50", "1.5", false},
+		{"inertia.accel_rate", "0", "", true},
+		{"keyboard.layout", "iso", "iso", false},
+		{"keyboard.layout", "dvorak", "", true},
+		{"typing_test.theme", "dracula", "dracula", false},
+		{"typing_test.theme", " ", "", true},
+		{"days.timezone", "Europe/London", "Europe/London", false},
+		{"days.timezone", "", "", false},
+		{"days.timezone", "Mars/Olympus", "", true},
+		{"days.start_hour", "4", "4", false},
+		{"days.start_hour", "24", "", true},
+		{"retention.keystroke_days", "0", "0", false},
+		{"retention.keystroke_days", "-1", "", true},
+		{"sessions.idle_minutes", "15", "15", false},
+		{"sessions.idle_minutes", "0", "", true},
+		{"breaks.break_after_minutes", "0", "0", false},
+		{"breaks.micropause_seconds", "0", "", true},
+		{"breaks.notifier", "webhook", "webhook", false},
+		{"breaks.notifier", "pager", "", true},
+		{"breaks.webhook_url", "https://hooks.example.com/T0/B0", "https://hooks.example.com/T0/B0", false},
+		{"breaks.webhook_url", "hooks.example.com", "", true},
+		{"breaks.webhook_url", "ftp://example.com/x", "", true},
+		{"goals.keystrokes", "10000", "10000", false},
+		{"goals.keystrokes", "-5", "", true},
+		{"goals.max_mouse_feet", "250.5", "250.5", false},
+		{"goals.max_mouse_feet", "far", "", true},
+		{"metrics.addr", "127.0.0.1:9100", "127.0.0.1:9100", false},
+		{"metrics.addr", "", "", false},
+		{"metrics.addr", "localhost", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			k, ok := Lookup(tt.key)
+			if !ok {
+				t.Fatalf("Lookup(%q) failed", tt.key)
+			}
+			cfg := Default()
+			before := k.Get(cfg)
+			err := k.Set(&cfg, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if got := k.Get(cfg); got != before {
+					t.Errorf("failed Set changed the value to %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			if got := k.Get(cfg); got != tt.want {
+				t.Errorf("Get = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Errorf("defaults should be valid: %v", err)
+	}
+}
+
+func TestTemplateLoads(t *testing.T) {
+	// Uncommenting every line of the template gives the defaults
+	var lines []string
+	for _, line := range strings.Split(Template(), "\n") {
+		if strings.HasPrefix(line, "# ") && strings.Contains(line, " = ") {
+			line = strings.TrimPrefix(line, "# ")
+		}
+		lines = append(lines, line)
+	}
+
+	f, err := Load(writeFile(t, strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if f.Config != Default() {
+		t.Errorf("Config = %+v, want defaults", f.Config)
+	}
+	for _, name := range Names() {
+		if !f.Sets(name) {
+			t.Errorf("template is missing %s", name)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+// Key is one setting, addressed by its dotted name, e.g. "mouse.distance_unit"
+type Key struct {
+	Name    string // section.name, as in the config file
+	Setting string // settings table key holding the database override
+	Help    string
+	Restart bool // the menu bar app only reads it at startup
+
+	quoted bool // the TOML value is a string
+	get    func(Config) string
+	set    func(*Config, string) error
+}
+
+// Get returns the key's value in c as text
+func (k Key) Get(c Config) string {
+	return k.get(c)
+}
+
+// Set parses and validates value and stores it in c
+func (k Key) Set(c *Config, value string) error {
+	if err := k.set(c, strings.TrimSpace(value)); err != nil {
+		return fmt.Errorf("%s: %w", k.Name, err)
+	}
+	return nil
+}
+
+// tomlValue returns the key's value in c as a TOML literal
+func (k Key) tomlValue(c Config) string {
+	if k.quoted {
+		return strconv.Quote(k.get(c))
+	}
+	return k.get(c)
+}
+
+// Keys lists every setting in config file order
+var Keys = []Key{
+	boolKey("menubar.show_keystrokes", "menubar_show_keystrokes", "Show today's keystrokes in the menu bar",
+		func(c *Config) *bool { return &c.Menubar.ShowKeystrokes }),
+	boolKey("menubar.show_words", "menubar_show_words", "Show today's words in the menu bar",
+		func(c *Config) *bool { return &c.Menubar.ShowWords }),
+	boolKey("menubar.show_clicks", "menubar_show_clicks", "Show today's mouse clicks in the menu bar",
+		func(c *Config) *bool { return &c.Menubar.ShowClicks }),
+	boolKey("menubar.show_distance", "menubar_show_distance", "Show today's mouse distance in the menu bar",
+		func(c *Config) *bool { return &c.Menubar.ShowDistance }),
+	boolKey("menubar.show_wpm", "menubar_show_wpm", "Show your live typing speed in the menu bar",
+		func(c *Config) *bool { return &c.Menubar.ShowWPM }),
+	boolKey("menubar.show_goals", "menubar_show_goals", "Show progress towards your goals and your streak in the menu bar",
+		func(c *Config) *bool { return &c.Menubar.ShowGoals }),
+
+	restart(boolKey("mouse.tracking", "mouse_tracking_enabled", "Track mouse movement and clicks",
+		func(c *Config) *bool { return &c.Mouse.Tracking })),
+	choiceKey("mouse.distance_unit", "distance_unit", "Unit for mouse distance", DistanceUnits,
+		func(c *Config) *string { return &c.Mouse.DistanceUnit }),
+
+	restart(boolKey("inertia.enabled", "inertia_enabled", "Accelerate held-down keys",
+		func(c *Config) *bool { return &c.Inertia.Enabled })),
+	restart(choiceKey("inertia.max_speed", "inertia_max_speed", "Fastest repeat rate", InertiaSpeeds,
+		func(c *Config) *string { return &c.Inertia.MaxSpeed })),
+	restart(intKey("inertia.threshold_ms", "inertia_threshold", "Milliseconds a key is held before accelerating", 1, 5000,
+		func(c *Config) *int { return &c.Inertia.Threshold })),
+	restart(floatKey("inertia.accel_rate", "inertia_accel_rate", "Acceleration multiplier", 0.1, 10,
+		func(c *Config) *float64 { return &c.Inertia.AccelRate })),
+
+	choiceKey("keyboard.layout", "keyboard_layout", "Physical layout of the key frequency heatmap", keyboard.Layouts,
+		func(c *Config) *string { return &c.Keyboard.Layout }),
+
+	stringKey("typing_test.theme", "typing_test_theme", "Typing test color theme",
+		func(c *Config) *string { return &c.TypingTest.Theme }),
+
+	restart(timezoneKey("days.timezone", "timezone", "Timezone days are counted in, e.g. Europe/London; empty follows the system clock",
+		func(c *Config) *string { return &c.Days.Timezone })),
+	restart(intKey("days.start_hour", "day_start_hour", "Hour a new day begins, e.g. 4 so typing until 3:59am counts towards the day before", 0, 23,
+		func(c *Config) *int { return &c.Days.StartHour })),
+
+	intKey("retention.keystroke_days", "retention_keystroke_days", "Days of individual keystrokes to keep before folding them into totals; 0 keeps them forever", 0, 100000,
+		func(c *Config) *int { return &c.Retention.KeystrokeDays }),
+
+	restart(intKey("sessions.idle_minutes", "sessions_idle_minutes", "Minutes without keyboard or mouse activity that end a session", 1, 240,
+		func(c *Config) *int { return &c.Sessions.IdleMinutes })),
+
+	restart(boolKey("breaks.enabled", "breaks_enabled", "Remind you to take rest breaks",
+		func(c *Config) *bool { return &c.Breaks.Enabled })),
+	restart(intKey("breaks.break_after_minutes", "breaks_break_after_minutes", "Minutes of activity before a break is due; 0 turns breaks off", 0, 600,
+		func(c *Config) *int { return &c.Breaks.BreakAfter })),
+	restart(intKey("breaks.break_minutes", "breaks_break_minutes", "Length of a break in minutes", 1, 120,
+		func(c *Config) *int { return &c.Breaks.BreakLength })),
+	restart(intKey("breaks.micropause_keystrokes", "breaks_micropause_keystrokes", "Keystrokes without a pause before a micro-pause is due; 0 turns micro-pauses off", 0, 100000,
+		func(c *Config) *int { return &c.Breaks.MicroPauseKeystrokes })),
+	restart(intKey("breaks.micropause_seconds", "breaks_micropause_seconds", "Length of a micro-pause in seconds", 1, 600,
+		func(c *Config) *int { return &c.Breaks.MicroPauseLength })),
+	restart(choiceKey("breaks.notifier", "breaks_notifier", "How break reminders and goal notifications are delivered", Notifiers,
+		func(c *Config) *string { return &c.Breaks.Notifier })),
+	restart(urlKey("breaks.webhook_url", "breaks_webhook_url", "URL the webhook notifier POSTs reminders to",
+		func(c *Config) *string { return &c.Breaks.WebhookURL })),
+
+	intKey("goals.keystrokes", "goals_keystrokes", "Keystrokes to type each day; 0 for no goal", 0, 10000000,
+		func(c *Config) *int { return &c.Goals.Keystrokes }),
+	intKey("goals.words", "goals_words", "Words to type each day; 0 for no goal", 0, 1000000,
+		func(c *Config) *int { return &c.Goals.Words }),
+	intKey("goals.typing_tests", "goals_typing_tests", "Typing tests to take each day; 0 for no goal", 0, 1000,
+		func(c *Config) *int { return &c.Goals.TypingTests }),
+	floatKey("goals.max_mouse_feet", "goals_max_mouse_feet", "Most feet of mouse movement each day; 0 for no goal", 0, 1000000,
+		func(c *Config) *float64 { return &c.Goals.MaxMouseFeet }),
+	boolKey("goals.notify", "goals_notify", "Notify you as goals are met, with breaks.notifier",
+		func(c *Config) *bool { return &c.Goals.Notify }),
+
+	restart(addrKey("metrics.addr", "metrics_addr", "host:port to serve Prometheus metrics on, e.g. 127.0.0.1:9777; empty is off",
+		func(c *Config) *string { return &c.Metrics.Addr })),
+}
+
+// Lookup finds a key by its dotted name
+func Lookup(name string) (Key, bool) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Names lists every key name in config file order
+func Names() []string {
+	names := make([]string, len(Keys))
+	for i, k := range Keys {
+		names[i] = k.Name
+	}
+	return names
+}
+
+// restart marks a key the menu bar app only reads at startup
+func restart(k Key) Key {
+	k.Restart = true
+	return k
+}
+
+func boolKey(name, setting, help string, field func(*Config) *bool) Key {
+	return Key{
+		Name: name, Setting: setting, Help: help,
+		get: func(c Config) string { return strconv.FormatBool(*field(&c)) },
+		set: func(c *Config, value string) error {
+			v, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("want true or false, got %q", value)
+			}
+			*field(c) = v
+			return nil
+		},
+	}
+}
+
+func intKey(name, setting, help string, min, max int, field func(*Config) *int) Key {
+	return Key{
+		Name: name, Setting: setting, Help: help,
+		get: func(c Config) string { return strconv.Itoa(*field(&c)) },
+		set: func(c *Config, value string) error {
+			v, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("want a whole number, got %q", value)
+			}
+			if v < min || v > max {
+				return fmt.Errorf("must be between %d and %d, got %d", min, max, v)
+			}
+			*field(c) = v
+			return nil
+		},
+	}
+}
+
+func floatKey(name, setting, help string, min, max float64, field func(*Config) *float64) Key {
+	return Key{
+		Name: name, Setting: setting, Help: help,
+		get: func(c Config) string { return strconv.FormatFloat(*field(&c), 'f', -1, 64) },
+		set: func(c *Config, value string) error {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("want a number, got %q", value)
+			}
+			if v < min || v > max {
+				return fmt.Errorf("must be between %g and %g, got %g", min, max, v)
+			}
+			*field(c) = v
+			return nil
+		},
+	}
+}
+
+func choiceKey(name, setting, help string, choices []string, field func(*Config) *string) Key {
+	return Key{
+		Name: name, Setting: setting, Help: help + " (" + strings.Join(choices, ", ") + ")", quoted: true,
+		get: func(c Config) string { return *field(&c) },
+		set: func(c *Config, value string) error {
+			if !slices.Contains(choices, value) {
+				return fmt.Errorf("want one of %s, got %q", strings.Join(choices, ", "), value)
+			}
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+// urlKey is an http or https URL, or empty
+func urlKey(name, setting, help string, field func(*Config) *string) Key {
+	return Key{
+		Name: name, Setting: setting, Help: help, quoted: true,
+		get: func(c Config) string { return *field(&c) },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				u, err := url.Parse(value)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("want an http or https URL, got %q", value)
+				}
+			}
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+// timezoneKey is an IANA zone name, or empty
+func timezoneKey(name, setting, help string, field func(*Config) *string) Key {
+	return Key{
+		Name: name, Setting: setting, Help: help, quoted: true,
+		get: func(c Config) string { return *field(&c) },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				if _, err := time.LoadLocation(value); err != nil {
+					return fmt.Errorf("unknown timezone %q", value)
+				}
+			}
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+// addrKey is a host:port network address, or empty
+func addrKey(name, setting, help string, field func(*Config) *string) Key {
+	return Key{
+		Name: name, Setting: setting, Help: help, quoted: true,
+		get: func(c Config) string { return *field(&c) },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				if _, _, err := net.SplitHostPort(value); err != nil {
+					return fmt.Errorf("want host:port, got %q", value)
+				}
+			}
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+func stringKey(name, setting, help string, field func(*Config) *string) Key {
+	return Key{
+		Name: name, Setting: setting, Help: help, quoted: true,
+		get: func(c Config) string { return *field(&c) },
+		set: func(c *Config, value string) error {
+			if value == "" {
+				return fmt.Errorf("must not be empty")
+			}
+			*field(c) = value
+			return nil
+		},
+	}
+}
@@ -0,0 +1,71 @@
+// Package corrections measures how much everyday typing goes into fixing
+// mistakes.
+//
+// Every Backspace and Forward Delete press is a correction, and every key
+// that types a character (see keyboard.IsText) is a character. The
+// correction rate is corrections per character typed. A correction burst is
+// MinBurst or more corrections in a row with nothing else pressed between
+// them, i.e. backing out a mistake rather than fixing a single typo.
+// Modifier presses don't interrupt a run. Held keys repeat without being
+// recorded, so holding Backspace counts once.
+package corrections
+
+import "github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+
+// MinBurst is how many corrections in a row make a correction burst
+const MinBurst = 3
+
+// Counts are the characters, corrections and correction bursts over a
+// stretch of typing
+type Counts struct {
+	Chars       int64 `json:"chars"`
+	Corrections int64 `json:"corrections"`
+	Bursts      int64 `json:"bursts"`
+}
+
+// Add adds o to c
+func (c *Counts) Add(o Counts) {
+	c.Chars += o.Chars
+	c.Corrections += o.Corrections
+	c.Bursts += o.Bursts
+}
+
+// Rate returns corrections per character typed, or 0 with nothing typed
+func (c Counts) Rate() float64 {
+	if c.Chars == 0 {
+		return 0
+	}
+	return float64(c.Corrections) / float64(c.Chars)
+}
+
+// IsCorrection reports whether code is Backspace or Forward Delete
+func IsCorrection(code int) bool {
+	return code == keyboard.KeyDelete || code == keyboard.KeyForwardDel
+}
+
+// Detector counts corrections in a stream of keystrokes. The zero value is
+// ready to use.
+type Detector struct {
+	run int // corrections in a row so far
+}
+
+// Press feeds a key press with the modifiers held and returns what it adds
+// to the counts
+func (d *Detector) Press(code int, mods keyboard.Modifiers) Counts {
+	switch {
+	case keyboard.IsModifier(code):
+		return Counts{}
+	case IsCorrection(code):
+		d.run++
+		c := Counts{Corrections: 1}
+		if d.run == MinBurst {
+			c.Bursts = 1
+		}
+		return c
+	}
+	d.run = 0
+	if keyboard.IsText(code, mods) {
+		return Counts{Chars: 1}
+	}
+	return Counts{}
+}
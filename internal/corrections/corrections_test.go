@@ -0,0 +1,66 @@
+package corrections
+
+import (
+	"testing"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+type press struct {
+	code int
+	mods keyboard.Modifiers
+}
+
+func keys(codes ...int) []press {
+	p := make([]press, len(codes))
+	for i, code := range codes {
+		p[i] = press{code: code}
+	}
+	return p
+}
+
+func TestDetector(t *testing.T) {
+	a, del, fwd := keyboard.KeyA, keyboard.KeyDelete, keyboard.KeyForwardDel
+
+	tests := []struct {
+		name    string
+		presses []press
+		want    Counts
+	}{
+		{"typing", keys(a, a, keyboard.KeySpace, keyboard.KeyComma, keyboard.KeyReturn), Counts{Chars: 5}},
+		{"single typo", keys(a, a, del, a), Counts{Chars: 3, Corrections: 1}},
+		{"forward delete", keys(a, fwd), Counts{Chars: 1, Corrections: 1}},
+		{"short run", keys(a, del, del, a), Counts{Chars: 2, Corrections: 2}},
+		{"burst", keys(a, a, a, del, del, del, a), Counts{Chars: 4, Corrections: 3, Bursts: 1}},
+		{"long burst counts once", keys(a, del, del, del, del, del, del), Counts{Chars: 1, Corrections: 6, Bursts: 1}},
+		{"two bursts", keys(del, del, del, a, del, del, del), Counts{Chars: 1, Corrections: 6, Bursts: 2}},
+		{"typing splits a run", keys(del, del, a, del, del), Counts{Chars: 1, Corrections: 4}},
+		{"arrow splits a run", keys(del, del, keyboard.KeyLeft, del), Counts{Corrections: 3}},
+		{"modifiers don't split a run", keys(del, keyboard.KeyOption, del, keyboard.KeyShift, del), Counts{Corrections: 3, Bursts: 1}},
+		{"option backspace", []press{{del, keyboard.ModOption}, {del, keyboard.ModOption}, {del, keyboard.ModOption}}, Counts{Corrections: 3, Bursts: 1}},
+		{"shortcuts aren't characters", []press{{keyboard.KeyS, keyboard.ModCommand}, {keyboard.KeyC, keyboard.ModControl}}, Counts{}},
+		{"capitals are characters", []press{{a, keyboard.ModShift}}, Counts{Chars: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Detector
+			var got Counts
+			for _, p := range tt.presses {
+				got.Add(d.Press(p.code, p.mods))
+			}
+			if got != tt.want {
+				t.Errorf("counts = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRate(t *testing.T) {
+	if got := (Counts{}).Rate(); got != 0 {
+		t.Errorf("Rate() with nothing typed = %v, want 0", got)
+	}
+	if got := (Counts{Chars: 200, Corrections: 9}).Rate(); got != 0.045 {
+		t.Errorf("Rate() = %v, want 0.045", got)
+	}
+}
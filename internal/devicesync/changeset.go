@@ -0,0 +1,147 @@
+// Package devicesync shares telemetry between devices through a folder that
+// some other tool (Syncthing, Dropbox, iCloud Drive, ...) keeps in sync.
+//
+// Each device appends changesets to its own file, <device-id>.ndjson, and
+// never touches anyone else's. A changeset holds the counts recorded since
+// the previous one, keyed by day and hour or by day and key. A keystroke that
+// moves to another day after it was pushed (because the timezone or day
+// start changed) is sent as a negative count on its old day and a positive
+// one on its new, so merging is still a sum over every changeset in the
+// folder: there is nothing to conflict. Changesets carry a per-device
+// sequence number, so a file that gets duplicated (say, as a sync-conflict
+// copy) is not counted twice.
+package devicesync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// FormatVersion is bumped whenever the changeset format changes incompatibly
+const FormatVersion = 1
+
+// HeartbeatInterval is how often an idle device still writes an empty
+// changeset, so other devices can see it is alive
+const HeartbeatInterval = time.Hour
+
+// fileExt is the extension of changeset files
+const fileExt = ".ndjson"
+
+// Changeset is one line of a device's changeset file
+type Changeset struct {
+	Version int         `json:"v"`
+	Device  string      `json:"device"`
+	Name    string      `json:"name"`
+	Seq     int64       `json:"seq"`
+	Created time.Time   `json:"created"`
+	Hours   []HourCount `json:"hours,omitempty"`
+	Keys    []KeyCount  `json:"keys,omitempty"`
+	Words   []WordCount `json:"words,omitempty"`
+}
+
+// HourCount is the keystrokes typed in one hour of a day
+type HourCount struct {
+	Date       string `json:"date"`
+	Hour       int    `json:"hour"`
+	Keystrokes int64  `json:"keystrokes"`
+}
+
+// KeyCount is how often a keycode was pressed on a day
+type KeyCount struct {
+	Date    string `json:"date"`
+	Keycode int    `json:"keycode"`
+	Count   int64  `json:"count"`
+}
+
+// WordCount is the words typed on a day
+type WordCount struct {
+	Date  string `json:"date"`
+	Words int64  `json:"words"`
+}
+
+// FilePath returns the changeset file a device writes to
+func FilePath(dir, deviceID string) string {
+	return filepath.Join(dir, deviceID+fileExt)
+}
+
+// Push appends this device's changes since its last push to its changeset
+// file in dir. An idle device only writes an empty changeset once per
+// HeartbeatInterval. It returns the changeset written, or nil if none was.
+func Push(store *storage.Store, dir string) (*Changeset, error) {
+	id, err := store.DeviceID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device ID: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	delta, err := store.PendingSync()
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect changes: %w", err)
+	}
+	now := time.Now().UTC()
+	if delta.Empty() && now.Sub(delta.LastPush) < HeartbeatInterval {
+		return nil, nil
+	}
+
+	cs := &Changeset{
+		Version: FormatVersion,
+		Device:  id,
+		Name:    store.DeviceName(),
+		Seq:     delta.Seq,
+		Created: now,
+	}
+	for _, h := range delta.Hours {
+		cs.Hours = append(cs.Hours, HourCount{Date: h.Date, Hour: h.Hour, Keystrokes: h.Keystrokes})
+	}
+	for _, k := range delta.Keys {
+		cs.Keys = append(cs.Keys, KeyCount{Date: k.Date, Keycode: k.Keycode, Count: k.Count})
+	}
+	for _, d := range delta.Days {
+		cs.Words = append(cs.Words, WordCount{Date: d.Date, Words: d.Words})
+	}
+
+	// Claim the sequence number before writing, so a changeset is never
+	// written twice under it. Should the process die in between, this
+	// changeset's counts never reach other devices.
+	if err := store.MarkSynced(delta); err != nil {
+		return nil, fmt.Errorf("failed to push changes: %w", err)
+	}
+	if err := appendChangeset(FilePath(dir, id), cs); err != nil {
+		if uerr := store.UnmarkSynced(delta); uerr != nil {
+			return nil, fmt.Errorf("failed to push changes: %w (and failed to roll back: %v)", err, uerr)
+		}
+		return nil, fmt.Errorf("failed to push changes: %w", err)
+	}
+	return cs, nil
+}
+
+// appendChangeset writes cs as a single line so a reader never sees half of
+// one, and syncs it to disk before returning
+func appendChangeset(path string, cs *Changeset) error {
+	line, err := json.Marshal(cs)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,263 @@
+package devicesync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+func newTestStore(t *testing.T, name string) *storage.Store {
+	t.Helper()
+	store, err := storage.NewWithPath(filepath.Join(t.TempDir(), "typtel.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.SetDeviceName(name); err != nil {
+		t.Fatalf("SetDeviceName failed: %v", err)
+	}
+	return store
+}
+
+func typeKeys(t *testing.T, store *storage.Store, at time.Time, keycodes ...int) {
+	t.Helper()
+	for _, k := range keycodes {
+		if err := store.RecordKeystrokeAt(k, at); err != nil {
+			t.Fatalf("RecordKeystrokeAt failed: %v", err)
+		}
+	}
+}
+
+func TestPushAndMerge(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2024, 2, 1, 9, 30, 0, 0, time.Local)
+
+	laptop := newTestStore(t, "laptop")
+	desktop := newTestStore(t, "desktop")
+
+	typeKeys(t, laptop, at, 0, 1, 2)
+	laptop.IncrementWordCount("2024-02-01")
+	typeKeys(t, desktop, at.Add(time.Hour), 0, 0)
+
+	for _, store := range []*storage.Store{laptop, desktop} {
+		cs, err := Push(store, dir)
+		if err != nil {
+			t.Fatalf("Push failed: %v", err)
+		}
+		if cs == nil || cs.Seq != 1 {
+			t.Fatalf("Expected first changeset with seq 1, got %+v", cs)
+		}
+	}
+
+	// More typing on the laptop lands in a second changeset
+	typeKeys(t, laptop, at, 3)
+	if cs, err := Push(laptop, dir); err != nil || cs == nil || cs.Seq != 2 {
+		t.Fatalf("Second push = %+v, %v; want seq 2", cs, err)
+	}
+
+	// Nothing new and a recent push: no changeset
+	if cs, err := Push(laptop, dir); err != nil || cs != nil {
+		t.Errorf("Idle push = %+v, %v; want nothing written", cs, err)
+	}
+
+	view, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	devices := view.Devices()
+	if len(devices) != 2 || devices[0].Name != "desktop" || devices[1].Name != "laptop" {
+		t.Fatalf("Devices = %+v, want desktop and laptop", devices)
+	}
+
+	laptopID, _ := laptop.DeviceID()
+	l := view.Device(laptopID)
+	if got := l.DayStats("2024-02-01"); got.Keystrokes != 4 || got.Words != 1 {
+		t.Errorf("Laptop day = %+v, want 4 keystrokes and 1 word", got)
+	}
+	if l.Changesets != 2 || l.LastSeen.IsZero() {
+		t.Errorf("Laptop changesets = %d, last seen %v", l.Changesets, l.LastSeen)
+	}
+	if hours := l.HourlyStats("2024-02-01"); hours[9] != 4 {
+		t.Errorf("Laptop hour 9 = %d, want 4", hours[9])
+	}
+	if keys := l.KeyCounts("2024-02-01"); keys[0] != 1 || keys[3] != 1 {
+		t.Errorf("Laptop keys = %v", keys)
+	}
+
+	// The desktop's combined view reads its own counts from the database
+	desktopID, _ := desktop.DeviceID()
+	typeKeys(t, desktop, at, 5) // not pushed yet
+	days, err := view.Breakdown(desktop, desktopID, []string{"2024-02-01"})
+	if err != nil {
+		t.Fatalf("Breakdown failed: %v", err)
+	}
+	day := days[0]
+	if day.ByDevice[desktopID].Keystrokes != 3 || day.ByDevice[laptopID].Keystrokes != 4 || day.Total.Keystrokes != 7 {
+		t.Errorf("Breakdown = %+v, want desktop 3 + laptop 4 = 7", day)
+	}
+}
+
+func TestLoadIgnoresDuplicatesAndTornLines(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, "laptop")
+	typeKeys(t, store, time.Date(2024, 2, 1, 9, 0, 0, 0, time.Local), 0, 0)
+	if _, err := Push(store, dir); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	id, _ := store.DeviceID()
+	data, err := os.ReadFile(FilePath(dir, id))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	// A sync-conflict copy of the same file, plus a half-synced line
+	conflict := filepath.Join(dir, id+".sync-conflict-20240201-120000"+fileExt)
+	if err := os.WriteFile(conflict, append(data, []byte(`{"v":1,"device":"`+id+`","seq":2,"hou`)...), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	// Files that are not changesets are ignored
+	os.WriteFile(filepath.Join(dir, ".stignore"), []byte("*.tmp\n"), 0644)
+
+	view, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := view.Device(id).DayStats("2024-02-01").Keystrokes; got != 2 {
+		t.Errorf("Keystrokes = %d, want 2 (duplicate changeset counted once)", got)
+	}
+	if view.Skipped != 1 {
+		t.Errorf("Skipped = %d, want 1", view.Skipped)
+	}
+}
+
+func TestPushExcludesImportedData(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, "laptop")
+
+	store.IncrementWordCount("2024-02-01")
+	if err := store.AddImportedDailyTotals("2024-02-01", storage.SourceWhatPulse, 5000, 100); err != nil {
+		t.Fatalf("AddImportedDailyTotals failed: %v", err)
+	}
+
+	cs, err := Push(store, dir)
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if len(cs.Words) != 1 || cs.Words[0].Words != 1 {
+		t.Errorf("Words = %+v, want only the 1 recorded word", cs.Words)
+	}
+
+	// Later words are sent as a delta
+	store.IncrementWordCount("2024-02-01")
+	store.IncrementWordCount("2024-02-01")
+	cs, err = Push(store, dir)
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if len(cs.Words) != 1 || cs.Words[0].Words != 2 {
+		t.Errorf("Words = %+v, want a delta of 2", cs.Words)
+	}
+}
+
+func TestPushMovesRedatedKeystrokes(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, "laptop")
+	id, _ := store.DeviceID()
+
+	typeKeys(t, store, time.Date(2024, 2, 1, 2, 30, 0, 0, time.Local), 7, 7)
+	if _, err := Push(store, dir); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	// 2:30am now belongs to the previous day
+	if err := store.SetDayStartHour(4); err != nil {
+		t.Fatalf("SetDayStartHour failed: %v", err)
+	}
+	cs, err := Push(store, dir)
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if cs == nil || len(cs.Hours) != 2 || cs.Hours[0].Keystrokes != 2 || cs.Hours[1].Keystrokes != -2 {
+		t.Fatalf("Changeset = %+v, want 2 keystrokes moved from 2024-02-01 to 2024-01-31", cs)
+	}
+
+	view, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	d := view.Device(id)
+	if old, moved := d.DayStats("2024-02-01").Keystrokes, d.DayStats("2024-01-31").Keystrokes; old != 0 || moved != 2 {
+		t.Errorf("Keystrokes = %d on 2024-02-01 and %d on 2024-01-31, want 0 and 2", old, moved)
+	}
+	if keys := d.KeyCounts("2024-01-31"); keys[7] != 2 {
+		t.Errorf("Keys on 2024-01-31 = %v, want keycode 7 twice", keys)
+	}
+}
+
+func TestPushRetriesAfterFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, "laptop")
+	id, _ := store.DeviceID()
+	typeKeys(t, store, time.Date(2024, 2, 1, 9, 0, 0, 0, time.Local), 0, 1)
+
+	// A directory in the way of the changeset file makes the write fail
+	if err := os.Mkdir(FilePath(dir, id), 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	if _, err := Push(store, dir); err == nil {
+		t.Fatal("Expected push to fail")
+	}
+	if err := os.Remove(FilePath(dir, id)); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	cs, err := Push(store, dir)
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if cs == nil || cs.Seq != 1 || len(cs.Hours) != 1 || cs.Hours[0].Keystrokes != 2 {
+		t.Errorf("Changeset = %+v, want seq 1 with both keystrokes", cs)
+	}
+}
+
+func TestPruneKeepsUnpushedKeystrokes(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, "laptop")
+	if err := store.SetSyncDir(dir); err != nil {
+		t.Fatalf("SetSyncDir failed: %v", err)
+	}
+	at := time.Date(2024, 2, 1, 9, 0, 0, 0, time.Local)
+
+	typeKeys(t, store, at, 0)
+	if _, err := Push(store, dir); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	typeKeys(t, store, at, 1)
+
+	pruned, err := store.PruneKeystrokesBefore("2024-03-01")
+	if err != nil {
+		t.Fatalf("PruneKeystrokesBefore failed: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("Pruned %d keystrokes, want only the pushed one", pruned)
+	}
+
+	cs, err := Push(store, dir)
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if cs == nil || len(cs.Keys) != 1 || cs.Keys[0].Keycode != 1 {
+		t.Errorf("Changeset = %+v, want the unpushed keystroke", cs)
+	}
+}
+
+func TestLoadMissingDir(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing sync folder")
+	}
+}
@@ -0,0 +1,216 @@
+package devicesync
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// Device is everything merged from one device's changesets
+type Device struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	LastSeen   time.Time `json:"last_seen"` // When its newest changeset was written
+	Changesets int       `json:"changesets"`
+
+	hours map[string]*[24]int64
+	keys  map[string]map[int]int64
+	words map[string]int64
+	seqs  map[int64]bool
+}
+
+// DayStats returns the device's keystroke and word totals for a day
+func (d *Device) DayStats(date string) storage.DailyStats {
+	stats := storage.DailyStats{Date: date, Words: d.words[date]}
+	if hours := d.hours[date]; hours != nil {
+		for _, n := range hours {
+			stats.Keystrokes += n
+		}
+	}
+	return stats
+}
+
+// HourlyStats returns the device's keystrokes in each hour of a day
+func (d *Device) HourlyStats(date string) [24]int64 {
+	if hours := d.hours[date]; hours != nil {
+		return *hours
+	}
+	return [24]int64{}
+}
+
+// KeyCounts returns the device's presses per keycode on a day
+func (d *Device) KeyCounts(date string) map[int]int64 {
+	counts := make(map[int]int64, len(d.keys[date]))
+	for k, n := range d.keys[date] {
+		counts[k] = n
+	}
+	return counts
+}
+
+// TotalKeystrokes returns every keystroke the device has shared
+func (d *Device) TotalKeystrokes() int64 {
+	var total int64
+	for _, hours := range d.hours {
+		for _, n := range hours {
+			total += n
+		}
+	}
+	return total
+}
+
+func (d *Device) apply(cs *Changeset) {
+	d.seqs[cs.Seq] = true
+	d.Changesets++
+	if !cs.Created.Before(d.LastSeen) {
+		d.LastSeen = cs.Created
+		if cs.Name != "" {
+			d.Name = cs.Name
+		}
+	}
+
+	for _, h := range cs.Hours {
+		if h.Hour < 0 || h.Hour > 23 {
+			continue
+		}
+		if d.hours[h.Date] == nil {
+			d.hours[h.Date] = new([24]int64)
+		}
+		d.hours[h.Date][h.Hour] += h.Keystrokes
+	}
+	for _, k := range cs.Keys {
+		if d.keys[k.Date] == nil {
+			d.keys[k.Date] = make(map[int]int64)
+		}
+		d.keys[k.Date][k.Keycode] += k.Count
+	}
+	for _, w := range cs.Words {
+		d.words[w.Date] += w.Words
+	}
+}
+
+// View is the merge of every changeset in a sync folder
+type View struct {
+	devices map[string]*Device
+
+	// Skipped counts lines that could not be used: torn writes still being
+	// synced, or changesets from a newer typtel
+	Skipped int
+}
+
+// Load reads and merges every changeset file in dir
+func Load(dir string) (*View, error) {
+	v := &View{devices: make(map[string]*Device)}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileExt) {
+			continue
+		}
+		if err := v.loadFile(filepath.Join(dir, e.Name())); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+func (v *View) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var cs Changeset
+		if err := json.Unmarshal(scanner.Bytes(), &cs); err != nil || cs.Device == "" || cs.Version > FormatVersion {
+			v.Skipped++
+			continue
+		}
+
+		d := v.devices[cs.Device]
+		if d == nil {
+			d = &Device{
+				ID:    cs.Device,
+				hours: make(map[string]*[24]int64),
+				keys:  make(map[string]map[int]int64),
+				words: make(map[string]int64),
+				seqs:  make(map[int64]bool),
+			}
+			v.devices[cs.Device] = d
+		}
+		// The same changeset seen twice (a copied file) only counts once
+		if d.seqs[cs.Seq] {
+			continue
+		}
+		d.apply(&cs)
+	}
+	return scanner.Err()
+}
+
+// Devices returns every device in the folder, ordered by name
+func (v *View) Devices() []*Device {
+	devices := make([]*Device, 0, len(v.devices))
+	for _, d := range v.devices {
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].Name != devices[j].Name {
+			return devices[i].Name < devices[j].Name
+		}
+		return devices[i].ID < devices[j].ID
+	})
+	return devices
+}
+
+// Device returns the device with the given ID, or nil
+func (v *View) Device(id string) *Device {
+	return v.devices[id]
+}
+
+// DayBreakdown is one day's totals across devices
+type DayBreakdown struct {
+	Date     string                        `json:"date"`
+	Total    storage.DailyStats            `json:"total"`
+	ByDevice map[string]storage.DailyStats `json:"by_device"` // Keyed by device ID
+}
+
+// Breakdown combines the local database with every other device's changesets
+// for the given dates. The local device is read from store rather than from
+// its changesets, so counts not yet pushed are included.
+func (v *View) Breakdown(store *storage.Store, localID string, dates []string) ([]DayBreakdown, error) {
+	var days []DayBreakdown
+	for _, date := range dates {
+		day := DayBreakdown{Date: date, Total: storage.DailyStats{Date: date}, ByDevice: make(map[string]storage.DailyStats)}
+
+		local, err := store.GetDayStats(date)
+		if err != nil {
+			return nil, err
+		}
+		day.ByDevice[localID] = *local
+
+		for id, d := range v.devices {
+			if id != localID {
+				day.ByDevice[id] = d.DayStats(date)
+			}
+		}
+		for _, stats := range day.ByDevice {
+			day.Total.Keystrokes += stats.Keystrokes
+			day.Total.Words += stats.Words
+		}
+
+		days = append(days, day)
+	}
+	return days, nil
+}
@@ -0,0 +1,300 @@
+// Package export dumps typtel telemetry into portable files and loads them back.
+//
+// The schema is versioned by SchemaVersion and is the same across formats.
+// Every table is flat, so each one maps directly onto a CSV file, an NDJSON
+// stream or a Parquet table:
+//
+//	daily   date, keystrokes, words
+//	hourly  date, hour, keystrokes
+//	mouse   date, distance_px, clicks, movements
+//	keys    date, keycode, count
+//	tests   timestamp, wpm, accuracy, duration_ms, word_count, punctuation
+//
+// Dates are logical days ("2006-01-02"), hours are 0-23, keycodes are macOS
+// virtual keycodes, distance is in screen pixels and test timestamps are
+// RFC 3339 in UTC.
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// SchemaVersion is bumped whenever a table or column changes meaning
+const SchemaVersion = 1
+
+// Table names
+const (
+	TableDaily  = "daily"
+	TableHourly = "hourly"
+	TableMouse  = "mouse"
+	TableKeys   = "keys"
+	TableTests  = "tests"
+)
+
+// Tables lists every exportable table in output order
+var Tables = []string{TableDaily, TableHourly, TableMouse, TableKeys, TableTests}
+
+// DailyRecord is one row of the daily table
+type DailyRecord struct {
+	Date       string `json:"date"`
+	Keystrokes int64  `json:"keystrokes"`
+	Words      int64  `json:"words"`
+}
+
+// HourlyRecord is one row of the hourly table
+type HourlyRecord struct {
+	Date       string `json:"date"`
+	Hour       int    `json:"hour"`
+	Keystrokes int64  `json:"keystrokes"`
+}
+
+// MouseRecord is one row of the mouse table
+type MouseRecord struct {
+	Date       string  `json:"date"`
+	DistancePx float64 `json:"distance_px"`
+	Clicks     int64   `json:"clicks"`
+	Movements  int64   `json:"movements"`
+}
+
+// KeyRecord is one row of the keys table
+type KeyRecord struct {
+	Date    string `json:"date"`
+	Keycode int    `json:"keycode"`
+	Count   int64  `json:"count"`
+}
+
+// TestRecord is one row of the tests table
+type TestRecord struct {
+	Timestamp   time.Time `json:"timestamp"`
+	WPM         float64   `json:"wpm"`
+	Accuracy    float64   `json:"accuracy"`
+	DurationMS  int64     `json:"duration_ms"`
+	WordCount   int       `json:"word_count"`
+	Punctuation bool      `json:"punctuation"`
+}
+
+// Dataset is a complete export document
+type Dataset struct {
+	SchemaVersion int            `json:"schema_version"`
+	From          string         `json:"from,omitempty"`
+	To            string         `json:"to,omitempty"`
+	Daily         []DailyRecord  `json:"daily,omitempty"`
+	Hourly        []HourlyRecord `json:"hourly,omitempty"`
+	Mouse         []MouseRecord  `json:"mouse,omitempty"`
+	Keys          []KeyRecord    `json:"keys,omitempty"`
+	Tests         []TestRecord   `json:"tests,omitempty"`
+}
+
+// Options selects what to export
+type Options struct {
+	From   string   // Inclusive start date, empty for unbounded
+	To     string   // Inclusive end date, empty for unbounded
+	Tables []string // Tables to include, empty for all
+}
+
+// ParseTables splits a comma-separated table list and validates each name
+func ParseTables(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	var tables []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if !isTable(name) {
+			return nil, fmt.Errorf("unknown table %q (valid: %s)", name, strings.Join(Tables, ", "))
+		}
+		tables = append(tables, name)
+	}
+	return tables, nil
+}
+
+// ValidateDate checks a --from/--to value
+func ValidateDate(date string) error {
+	if date == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
+	return nil
+}
+
+func isTable(name string) bool {
+	for _, t := range Tables {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (o Options) wants(table string) bool {
+	if len(o.Tables) == 0 {
+		return true
+	}
+	for _, t := range o.Tables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// Collect reads the selected tables from the store
+func Collect(store *storage.Store, opts Options) (*Dataset, error) {
+	if err := ValidateDate(opts.From); err != nil {
+		return nil, err
+	}
+	if err := ValidateDate(opts.To); err != nil {
+		return nil, err
+	}
+
+	ds := &Dataset{SchemaVersion: SchemaVersion, From: opts.From, To: opts.To}
+
+	if opts.wants(TableDaily) {
+		days, err := store.GetDailyRange(opts.From, opts.To)
+		if err != nil {
+			return nil, fmt.Errorf("daily: %w", err)
+		}
+		for _, d := range days {
+			ds.Daily = append(ds.Daily, DailyRecord{Date: d.Date, Keystrokes: d.Keystrokes, Words: d.Words})
+		}
+	}
+
+	if opts.wants(TableHourly) {
+		hours, err := store.GetHourlyRange(opts.From, opts.To)
+		if err != nil {
+			return nil, fmt.Errorf("hourly: %w", err)
+		}
+		for _, h := range hours {
+			ds.Hourly = append(ds.Hourly, HourlyRecord{Date: h.Date, Hour: h.Hour, Keystrokes: h.Keystrokes})
+		}
+	}
+
+	if opts.wants(TableMouse) {
+		days, err := store.GetMouseRange(opts.From, opts.To)
+		if err != nil {
+			return nil, fmt.Errorf("mouse: %w", err)
+		}
+		for _, m := range days {
+			ds.Mouse = append(ds.Mouse, MouseRecord{Date: m.Date, DistancePx: m.TotalDistance, Clicks: m.ClickCount, Movements: m.MovementCount})
+		}
+	}
+
+	if opts.wants(TableKeys) {
+		keys, err := store.GetKeyCountRange(opts.From, opts.To)
+		if err != nil {
+			return nil, fmt.Errorf("keys: %w", err)
+		}
+		for _, k := range keys {
+			ds.Keys = append(ds.Keys, KeyRecord{Date: k.Date, Keycode: k.Keycode, Count: k.Count})
+		}
+	}
+
+	if opts.wants(TableTests) {
+		tests, err := store.GetTypingTestHistory(opts.From, opts.To)
+		if err != nil {
+			return nil, fmt.Errorf("tests: %w", err)
+		}
+		for _, r := range tests {
+			ds.Tests = append(ds.Tests, TestRecord{
+				Timestamp:   r.Timestamp.UTC(),
+				WPM:         r.WPM,
+				Accuracy:    r.Accuracy,
+				DurationMS:  r.Duration.Milliseconds(),
+				WordCount:   r.WordCount,
+				Punctuation: r.Punctuation,
+			})
+		}
+	}
+
+	return ds, nil
+}
+
+// ImportSummary counts the rows applied per table
+type ImportSummary struct {
+	Daily  int
+	Hourly int
+	Mouse  int
+	Keys   int
+	Tests  int
+}
+
+// Total returns the number of rows applied across all tables
+func (s ImportSummary) Total() int {
+	return s.Daily + s.Hourly + s.Mouse + s.Keys + s.Tests
+}
+
+// Apply loads a dataset exported by typtel into the store. Counts are added
+// to whatever is already stored, so importing into an empty database
+// reproduces the export exactly and importing into an existing one sums
+// colliding days.
+func Apply(store *storage.Store, ds *Dataset) (ImportSummary, error) {
+	return ApplyFrom(store, ds, storage.SourceExport)
+}
+
+// ApplyFrom loads a dataset like Apply, tagging daily totals and typing tests
+// with source. Typing tests only count towards personal bests when they were
+// taken in typtel; results from other tools go into the history alone.
+func ApplyFrom(store *storage.Store, ds *Dataset, source string) (ImportSummary, error) {
+	var sum ImportSummary
+	if ds.SchemaVersion > SchemaVersion {
+		return sum, fmt.Errorf("export schema version %d is newer than supported version %d", ds.SchemaVersion, SchemaVersion)
+	}
+	countsTowardsBests := source == storage.SourceExport || source == storage.SourceTyptelDB
+
+	for _, d := range ds.Daily {
+		if err := store.AddImportedDailyTotals(d.Date, source, d.Keystrokes, d.Words); err != nil {
+			return sum, fmt.Errorf("daily %s: %w", d.Date, err)
+		}
+		sum.Daily++
+	}
+
+	for _, h := range ds.Hourly {
+		if err := store.AddHourlyCount(h.Date, h.Hour, h.Keystrokes); err != nil {
+			return sum, fmt.Errorf("hourly %s %d: %w", h.Date, h.Hour, err)
+		}
+		sum.Hourly++
+	}
+
+	for _, m := range ds.Mouse {
+		if err := store.AddMouseTotals(m.Date, m.DistancePx, m.Clicks, m.Movements); err != nil {
+			return sum, fmt.Errorf("mouse %s: %w", m.Date, err)
+		}
+		sum.Mouse++
+	}
+
+	for _, k := range ds.Keys {
+		if err := store.AddKeyCount(k.Date, k.Keycode, k.Count); err != nil {
+			return sum, fmt.Errorf("keys %s %d: %w", k.Date, k.Keycode, err)
+		}
+		sum.Keys++
+	}
+
+	for _, t := range ds.Tests {
+		result := storage.TypingTestResult{
+			Timestamp:   t.Timestamp,
+			WPM:         t.WPM,
+			Accuracy:    t.Accuracy,
+			Duration:    time.Duration(t.DurationMS) * time.Millisecond,
+			WordCount:   t.WordCount,
+			Punctuation: t.Punctuation,
+			Source:      source,
+		}
+		if err := store.RecordTypingTest(result); err != nil {
+			return sum, fmt.Errorf("tests %s: %w", t.Timestamp.Format(time.RFC3339), err)
+		}
+		if countsTowardsBests {
+			if err := store.SaveTypingTestResultForMode(t.WPM, result.Mode()); err != nil {
+				return sum, fmt.Errorf("tests %s: %w", t.Timestamp.Format(time.RFC3339), err)
+			}
+		}
+		sum.Tests++
+	}
+
+	return sum, nil
+}
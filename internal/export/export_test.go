@@ -0,0 +1,230 @@
+package export
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+func newTestStore(t *testing.T) *storage.Store {
+	t.Helper()
+	store, err := storage.NewWithPath(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// seedStore records a small but complete set of telemetry
+func seedStore(t *testing.T, store *storage.Store) {
+	t.Helper()
+
+	base := time.Date(2024, 3, 10, 9, 15, 0, 0, time.Local)
+	for i, keycode := range []int{0, 1, 1, 49, 36, 0} {
+		if err := store.RecordKeystrokeAt(keycode, base.Add(time.Duration(i)*90*time.Minute)); err != nil {
+			t.Fatalf("RecordKeystrokeAt failed: %v", err)
+		}
+	}
+	if err := store.IncrementWordCount("2024-03-10"); err != nil {
+		t.Fatalf("IncrementWordCount failed: %v", err)
+	}
+	if err := store.AddMouseTotals("2024-03-10", 1234.5, 7, 42); err != nil {
+		t.Fatalf("AddMouseTotals failed: %v", err)
+	}
+	if err := store.RecordTypingTest(storage.TypingTestResult{
+		Timestamp:   time.Date(2024, 3, 10, 12, 0, 0, 123e6, time.UTC),
+		WPM:         87.25,
+		Accuracy:    96.5,
+		Duration:    41500 * time.Millisecond,
+		WordCount:   25,
+		Punctuation: true,
+	}); err != nil {
+		t.Fatalf("RecordTypingTest failed: %v", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	src := newTestStore(t)
+	seedStore(t, src)
+
+	want, err := Collect(src, Options{})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(want.Daily) == 0 || len(want.Hourly) == 0 || len(want.Mouse) == 0 || len(want.Keys) == 0 || len(want.Tests) == 0 {
+		t.Fatalf("Expected every table to have rows, got %+v", want)
+	}
+
+	for _, format := range []string{FormatJSON, FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, want, format, nil); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			ds, err := Read(&buf, format)
+			if err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+
+			dst := newTestStore(t)
+			if _, err := Apply(dst, ds); err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			got, err := Collect(dst, Options{})
+			if err != nil {
+				t.Fatalf("Collect failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Round trip mismatch\ngot:  %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+
+	t.Run(FormatCSV, func(t *testing.T) {
+		dir := t.TempDir()
+		if err := WriteCSVDir(dir, want, nil); err != nil {
+			t.Fatalf("WriteCSVDir failed: %v", err)
+		}
+		ds, err := ReadCSVDir(dir)
+		if err != nil {
+			t.Fatalf("ReadCSVDir failed: %v", err)
+		}
+
+		dst := newTestStore(t)
+		if _, err := Apply(dst, ds); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		got, err := Collect(dst, Options{})
+		if err != nil {
+			t.Fatalf("Collect failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Round trip mismatch\ngot:  %+v\nwant: %+v", got, want)
+		}
+	})
+}
+
+func TestApplySumsExistingData(t *testing.T) {
+	store := newTestStore(t)
+	seedStore(t, store)
+
+	ds, err := Collect(store, Options{Tables: []string{TableDaily}})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	before := ds.Daily[0].Keystrokes
+
+	if _, err := Apply(store, ds); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	stats, err := store.GetDayStats(ds.Daily[0].Date)
+	if err != nil {
+		t.Fatalf("GetDayStats failed: %v", err)
+	}
+	if stats.Keystrokes != 2*before {
+		t.Errorf("Keystrokes = %d, want %d", stats.Keystrokes, 2*before)
+	}
+}
+
+func TestApplyRejectsNewerSchema(t *testing.T) {
+	store := newTestStore(t)
+	if _, err := Apply(store, &Dataset{SchemaVersion: SchemaVersion + 1}); err == nil {
+		t.Error("Expected error for newer schema version")
+	}
+}
+
+func TestCollectFilters(t *testing.T) {
+	store := newTestStore(t)
+	seedStore(t, store)
+	if err := store.AddDailyTotals("2024-04-01", 10, 2); err != nil {
+		t.Fatalf("AddDailyTotals failed: %v", err)
+	}
+
+	ds, err := Collect(store, Options{From: "2024-04-01", Tables: []string{TableDaily}})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(ds.Daily) != 1 || ds.Daily[0].Date != "2024-04-01" {
+		t.Errorf("Daily = %+v, want only 2024-04-01", ds.Daily)
+	}
+	if ds.Hourly != nil || ds.Mouse != nil || ds.Keys != nil || ds.Tests != nil {
+		t.Error("Expected unselected tables to be empty")
+	}
+
+	if _, err := Collect(store, Options{To: "April"}); err == nil {
+		t.Error("Expected error for invalid date")
+	}
+}
+
+func TestParseTables(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"daily", []string{"daily"}, false},
+		{"daily, keys", []string{"daily", "keys"}, false},
+		{"daily,bogus", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTables(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTables(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTables(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCSVSingleTable(t *testing.T) {
+	ds := &Dataset{SchemaVersion: SchemaVersion, Daily: []DailyRecord{{Date: "2024-03-10", Keystrokes: 6, Words: 1}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, ds, FormatCSV, []string{TableDaily}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := "date,keystrokes,words\n2024-03-10,6,1\n"
+	if buf.String() != want {
+		t.Errorf("CSV = %q, want %q", buf.String(), want)
+	}
+
+	if err := Write(&buf, ds, FormatCSV, nil); err == nil {
+		t.Error("Expected error writing several tables to one CSV stream")
+	}
+}
+
+func TestReadCSVUnknownHeader(t *testing.T) {
+	if _, err := Read(bytes.NewBufferString("foo,bar\n1,2\n"), FormatCSV); err == nil {
+		t.Error("Expected error for unrecognised header")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"out.csv", FormatCSV, false},
+		{"out.JSON", FormatJSON, false},
+		{"out.ndjson", FormatNDJSON, false},
+		{"out.jsonl", FormatNDJSON, false},
+		{"out.txt", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := DetectFormat(tt.path)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, %v; want %q, wantErr %v", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// Formats lists the supported formats
+var Formats = []string{FormatCSV, FormatJSON, FormatNDJSON}
+
+// csvHeaders is the column order for each table in CSV output
+var csvHeaders = map[string][]string{
+	TableDaily:  {"date", "keystrokes", "words"},
+	TableHourly: {"date", "hour", "keystrokes"},
+	TableMouse:  {"date", "distance_px", "clicks", "movements"},
+	TableKeys:   {"date", "keycode", "count"},
+	TableTests:  {"timestamp", "wpm", "accuracy", "duration_ms", "word_count", "punctuation"},
+}
+
+// DetectFormat guesses the format from a file extension
+func DetectFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("cannot detect format of %q, pass --format", path)
+}
+
+// Write encodes the dataset. CSV holds a single table per stream, so it
+// requires exactly one table with data selected; use WriteCSVDir for several.
+func Write(w io.Writer, ds *Dataset, format string, tables []string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ds)
+	case FormatNDJSON:
+		return writeNDJSON(w, ds)
+	case FormatCSV:
+		if len(tables) != 1 {
+			return fmt.Errorf("csv output holds one table; pass --tables with a single table or --output with a directory")
+		}
+		return writeCSV(w, ds, tables[0])
+	}
+	return fmt.Errorf("unknown format %q (valid: %s)", format, strings.Join(Formats, ", "))
+}
+
+// WriteCSVDir writes one <table>.csv file per selected table into dir
+func WriteCSVDir(dir string, ds *Dataset, tables []string) error {
+	if len(tables) == 0 {
+		tables = Tables
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		f, err := os.Create(filepath.Join(dir, table+".csv"))
+		if err != nil {
+			return err
+		}
+		if err := writeCSV(f, ds, table); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeNDJSON(w io.Writer, ds *Dataset) error {
+	bw := bufio.NewWriter(w)
+
+	meta, err := json.Marshal(struct {
+		Table         string `json:"table"`
+		SchemaVersion int    `json:"schema_version"`
+		From          string `json:"from,omitempty"`
+		To            string `json:"to,omitempty"`
+	}{"meta", ds.SchemaVersion, ds.From, ds.To})
+	if err != nil {
+		return err
+	}
+	bw.Write(meta)
+	bw.WriteByte('\n')
+
+	line := func(table string, record interface{}) error {
+		b, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		// Splice the table name into the record object
+		fmt.Fprintf(bw, "{\"table\":%q,%s\n", table, b[1:])
+		return nil
+	}
+
+	for _, r := range ds.Daily {
+		if err := line(TableDaily, r); err != nil {
+			return err
+		}
+	}
+	for _, r := range ds.Hourly {
+		if err := line(TableHourly, r); err != nil {
+			return err
+		}
+	}
+	for _, r := range ds.Mouse {
+		if err := line(TableMouse, r); err != nil {
+			return err
+		}
+	}
+	for _, r := range ds.Keys {
+		if err := line(TableKeys, r); err != nil {
+			return err
+		}
+	}
+	for _, r := range ds.Tests {
+		if err := line(TableTests, r); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func writeCSV(w io.Writer, ds *Dataset, table string) error {
+	header, ok := csvHeaders[table]
+	if !ok {
+		return fmt.Errorf("unknown table %q", table)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	i64 := func(n int64) string { return strconv.FormatInt(n, 10) }
+	f64 := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+
+	// Row write errors are sticky and surface through cw.Error below
+	switch table {
+	case TableDaily:
+		for _, r := range ds.Daily {
+			cw.Write([]string{r.Date, i64(r.Keystrokes), i64(r.Words)})
+		}
+	case TableHourly:
+		for _, r := range ds.Hourly {
+			cw.Write([]string{r.Date, strconv.Itoa(r.Hour), i64(r.Keystrokes)})
+		}
+	case TableMouse:
+		for _, r := range ds.Mouse {
+			cw.Write([]string{r.Date, f64(r.DistancePx), i64(r.Clicks), i64(r.Movements)})
+		}
+	case TableKeys:
+		for _, r := range ds.Keys {
+			cw.Write([]string{r.Date, strconv.Itoa(r.Keycode), i64(r.Count)})
+		}
+	case TableTests:
+		for _, r := range ds.Tests {
+			cw.Write([]string{
+				r.Timestamp.UTC().Format(time.RFC3339Nano), f64(r.WPM), f64(r.Accuracy),
+				i64(r.DurationMS), strconv.Itoa(r.WordCount), strconv.FormatBool(r.Punctuation),
+			})
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Read decodes a dataset written by Write
+func Read(r io.Reader, format string) (*Dataset, error) {
+	switch format {
+	case FormatJSON:
+		var ds Dataset
+		if err := json.NewDecoder(r).Decode(&ds); err != nil {
+			return nil, err
+		}
+		return &ds, nil
+	case FormatNDJSON:
+		return readNDJSON(r)
+	case FormatCSV:
+		ds := &Dataset{SchemaVersion: SchemaVersion}
+		if err := readCSV(r, ds); err != nil {
+			return nil, err
+		}
+		return ds, nil
+	}
+	return nil, fmt.Errorf("unknown format %q (valid: %s)", format, strings.Join(Formats, ", "))
+}
+
+// ReadCSVDir reads every <table>.csv file present in dir
+func ReadCSVDir(dir string) (*Dataset, error) {
+	ds := &Dataset{SchemaVersion: SchemaVersion}
+	found := false
+	for _, table := range Tables {
+		f, err := os.Open(filepath.Join(dir, table+".csv"))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		found = true
+		err = readCSV(f, ds)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s.csv: %w", table, err)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no table CSV files found in %s", dir)
+	}
+	return ds, nil
+}
+
+func readNDJSON(r io.Reader) (*Dataset, error) {
+	ds := &Dataset{SchemaVersion: SchemaVersion}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		b := scanner.Bytes()
+		if len(strings.TrimSpace(string(b))) == 0 {
+			continue
+		}
+
+		var head struct {
+			Table         string `json:"table"`
+			SchemaVersion int    `json:"schema_version"`
+			From          string `json:"from"`
+			To            string `json:"to"`
+		}
+		if err := json.Unmarshal(b, &head); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		var err error
+		switch head.Table {
+		case "meta":
+			ds.SchemaVersion, ds.From, ds.To = head.SchemaVersion, head.From, head.To
+		case TableDaily:
+			var rec DailyRecord
+			if err = json.Unmarshal(b, &rec); err == nil {
+				ds.Daily = append(ds.Daily, rec)
+			}
+		case TableHourly:
+			var rec HourlyRecord
+			if err = json.Unmarshal(b, &rec); err == nil {
+				ds.Hourly = append(ds.Hourly, rec)
+			}
+		case TableMouse:
+			var rec MouseRecord
+			if err = json.Unmarshal(b, &rec); err == nil {
+				ds.Mouse = append(ds.Mouse, rec)
+			}
+		case TableKeys:
+			var rec KeyRecord
+			if err = json.Unmarshal(b, &rec); err == nil {
+				ds.Keys = append(ds.Keys, rec)
+			}
+		case TableTests:
+			var rec TestRecord
+			if err = json.Unmarshal(b, &rec); err == nil {
+				ds.Tests = append(ds.Tests, rec)
+			}
+		default:
+			err = fmt.Errorf("unknown table %q", head.Table)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+	}
+
+	return ds, scanner.Err()
+}
+
+// TableForHeader returns the table whose CSV header matches, or "" if none does
+func TableForHeader(header []string) string {
+	for name, cols := range csvHeaders {
+		if strings.Join(cols, ",") == strings.Join(header, ",") {
+			return name
+		}
+	}
+	return ""
+}
+
+// readCSV appends rows from a single-table CSV stream, identifying the table
+// by its header row
+func readCSV(r io.Reader, ds *Dataset) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+
+	table := TableForHeader(header)
+	if table == "" {
+		return fmt.Errorf("unrecognised CSV header %q", strings.Join(header, ","))
+	}
+
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := appendCSVRow(ds, table, row); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+func appendCSVRow(ds *Dataset, table string, row []string) error {
+	var errs []error
+	i64 := func(s string) int64 {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return n
+	}
+	f64 := func(s string) float64 {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return f
+	}
+
+	switch table {
+	case TableDaily:
+		ds.Daily = append(ds.Daily, DailyRecord{Date: row[0], Keystrokes: i64(row[1]), Words: i64(row[2])})
+	case TableHourly:
+		ds.Hourly = append(ds.Hourly, HourlyRecord{Date: row[0], Hour: int(i64(row[1])), Keystrokes: i64(row[2])})
+	case TableMouse:
+		ds.Mouse = append(ds.Mouse, MouseRecord{Date: row[0], DistancePx: f64(row[1]), Clicks: i64(row[2]), Movements: i64(row[3])})
+	case TableKeys:
+		ds.Keys = append(ds.Keys, KeyRecord{Date: row[0], Keycode: int(i64(row[1])), Count: i64(row[2])})
+	case TableTests:
+		ts, err := time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			errs = append(errs, err)
+		}
+		punct, err := strconv.ParseBool(row[5])
+		if err != nil {
+			errs = append(errs, err)
+		}
+		ds.Tests = append(ds.Tests, TestRecord{
+			Timestamp: ts, WPM: f64(row[1]), Accuracy: f64(row[2]),
+			DurationMS: i64(row[3]), WordCount: int(i64(row[4])), Punctuation: punct,
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Package goals measures days against daily targets and counts streaks of
+// days that met them.
+//
+// Goals are minimums for keystrokes, words and typing tests and a maximum
+// for mouse distance; a goal of 0 is unset. A day meets its goals when
+// something was typed and every goal set for it was met. Goals change over
+// time, so a day is judged by the goals in effect on it (see History), and
+// changing a goal never rewrites past streaks.
+package goals
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+)
+
+// NotificationKind marks goal progress sent with internal/notify
+const NotificationKind = "goal"
+
+// Metric names what a goal measures
+type Metric string
+
+const (
+	Keystrokes    Metric = "keystrokes"
+	Words         Metric = "words"
+	TypingTests   Metric = "typing_tests"
+	MouseDistance Metric = "mouse_distance" // in feet; a maximum
+)
+
+// Day is one day's totals
+type Day struct {
+	Date        string
+	Keystrokes  int64
+	Words       int64
+	TypingTests int64
+	MouseFeet   float64
+}
+
+// Target is a day's progress towards one goal
+type Target struct {
+	Metric Metric  `json:"metric"`
+	Goal   float64 `json:"goal"`
+	Value  float64 `json:"value"`
+	Max    bool    `json:"max,omitempty"` // the goal is a ceiling, not a minimum
+	Met    bool    `json:"met"`
+}
+
+// Targets returns d's progress towards each goal set in g
+func Targets(g config.Goals, d Day) []Target {
+	var targets []Target
+	minimum := func(m Metric, goal int, value int64) {
+		if goal > 0 {
+			targets = append(targets, Target{Metric: m, Goal: float64(goal), Value: float64(value), Met: value >= int64(goal)})
+		}
+	}
+	minimum(Keystrokes, g.Keystrokes, d.Keystrokes)
+	minimum(Words, g.Words, d.Words)
+	minimum(TypingTests, g.TypingTests, d.TypingTests)
+	if g.MaxMouseFeet > 0 {
+		targets = append(targets, Target{Metric: MouseDistance, Goal: g.MaxMouseFeet, Value: d.MouseFeet, Max: true, Met: d.MouseFeet <= g.MaxMouseFeet})
+	}
+	return targets
+}
+
+// Met reports whether d met the goals in g: something was typed, at least
+// one goal is set and every goal is met
+func Met(g config.Goals, d Day) bool {
+	targets := Targets(g, d)
+	if d.Keystrokes == 0 || len(targets) == 0 {
+		return false
+	}
+	for _, t := range targets {
+		if !t.Met {
+			return false
+		}
+	}
+	return true
+}
+
+// Change is a set of goals that took effect on Date
+type Change struct {
+	Date  string
+	Goals config.Goals
+}
+
+// History is every change of goals, oldest first
+type History []Change
+
+// On returns the goals in effect on date. Days before the first change are
+// judged by the first goals recorded; without any, current applies.
+func (h History) On(date string, current config.Goals) config.Goals {
+	if len(h) == 0 {
+		return current
+	}
+	g := h[0].Goals
+	for _, c := range h {
+		if c.Date > date {
+			break
+		}
+		g = c.Goals
+	}
+	return g
+}
+
+// Streak counts days in a row that met their goals
+type Streak struct {
+	Current  int  `json:"current"` // ending today, or yesterday while today's goals aren't met yet
+	Longest  int  `json:"longest"`
+	TodayMet bool `json:"today_met"`
+}
+
+// Streaks counts streaks in days up to today, judging each day by goalsOn.
+// Days missing from days had nothing typed and break a streak.
+func Streaks(days []Day, goalsOn func(date string) config.Goals, today string) Streak {
+	end, err := time.Parse("2006-01-02", today)
+	if err != nil || len(days) == 0 {
+		return Streak{}
+	}
+	byDate := make(map[string]Day, len(days))
+	start := end
+	for _, d := range days {
+		byDate[d.Date] = d
+		if t, err := time.Parse("2006-01-02", d.Date); err == nil && t.Before(start) {
+			start = t
+		}
+	}
+
+	var s Streak
+	run, beforeToday := 0, 0
+	for t := start; !t.After(end); t = t.AddDate(0, 0, 1) {
+		date := t.Format("2006-01-02")
+		beforeToday = run
+		d := byDate[date]
+		d.Date = date
+		if Met(goalsOn(date), d) {
+			run++
+		} else {
+			run = 0
+		}
+		s.Longest = max(s.Longest, run)
+	}
+
+	s.TodayMet = run > 0
+	s.Current = run
+	if !s.TodayMet {
+		s.Current = beforeToday
+	}
+	return s
+}
+
+// Message describes a change in a goal's progress for a notification, e.g.
+// "Keystroke goal met: 10,000 today."
+func Message(t Target, distance func(feet float64) string) string {
+	switch {
+	case t.Max && !t.Met:
+		return fmt.Sprintf("Over your mouse distance goal: %s of %s.", distance(t.Value), distance(t.Goal))
+	case t.Max:
+		return fmt.Sprintf("Back under your mouse distance goal of %s.", distance(t.Goal))
+	case t.Met:
+		return fmt.Sprintf("%s goal met: %s today.", t.Metric.Label(), formatCount(t.Value))
+	}
+	return fmt.Sprintf("%s goal: %s of %s so far.", t.Metric.Label(), formatCount(t.Value), formatCount(t.Goal))
+}
+
+// Changed returns the targets in after whose Met differs from before; a
+// target missing from before is unchanged
+func Changed(before, after []Target) []Target {
+	var changed []Target
+	for _, a := range after {
+		for _, b := range before {
+			if a.Metric == b.Metric && a.Met != b.Met {
+				changed = append(changed, a)
+			}
+		}
+	}
+	return changed
+}
+
+// Label names the metric for display, e.g. "Keystroke"
+func (m Metric) Label() string {
+	switch m {
+	case Keystrokes:
+		return "Keystroke"
+	case Words:
+		return "Word"
+	case TypingTests:
+		return "Typing test"
+	case MouseDistance:
+		return "Mouse distance"
+	}
+	return string(m)
+}
+
+// formatCount formats a whole count with thousands separators
+func formatCount(v float64) string {
+	s := fmt.Sprintf("%.0f", v)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
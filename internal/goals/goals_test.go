@@ -0,0 +1,174 @@
+package goals
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+)
+
+var tenThousand = config.Goals{Keystrokes: 10000}
+
+func TestTargets(t *testing.T) {
+	g := config.Goals{Keystrokes: 10000, Words: 2000, TypingTests: 1, MaxMouseFeet: 500}
+	d := Day{Keystrokes: 7450, Words: 2000, MouseFeet: 620}
+	want := []Target{
+		{Metric: Keystrokes, Goal: 10000, Value: 7450},
+		{Metric: Words, Goal: 2000, Value: 2000, Met: true},
+		{Metric: TypingTests, Goal: 1},
+		{Metric: MouseDistance, Goal: 500, Value: 620, Max: true},
+	}
+	got := Targets(g, d)
+	if len(got) != len(want) {
+		t.Fatalf("Targets = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("target %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := Targets(config.Goals{}, d); len(got) != 0 {
+		t.Errorf("Targets without goals = %+v, want none", got)
+	}
+}
+
+func TestMet(t *testing.T) {
+	tests := []struct {
+		name  string
+		goals config.Goals
+		day   Day
+		want  bool
+	}{
+		{"no goals", config.Goals{}, Day{Keystrokes: 500}, false},
+		{"short", tenThousand, Day{Keystrokes: 9999}, false},
+		{"met", tenThousand, Day{Keystrokes: 10000}, true},
+		{"one of two", config.Goals{Keystrokes: 100, Words: 50}, Day{Keystrokes: 200, Words: 10}, false},
+		{"under the mouse limit", config.Goals{MaxMouseFeet: 100}, Day{Keystrokes: 1, MouseFeet: 99}, true},
+		{"over the mouse limit", config.Goals{MaxMouseFeet: 100}, Day{Keystrokes: 1, MouseFeet: 101}, false},
+		{"nothing typed", config.Goals{MaxMouseFeet: 100}, Day{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Met(tt.goals, tt.day); got != tt.want {
+				t.Errorf("Met = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryOn(t *testing.T) {
+	current := config.Goals{Keystrokes: 3}
+	if got := History(nil).On("2024-05-02", current); got != current {
+		t.Errorf("Without history On = %+v, want the current goals", got)
+	}
+
+	h := History{
+		{Date: "2024-05-01", Goals: config.Goals{Keystrokes: 1}},
+		{Date: "2024-05-10", Goals: config.Goals{Keystrokes: 2}},
+	}
+	tests := []struct {
+		date string
+		want int
+	}{
+		{"2024-04-01", 1}, // before the history, the first goals
+		{"2024-05-01", 1},
+		{"2024-05-09", 1},
+		{"2024-05-10", 2},
+		{"2024-06-01", 2},
+	}
+	for _, tt := range tests {
+		if got := h.On(tt.date, current); got.Keystrokes != tt.want {
+			t.Errorf("On(%s) = %+v, want %d keystrokes", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	// day returns a day of 2024-05 with n keystrokes
+	day := func(d, n int) Day {
+		return Day{Date: fmt.Sprintf("2024-05-%02d", d), Keystrokes: int64(n)}
+	}
+	always := func(string) config.Goals { return tenThousand }
+	changed := History{
+		{Date: "2024-05-01", Goals: config.Goals{Keystrokes: 5000}},
+		{Date: "2024-05-10", Goals: tenThousand},
+	}
+
+	tests := []struct {
+		name    string
+		days    []Day
+		goalsOn func(string) config.Goals
+		want    Streak
+	}{
+		{"no days", nil, always, Streak{}},
+		{
+			name:    "met through today",
+			days:    []Day{day(8, 10000), day(9, 12000), day(10, 10000)},
+			goalsOn: always,
+			want:    Streak{Current: 3, Longest: 3, TodayMet: true},
+		},
+		{
+			name:    "today in progress keeps yesterday's streak",
+			days:    []Day{day(8, 10000), day(9, 12000), day(10, 500)},
+			goalsOn: always,
+			want:    Streak{Current: 2, Longest: 2},
+		},
+		{
+			name:    "missed yesterday",
+			days:    []Day{day(1, 10000), day(2, 10000), day(3, 10000), day(9, 100), day(10, 500)},
+			goalsOn: always,
+			want:    Streak{Current: 0, Longest: 3},
+		},
+		{
+			name:    "a day without data breaks the streak",
+			days:    []Day{day(7, 10000), day(9, 10000), day(10, 10000)},
+			goalsOn: always,
+			want:    Streak{Current: 2, Longest: 2, TodayMet: true},
+		},
+		{
+			name:    "each day is judged by its own goals",
+			days:    []Day{day(8, 6000), day(9, 6000), day(10, 6000)},
+			goalsOn: func(date string) config.Goals { return changed.On(date, tenThousand) },
+			want:    Streak{Current: 2, Longest: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Streaks(tt.days, tt.goalsOn, "2024-05-10"); got != tt.want {
+				t.Errorf("Streaks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChanged(t *testing.T) {
+	before := []Target{{Metric: Keystrokes, Goal: 10, Value: 9}, {Metric: MouseDistance, Goal: 5, Value: 4, Max: true, Met: true}}
+	after := []Target{{Metric: Keystrokes, Goal: 10, Value: 10, Met: true}, {Metric: MouseDistance, Goal: 5, Value: 4.5, Max: true, Met: true}, {Metric: Words, Goal: 1, Value: 1, Met: true}}
+	got := Changed(before, after)
+	if len(got) != 1 || got[0].Metric != Keystrokes {
+		t.Errorf("Changed = %+v, want only the keystroke goal", got)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	feet := func(f float64) string { return fmt.Sprintf("%.0fft", f) }
+	tests := []struct {
+		target Target
+		want   string
+	}{
+		{Target{Metric: Keystrokes, Goal: 10000, Value: 10002, Met: true}, "Keystroke goal met: 10,002 today."},
+		{Target{Metric: Words, Goal: 3000, Value: 1200}, "Word goal: 1,200 of 3,000 so far."},
+		{Target{Metric: MouseDistance, Goal: 500, Value: 512.4, Max: true}, "Over your mouse distance goal: 512ft of 500ft."},
+		{Target{Metric: MouseDistance, Goal: 500, Value: 20, Max: true, Met: true}, "Back under your mouse distance goal of 500ft."},
+	}
+	cars := func(f float64) string { return fmt.Sprintf("%.0f cars", f/15) }
+	if got, want := Message(Target{Metric: MouseDistance, Goal: 300, Value: 450, Max: true}, cars), "Over your mouse distance goal: 30 cars of 20 cars."; got != want {
+		t.Errorf("Message in cars = %q, want %q", got, want)
+	}
+	for _, tt := range tests {
+		if got := Message(tt.target, feet); got != tt.want {
+			t.Errorf("Message(%+v) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
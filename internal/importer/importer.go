@@ -0,0 +1,220 @@
+// Package importer brings history from other tools and other typtel machines
+// into the local database.
+//
+// Every source is converted into an export.Dataset and applied with
+// export.ApplyFrom, so imported counts are always added to what is already
+// stored and tagged with where they came from.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/export"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// Sources lists the sources that can be imported
+var Sources = []string{
+	storage.SourceExport,
+	storage.SourceTyptelDB,
+	storage.SourceMonkeytype,
+	storage.SourceWhatPulse,
+}
+
+// Options controls an import
+type Options struct {
+	Source string // One of Sources, empty to detect from the file
+	Format string // Format of a 'typtel export' file, empty to detect
+}
+
+// Result describes a finished import
+type Result struct {
+	Source  string
+	Summary export.ImportSummary
+}
+
+// sqliteMagic starts every SQLite database file
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// Run loads path into store and records it in the import log
+func Run(store *storage.Store, path string, opts Options) (Result, error) {
+	source := opts.Source
+	if source == "" {
+		var err error
+		if source, err = Detect(path); err != nil {
+			return Result{}, err
+		}
+	}
+
+	ds, err := Load(path, source, opts.Format)
+	if err != nil {
+		return Result{Source: source}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	sum, err := export.ApplyFrom(store, ds, source)
+	if err != nil {
+		return Result{Source: source, Summary: sum}, fmt.Errorf("import failed after %d rows: %w", sum.Total(), err)
+	}
+
+	origin, err := filepath.Abs(path)
+	if err != nil {
+		origin = path
+	}
+	if err := store.LogImport(source, origin, sum.Total()); err != nil {
+		return Result{Source: source, Summary: sum}, fmt.Errorf("failed to record import: %w", err)
+	}
+
+	return Result{Source: source, Summary: sum}, nil
+}
+
+// Load reads path as the given source
+func Load(path, source, format string) (*export.Dataset, error) {
+	switch source {
+	case storage.SourceExport:
+		return loadExport(path, format)
+	case storage.SourceTyptelDB:
+		return loadTyptelDB(path)
+	case storage.SourceMonkeytype:
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			return ReadMonkeytypeJSON(f)
+		}
+		return ReadMonkeytypeCSV(f)
+	case storage.SourceWhatPulse:
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadWhatPulseCSV(f)
+	}
+	return nil, fmt.Errorf("unknown source %q (valid: %s)", source, strings.Join(Sources, ", "))
+}
+
+// Detect works out which source produced path from its contents
+func Detect(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return storage.SourceExport, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if magic, _ := r.Peek(len(sqliteMagic)); bytes.Equal(magic, sqliteMagic) {
+		return storage.SourceTyptelDB, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return storage.SourceExport, nil
+	case ".json":
+		return detectJSON(r)
+	case ".csv":
+		header, err := csv.NewReader(r).Read()
+		if err != nil {
+			return "", fmt.Errorf("reading CSV header: %w", err)
+		}
+		switch {
+		case export.TableForHeader(header) != "":
+			return storage.SourceExport, nil
+		case isMonkeytypeHeader(header):
+			return storage.SourceMonkeytype, nil
+		case isWhatPulseHeader(header):
+			return storage.SourceWhatPulse, nil
+		}
+		return "", fmt.Errorf("unrecognised CSV header in %s, pass --source", path)
+	}
+
+	return "", fmt.Errorf("cannot detect the source of %s, pass --source", path)
+}
+
+// detectJSON tells a typtel export document from a Monkeytype results export,
+// which is either a bare array or an API response with a "data" array
+func detectJSON(r io.Reader) (string, error) {
+	var doc interface{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return "", err
+	}
+	switch v := doc.(type) {
+	case []interface{}:
+		return storage.SourceMonkeytype, nil
+	case map[string]interface{}:
+		if _, ok := v["schema_version"]; ok {
+			return storage.SourceExport, nil
+		}
+		if _, ok := v["data"]; ok {
+			return storage.SourceMonkeytype, nil
+		}
+	}
+	return "", fmt.Errorf("unrecognised JSON document, pass --source")
+}
+
+func loadExport(path, format string) (*export.Dataset, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return export.ReadCSVDir(path)
+	}
+
+	if format == "" {
+		if format, err = export.DetectFormat(path); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return export.Read(f, format)
+}
+
+// headerIndex maps lower-cased, trimmed column names to their position
+func headerIndex(header []string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		index[name] = i
+	}
+	return index
+}
+
+// column returns the first of names present in index, or -1
+func column(index map[string]int, names ...string) int {
+	for _, name := range names {
+		if i, ok := index[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// field returns row[i], or "" when the column is missing
+func field(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
@@ -0,0 +1,237 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/export"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+const monkeytypeCSV = `_id,isPb,wpm,acc,rawWpm,consistency,charStats,mode,mode2,quoteLength,restartCount,testDuration,afkDuration,incompleteTestSeconds,punctuation,numbers,language,funbox,difficulty,lazyMode,blindMode,bailedOut,tags,timestamp
+abc,true,92.4,97.1,95,80,"250;3;1;0",words,25,-1,0,16.23,0,0,false,false,english,none,normal,false,false,false,,1704103200000
+def,false,78,94.5,80,75,"300;5;2;0",time,30,-1,1,30,0,0,true,false,english,none,normal,false,false,false,,1704189600000
+`
+
+const monkeytypeJSON = `[
+  {"_id":"abc","wpm":92.4,"acc":97.1,"mode":"words","mode2":"25","testDuration":16.23,"punctuation":false,"timestamp":1704103200000},
+  {"_id":"def","wpm":78,"acc":94.5,"mode":"time","mode2":30,"testDuration":30,"punctuation":true,"timestamp":1704189600000}
+]`
+
+const whatPulseCSV = `Date,Keys,Clicks,Download (MB),Upload (MB)
+2024-01-01,"12,000",300,10,1
+2024-01-02,8000,200,5,1
+2024-01-02,500,0,1,0
+`
+
+func newTestStore(t *testing.T) (*storage.Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "typtel.db")
+	store, err := storage.NewWithPath(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, path
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestReadMonkeytype(t *testing.T) {
+	csvDS, err := ReadMonkeytypeCSV(strings.NewReader(monkeytypeCSV))
+	if err != nil {
+		t.Fatalf("ReadMonkeytypeCSV failed: %v", err)
+	}
+	jsonDS, err := ReadMonkeytypeJSON(strings.NewReader(monkeytypeJSON))
+	if err != nil {
+		t.Fatalf("ReadMonkeytypeJSON failed: %v", err)
+	}
+
+	want := []export.TestRecord{
+		{Timestamp: time.UnixMilli(1704103200000).UTC(), WPM: 92.4, Accuracy: 97.1, DurationMS: 16230, WordCount: 25},
+		{Timestamp: time.UnixMilli(1704189600000).UTC(), WPM: 78, Accuracy: 94.5, DurationMS: 30000, Punctuation: true},
+	}
+	for name, ds := range map[string]*export.Dataset{"csv": csvDS, "json": jsonDS} {
+		if len(ds.Tests) != len(want) {
+			t.Fatalf("%s: got %d tests, want %d", name, len(ds.Tests), len(want))
+		}
+		for i := range want {
+			if ds.Tests[i] != want[i] {
+				t.Errorf("%s: test %d = %+v, want %+v", name, i, ds.Tests[i], want[i])
+			}
+		}
+	}
+
+	// API responses wrap the results in "data"
+	wrapped, err := ReadMonkeytypeJSON(strings.NewReader(`{"message":"ok","data":` + monkeytypeJSON + `}`))
+	if err != nil {
+		t.Fatalf("ReadMonkeytypeJSON (wrapped) failed: %v", err)
+	}
+	if len(wrapped.Tests) != 2 {
+		t.Errorf("Expected 2 wrapped tests, got %d", len(wrapped.Tests))
+	}
+}
+
+func TestReadWhatPulseCSV(t *testing.T) {
+	ds, err := ReadWhatPulseCSV(strings.NewReader(whatPulseCSV))
+	if err != nil {
+		t.Fatalf("ReadWhatPulseCSV failed: %v", err)
+	}
+
+	wantDaily := []export.DailyRecord{{Date: "2024-01-01", Keystrokes: 12000}, {Date: "2024-01-02", Keystrokes: 8500}}
+	if len(ds.Daily) != len(wantDaily) || ds.Daily[0] != wantDaily[0] || ds.Daily[1] != wantDaily[1] {
+		t.Errorf("Daily = %+v, want %+v", ds.Daily, wantDaily)
+	}
+	if len(ds.Mouse) != 2 || ds.Mouse[1].Clicks != 200 {
+		t.Errorf("Mouse = %+v, want clicks for both days", ds.Mouse)
+	}
+
+	if _, err := ReadWhatPulseCSV(strings.NewReader("Date,Keys\nyesterday,5\n")); err == nil {
+		t.Error("Expected error for unparseable date")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	_, dbPath := newTestStore(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"typtel db", dbPath, storage.SourceTyptelDB, false},
+		{"monkeytype csv", writeFile(t, "results.csv", monkeytypeCSV), storage.SourceMonkeytype, false},
+		{"monkeytype json", writeFile(t, "results.json", monkeytypeJSON), storage.SourceMonkeytype, false},
+		{"whatpulse csv", writeFile(t, "pulses.csv", whatPulseCSV), storage.SourceWhatPulse, false},
+		{"export json", writeFile(t, "out.json", `{"schema_version":1}`), storage.SourceExport, false},
+		{"export csv", writeFile(t, "daily.csv", "date,keystrokes,words\n"), storage.SourceExport, false},
+		{"export dir", t.TempDir(), storage.SourceExport, false},
+		{"unknown csv", writeFile(t, "other.csv", "a,b\n"), "", true},
+		{"unknown ext", writeFile(t, "notes.txt", "hello"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Detect error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Detect = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSumsCollidingDays(t *testing.T) {
+	store, _ := newTestStore(t)
+	if err := store.AddDailyTotals("2024-01-02", 1000, 200); err != nil {
+		t.Fatalf("AddDailyTotals failed: %v", err)
+	}
+
+	result, err := Run(store, writeFile(t, "pulses.csv", whatPulseCSV), Options{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Source != storage.SourceWhatPulse || result.Summary.Daily != 2 {
+		t.Errorf("Result = %+v, want 2 whatpulse days", result)
+	}
+
+	stats, err := store.GetDayStats("2024-01-02")
+	if err != nil {
+		t.Fatalf("GetDayStats failed: %v", err)
+	}
+	if stats.Keystrokes != 9500 || stats.Words != 200 {
+		t.Errorf("2024-01-02 = %d keystrokes, %d words; want 9500, 200", stats.Keystrokes, stats.Words)
+	}
+
+	sources, err := store.GetDailySources("2024-01-02")
+	if err != nil {
+		t.Fatalf("GetDailySources failed: %v", err)
+	}
+	if sources[storage.SourceWhatPulse].Keystrokes != 8500 || sources[storage.SourceTyptel].Keystrokes != 1000 {
+		t.Errorf("Sources = %+v, want whatpulse 8500 and typtel 1000", sources)
+	}
+
+	log, err := store.GetImportLog()
+	if err != nil {
+		t.Fatalf("GetImportLog failed: %v", err)
+	}
+	if len(log) != 1 || log[0].Source != storage.SourceWhatPulse || log[0].Rows != result.Summary.Total() {
+		t.Errorf("Import log = %+v", log)
+	}
+}
+
+func TestRunMonkeytypeSkipsPersonalBests(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	if _, err := Run(store, writeFile(t, "results.csv", monkeytypeCSV), Options{}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	history, err := store.GetTypingTestHistory("", "")
+	if err != nil {
+		t.Fatalf("GetTypingTestHistory failed: %v", err)
+	}
+	if len(history) != 2 || history[0].Source != storage.SourceMonkeytype {
+		t.Errorf("History = %+v, want 2 monkeytype tests", history)
+	}
+	if stats := store.GetTypingTestStats(); stats.TestCount != 0 {
+		t.Errorf("Expected Monkeytype results to leave personal bests alone, got %d tests", stats.TestCount)
+	}
+}
+
+func TestRunTyptelDB(t *testing.T) {
+	other, otherPath := newTestStore(t)
+	at := time.Date(2024, 1, 5, 14, 0, 0, 0, time.Local)
+	for i := 0; i < 3; i++ {
+		other.RecordKeystrokeAt(4, at)
+	}
+	other.IncrementWordCount("2024-01-05")
+	other.Close()
+
+	store, _ := newTestStore(t)
+	store.RecordKeystrokeAt(4, at)
+
+	result, err := Run(store, otherPath, Options{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Source != storage.SourceTyptelDB {
+		t.Errorf("Source = %q, want %q", result.Source, storage.SourceTyptelDB)
+	}
+
+	stats, err := store.GetDayStats("2024-01-05")
+	if err != nil {
+		t.Fatalf("GetDayStats failed: %v", err)
+	}
+	if stats.Keystrokes != 4 || stats.Words != 1 {
+		t.Errorf("2024-01-05 = %d keystrokes, %d words; want 4, 1", stats.Keystrokes, stats.Words)
+	}
+
+	hourly, err := store.GetHourlyStats("2024-01-05")
+	if err != nil {
+		t.Fatalf("GetHourlyStats failed: %v", err)
+	}
+	if hourly[14].Keystrokes != 4 {
+		t.Errorf("Hour 14 = %d keystrokes, want 4", hourly[14].Keystrokes)
+	}
+}
+
+func TestRunUnknownSource(t *testing.T) {
+	store, _ := newTestStore(t)
+	if _, err := Run(store, writeFile(t, "x.csv", "a\n"), Options{Source: "keybr"}); err == nil {
+		t.Error("Expected error for unknown source")
+	}
+}
@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/export"
+)
+
+// monkeytypeResult holds the fields typtel keeps from a Monkeytype result.
+// Monkeytype's JSON uses numbers where its CSV has strings, so mode2 (the
+// word count or time limit) is decoded loosely.
+type monkeytypeResult struct {
+	WPM          float64     `json:"wpm"`
+	Acc          float64     `json:"acc"`
+	Mode         string      `json:"mode"`
+	Mode2        interface{} `json:"mode2"`
+	TestDuration float64     `json:"testDuration"` // seconds
+	Punctuation  bool        `json:"punctuation"`
+	Timestamp    float64     `json:"timestamp"` // epoch milliseconds
+}
+
+func (m monkeytypeResult) record() export.TestRecord {
+	rec := export.TestRecord{
+		Timestamp:   time.UnixMilli(int64(m.Timestamp)).UTC(),
+		WPM:         m.WPM,
+		Accuracy:    m.Acc,
+		DurationMS:  int64(math.Round(m.TestDuration * 1000)),
+		Punctuation: m.Punctuation,
+	}
+	// Only word tests have a fixed length; time and quote tests keep 0
+	if m.Mode == "words" {
+		rec.WordCount, _ = strconv.Atoi(strings.TrimSpace(fmt.Sprint(m.Mode2)))
+	}
+	return rec
+}
+
+// isMonkeytypeHeader reports whether a CSV header is a Monkeytype results export
+func isMonkeytypeHeader(header []string) bool {
+	index := headerIndex(header)
+	return column(index, "wpm") >= 0 && column(index, "acc") >= 0 && column(index, "timestamp") >= 0
+}
+
+// ReadMonkeytypeJSON reads a Monkeytype results export, either a bare array
+// of results or an API response wrapping them in "data"
+func ReadMonkeytypeJSON(r io.Reader) (*export.Dataset, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []monkeytypeResult
+	if err := json.Unmarshal(raw, &results); err != nil {
+		var wrapped struct {
+			Data []monkeytypeResult `json:"data"`
+		}
+		if err2 := json.Unmarshal(raw, &wrapped); err2 != nil {
+			return nil, err
+		}
+		results = wrapped.Data
+	}
+
+	ds := &export.Dataset{SchemaVersion: export.SchemaVersion}
+	for _, m := range results {
+		ds.Tests = append(ds.Tests, m.record())
+	}
+	return ds, nil
+}
+
+// ReadMonkeytypeCSV reads the CSV results export from Monkeytype's account page
+func ReadMonkeytypeCSV(r io.Reader) (*export.Dataset, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if !isMonkeytypeHeader(header) {
+		return nil, fmt.Errorf("not a Monkeytype results CSV: need wpm, acc and timestamp columns")
+	}
+	index := headerIndex(header)
+	wpmCol, accCol, tsCol := column(index, "wpm"), column(index, "acc"), column(index, "timestamp")
+	modeCol, mode2Col := column(index, "mode"), column(index, "mode2")
+	durationCol, punctCol := column(index, "testduration"), column(index, "punctuation")
+
+	ds := &export.Dataset{SchemaVersion: export.SchemaVersion}
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var m monkeytypeResult
+		if m.WPM, err = strconv.ParseFloat(field(row, wpmCol), 64); err != nil {
+			return nil, fmt.Errorf("line %d: wpm: %w", line, err)
+		}
+		if m.Acc, err = strconv.ParseFloat(field(row, accCol), 64); err != nil {
+			return nil, fmt.Errorf("line %d: acc: %w", line, err)
+		}
+		if m.Timestamp, err = strconv.ParseFloat(field(row, tsCol), 64); err != nil {
+			return nil, fmt.Errorf("line %d: timestamp: %w", line, err)
+		}
+		m.Mode = field(row, modeCol)
+		m.Mode2 = field(row, mode2Col)
+		m.TestDuration, _ = strconv.ParseFloat(field(row, durationCol), 64)
+		m.Punctuation, _ = strconv.ParseBool(field(row, punctCol))
+
+		ds.Tests = append(ds.Tests, m.record())
+	}
+
+	return ds, nil
+}
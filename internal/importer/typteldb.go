@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/export"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// loadTyptelDB reads everything from another machine's typtel.db. The
+// database is copied first (with its WAL, if any) so that migrating an older
+// schema never modifies the original.
+func loadTyptelDB(path string) (*export.Dataset, error) {
+	tmpDir, err := os.MkdirTemp("", "typtel-import-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	copyPath := filepath.Join(tmpDir, "typtel.db")
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := copyFile(path+suffix, copyPath+suffix); err != nil {
+			if suffix != "" && os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+	}
+
+	store, err := storage.OpenReadOnly(copyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	return export.Collect(store, export.Options{})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
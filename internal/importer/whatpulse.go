@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/export"
+)
+
+// Column names WhatPulse has used for its per-day statistics exports
+var (
+	whatPulseDateColumns   = []string{"date", "day"}
+	whatPulseKeysColumns   = []string{"keys", "keys typed", "key count", "keystrokes"}
+	whatPulseClicksColumns = []string{"clicks", "mouse clicks"}
+)
+
+// whatPulseDateLayouts are tried in order when parsing the date column
+var whatPulseDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006/01/02",
+	"01/02/2006",
+}
+
+// isWhatPulseHeader reports whether a CSV header is a WhatPulse keys/day export
+func isWhatPulseHeader(header []string) bool {
+	index := headerIndex(header)
+	return column(index, whatPulseDateColumns...) >= 0 && column(index, whatPulseKeysColumns...) >= 0
+}
+
+// ReadWhatPulseCSV reads a WhatPulse statistics export with a row per day (or
+// per pulse; rows on the same day are summed). Keys become daily keystroke
+// totals and clicks, when present, become mouse clicks.
+func ReadWhatPulseCSV(r io.Reader) (*export.Dataset, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if !isWhatPulseHeader(header) {
+		return nil, fmt.Errorf("not a WhatPulse CSV: need date and keys columns")
+	}
+	index := headerIndex(header)
+	dateCol := column(index, whatPulseDateColumns...)
+	keysCol := column(index, whatPulseKeysColumns...)
+	clicksCol := column(index, whatPulseClicksColumns...)
+
+	keys := make(map[string]int64)
+	clicks := make(map[string]int64)
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		date, err := parseWhatPulseDate(field(row, dateCol))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		n, err := parseCount(field(row, keysCol))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: keys: %w", line, err)
+		}
+		keys[date] += n
+
+		if clicksCol >= 0 {
+			c, err := parseCount(field(row, clicksCol))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: clicks: %w", line, err)
+			}
+			clicks[date] += c
+		}
+	}
+
+	dates := make([]string, 0, len(keys))
+	for date := range keys {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	ds := &export.Dataset{SchemaVersion: export.SchemaVersion}
+	for _, date := range dates {
+		ds.Daily = append(ds.Daily, export.DailyRecord{Date: date, Keystrokes: keys[date]})
+		if clicks[date] > 0 {
+			ds.Mouse = append(ds.Mouse, export.MouseRecord{Date: date, Clicks: clicks[date]})
+		}
+	}
+	return ds, nil
+}
+
+func parseWhatPulseDate(s string) (string, error) {
+	for _, layout := range whatPulseDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("unrecognised date %q", s)
+}
+
+// parseCount parses a count that may use thousands separators ("12,345")
+func parseCount(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	s = strings.NewReplacer(",", "", " ", "", "_", "").Replace(s)
+	return strconv.ParseInt(s, 10, 64)
+}
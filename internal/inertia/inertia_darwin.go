@@ -111,6 +111,8 @@ import (
 	"sync"
 	"time"
 	"unsafe"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/paths"
 )
 
 // Debug logging
@@ -121,10 +123,8 @@ var (
 
 func init() {
 	if debugMode {
-		// Log to file in ~/.local/share/typtel/logs/
-		homeDir, _ := os.UserHomeDir()
-		logDir := filepath.Join(homeDir, ".local", "share", "typtel", "logs")
-		os.MkdirAll(logDir, 0755)
+		// Log to file in the typtel log directory (see internal/paths)
+		logDir, _ := paths.LogDir()
 		logFile, err := os.OpenFile(
 			filepath.Join(logDir, "inertia-debug.log"),
 			os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
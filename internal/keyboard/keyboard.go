@@ -0,0 +1,253 @@
+// Package keyboard names macOS virtual keycodes and lays them out as
+// physical keyboards for key frequency heatmaps.
+package keyboard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Physical layouts
+const (
+	ANSI = "ansi" // US-style: wide Return, long left Shift
+	ISO  = "iso"  // European-style: tall Return, short left Shift
+)
+
+// Layouts lists the layout names
+var Layouts = []string{ANSI, ISO}
+
+// DefaultLayout is used when none is configured
+const DefaultLayout = ANSI
+
+// macOS virtual keycodes (kVK_* in Carbon's Events.h) as recorded by the
+// keylogger
+const (
+	KeyA            = 0x00
+	KeyS            = 0x01
+	KeyD            = 0x02
+	KeyF            = 0x03
+	KeyH            = 0x04
+	KeyG            = 0x05
+	KeyZ            = 0x06
+	KeyX            = 0x07
+	KeyC            = 0x08
+	KeyV            = 0x09
+	KeyISOSection   = 0x0A
+	KeyB            = 0x0B
+	KeyQ            = 0x0C
+	KeyW            = 0x0D
+	KeyE            = 0x0E
+	KeyR            = 0x0F
+	KeyY            = 0x10
+	KeyT            = 0x11
+	Key1            = 0x12
+	Key2            = 0x13
+	Key3            = 0x14
+	Key4            = 0x15
+	Key6            = 0x16
+	Key5            = 0x17
+	KeyEqual        = 0x18
+	Key9            = 0x19
+	Key7            = 0x1A
+	KeyMinus        = 0x1B
+	Key8            = 0x1C
+	Key0            = 0x1D
+	KeyRightBracket = 0x1E
+	KeyO            = 0x1F
+	KeyU            = 0x20
+	KeyLeftBracket  = 0x21
+	KeyI            = 0x22
+	KeyP            = 0x23
+	KeyReturn       = 0x24
+	KeyL            = 0x25
+	KeyJ            = 0x26
+	KeyQuote        = 0x27
+	KeyK            = 0x28
+	KeySemicolon    = 0x29
+	KeyBackslash    = 0x2A
+	KeyComma        = 0x2B
+	KeySlash        = 0x2C
+	KeyN            = 0x2D
+	KeyM            = 0x2E
+	KeyPeriod       = 0x2F
+	KeyTab          = 0x30
+	KeySpace        = 0x31
+	KeyGrave        = 0x32
+	KeyDelete       = 0x33 // backspace
+	KeyEscape       = 0x35
+	KeyRightCommand = 0x36
+	KeyCommand      = 0x37
+	KeyShift        = 0x38
+	KeyCapsLock     = 0x39
+	KeyOption       = 0x3A
+	KeyControl      = 0x3B
+	KeyRightShift   = 0x3C
+	KeyRightOption  = 0x3D
+	KeyRightControl = 0x3E
+	KeyFunction     = 0x3F
+	KeyKeypadEnter  = 0x4C
+	KeyF5           = 0x60
+	KeyF6           = 0x61
+	KeyF7           = 0x62
+	KeyF3           = 0x63
+	KeyF8           = 0x64
+	KeyF9           = 0x65
+	KeyF11          = 0x67
+	KeyF10          = 0x6D
+	KeyF12          = 0x6F
+	KeyHome         = 0x73
+	KeyPageUp       = 0x74
+	KeyForwardDel   = 0x75
+	KeyF4           = 0x76
+	KeyEnd          = 0x77
+	KeyF2           = 0x78
+	KeyPageDown     = 0x79
+	KeyF1           = 0x7A
+	KeyLeft         = 0x7B
+	KeyRight        = 0x7C
+	KeyDown         = 0x7D
+	KeyUp           = 0x7E
+)
+
+// names are short labels for keycodes, as printed on a US keyboard
+var names = map[int]string{
+	KeyA: "A", KeyB: "B", KeyC: "C", KeyD: "D", KeyE: "E", KeyF: "F", KeyG: "G",
+	KeyH: "H", KeyI: "I", KeyJ: "J", KeyK: "K", KeyL: "L", KeyM: "M", KeyN: "N",
+	KeyO: "O", KeyP: "P", KeyQ: "Q", KeyR: "R", KeyS: "S", KeyT: "T", KeyU: "U",
+	KeyV: "V", KeyW: "W", KeyX: "X", KeyY: "Y", KeyZ: "Z",
+	Key0: "0", Key1: "1", Key2: "2", Key3: "3", Key4: "4",
+	Key5: "5", Key6: "6", Key7: "7", Key8: "8", Key9: "9",
+	KeyGrave: "`", KeyMinus: "-", KeyEqual: "=", KeyLeftBracket: "[", KeyRightBracket: "]",
+	KeyBackslash: "\\", KeySemicolon: ";", KeyQuote: "'", KeyComma: ",", KeyPeriod: ".",
+	KeySlash: "/", KeyISOSection: "§",
+	KeyReturn: "Return", KeyKeypadEnter: "Enter", KeyTab: "Tab", KeySpace: "Space", KeyDelete: "Delete",
+	KeyEscape: "Esc", KeyCapsLock: "Caps",
+	KeyShift: "Shift", KeyRightShift: "Shift", KeyControl: "Ctrl", KeyRightControl: "Ctrl",
+	KeyOption: "Opt", KeyRightOption: "Opt", KeyCommand: "Cmd", KeyRightCommand: "Cmd",
+	KeyFunction: "Fn", KeyF1: "F1", KeyF2: "F2", KeyF3: "F3", KeyF4: "F4", KeyF5: "F5", KeyF6: "F6",
+	KeyF7: "F7", KeyF8: "F8", KeyF9: "F9", KeyF10: "F10", KeyF11: "F11", KeyF12: "F12",
+	KeyHome: "Home", KeyEnd: "End", KeyPageUp: "PgUp", KeyPageDown: "PgDn",
+	KeyForwardDel: "Del", KeyLeft: "←", KeyRight: "→", KeyUp: "↑", KeyDown: "↓",
+}
+
+// Name returns the label of a keycode, e.g. "A" or "Return"
+func Name(code int) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("key %d", code)
+}
+
+// Key is one key cap, positioned in key units (1 = the width of a letter key)
+// from the top left of the keyboard. A key may have several caps, such as
+// the two halves of an ISO Return.
+type Key struct {
+	Code  int
+	Label string // empty on the second half of a split key
+	X, Y  float64
+	W     float64
+}
+
+// Layout is a physical keyboard: Mac-style rows with a function row, no
+// numeric keypad
+type Layout struct {
+	Name  string
+	Keys  []Key
+	Width float64 // in key units
+	Rows  int
+}
+
+// keyCap is a key in a row definition: code and width
+type keyCap struct {
+	code  int
+	width float64
+}
+
+// u is a one unit wide key
+func u(code int) keyCap { return keyCap{code, 1} }
+
+// wide is a key of the given width
+func wide(code int, width float64) keyCap { return keyCap{code, width} }
+
+var functionRow = []keyCap{
+	wide(KeyEscape, 1.5),
+	wide(KeyF1, 1.125), wide(KeyF2, 1.125), wide(KeyF3, 1.125), wide(KeyF4, 1.125),
+	wide(KeyF5, 1.125), wide(KeyF6, 1.125), wide(KeyF7, 1.125), wide(KeyF8, 1.125),
+	wide(KeyF9, 1.125), wide(KeyF10, 1.125), wide(KeyF11, 1.125), wide(KeyF12, 1.125),
+}
+
+var bottomRow = []keyCap{
+	u(KeyFunction), u(KeyControl), u(KeyOption), wide(KeyCommand, 1.25),
+	wide(KeySpace, 5), wide(KeyRightCommand, 1.25), u(KeyRightOption),
+	wide(KeyLeft, 0.875), wide(KeyUp, 0.875), wide(KeyDown, 0.875), wide(KeyRight, 0.875),
+}
+
+var letters = [3][]keyCap{
+	{u(KeyQ), u(KeyW), u(KeyE), u(KeyR), u(KeyT), u(KeyY), u(KeyU), u(KeyI), u(KeyO), u(KeyP), u(KeyLeftBracket), u(KeyRightBracket)},
+	{u(KeyA), u(KeyS), u(KeyD), u(KeyF), u(KeyG), u(KeyH), u(KeyJ), u(KeyK), u(KeyL), u(KeySemicolon), u(KeyQuote)},
+	{u(KeyZ), u(KeyX), u(KeyC), u(KeyV), u(KeyB), u(KeyN), u(KeyM), u(KeyComma), u(KeyPeriod), u(KeySlash)},
+}
+
+var digits = []keyCap{
+	u(Key1), u(Key2), u(Key3), u(Key4), u(Key5), u(Key6), u(Key7), u(Key8), u(Key9), u(Key0), u(KeyMinus), u(KeyEqual),
+}
+
+func row(parts ...[]keyCap) []keyCap {
+	var r []keyCap
+	for _, p := range parts {
+		r = append(r, p...)
+	}
+	return r
+}
+
+var ansiRows = [][]keyCap{
+	functionRow,
+	row([]keyCap{u(KeyGrave)}, digits, []keyCap{wide(KeyDelete, 2)}),
+	row([]keyCap{wide(KeyTab, 1.5)}, letters[0], []keyCap{wide(KeyBackslash, 1.5)}),
+	row([]keyCap{wide(KeyCapsLock, 1.75)}, letters[1], []keyCap{wide(KeyReturn, 2.25)}),
+	row([]keyCap{wide(KeyShift, 2.25)}, letters[2], []keyCap{wide(KeyRightShift, 2.75)}),
+	bottomRow,
+}
+
+// ISO moves ` next to the left Shift, adds § in its place and makes Return
+// two rows tall, with \ on the home row
+var isoRows = [][]keyCap{
+	functionRow,
+	row([]keyCap{u(KeyISOSection)}, digits, []keyCap{wide(KeyDelete, 2)}),
+	row([]keyCap{wide(KeyTab, 1.5)}, letters[0], []keyCap{wide(KeyReturn, 1.5)}),
+	row([]keyCap{wide(KeyCapsLock, 1.75)}, letters[1], []keyCap{u(KeyBackslash), wide(KeyReturn, 1.25)}),
+	row([]keyCap{wide(KeyShift, 1.25), u(KeyGrave)}, letters[2], []keyCap{wide(KeyRightShift, 2.75)}),
+	bottomRow,
+}
+
+// Get returns a layout by name
+func Get(name string) (*Layout, error) {
+	switch strings.ToLower(name) {
+	case ANSI, "":
+		return build(ANSI, ansiRows), nil
+	case ISO:
+		return build(ISO, isoRows), nil
+	}
+	return nil, fmt.Errorf("unknown keyboard layout %q, want one of %s", name, strings.Join(Layouts, ", "))
+}
+
+func build(name string, rows [][]keyCap) *Layout {
+	l := &Layout{Name: name, Rows: len(rows)}
+	seen := make(map[int]bool)
+	for y, r := range rows {
+		x := 0.0
+		for _, c := range r {
+			key := Key{Code: c.code, X: x, Y: float64(y), W: c.width}
+			if !seen[c.code] {
+				key.Label = Name(c.code)
+				seen[c.code] = true
+			}
+			l.Keys = append(l.Keys, key)
+			x += c.width
+		}
+		if x > l.Width {
+			l.Width = x
+		}
+	}
+	return l
+}
@@ -0,0 +1,88 @@
+package keyboard
+
+import "testing"
+
+func TestLayouts(t *testing.T) {
+	for _, name := range Layouts {
+		t.Run(name, func(t *testing.T) {
+			l, err := Get(name)
+			if err != nil {
+				t.Fatalf("Get(%q) failed: %v", name, err)
+			}
+			if l.Rows != 6 || l.Width != 15 {
+				t.Errorf("%s is %vx%d, want 15x6", name, l.Width, l.Rows)
+			}
+
+			// Every row spans the full width and every key is labelled once
+			rowWidth := make(map[float64]float64)
+			labels := make(map[int]int)
+			for _, k := range l.Keys {
+				rowWidth[k.Y] += k.W
+				if k.Label != "" {
+					labels[k.Code]++
+				}
+			}
+			for y, width := range rowWidth {
+				if width != l.Width {
+					t.Errorf("row %v is %v wide, want %v", y, width, l.Width)
+				}
+			}
+			for code, n := range labels {
+				if n != 1 {
+					t.Errorf("%s is labelled %d times", Name(code), n)
+				}
+			}
+			for _, code := range []int{KeyA, KeySpace, KeyReturn, KeyGrave, KeyBackslash, KeyUp, KeyEscape} {
+				if labels[code] != 1 {
+					t.Errorf("%s is missing", Name(code))
+				}
+			}
+		})
+	}
+}
+
+func TestISODiffersFromANSI(t *testing.T) {
+	caps := func(name string) map[int]int {
+		l, _ := Get(name)
+		n := make(map[int]int)
+		for _, k := range l.Keys {
+			n[k.Code]++
+		}
+		return n
+	}
+	ansi, iso := caps(ANSI), caps(ISO)
+
+	if ansi[KeyISOSection] != 0 || iso[KeyISOSection] != 1 {
+		t.Error("only ISO should have the § key")
+	}
+	if ansi[KeyReturn] != 1 || iso[KeyReturn] != 2 {
+		t.Errorf("Return has %d caps on ANSI and %d on ISO, want 1 and 2", ansi[KeyReturn], iso[KeyReturn])
+	}
+}
+
+func TestGetUnknown(t *testing.T) {
+	if _, err := Get("dvorak"); err == nil {
+		t.Error("expected an error for an unknown layout")
+	}
+	if l, err := Get(""); err != nil || l.Name != DefaultLayout {
+		t.Errorf("Get(\"\") = %v, %v, want the default layout", l, err)
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		code int
+		want string
+	}{
+		{KeyA, "A"},
+		{KeyReturn, "Return"},
+		{KeyDelete, "Delete"},
+		{KeyRightCommand, "Cmd"},
+		{200, "key 200"},
+	}
+	for _, tt := range tests {
+		if got := Name(tt.code); got != tt.want {
+			t.Errorf("Name(%d) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
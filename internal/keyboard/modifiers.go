@@ -0,0 +1,80 @@
+package keyboard
+
+import "strings"
+
+// Modifiers is the set of modifier keys held during a keystroke. The values
+// are stored in the database, so existing bits must not change.
+type Modifiers uint8
+
+// Modifier bits
+const (
+	ModShift Modifiers = 1 << iota
+	ModControl
+	ModOption
+	ModCommand
+)
+
+// ShortcutModifiers are the modifiers that turn a key into a shortcut. Shift
+// on its own just types capitals and symbols.
+const ShortcutModifiers = ModControl | ModOption | ModCommand
+
+// modifierOrder is the order modifiers are written in chords, as in macOS
+// menus (⌃⌥⇧⌘)
+var modifierOrder = []struct {
+	mod  Modifiers
+	name string
+}{
+	{ModControl, "Ctrl"},
+	{ModOption, "Opt"},
+	{ModShift, "Shift"},
+	{ModCommand, "Cmd"},
+}
+
+// String returns the modifiers joined with "+", e.g. "Ctrl+Shift"
+func (m Modifiers) String() string {
+	var parts []string
+	for _, o := range modifierOrder {
+		if m&o.mod != 0 {
+			parts = append(parts, o.name)
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// modifierKeys maps modifier keycodes to the modifier they hold
+var modifierKeys = map[int]Modifiers{
+	KeyShift:        ModShift,
+	KeyRightShift:   ModShift,
+	KeyControl:      ModControl,
+	KeyRightControl: ModControl,
+	KeyOption:       ModOption,
+	KeyRightOption:  ModOption,
+	KeyCommand:      ModCommand,
+	KeyRightCommand: ModCommand,
+}
+
+// IsModifier reports whether code is a modifier key, including Caps Lock and
+// Fn, which never form chords
+func IsModifier(code int) bool {
+	_, ok := modifierKeys[code]
+	return ok || code == KeyCapsLock || code == KeyFunction
+}
+
+// ModifierOf returns the modifier a key holds, or 0 if it isn't one
+func ModifierOf(code int) Modifiers {
+	return modifierKeys[code]
+}
+
+// IsShortcut reports whether pressing code with mods held is a shortcut: a
+// non-modifier key with Ctrl, Opt or Cmd held
+func IsShortcut(code int, mods Modifiers) bool {
+	return mods&ShortcutModifiers != 0 && !IsModifier(code)
+}
+
+// Chord names a key pressed with modifiers, e.g. "Cmd+S" or "Ctrl+Shift+Tab"
+func Chord(code int, mods Modifiers) string {
+	if mods == 0 {
+		return Name(code)
+	}
+	return mods.String() + "+" + Name(code)
+}
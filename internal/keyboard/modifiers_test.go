@@ -0,0 +1,65 @@
+package keyboard
+
+import "testing"
+
+func TestChord(t *testing.T) {
+	tests := []struct {
+		code int
+		mods Modifiers
+		want string
+	}{
+		{KeyS, ModCommand, "Cmd+S"},
+		{KeyR, ModControl, "Ctrl+R"},
+		{KeyTab, ModControl | ModShift, "Ctrl+Shift+Tab"},
+		{KeyZ, ModCommand | ModShift, "Shift+Cmd+Z"},
+		{KeyLeft, ModOption, "Opt+←"},
+		{KeyF, ModCommand | ModOption | ModControl | ModShift, "Ctrl+Opt+Shift+Cmd+F"},
+		{KeyA, 0, "A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Chord(tt.code, tt.mods); got != tt.want {
+				t.Errorf("Chord = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsShortcut(t *testing.T) {
+	tests := []struct {
+		name string
+		code int
+		mods Modifiers
+		want bool
+	}{
+		{"Cmd+S", KeyS, ModCommand, true},
+		{"Opt+E", KeyE, ModOption, true},
+		{"Ctrl+Shift+Tab", KeyTab, ModControl | ModShift, true},
+		{"plain key", KeyS, 0, false},
+		{"capital letter", KeyS, ModShift, false},
+		{"Cmd pressed with Shift held", KeyCommand, ModShift, false},
+		{"Shift pressed with Cmd held", KeyShift, ModCommand, false},
+		{"Caps Lock with Cmd held", KeyCapsLock, ModCommand, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsShortcut(tt.code, tt.mods); got != tt.want {
+				t.Errorf("IsShortcut = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModifierOf(t *testing.T) {
+	if ModifierOf(KeyRightCommand) != ModCommand || ModifierOf(KeyShift) != ModShift {
+		t.Error("Command and Shift keys should hold their modifiers")
+	}
+	if ModifierOf(KeyA) != 0 || ModifierOf(KeyFunction) != 0 {
+		t.Error("A and Fn don't hold chord modifiers")
+	}
+	if !IsModifier(KeyFunction) || !IsModifier(KeyCapsLock) || IsModifier(KeySpace) {
+		t.Error("IsModifier should cover Fn and Caps Lock but not Space")
+	}
+}
@@ -0,0 +1,54 @@
+package keyboard
+
+// symbols are the punctuation keys
+var symbols = map[int]bool{
+	KeyGrave:        true,
+	KeyMinus:        true,
+	KeyEqual:        true,
+	KeyLeftBracket:  true,
+	KeyRightBracket: true,
+	KeyBackslash:    true,
+	KeySemicolon:    true,
+	KeyQuote:        true,
+	KeyComma:        true,
+	KeyPeriod:       true,
+	KeySlash:        true,
+	KeyISOSection:   true,
+}
+
+// whitespace are the keys that type spaces and line breaks
+var whitespace = map[int]bool{
+	KeySpace:       true,
+	KeyReturn:      true,
+	KeyKeypadEnter: true,
+	KeyTab:         true,
+}
+
+// IsLetter reports whether code is a letter or digit key
+func IsLetter(code int) bool {
+	name := names[code]
+	if len(name) != 1 {
+		return false
+	}
+	c := name[0]
+	return c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// IsSymbol reports whether code is a punctuation key
+func IsSymbol(code int) bool {
+	return symbols[code]
+}
+
+// IsWhitespace reports whether code is Space, Return, Enter or Tab
+func IsWhitespace(code int) bool {
+	return whitespace[code]
+}
+
+// IsText reports whether pressing code with mods held types a character:
+// a letter, digit, punctuation or whitespace key without Cmd or Ctrl
+func IsText(code int, mods Modifiers) bool {
+	if mods&(ModCommand|ModControl) != 0 {
+		return false
+	}
+	return IsLetter(code) || IsSymbol(code) || IsWhitespace(code)
+}
@@ -0,0 +1,36 @@
+package keyboard
+
+import "testing"
+
+func TestIsText(t *testing.T) {
+	tests := []struct {
+		name string
+		code int
+		mods Modifiers
+		want bool
+	}{
+		{"letter", KeyA, 0, true},
+		{"capital", KeyA, ModShift, true},
+		{"digit", Key7, 0, true},
+		{"punctuation", KeyComma, 0, true},
+		{"option symbol", KeyE, ModOption, true},
+		{"space", KeySpace, 0, true},
+		{"return", KeyReturn, 0, true},
+		{"keypad enter", KeyKeypadEnter, 0, true},
+		{"shortcut", KeyS, ModCommand, false},
+		{"control key", KeyW, ModControl, false},
+		{"backspace", KeyDelete, 0, false},
+		{"arrow", KeyLeft, 0, false},
+		{"escape", KeyEscape, 0, false},
+		{"function key", KeyF5, 0, false},
+		{"modifier", KeyShift, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsText(tt.code, tt.mods); got != tt.want {
+				t.Errorf("IsText = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
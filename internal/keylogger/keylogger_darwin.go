@@ -10,20 +10,25 @@ package keylogger
 #include <CoreGraphics/CoreGraphics.h>
 #include <ApplicationServices/ApplicationServices.h>
 
-extern void goKeystrokeCallback(int keycode, int isRepeat);
+extern void goKeystrokeCallback(int keycode, int isRepeat, uint64_t flags);
+extern void goFlagsCallback(int keycode, uint64_t flags);
 
 static CGEventRef eventCallback(CGEventTapProxy proxy, CGEventType type, CGEventRef event, void *refcon) {
+    CGKeyCode keycode = (CGKeyCode)CGEventGetIntegerValueField(event, kCGKeyboardEventKeycode);
+    uint64_t flags = (uint64_t)CGEventGetFlags(event);
     if (type == kCGEventKeyDown) {
-        CGKeyCode keycode = (CGKeyCode)CGEventGetIntegerValueField(event, kCGKeyboardEventKeycode);
         // Check if this is a key repeat event (holding key down)
         int isRepeat = (int)CGEventGetIntegerValueField(event, kCGKeyboardEventAutorepeat);
-        goKeystrokeCallback((int)keycode, isRepeat);
+        goKeystrokeCallback((int)keycode, isRepeat, flags);
+    } else if (type == kCGEventFlagsChanged) {
+        // Modifier keys don't send key down events, only flag changes
+        goFlagsCallback((int)keycode, flags);
     }
     return event;
 }
 
 static CFMachPortRef createEventTap() {
-    CGEventMask eventMask = CGEventMaskBit(kCGEventKeyDown);
+    CGEventMask eventMask = CGEventMaskBit(kCGEventKeyDown) | CGEventMaskBit(kCGEventFlagsChanged);
     CFMachPortRef eventTap = CGEventTapCreate(
         kCGSessionEventTap,
         kCGHeadInsertEventTap,
@@ -54,28 +59,111 @@ import "C"
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
 )
 
+// Keystroke is a key press and the modifiers held with it
+type Keystroke struct {
+	Keycode   int
+	Modifiers keyboard.Modifiers
+	Time      time.Time // when the event tap saw the key, before any queueing
+}
+
 var (
-	keystrokeChan chan int
+	keystrokeChan chan Keystroke
 	mu            sync.Mutex
 	running       bool
+	dropped       atomic.Uint64
 )
 
+// Device-independent modifier flags (kCGEventFlagMask* in CGEventTypes.h)
+const (
+	flagShift       = 0x00020000
+	flagControl     = 0x00040000
+	flagOption      = 0x00080000
+	flagCommand     = 0x00100000
+	flagSecondaryFn = 0x00800000
+)
+
+// deviceFlags are the per-key modifier flags (NX_DEVICE*KEYMASK in
+// IOLLEvent.h), which tell a left modifier's release from a right one's
+// press while the other is held
+var deviceFlags = map[int]uint64{
+	keyboard.KeyControl:      0x00000001,
+	keyboard.KeyShift:        0x00000002,
+	keyboard.KeyRightShift:   0x00000004,
+	keyboard.KeyCommand:      0x00000008,
+	keyboard.KeyRightCommand: 0x00000010,
+	keyboard.KeyOption:       0x00000020,
+	keyboard.KeyRightOption:  0x00000040,
+	keyboard.KeyRightControl: 0x00002000,
+}
+
+// modifiersFromFlags converts event flags to the modifiers held. Fn is left
+// out: macOS sets it on arrow and function keys whether or not Fn is held.
+func modifiersFromFlags(flags uint64) keyboard.Modifiers {
+	var mods keyboard.Modifiers
+	if flags&flagShift != 0 {
+		mods |= keyboard.ModShift
+	}
+	if flags&flagControl != 0 {
+		mods |= keyboard.ModControl
+	}
+	if flags&flagOption != 0 {
+		mods |= keyboard.ModOption
+	}
+	if flags&flagCommand != 0 {
+		mods |= keyboard.ModCommand
+	}
+	return mods
+}
+
 //export goKeystrokeCallback
-func goKeystrokeCallback(keycode C.int, isRepeat C.int) {
+func goKeystrokeCallback(keycode C.int, isRepeat C.int, flags C.uint64_t) {
 	// Ignore key repeat events - holding a key counts as 1 keypress
 	if isRepeat != 0 {
 		return
 	}
+	send(Keystroke{Keycode: int(keycode), Modifiers: modifiersFromFlags(uint64(flags))})
+}
+
+//export goFlagsCallback
+func goFlagsCallback(keycode C.int, flags C.uint64_t) {
+	code, f := int(keycode), uint64(flags)
 
+	// Flags change on both press and release; only count presses
+	switch {
+	case code == keyboard.KeyCapsLock:
+		// Every press toggles Caps Lock
+	case code == keyboard.KeyFunction:
+		if f&flagSecondaryFn == 0 {
+			return
+		}
+	case deviceFlags[code] != 0:
+		if f&deviceFlags[code] == 0 {
+			return
+		}
+	default:
+		return
+	}
+
+	// Record the other modifiers held, not the one being pressed
+	send(Keystroke{Keycode: code, Modifiers: modifiersFromFlags(f) &^ keyboard.ModifierOf(code)})
+}
+
+func send(k Keystroke) {
+	k.Time = time.Now()
 	mu.Lock()
 	defer mu.Unlock()
 	if keystrokeChan != nil {
 		select {
-		case keystrokeChan <- int(keycode):
+		case keystrokeChan <- k:
 		default:
 			// Channel full, drop keystroke
+			dropped.Add(1)
 		}
 	}
 }
@@ -85,8 +173,9 @@ func CheckAccessibilityPermissions() bool {
 	return C.checkAccessibilityPermissions() != 0
 }
 
-// Start begins capturing keystrokes and returns a channel that receives keycodes
-func Start() (<-chan int, error) {
+// Start begins capturing keystrokes, including presses of modifier keys, and
+// returns a channel that receives them
+func Start() (<-chan Keystroke, error) {
 	mu.Lock()
 	defer mu.Unlock()
 
@@ -98,7 +187,7 @@ func Start() (<-chan int, error) {
 		return nil, errors.New("accessibility permissions not granted - please enable in System Preferences > Privacy & Security > Accessibility")
 	}
 
-	keystrokeChan = make(chan int, 1000)
+	keystrokeChan = make(chan Keystroke, 1000)
 
 	go func() {
 		eventTap := C.createEventTap()
@@ -114,6 +203,12 @@ func Start() (<-chan int, error) {
 	return keystrokeChan, nil
 }
 
+// Dropped returns how many keystrokes were dropped because the channel was
+// full
+func Dropped() uint64 {
+	return dropped.Load()
+}
+
 // Stop stops the keylogger
 func Stop() {
 	mu.Lock()
@@ -0,0 +1,108 @@
+// Package leaderboard defines the personal leaderboards: rankings of days,
+// hours, sessions or typing tests by one metric.
+//
+// A board ranks the entries of one period, such as the hours with the most
+// words typed or the days with the least mouse travel, best first. Only the
+// boards in All are listed; storage ranks the entries, and ties go to the
+// earlier one.
+package leaderboard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metric names what a board ranks by
+type Metric string
+
+const (
+	Keystrokes     Metric = "keystrokes"
+	Words          Metric = "words"
+	MouseDistance  Metric = "mouse_distance" // pixels
+	Clicks         Metric = "clicks"
+	TestWPM        Metric = "test_wpm"
+	SessionMinutes Metric = "session_minutes"
+)
+
+// Period names what one ranked entry covers
+type Period string
+
+const (
+	Day     Period = "day"
+	Hour    Period = "hour"
+	Session Period = "session" // a session of activity, see internal/sessions
+	Test    Period = "test"    // a typing test
+)
+
+// Direction says whether the highest or the lowest value ranks first
+type Direction string
+
+const (
+	Most  Direction = "most"
+	Least Direction = "least"
+)
+
+// Board is a leaderboard and the rule it ranks by
+type Board struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Icon        string    `json:"icon"`
+	Description string    `json:"description"`
+	Metric      Metric    `json:"metric"`
+	Period      Period    `json:"period"`
+	Direction   Direction `json:"direction"`
+}
+
+// Stillness is the board of days with the least mouse movement
+var Stillness = Board{
+	ID: "stillness", Name: "Stillness", Icon: "🧘", Description: "Days you didn't move the mouse (much)",
+	Metric: MouseDistance, Period: Day, Direction: Least,
+}
+
+// All lists every board in display order
+var All = []Board{
+	Stillness,
+	{ID: "keystrokes", Name: "Most Keystrokes", Icon: "⌨️", Description: "Days with the most keystrokes", Metric: Keystrokes, Period: Day, Direction: Most},
+	{ID: "words-hour", Name: "Most Words in an Hour", Icon: "📝", Description: "Hours with the most words typed", Metric: Words, Period: Hour, Direction: Most},
+	{ID: "test-wpm", Name: "Fastest Typing Tests", Icon: "⚡", Description: "Typing tests with the highest WPM", Metric: TestWPM, Period: Test, Direction: Most},
+	{ID: "focus", Name: "Longest Focus Sessions", Icon: "🎯", Description: "Sessions of activity without a break", Metric: SessionMinutes, Period: Session, Direction: Most},
+	{ID: "fewest-clicks", Name: "Fewest Clicks", Icon: "🐭", Description: "Days with the fewest mouse clicks", Metric: Clicks, Period: Day, Direction: Least},
+}
+
+// IDs lists the ID of every board
+func IDs() []string {
+	ids := make([]string, len(All))
+	for i, b := range All {
+		ids[i] = b.ID
+	}
+	return ids
+}
+
+// Get returns the board with the given ID
+func Get(id string) (Board, error) {
+	for _, b := range All {
+		if b.ID == strings.ToLower(id) {
+			return b, nil
+		}
+	}
+	return Board{}, fmt.Errorf("unknown leaderboard %q, want one of %s", id, strings.Join(IDs(), ", "))
+}
+
+// Column is the heading of the values a board lists
+func (b Board) Column() string {
+	switch b.Metric {
+	case Keystrokes:
+		return "Keystrokes"
+	case Words:
+		return "Words"
+	case MouseDistance:
+		return "Distance"
+	case Clicks:
+		return "Clicks"
+	case TestWPM:
+		return "WPM"
+	case SessionMinutes:
+		return "Duration"
+	}
+	return string(b.Metric)
+}
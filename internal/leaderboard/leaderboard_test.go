@@ -0,0 +1,43 @@
+package leaderboard
+
+import "testing"
+
+func TestAllHaveUniqueIDs(t *testing.T) {
+	seen := map[string]bool{}
+	for _, b := range All {
+		if b.ID == "" || b.Name == "" || b.Metric == "" || b.Period == "" {
+			t.Errorf("Incomplete board %+v", b)
+		}
+		if b.Direction != Most && b.Direction != Least {
+			t.Errorf("Board %s has direction %q", b.ID, b.Direction)
+		}
+		if seen[b.ID] {
+			t.Errorf("Duplicate board ID %q", b.ID)
+		}
+		seen[b.ID] = true
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    Metric
+		wantErr bool
+	}{
+		{"stillness", MouseDistance, false},
+		{"Test-WPM", TestWPM, false},
+		{"fastest", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			b, err := Get(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+			}
+			if b.Metric != tt.want {
+				t.Errorf("Get(%q) metric = %q, want %q", tt.id, b.Metric, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+// Default holds the capture daemon's metrics
+var Default = NewRegistry()
+
+// Activity recorded by the daemon
+var (
+	Keystrokes    = Default.Counter("typtel_keystrokes_total", "Keystrokes recorded.")
+	Words         = Default.Counter("typtel_words_total", "Words recorded.")
+	MouseClicks   = Default.Counter("typtel_mouse_clicks_total", "Mouse clicks recorded.")
+	MouseDistance = Default.Counter("typtel_mouse_distance_pixels_total", "Mouse distance recorded, in pixels.")
+)
+
+// Database write latency by kind of write
+var (
+	KeystrokeWrite = dbWrite("keystroke")
+	WordWrite      = dbWrite("word")
+	MouseMoveWrite = dbWrite("mouse_movement")
+	ClickWrite     = dbWrite("mouse_click")
+)
+
+// Sources of dropped events, for RegisterDropped
+const (
+	SourceKeylogger     = "keylogger"
+	SourceMouseMovement = "mouse_movement"
+	SourceMouseClick    = "mouse_click"
+)
+
+func dbWrite(op string) *Histogram {
+	return Default.Histogram("typtel_db_write_duration_seconds", "Time taken to write an event to the database.", DurationBuckets, "op", op)
+}
+
+// RegisterDropped exposes a capture channel's count of events dropped
+// because the channel was full
+func RegisterDropped(source string, dropped func() uint64) {
+	Default.CounterFunc("typtel_dropped_events_total", "Events dropped because the capture channel was full.",
+		func() float64 { return float64(dropped()) }, "source", source)
+}
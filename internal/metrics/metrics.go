@@ -0,0 +1,229 @@
+// Package metrics exposes the capture daemon's counters for Prometheus in the
+// text exposition format, so they can be scraped and graphed in Grafana.
+//
+// Counters count from when the daemon started; Prometheus handles the reset
+// when it restarts. Nothing is served unless a listen address is configured
+// with 'typtel metrics on'.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultAddr is where the daemon serves /metrics unless told otherwise. It
+// is loopback-only, like 'typtel serve'.
+const DefaultAddr = "127.0.0.1:9777"
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Counter is a monotonically increasing float64, safe for concurrent use
+type Counter struct {
+	bits atomic.Uint64
+}
+
+// Inc adds one
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds v, which must not be negative
+func (c *Counter) Add(v float64) {
+	for {
+		old := c.bits.Load()
+		if c.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// Value returns the current count
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(c.bits.Load())
+}
+
+// Histogram counts observations into cumulative buckets, safe for concurrent
+// use
+type Histogram struct {
+	bounds []float64 // upper bounds, ascending, without +Inf
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    Counter
+}
+
+// Observe records one value
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	if i < len(h.counts) {
+		h.counts[i].Add(1)
+	}
+	h.count.Add(1)
+	h.sum.Add(v)
+}
+
+// ObserveSince records the seconds elapsed since start
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// DurationBuckets suit SQLite writes: 100µs to 2.5s
+var DurationBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// family is every series sharing a metric name
+type family struct {
+	name   string
+	help   string
+	typ    string
+	series []*series
+}
+
+// series is one labelled time series, backed by exactly one of its fields
+type series struct {
+	labels  string // rendered, e.g. {op="keystroke"}
+	counter *Counter
+	fn      func() float64
+	hist    *Histogram
+}
+
+// Registry holds metrics in registration order
+type Registry struct {
+	mu       sync.Mutex
+	families []*family
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter registers and returns a counter. labels are name/value pairs.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{}
+	r.add(name, help, "counter", &series{labels: formatLabels(labels), counter: c})
+	return c
+}
+
+// CounterFunc registers a counter whose value is read from fn at scrape time,
+// for counts kept elsewhere. labels are name/value pairs.
+func (r *Registry) CounterFunc(name, help string, fn func() float64, labels ...string) {
+	r.add(name, help, "counter", &series{labels: formatLabels(labels), fn: fn})
+}
+
+// Histogram registers and returns a histogram with the given ascending
+// bucket upper bounds. labels are name/value pairs.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{bounds: buckets, counts: make([]atomic.Uint64, len(buckets))}
+	r.add(name, help, "histogram", &series{labels: formatLabels(labels), hist: h})
+	return h
+}
+
+func (r *Registry) add(name, help, typ string, s *series) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range r.families {
+		if f.name == name {
+			if f.typ != typ {
+				panic(fmt.Sprintf("metrics: %s registered as both %s and %s", name, f.typ, typ))
+			}
+			f.series = append(f.series, s)
+			return
+		}
+	}
+	r.families = append(r.families, &family{name: name, help: help, typ: typ, series: []*series{s}})
+}
+
+// Write renders every metric in the text exposition format
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	for _, f := range r.families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+		for _, s := range f.series {
+			switch {
+			case s.counter != nil:
+				fmt.Fprintf(&b, "%s%s %s\n", f.name, s.labels, formatValue(s.counter.Value()))
+			case s.fn != nil:
+				fmt.Fprintf(&b, "%s%s %s\n", f.name, s.labels, formatValue(s.fn()))
+			case s.hist != nil:
+				writeHistogram(&b, f.name, s.labels, s.hist)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHistogram(b *strings.Builder, name, labels string, h *Histogram) {
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		fmt.Fprintf(b, "%s_bucket%s %d\n", name, withLabel(labels, "le", formatValue(bound)), cumulative)
+	}
+	count := h.count.Load()
+	fmt.Fprintf(b, "%s_bucket%s %d\n", name, withLabel(labels, "le", "+Inf"), count)
+	fmt.Fprintf(b, "%s_sum%s %s\n", name, labels, formatValue(h.sum.Value()))
+	fmt.Fprintf(b, "%s_count%s %d\n", name, labels, count)
+}
+
+// Handler serves the registry to scrapers
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if err := r.Write(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// ListenAndServe serves the registry at /metrics on addr until it fails
+func (r *Registry) ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", r.Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+// formatLabels renders name/value pairs as {a="1",b="2"}
+func formatLabels(pairs []string) string {
+	if len(pairs)%2 != 0 {
+		panic("metrics: labels must be name/value pairs")
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%s", pairs[i], strconv.Quote(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// withLabel appends one more label to rendered labels
+func withLabel(labels, name, value string) string {
+	pair := fmt.Sprintf("%s=%q", name, value)
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	var c Counter
+	c.Inc()
+	c.Add(2.5)
+	if got := c.Value(); got != 3.5 {
+		t.Errorf("Value() = %v, want 3.5", got)
+	}
+}
+
+func TestCounterConcurrent(t *testing.T) {
+	var c Counter
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+	if got := c.Value(); got != 8000 {
+		t.Errorf("Value() = %v, want 8000", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	r := NewRegistry()
+	keys := r.Counter("test_keystrokes_total", "Keystrokes.")
+	keys.Add(42)
+	dropped := uint64(3)
+	r.CounterFunc("test_dropped_total", "Dropped.", func() float64 { return float64(dropped) }, "source", "keylogger")
+	r.CounterFunc("test_dropped_total", "Dropped.", func() float64 { return 0 }, "source", "mouse")
+	h := r.Histogram("test_write_seconds", "Writes.", []float64{0.001, 0.01}, "op", "keystroke")
+	h.Observe(0.0005)
+	h.Observe(0.001)
+	h.Observe(0.005)
+	h.Observe(2)
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	want := `# HELP test_keystrokes_total Keystrokes.
+# TYPE test_keystrokes_total counter
+test_keystrokes_total 42
+# HELP test_dropped_total Dropped.
+# TYPE test_dropped_total counter
+test_dropped_total{source="keylogger"} 3
+test_dropped_total{source="mouse"} 0
+# HELP test_write_seconds Writes.
+# TYPE test_write_seconds histogram
+test_write_seconds_bucket{op="keystroke",le="0.001"} 2
+test_write_seconds_bucket{op="keystroke",le="0.01"} 3
+test_write_seconds_bucket{op="keystroke",le="+Inf"} 4
+test_write_seconds_sum{op="keystroke"} 2.0065
+test_write_seconds_count{op="keystroke"} 4
+`
+	if got := b.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	r.Histogram("test_seconds", "Test.", []float64{1})
+
+	var b strings.Builder
+	r.Write(&b)
+	for _, want := range []string{`test_seconds_bucket{le="1"} 0`, `test_seconds_bucket{le="+Inf"} 0`, "test_seconds_sum 0", "test_seconds_count 0"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected %q in\n%s", want, b.String())
+		}
+	}
+}
+
+func TestRegisterTypeMismatchPanics(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("test_total", "Test.")
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a histogram under a counter's name to panic")
+		}
+	}()
+	r.Histogram("test_total", "Test.", DurationBuckets)
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("test_total", "Test.").Inc()
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if ct := resp.Header.Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ContentType)
+	}
+	if !strings.Contains(string(body), "test_total 1\n") {
+		t.Errorf("Unexpected body:\n%s", body)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	RegisterDropped(SourceKeylogger, func() uint64 { return 7 })
+	Keystrokes.Inc()
+	KeystrokeWrite.Observe(0.0002)
+
+	var b strings.Builder
+	if err := Default.Write(&b); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# TYPE typtel_keystrokes_total counter",
+		"typtel_keystrokes_total 1",
+		"typtel_words_total 0",
+		"typtel_mouse_clicks_total 0",
+		"typtel_mouse_distance_pixels_total 0",
+		`typtel_dropped_events_total{source="keylogger"} 7`,
+		"# TYPE typtel_db_write_duration_seconds histogram",
+		`typtel_db_write_duration_seconds_bucket{op="keystroke",le="0.00025"} 1`,
+		`typtel_db_write_duration_seconds_count{op="mouse_click"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in\n%s", want, out)
+		}
+	}
+}
@@ -155,6 +155,7 @@ import (
 	"errors"
 	"math"
 	"sync"
+	"sync/atomic"
 )
 
 // MousePosition represents a mouse position with coordinates
@@ -180,6 +181,9 @@ var (
 	running      bool
 	lastX, lastY float64
 	initialized  bool
+
+	droppedMovements atomic.Uint64
+	droppedClicks    atomic.Uint64
 )
 
 //export goMouseCallback
@@ -212,6 +216,7 @@ func goMouseCallback(x, y C.double) {
 			case mouseChan <- MouseMovement{X: newX, Y: newY, Distance: distance}:
 			default:
 				// Channel full, drop event
+				droppedMovements.Add(1)
 			}
 		}
 	}
@@ -227,6 +232,7 @@ func goMouseClickCallback() {
 		case clickChan <- MouseClick{}:
 		default:
 			// Channel full, drop event
+			droppedClicks.Add(1)
 		}
 	}
 }
@@ -279,6 +285,18 @@ func Start() (<-chan MouseMovement, <-chan MouseClick, error) {
 	return mouseChan, clickChan, nil
 }
 
+// DroppedMovements returns how many movements were dropped because the
+// channel was full
+func DroppedMovements() uint64 {
+	return droppedMovements.Load()
+}
+
+// DroppedClicks returns how many clicks were dropped because the channel was
+// full
+func DroppedClicks() uint64 {
+	return droppedClicks.Load()
+}
+
 // Stop stops the mouse tracker
 func Stop() {
 	mu.Lock()
@@ -0,0 +1,138 @@
+// Package notify delivers notifications, such as break reminders and goals
+// met, with the notifier set in breaks.notifier: a desktop notification,
+// the terminal bell or a webhook.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+)
+
+// Notification is a message to send
+type Notification struct {
+	Kind    string `json:"kind"` // what it's about, e.g. "break" or "goal"
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// NewNotifier returns the notifier configured in c. The bell writes to w.
+func NewNotifier(c config.Breaks, w io.Writer) (Notifier, error) {
+	switch c.Notifier {
+	case config.NotifierBell:
+		return Bell{W: w}, nil
+	case config.NotifierWebhook:
+		if c.WebhookURL == "" {
+			return nil, fmt.Errorf("breaks.webhook_url must be set to use the webhook notifier")
+		}
+		return &Webhook{URL: c.WebhookURL}, nil
+	default:
+		return Desktop{}, nil
+	}
+}
+
+// Desktop shows a desktop notification with osascript on macOS or
+// notify-send on Linux
+type Desktop struct {
+	run func(name string, args ...string) error // runs a command; nil uses os/exec
+}
+
+// Notify implements Notifier
+func (d Desktop) Notify(n Notification) error {
+	run := d.run
+	if run == nil {
+		run = func(name string, args ...string) error {
+			return exec.Command(name, args...).Run()
+		}
+	}
+
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(n.Message), appleScriptString(n.Title))
+		err = run("osascript", "-e", script)
+	case "linux":
+		err = run("notify-send", "--app-name=typtel", n.Title, n.Message)
+	default:
+		return fmt.Errorf("desktop notifications aren't supported on %s", runtime.GOOS)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to show notification: %w", err)
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	var b bytes.Buffer
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// Bell rings the terminal bell and prints the notification
+type Bell struct {
+	W io.Writer
+}
+
+// Notify implements Notifier
+func (b Bell) Notify(n Notification) error {
+	_, err := fmt.Fprintf(b.W, "\a%s: %s\n", n.Title, n.Message)
+	return err
+}
+
+// webhookTimeout bounds how long a webhook may take to answer
+const webhookTimeout = 10 * time.Second
+
+// Webhook POSTs notifications as JSON to a URL, e.g. a chat integration
+type Webhook struct {
+	URL    string
+	Client *http.Client // nil uses a client with a 10 second timeout
+}
+
+// webhookPayload is the body POSTed by Webhook
+type webhookPayload struct {
+	Notification
+	Text string    `json:"text"` // title and message together, for Slack-style hooks
+	Time time.Time `json:"time"`
+}
+
+// Notify implements Notifier
+func (w *Webhook) Notify(n Notification) error {
+	body, err := json.Marshal(webhookPayload{Notification: n, Text: n.Title + ": " + n.Message, Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
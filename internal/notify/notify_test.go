@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+)
+
+var testNotification = Notification{Kind: "break", Title: "Time for a break", Message: "Step away."}
+
+func TestBell(t *testing.T) {
+	var out bytes.Buffer
+	if err := (Bell{W: &out}).Notify(testNotification); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if got := out.String(); got != "\aTime for a break: Step away.\n" {
+		t.Errorf("Bell wrote %q", got)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var got webhookPayload
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Got %s with Content-Type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Decode failed: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	hook := &Webhook{URL: srv.URL}
+	if err := hook.Notify(testNotification); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if got.Notification != testNotification || got.Text != "Time for a break: Step away." || got.Time.IsZero() {
+		t.Errorf("Webhook received %+v", got)
+	}
+
+	status = http.StatusInternalServerError
+	if err := hook.Notify(testNotification); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Notify with a failing webhook = %v, want the status", err)
+	}
+}
+
+func TestDesktop(t *testing.T) {
+	var cmd []string
+	d := Desktop{run: func(name string, args ...string) error {
+		cmd = append([]string{name}, args...)
+		return nil
+	}}
+	err := d.Notify(Notification{Title: `Say "hi"`, Message: `back\slash`})
+
+	switch runtime.GOOS {
+	case "darwin":
+		want := `display notification "back\\slash" with title "Say \"hi\""`
+		if err != nil || len(cmd) != 3 || cmd[0] != "osascript" || cmd[2] != want {
+			t.Errorf("Ran %q, %v; want osascript -e %s", cmd, err, want)
+		}
+	case "linux":
+		if err != nil || len(cmd) != 4 || cmd[0] != "notify-send" || cmd[2] != `Say "hi"` || cmd[3] != `back\slash` {
+			t.Errorf("Ran %q, %v; want notify-send with the title and message", cmd, err)
+		}
+	default:
+		if err == nil {
+			t.Errorf("Expected an error on %s", runtime.GOOS)
+		}
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	c := config.Default().Breaks
+	if n, err := NewNotifier(c, nil); err != nil {
+		t.Errorf("NewNotifier with defaults failed: %v", err)
+	} else if _, ok := n.(Desktop); !ok {
+		t.Errorf("Default notifier = %T, want Desktop", n)
+	}
+
+	c.Notifier = config.NotifierBell
+	if n, _ := NewNotifier(c, nil); n == nil {
+		t.Error("Expected a bell notifier")
+	} else if _, ok := n.(Bell); !ok {
+		t.Errorf("Bell notifier = %T", n)
+	}
+
+	c.Notifier = config.NotifierWebhook
+	if _, err := NewNotifier(c, nil); err == nil {
+		t.Error("The webhook notifier needs a URL")
+	}
+	c.WebhookURL = "https://hooks.example.com/x"
+	if n, err := NewNotifier(c, nil); err != nil || n.(*Webhook).URL != c.WebhookURL {
+		t.Errorf("NewNotifier(webhook) = %v, %v", n, err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package paths resolves where typtel keeps its database, logs and generated files.
+//
+// Resolution order for each directory:
+//
+//	data:  $TYPTEL_DATA_DIR, then $XDG_DATA_HOME/typtel, then ~/.local/share/typtel
+//	logs:  $TYPTEL_DATA_DIR/logs, then $XDG_STATE_HOME/typtel/logs, then <data>/logs
+//	cache: $TYPTEL_DATA_DIR/cache, then $XDG_CACHE_HOME/typtel, then <logs>
+//
+// The config file is $TYPTEL_CONFIG, then $TYPTEL_DATA_DIR/config.toml, then
+// $XDG_CONFIG_HOME/typtel/config.toml, then ~/.config/typtel/config.toml.
+//
+// When no environment variables are set the layout matches earlier releases,
+// so existing installs keep using ~/.local/share/typtel and its logs/ folder.
+package paths
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// Environment variables consulted by the resolver
+const (
+	EnvDataDir   = "TYPTEL_DATA_DIR"
+	EnvConfig    = "TYPTEL_CONFIG"
+	EnvXDGData   = "XDG_DATA_HOME"
+	EnvXDGState  = "XDG_STATE_HOME"
+	EnvXDGCache  = "XDG_CACHE_HOME"
+	EnvXDGConfig = "XDG_CONFIG_HOME"
+)
+
+const (
+	appDirName   = "typtel"
+	dbFileName   = "typtel.db"
+	logsDirName  = "logs"
+	cacheDirName = "cache"
+	configName   = "config.toml"
+)
+
+func homeDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		// Fallback: get home dir from user.Current() (launchctl may not set HOME)
+		if u, userErr := user.Current(); userErr == nil {
+			return u.HomeDir, nil
+		}
+		return "", err
+	}
+	return home, nil
+}
+
+// dataDir returns the data directory without creating it
+func dataDir() (string, error) {
+	if dir := os.Getenv(EnvDataDir); dir != "" {
+		return dir, nil
+	}
+	if xdg := os.Getenv(EnvXDGData); xdg != "" {
+		return filepath.Join(xdg, appDirName), nil
+	}
+	home, err := homeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", appDirName), nil
+}
+
+// logDir returns the log directory without creating it
+func logDir() (string, error) {
+	if dir := os.Getenv(EnvDataDir); dir != "" {
+		return filepath.Join(dir, logsDirName), nil
+	}
+	if xdg := os.Getenv(EnvXDGState); xdg != "" {
+		return filepath.Join(xdg, appDirName, logsDirName), nil
+	}
+	data, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(data, logsDirName), nil
+}
+
+// cacheDir returns the cache directory without creating it
+func cacheDir() (string, error) {
+	if dir := os.Getenv(EnvDataDir); dir != "" {
+		return filepath.Join(dir, cacheDirName), nil
+	}
+	if xdg := os.Getenv(EnvXDGCache); xdg != "" {
+		return filepath.Join(xdg, appDirName), nil
+	}
+	return logDir()
+}
+
+func ensure(dir string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// DataDir returns the directory holding the database, creating it if needed
+func DataDir() (string, error) {
+	return ensure(dataDir())
+}
+
+// LogDir returns the directory for application logs, creating it if needed
+func LogDir() (string, error) {
+	return ensure(logDir())
+}
+
+// CacheDir returns the directory for generated files such as chart HTML,
+// creating it if needed
+func CacheDir() (string, error) {
+	return ensure(cacheDir())
+}
+
+// DBPath returns the default database path inside DataDir
+func DBPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, dbFileName), nil
+}
+
+// ConfigPath returns the path of the config file, which need not exist
+func ConfigPath() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+	if dir := os.Getenv(EnvDataDir); dir != "" {
+		return filepath.Join(dir, configName), nil
+	}
+	if xdg := os.Getenv(EnvXDGConfig); xdg != "" {
+		return filepath.Join(xdg, appDirName, configName), nil
+	}
+	home, err := homeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", appDirName, configName), nil
+}
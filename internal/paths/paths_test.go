@@ -0,0 +1,157 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// clearEnv unsets every variable the resolver reads for the duration of the test
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{EnvDataDir, EnvConfig, EnvXDGData, EnvXDGState, EnvXDGCache, EnvXDGConfig} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestDefaultLayout(t *testing.T) {
+	clearEnv(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	want := filepath.Join(home, ".local", "share", "typtel")
+
+	data, err := DataDir()
+	if err != nil {
+		t.Fatalf("DataDir failed: %v", err)
+	}
+	if data != want {
+		t.Errorf("DataDir() = %q, want %q", data, want)
+	}
+
+	logs, err := LogDir()
+	if err != nil {
+		t.Fatalf("LogDir failed: %v", err)
+	}
+	if logs != filepath.Join(want, "logs") {
+		t.Errorf("LogDir() = %q, want %q", logs, filepath.Join(want, "logs"))
+	}
+
+	// Without XDG_CACHE_HOME generated files stay next to the logs, as before
+	cache, err := CacheDir()
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	if cache != logs {
+		t.Errorf("CacheDir() = %q, want %q", cache, logs)
+	}
+
+	db, err := DBPath()
+	if err != nil {
+		t.Fatalf("DBPath failed: %v", err)
+	}
+	if db != filepath.Join(want, "typtel.db") {
+		t.Errorf("DBPath() = %q, want %q", db, filepath.Join(want, "typtel.db"))
+	}
+}
+
+func TestXDGDirectories(t *testing.T) {
+	clearEnv(t)
+	root := t.TempDir()
+	t.Setenv(EnvXDGData, filepath.Join(root, "data"))
+	t.Setenv(EnvXDGState, filepath.Join(root, "state"))
+	t.Setenv(EnvXDGCache, filepath.Join(root, "cache"))
+
+	tests := []struct {
+		name string
+		fn   func() (string, error)
+		want string
+	}{
+		{"data", DataDir, filepath.Join(root, "data", "typtel")},
+		{"logs", LogDir, filepath.Join(root, "state", "typtel", "logs")},
+		{"cache", CacheDir, filepath.Join(root, "cache", "typtel")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if info, err := os.Stat(got); err != nil || !info.IsDir() {
+				t.Errorf("expected %q to be created", got)
+			}
+		})
+	}
+}
+
+func TestDataDirOverride(t *testing.T) {
+	clearEnv(t)
+	root := t.TempDir()
+	override := filepath.Join(root, "profile")
+	t.Setenv(EnvDataDir, override)
+	// TYPTEL_DATA_DIR wins over XDG settings
+	t.Setenv(EnvXDGData, filepath.Join(root, "xdg-data"))
+	t.Setenv(EnvXDGState, filepath.Join(root, "xdg-state"))
+	t.Setenv(EnvXDGCache, filepath.Join(root, "xdg-cache"))
+
+	data, _ := DataDir()
+	if data != override {
+		t.Errorf("DataDir() = %q, want %q", data, override)
+	}
+
+	logs, _ := LogDir()
+	if logs != filepath.Join(override, "logs") {
+		t.Errorf("LogDir() = %q, want %q", logs, filepath.Join(override, "logs"))
+	}
+
+	cache, _ := CacheDir()
+	if cache != filepath.Join(override, "cache") {
+		t.Errorf("CacheDir() = %q, want %q", cache, filepath.Join(override, "cache"))
+	}
+
+	db, _ := DBPath()
+	if db != filepath.Join(override, "typtel.db") {
+		t.Errorf("DBPath() = %q, want %q", db, filepath.Join(override, "typtel.db"))
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"default", nil, filepath.Join(home, ".config", "typtel", "config.toml")},
+		{"xdg", map[string]string{EnvXDGConfig: filepath.Join(root, "xdg")}, filepath.Join(root, "xdg", "typtel", "config.toml")},
+		{"data dir", map[string]string{EnvDataDir: filepath.Join(root, "profile"), EnvXDGConfig: filepath.Join(root, "xdg")}, filepath.Join(root, "profile", "config.toml")},
+		{"explicit", map[string]string{EnvConfig: filepath.Join(root, "my.toml"), EnvDataDir: filepath.Join(root, "profile")}, filepath.Join(root, "my.toml")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("HOME", home)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got, err := ConfigPath()
+			if err != nil {
+				t.Fatalf("ConfigPath failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ConfigPath() = %q, want %q", got, tt.want)
+			}
+			if _, err := os.Stat(filepath.Dir(got)); err == nil && tt.name == "default" {
+				t.Errorf("ConfigPath should not create %q", filepath.Dir(got))
+			}
+		})
+	}
+}
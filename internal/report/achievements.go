@@ -0,0 +1,41 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+)
+
+// Badge is an achievement as shown on the charts page
+type Badge struct {
+	Icon        string
+	Name        string
+	Description string
+	Unlocked    bool
+	State       string // when it was unlocked, or how far along it is
+	Percent     float64
+}
+
+// NewBadges formats achievement statuses for the charts page
+func NewBadges(statuses []achievements.Status) []Badge {
+	badges := make([]Badge, len(statuses))
+	for i, s := range statuses {
+		b := Badge{
+			Icon:        s.Icon,
+			Name:        s.Name,
+			Description: s.Description,
+			Unlocked:    s.Unlocked,
+			Percent:     roundPercent(s.Progress),
+		}
+		switch {
+		case s.UnlockedAt != nil:
+			b.State = "Unlocked " + s.UnlockedAt.Local().Format("Jan 2, 2006")
+		case s.Unlocked:
+			b.State = "Unlocked"
+		default:
+			b.State = fmt.Sprintf("%.0f%% there", b.Percent)
+		}
+		badges[i] = b
+	}
+	return badges
+}
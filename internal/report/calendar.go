@@ -0,0 +1,175 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// CalendarDays is how far back the calendar heatmap reaches
+const CalendarDays = 365
+
+const dateLayout = "2006-01-02"
+
+// minMonthGap is the fewest week columns a month label needs
+const minMonthGap = 3
+
+// Calendar is a GitHub-style contribution calendar of daily keystrokes: one
+// column per week, Sunday at the top.
+type Calendar struct {
+	From  string
+	To    string
+	Days  []CalendarDay // every day in the range, oldest first
+	Weeks []CalendarWeek
+}
+
+// CalendarWeek is one column of the calendar
+type CalendarWeek struct {
+	Month string          // month name if a month starts in this week
+	Days  [7]*CalendarDay // Sunday first; nil outside the calendar's range
+}
+
+// CalendarDay is one cell of the calendar
+type CalendarDay struct {
+	Date       string
+	Keystrokes int64
+	Words      int64
+	Level      int
+	Color      string
+	Title      string
+}
+
+// RangeSummary totals a stretch of calendar days
+type RangeSummary struct {
+	From       string
+	To         string
+	Days       int
+	ActiveDays int
+	Keystrokes int64
+	Words      int64
+}
+
+// DailyAverage is keystrokes per day over the whole range
+func (r RangeSummary) DailyAverage() int64 {
+	if r.Days == 0 {
+		return 0
+	}
+	return r.Keystrokes / int64(r.Days)
+}
+
+// BuildCalendar reads a calendar of the given number of days ending on to
+func BuildCalendar(store *storage.Store, to string, days int) (*Calendar, error) {
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", to)
+	}
+	from := end.AddDate(0, 0, -(days - 1)).Format(dateLayout)
+
+	daily, err := store.GetDailyRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return NewCalendar(from, to, daily), nil
+}
+
+// NewCalendar lays out daily stats between from and to; days missing from
+// daily count as no activity
+func NewCalendar(from, to string, daily []storage.DailyStats) *Calendar {
+	byDate := make(map[string]storage.DailyStats, len(daily))
+	var counts []int64
+	for _, d := range daily {
+		byDate[d.Date] = d
+		counts = append(counts, d.Keystrokes)
+	}
+	scale := NewScale(counts)
+
+	c := &Calendar{From: from, To: to}
+	for _, date := range datesBetween(from, to) {
+		d := byDate[date]
+		t, _ := time.Parse(dateLayout, date)
+		c.Days = append(c.Days, CalendarDay{
+			Date:       date,
+			Keystrokes: d.Keystrokes,
+			Words:      d.Words,
+			Level:      scale.Level(d.Keystrokes),
+			Color:      scale.Color(d.Keystrokes),
+			Title:      fmt.Sprintf("%s: %s keystrokes, %s words", t.Format("Mon Jan 2, 2006"), FormatAbsolute(d.Keystrokes), FormatAbsolute(d.Words)),
+		})
+	}
+
+	for i := range c.Days {
+		t, _ := time.Parse(dateLayout, c.Days[i].Date)
+		if i == 0 || t.Weekday() == time.Sunday {
+			c.Weeks = append(c.Weeks, CalendarWeek{})
+		}
+		week := &c.Weeks[len(c.Weeks)-1]
+		week.Days[t.Weekday()] = &c.Days[i]
+		if i == 0 || t.Day() == 1 {
+			week.Month = t.Format("Jan")
+		}
+	}
+
+	// Drop the partial first month's label if the next one would crowd it
+	for w := 1; w < len(c.Weeks) && w < minMonthGap; w++ {
+		if c.Weeks[w].Month != "" {
+			c.Weeks[0].Month = ""
+		}
+	}
+	return c
+}
+
+// Index returns the position of date in Days, or -1
+func (c *Calendar) Index(date string) int {
+	for i, d := range c.Days {
+		if d.Date == date {
+			return i
+		}
+	}
+	return -1
+}
+
+// Summary totals the days from index i to j inclusive, in either order
+func (c *Calendar) Summary(i, j int) RangeSummary {
+	if i > j {
+		i, j = j, i
+	}
+	if i < 0 {
+		i = 0
+	}
+	if j >= len(c.Days) {
+		j = len(c.Days) - 1
+	}
+	if i > j {
+		return RangeSummary{}
+	}
+
+	r := RangeSummary{From: c.Days[i].Date, To: c.Days[j].Date}
+	for _, d := range c.Days[i : j+1] {
+		r.Days++
+		r.Keystrokes += d.Keystrokes
+		r.Words += d.Words
+		if d.Keystrokes > 0 {
+			r.ActiveDays++
+		}
+	}
+	return r
+}
+
+// datesBetween lists the dates from from to to inclusive
+func datesBetween(from, to string) []string {
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return nil
+	}
+
+	var dates []string
+	for t := start; !t.After(end); t = t.AddDate(0, 0, 1) {
+		dates = append(dates, t.Format(dateLayout))
+	}
+	return dates
+}
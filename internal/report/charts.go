@@ -0,0 +1,361 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/corrections"
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// MaxHeatmapDays is the longest period shown in the hourly heatmap; longer
+// periods rely on the calendar instead
+const MaxHeatmapDays = 62
+
+// Period keys with special handling on the charts page
+const (
+	PeriodYearly = "yearly" // source data for the page's own date picker
+	PeriodCustom = "custom" // the range passed in ChartOptions
+)
+
+// chartPeriods are the windows always offered by the charts page
+var chartPeriods = []struct {
+	key   string
+	title string
+	days  int
+}{
+	{"weekly", "Weekly (7 days)", 7},
+	{"monthly", "Monthly (30 days)", 30},
+	{PeriodYearly, "Yearly (365 days)", CalendarDays},
+}
+
+// ChartOptions adds a custom range to the charts page. Empty fields leave
+// the page with its preset periods.
+type ChartOptions struct {
+	From   string // first day, YYYY-MM-DD
+	To     string // last day, YYYY-MM-DD (default: today)
+	Layout string // keyboard layout of the key heatmap (default: keyboard.layout setting)
+}
+
+// Charts is the model behind the charts page
+type Charts struct {
+	Periods  []Period
+	Selected string // key of the period shown first
+	Calendar *Calendar
+	Badges   []Badge // achievements, unlocked or not
+}
+
+// Period holds one selectable window of the charts page. The exported
+// fields with JSON tags are handed to the page's script.
+type Period struct {
+	Key              string       `json:"key"`
+	Title            string       `json:"-"`
+	Days             int          `json:"days"`
+	Dates            []string     `json:"dates"`
+	Labels           []string     `json:"labels"`
+	Keystrokes       []int64      `json:"keystrokes"`
+	Words            []int64      `json:"words"`
+	MouseFeet        []float64    `json:"mouse_feet"`
+	WPMMedian        []float64    `json:"wpm_median"` // 0 on days without measured typing
+	WPMP90           []float64    `json:"wpm_p90"`
+	Chars            []int64      `json:"chars"`       // characters typed, for the correction rate
+	Corrections      []int64      `json:"corrections"` // Backspace and Delete presses
+	CorrectionBursts []int64      `json:"correction_bursts"`
+	TotalKeystrokes  int64        `json:"total_keystrokes"`
+	TotalWords       int64        `json:"total_words"`
+	TotalMouseFeet   float64      `json:"total_mouse_feet"`
+	MedianWPM        float64      `json:"median_wpm"`      // over every burst in the period
+	CorrectionRate   float64      `json:"correction_rate"` // percent of characters typed
+	Heatmap          []HeatmapRow `json:"-"`               // empty beyond MaxHeatmapDays
+	Keyboard         *Keyboard    `json:"-"`
+}
+
+// HeatmapRow is one day of the hourly activity heatmap
+type HeatmapRow struct {
+	Label string
+	Cells []HeatmapCell
+}
+
+// HeatmapCell is one hour of the hourly activity heatmap
+type HeatmapCell struct {
+	Color string
+	Title string
+}
+
+// BuildCharts reads the charts page model from storage
+func BuildCharts(store *storage.Store, units Units, opts ChartOptions) (*Charts, error) {
+	today := store.Today()
+	end, _ := time.Parse(dateLayout, today)
+
+	layout := opts.Layout
+	if layout == "" {
+		layout = store.Config().Keyboard.Layout
+	}
+	if _, err := keyboard.Get(layout); err != nil {
+		return nil, err
+	}
+
+	c := &Charts{}
+	for _, p := range chartPeriods {
+		from := end.AddDate(0, 0, -(p.days - 1)).Format(dateLayout)
+		period, err := buildPeriod(store, units, from, today, layout)
+		if err != nil {
+			return nil, err
+		}
+		period.Key = p.key
+		period.Title = p.title
+		c.Periods = append(c.Periods, *period)
+	}
+	c.Selected = c.Periods[0].Key
+
+	if opts.From != "" {
+		to := opts.To
+		if to == "" {
+			to = today
+		}
+		if err := checkRange(opts.From, to); err != nil {
+			return nil, err
+		}
+		period, err := buildPeriod(store, units, opts.From, to, layout)
+		if err != nil {
+			return nil, err
+		}
+		period.Key = PeriodCustom
+		period.Title = fmt.Sprintf("%s to %s", opts.From, to)
+		c.Periods = append(c.Periods, *period)
+		c.Selected = PeriodCustom
+	}
+
+	calendar, err := BuildCalendar(store, today, CalendarDays)
+	if err != nil {
+		return nil, err
+	}
+	c.Calendar = calendar
+
+	statuses, err := store.GetAchievements(units.Feet)
+	if err != nil {
+		return nil, err
+	}
+	c.Badges = NewBadges(statuses)
+
+	return c, nil
+}
+
+// checkRange validates an inclusive date range
+func checkRange(from, to string) error {
+	for _, d := range []string{from, to} {
+		if _, err := time.Parse(dateLayout, d); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", d)
+		}
+	}
+	if from > to {
+		return fmt.Errorf("from %s is after to %s", from, to)
+	}
+	return nil
+}
+
+func buildPeriod(store *storage.Store, units Units, from, to, layout string) (*Period, error) {
+	daily, err := store.GetDailyRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	mouse, err := store.GetMouseRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	dailyByDate := make(map[string]storage.DailyStats, len(daily))
+	for _, d := range daily {
+		dailyByDate[d.Date] = d
+	}
+	mouseByDate := make(map[string]float64, len(mouse))
+	for _, m := range mouse {
+		mouseByDate[m.Date] = m.TotalDistance
+	}
+	speeds, err := store.GetDailySpeedRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	speedByDate := make(map[string]storage.Speed, len(speeds))
+	for _, s := range speeds {
+		speedByDate[s.Date] = s.Speed
+	}
+	corrs, err := store.GetDailyCorrectionsRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	corrByDate := make(map[string]corrections.Counts, len(corrs))
+	for _, c := range corrs {
+		corrByDate[c.Date] = c.Counts
+	}
+
+	dates := datesBetween(from, to)
+	p := &Period{Days: len(dates), Dates: dates}
+	var totalMouse float64
+	var totalCorr corrections.Counts
+	for _, date := range dates {
+		t, _ := time.Parse(dateLayout, date)
+		stat := dailyByDate[date]
+		p.Labels = append(p.Labels, t.Format("Jan 2"))
+		p.Keystrokes = append(p.Keystrokes, stat.Keystrokes)
+		p.Words = append(p.Words, stat.Words)
+		p.TotalKeystrokes += stat.Keystrokes
+		p.TotalWords += stat.Words
+
+		p.MouseFeet = append(p.MouseFeet, roundFeet(units.Feet(mouseByDate[date])))
+		totalMouse += mouseByDate[date]
+
+		p.WPMMedian = append(p.WPMMedian, roundWPM(speedByDate[date].MedianWPM))
+		p.WPMP90 = append(p.WPMP90, roundWPM(speedByDate[date].P90WPM))
+
+		c := corrByDate[date]
+		p.Chars = append(p.Chars, c.Chars)
+		p.Corrections = append(p.Corrections, c.Corrections)
+		p.CorrectionBursts = append(p.CorrectionBursts, c.Bursts)
+		totalCorr.Add(c)
+	}
+	p.TotalMouseFeet = roundFeet(units.Feet(totalMouse))
+	p.CorrectionRate = roundPercent(totalCorr.Rate())
+
+	overall, err := store.GetSpeed(from, to)
+	if err != nil {
+		return nil, err
+	}
+	p.MedianWPM = roundWPM(overall.MedianWPM)
+
+	if len(dates) <= MaxHeatmapDays {
+		hourly, err := store.GetHourlyRange(from, to)
+		if err != nil {
+			return nil, err
+		}
+		hourlyData := make(map[string][]storage.HourlyStats, len(dates))
+		for _, date := range dates {
+			hours := make([]storage.HourlyStats, 24)
+			for h := range hours {
+				hours[h].Hour = h
+			}
+			hourlyData[date] = hours
+		}
+		for _, h := range hourly {
+			if hours, ok := hourlyData[h.Date]; ok && h.Hour >= 0 && h.Hour < 24 {
+				hours[h.Hour].Keystrokes = h.Keystrokes
+			}
+		}
+		hourlySpeed, err := store.GetHourlySpeedRange(from, to)
+		if err != nil {
+			return nil, err
+		}
+		hourlyCorr, err := store.GetHourlyCorrectionsRange(from, to)
+		if err != nil {
+			return nil, err
+		}
+		p.Heatmap = heatmapRows(hourlyData, hourlySpeed, hourlyCorr)
+	}
+
+	p.Keyboard, err = BuildKeyboardRange(store, from, to, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// roundFeet keeps chart data to two decimals
+func roundFeet(feet float64) float64 {
+	return float64(int64(feet*100+0.5)) / 100
+}
+
+// roundWPM keeps typing speeds to one decimal
+func roundWPM(wpm float64) float64 {
+	return float64(int64(wpm*10+0.5)) / 10
+}
+
+// roundPercent turns a ratio into a percentage with one decimal
+func roundPercent(ratio float64) float64 {
+	return float64(int64(ratio*1000+0.5)) / 10
+}
+
+// heatmapRows lays out hourly stats as one row per day, oldest first. Cells
+// of hours with a measured typing speed or typed characters mention the
+// speed and correction rate.
+func heatmapRows(hourlyData map[string][]storage.HourlyStats, speeds []storage.HourlySpeed, corrs []storage.HourlyCorrections) []HeatmapRow {
+	type slot struct {
+		date string
+		hour int
+	}
+	medians := make(map[slot]float64, len(speeds))
+	for _, s := range speeds {
+		medians[slot{s.Date, s.Hour}] = s.MedianWPM
+	}
+	corrected := make(map[slot]corrections.Counts, len(corrs))
+	for _, c := range corrs {
+		corrected[slot{c.Date, c.Hour}] = c.Counts
+	}
+
+	var counts []int64
+	for _, hours := range hourlyData {
+		for _, h := range hours {
+			counts = append(counts, h.Keystrokes)
+		}
+	}
+	scale := NewScale(counts)
+
+	dates := make([]string, 0, len(hourlyData))
+	for date := range hourlyData {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	rows := make([]HeatmapRow, 0, len(dates))
+	for _, date := range dates {
+		t, _ := time.Parse(dateLayout, date)
+		row := HeatmapRow{Label: t.Format("Mon Jan 2")}
+		for _, h := range hourlyData[date] {
+			title := fmt.Sprintf("%s %d:00 - %d keystrokes", row.Label, h.Hour, h.Keystrokes)
+			if wpm := medians[slot{date, h.Hour}]; wpm > 0 {
+				title += fmt.Sprintf(", %.0f WPM", wpm)
+			}
+			if c := corrected[slot{date, h.Hour}]; c.Chars > 0 {
+				title += fmt.Sprintf(", %.1f%% corrected", c.Rate()*100)
+			}
+			row.Cells = append(row.Cells, HeatmapCell{
+				Color: scale.Color(h.Keystrokes),
+				Title: title,
+			})
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// hourLabels labels every third column of the hourly heatmap
+func hourLabels() []string {
+	labels := make([]string, 24)
+	for h := 0; h < 24; h += 3 {
+		labels[h] = fmt.Sprintf("%d", h)
+	}
+	return labels
+}
+
+// WriteCharts renders the charts page
+func WriteCharts(w io.Writer, c *Charts) error {
+	return templates.ExecuteTemplate(w, "charts.html", struct {
+		*Charts
+		HourLabels     []string
+		Palette        []string
+		MaxHeatmapDays int
+	}{c, hourLabels(), heatmapPalette, MaxHeatmapDays})
+}
+
+// SaveCharts builds the charts page and writes it to the cache directory,
+// returning its path
+func SaveCharts(store *storage.Store, units Units, opts ChartOptions) (string, error) {
+	c, err := BuildCharts(store, units, opts)
+	if err != nil {
+		return "", err
+	}
+	return save("charts.html", func(w io.Writer) error { return WriteCharts(w, c) })
+}
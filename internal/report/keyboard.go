@@ -0,0 +1,119 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// TopKeys is how many of the most pressed keys are listed beside the
+// keyboard heatmap
+const TopKeys = 10
+
+// keyGap is the space between key caps in the SVG keyboard, in key units
+const keyGap = 0.1
+
+// Keyboard is a keyboard drawing coloured by how often each key was pressed
+type Keyboard struct {
+	Layout string
+	Width  float64 // in key units
+	Rows   int
+	Keys   []KeyboardKey
+	Total  int64      // presses of every key, on the layout or not
+	Top    []KeyUsage // the most pressed keys, busiest first
+}
+
+// KeyboardKey is one key cap of the keyboard heatmap
+type KeyboardKey struct {
+	keyboard.Key
+	Count int64
+	Level int
+	Color string
+	Title string
+}
+
+// KeyUsage is a key's share of all presses
+type KeyUsage struct {
+	Name    string
+	Count   int64
+	Percent float64
+}
+
+// BuildKeyboard lays out key frequencies on a physical keyboard layout.
+// Levels follow the quartiles of the keys pressed, like the other heatmaps.
+func BuildKeyboard(freqs []storage.KeyFrequency, layout string) (*Keyboard, error) {
+	l, err := keyboard.Get(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int64, len(freqs))
+	var total int64
+	for _, f := range freqs {
+		counts[f.Keycode] += f.Count
+		total += f.Count
+	}
+
+	onLayout := make(map[int]bool)
+	var values []int64
+	for _, key := range l.Keys {
+		if !onLayout[key.Code] {
+			onLayout[key.Code] = true
+			values = append(values, counts[key.Code])
+		}
+	}
+	scale := NewScale(values)
+
+	kb := &Keyboard{Layout: l.Name, Width: l.Width, Rows: l.Rows, Total: total}
+	for _, key := range l.Keys {
+		n := counts[key.Code]
+		kb.Keys = append(kb.Keys, KeyboardKey{
+			Key:   key,
+			Count: n,
+			Level: scale.Level(n),
+			Color: scale.Color(n),
+			Title: fmt.Sprintf("%s - %s presses", keyboard.Name(key.Code), FormatAbsolute(n)),
+		})
+	}
+
+	for _, f := range freqs { // already busiest first
+		if len(kb.Top) == TopKeys {
+			break
+		}
+		kb.Top = append(kb.Top, KeyUsage{
+			Name:    keyboard.Name(f.Keycode),
+			Count:   f.Count,
+			Percent: float64(f.Count) / float64(total) * 100,
+		})
+	}
+	return kb, nil
+}
+
+// BuildKeyboardRange reads key frequencies between from and to and lays them
+// out on a keyboard
+func BuildKeyboardRange(store *storage.Store, from, to, layout string) (*Keyboard, error) {
+	freqs, err := store.GetKeyFrequencies(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key frequencies: %w", err)
+	}
+	return BuildKeyboard(freqs, layout)
+}
+
+// CapX is the left edge of the key's cap in the SVG drawing
+func (k KeyboardKey) CapX() float64 { return k.X + keyGap/2 }
+
+// CapY is the top edge of the key's cap in the SVG drawing
+func (k KeyboardKey) CapY() float64 { return k.Y + keyGap/2 }
+
+// CapW is the width of the key's cap in the SVG drawing
+func (k KeyboardKey) CapW() float64 { return k.W - keyGap }
+
+// CapH is the height of the key's cap in the SVG drawing
+func (k KeyboardKey) CapH() float64 { return 1 - keyGap }
+
+// LabelX is where the key's label is centred
+func (k KeyboardKey) LabelX() float64 { return k.X + k.W/2 }
+
+// LabelY is the baseline of the key's label
+func (k KeyboardKey) LabelY() float64 { return k.Y + 0.6 }
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/leaderboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// LeaderboardSize is how many days the stillness leaderboard page lists
+const LeaderboardSize = 30
+
+// Leaderboard is the model behind the stillness leaderboard page
+type Leaderboard struct {
+	Entries []LeaderboardEntry
+}
+
+// LeaderboardEntry is one ranked day, formatted for display
+type LeaderboardEntry struct {
+	Medal    string
+	Date     string
+	Distance string
+}
+
+// BuildLeaderboard reads the days with the least mouse movement
+func BuildLeaderboard(store *storage.Store, units Units) (*Leaderboard, error) {
+	entries, err := store.GetLeaderboard(leaderboard.Stillness, storage.LeaderboardOptions{Limit: LeaderboardSize})
+	if err != nil {
+		return nil, err
+	}
+
+	lb := &Leaderboard{}
+	for _, entry := range entries {
+		lb.Entries = append(lb.Entries, LeaderboardEntry{
+			Medal:    Medal(entry.Rank),
+			Date:     EntryLabel(entry),
+			Distance: units.FormatLeaderboardValue(leaderboard.MouseDistance, entry.Value),
+		})
+	}
+	return lb, nil
+}
+
+// EntryLabel names what a leaderboard entry ranks: its day, and the hour,
+// start time or typing test mode where there is one
+func EntryLabel(e storage.LeaderboardEntry) string {
+	t, _ := time.Parse("2006-01-02", e.Date)
+	label := t.Format("Monday, Jan 2, 2006")
+	switch {
+	case e.Mode != nil:
+		label += fmt.Sprintf(", %d words", e.Mode.WordCount)
+		if e.Mode.Punctuation {
+			label += " with punctuation"
+		}
+	case e.Start != nil:
+		label += e.Start.Format(", 15:04")
+	case e.Hour != nil:
+		label += fmt.Sprintf(", %02d:00", *e.Hour)
+	}
+	return label
+}
+
+// FormatLeaderboardValue shows a leaderboard value of metric m with its unit
+func (u Units) FormatLeaderboardValue(m leaderboard.Metric, v float64) string {
+	switch m {
+	case leaderboard.MouseDistance:
+		return u.FormatDistance(v)
+	case leaderboard.Keystrokes:
+		return FormatAbsolute(int64(v)) + " keystrokes"
+	case leaderboard.Words:
+		return FormatAbsolute(int64(v)) + " words"
+	case leaderboard.Clicks:
+		return FormatAbsolute(int64(v)) + " clicks"
+	case leaderboard.TestWPM:
+		return fmt.Sprintf("%.0f WPM", v)
+	case leaderboard.SessionMinutes:
+		minutes := int(math.Round(v))
+		if minutes < 60 {
+			return fmt.Sprintf("%dm", minutes)
+		}
+		return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+	}
+	return fmt.Sprintf("%g", v)
+}
+
+// Medal shows the top three ranks as medals and the rest as #N
+func Medal(rank int) string {
+	switch rank {
+	case 1:
+		return "🥇"
+	case 2:
+		return "🥈"
+	case 3:
+		return "🥉"
+	default:
+		return fmt.Sprintf("#%d", rank)
+	}
+}
+
+// WriteLeaderboard renders the stillness leaderboard page
+func WriteLeaderboard(w io.Writer, lb *Leaderboard) error {
+	return templates.ExecuteTemplate(w, "leaderboard.html", lb)
+}
+
+// SaveLeaderboard builds the leaderboard page and writes it to the cache
+// directory, returning its path
+func SaveLeaderboard(store *storage.Store, units Units) (string, error) {
+	lb, err := BuildLeaderboard(store, units)
+	if err != nil {
+		return "", err
+	}
+	return save("leaderboard.html", func(w io.Writer) error { return WriteLeaderboard(w, lb) })
+}
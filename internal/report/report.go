@@ -0,0 +1,133 @@
+// Package report renders typtel's HTML reports (charts and the stillness
+// leaderboard) and the number and distance formatting they share with the
+// CLI and menu bar.
+//
+// Reports are built in two steps: Build* functions read a typed model from
+// storage, and Write* functions render that model through html/template.
+// Keeping the steps apart lets the templates be golden-file tested without a
+// database.
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/charts"
+	"github.com/aayushbajaj/typing-telemetry/internal/paths"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// DefaultPPI is the pixels per inch assumed when the display's is unknown
+const DefaultPPI = 100.0
+
+// Distances in feet used by the non-default units
+const (
+	feetPerMile  = 5280.0
+	feetPerCar   = 15.0
+	feetPerField = 330.0
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"chartScript": func() template.HTML { return template.HTML(charts.ScriptTag()) },
+	"abs":         FormatAbsolute,
+}).ParseFS(templateFS, "templates/*.html"))
+
+// Units controls how mouse distances are converted and displayed. The zero
+// value shows feet and miles at DefaultPPI.
+type Units struct {
+	PPI      float64 // display pixels per inch
+	Distance string  // one of storage.DistanceUnit*
+}
+
+// Feet converts a distance in pixels to feet
+func (u Units) Feet(pixels float64) float64 {
+	ppi := u.PPI
+	if ppi <= 0 {
+		ppi = DefaultPPI
+	}
+	return pixels / ppi / 12.0
+}
+
+// FormatDistance renders a distance in pixels in the configured unit
+func (u Units) FormatDistance(pixels float64) string {
+	return u.FormatFeet(u.Feet(pixels))
+}
+
+// FormatFeet renders a distance in feet, such as a mouse distance goal, in
+// the configured unit
+func (u Units) FormatFeet(feet float64) string {
+	switch u.Distance {
+	case storage.DistanceUnitCars:
+		cars := feet / feetPerCar
+		if cars >= 1000 {
+			return fmt.Sprintf("%.1fk cars", cars/1000)
+		} else if cars >= 1 {
+			return fmt.Sprintf("%.0f cars", cars)
+		}
+		return fmt.Sprintf("%.1f cars", cars)
+
+	case storage.DistanceUnitFrisbee:
+		fields := feet / feetPerField
+		if fields >= 100 {
+			return fmt.Sprintf("%.0f fields", fields)
+		} else if fields >= 1 {
+			return fmt.Sprintf("%.1f fields", fields)
+		}
+		return fmt.Sprintf("%.2f fields", fields)
+
+	default:
+		if feet >= feetPerMile {
+			return fmt.Sprintf("%.1fmi", feet/feetPerMile)
+		} else if feet >= 1 {
+			return fmt.Sprintf("%.0fft", feet)
+		}
+		inches := feet * 12
+		return fmt.Sprintf("%.0fin", inches)
+	}
+}
+
+// FormatAbsolute renders n with thousands separators, e.g. 1,234,567
+func FormatAbsolute(n int64) string {
+	s := fmt.Sprintf("%d", n)
+	if n < 0 {
+		return s
+	}
+
+	result := ""
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			result += ","
+		}
+		result += string(c)
+	}
+	return result
+}
+
+// save renders a report into the cache directory and returns its path
+func save(name string, render func(io.Writer) error) (string, error) {
+	cacheDir, err := paths.CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	htmlPath := filepath.Join(cacheDir, name)
+	f, err := os.Create(htmlPath)
+	if err != nil {
+		return "", err
+	}
+	if err := render(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return htmlPath, nil
+}
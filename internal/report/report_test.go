@@ -0,0 +1,600 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+	"github.com/aayushbajaj/typing-telemetry/internal/charts"
+	"github.com/aayushbajaj/typing-telemetry/internal/corrections"
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/leaderboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/paths"
+	"github.com/aayushbajaj/typing-telemetry/internal/speed"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// checkGolden compares got with testdata/name, rewriting it with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Failed to update %s: %v", path, err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s (run with -update to create it): %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Output differs from %s; run 'go test ./internal/report -update' and review the diff", path)
+	}
+}
+
+func TestFormatAbsolute(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0"},
+		{5, "5"},
+		{42, "42"},
+		{999, "999"},
+		{1000, "1,000"},
+		{12345, "12,345"},
+		{123456, "123,456"},
+		{1234567, "1,234,567"},
+		{1000000000, "1,000,000,000"},
+		{123456789012, "123,456,789,012"},
+		{-100, "-100"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatAbsolute(tt.input); got != tt.expected {
+			t.Errorf("FormatAbsolute(%d) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatDistance(t *testing.T) {
+	const ppi = 100.0
+	foot := ppi * 12
+
+	tests := []struct {
+		unit     string
+		pixels   float64
+		expected string
+	}{
+		{"", 600, "6in"},
+		{"", 10 * foot, "10ft"},
+		{storage.DistanceUnitFeet, 5280 * 2.5 * foot, "2.5mi"},
+		{storage.DistanceUnitCars, 7.5 * foot, "0.5 cars"},
+		{storage.DistanceUnitCars, 150 * foot, "10 cars"},
+		{storage.DistanceUnitCars, 15000 * 2 * foot, "2.0k cars"},
+		{storage.DistanceUnitFrisbee, 33 * foot, "0.10 fields"},
+		{storage.DistanceUnitFrisbee, 660 * foot, "2.0 fields"},
+		{storage.DistanceUnitFrisbee, 330 * 150 * foot, "150 fields"},
+	}
+
+	for _, tt := range tests {
+		units := Units{PPI: ppi, Distance: tt.unit}
+		if got := units.FormatDistance(tt.pixels); got != tt.expected {
+			t.Errorf("FormatDistance(%v) in %q = %q, want %q", tt.pixels, tt.unit, got, tt.expected)
+		}
+	}
+
+	// A zero PPI falls back to DefaultPPI
+	if got := (Units{}).Feet(DefaultPPI * 12); got != 1 {
+		t.Errorf("Feet at default PPI = %v, want 1", got)
+	}
+}
+
+func TestScale(t *testing.T) {
+	// Quartiles of 1..100 are 26, 51 and 76
+	var values []int64
+	for v := int64(1); v <= 100; v++ {
+		values = append(values, v)
+	}
+	values = append(values, 0, 0, 0) // zeros don't shift the quartiles
+	scale := NewScale(values)
+
+	tests := []struct {
+		value int64
+		level int
+	}{
+		{0, 0},
+		{1, 1},
+		{25, 1},
+		{26, 2},
+		{50, 2},
+		{51, 3},
+		{76, 4},
+		{100, 4},
+		{5000, 4},
+	}
+	for _, tt := range tests {
+		if got := scale.Level(tt.value); got != tt.level {
+			t.Errorf("Level(%d) = %d, want %d", tt.value, got, tt.level)
+		}
+	}
+	if got := scale.Color(0); got != "#1a1a2e" {
+		t.Errorf("Color(0) = %s, want #1a1a2e", got)
+	}
+	if got := scale.Color(100); got != "#7bc96f" {
+		t.Errorf("Color(100) = %s, want #7bc96f", got)
+	}
+
+	// One outlier no longer dims everything else, as a max-based scale would
+	skewed := NewScale([]int64{100, 110, 120, 130, 100000})
+	if got := skewed.Level(130); got != 4 {
+		t.Errorf("Level(130) with an outlier = %d, want 4", got)
+	}
+
+	// Equal values are all at the top level
+	if got := NewScale([]int64{7, 7, 7}).Level(7); got != 4 {
+		t.Errorf("Level of equal values = %d, want 4", got)
+	}
+	if got := NewScale(nil).Level(0); got != 0 {
+		t.Errorf("Level(0) of an empty scale = %d, want 0", got)
+	}
+}
+
+func TestNewCalendar(t *testing.T) {
+	// 2024-01-03 is a Wednesday; February 1st starts a new month label
+	c := NewCalendar("2024-01-03", "2024-02-05", []storage.DailyStats{
+		{Date: "2024-01-03", Keystrokes: 10, Words: 2},
+		{Date: "2024-01-20", Keystrokes: 500},
+	})
+
+	if len(c.Days) != 34 {
+		t.Fatalf("Got %d days, want 34", len(c.Days))
+	}
+	if len(c.Weeks) != 6 {
+		t.Fatalf("Got %d weeks, want 6", len(c.Weeks))
+	}
+
+	first := c.Weeks[0]
+	if first.Days[2] != nil || first.Days[3] == nil || first.Days[3].Date != "2024-01-03" {
+		t.Errorf("First week should start on Wednesday, got %+v", first.Days)
+	}
+	if first.Month != "Jan" {
+		t.Errorf("First week month = %q, want Jan", first.Month)
+	}
+	if c.Weeks[4].Month != "Feb" {
+		t.Errorf("Week 4 month = %q, want Feb", c.Weeks[4].Month)
+	}
+	if last := c.Weeks[5]; last.Days[1] == nil || last.Days[2] != nil {
+		t.Errorf("Last week should end on Monday, got %+v", last.Days)
+	}
+
+	if day := c.Days[0]; day.Level == 0 || day.Words != 2 || !strings.Contains(day.Title, "10 keystrokes") {
+		t.Errorf("First day = %+v", day)
+	}
+	if day := c.Days[1]; day.Level != 0 || day.Color != "#1a1a2e" {
+		t.Errorf("Missing day should be empty, got %+v", day)
+	}
+
+	// A first month with no room for its label loses it
+	c = NewCalendar("2024-01-21", "2024-03-01", nil)
+	if c.Weeks[0].Month != "" || c.Weeks[1].Month != "Feb" {
+		t.Errorf("Months = %q, %q; want the crowded Jan label dropped", c.Weeks[0].Month, c.Weeks[1].Month)
+	}
+}
+
+func TestCalendarSummary(t *testing.T) {
+	c := NewCalendar("2024-01-01", "2024-01-10", []storage.DailyStats{
+		{Date: "2024-01-02", Keystrokes: 100, Words: 20},
+		{Date: "2024-01-04", Keystrokes: 300, Words: 60},
+		{Date: "2024-01-09", Keystrokes: 1000},
+	})
+
+	i, j := c.Index("2024-01-05"), c.Index("2024-01-02")
+	if i != 4 || j != 1 {
+		t.Fatalf("Index = %d, %d; want 4, 1", i, j)
+	}
+	if c.Index("2023-12-31") != -1 {
+		t.Error("Index of a date outside the calendar should be -1")
+	}
+
+	got := c.Summary(i, j)
+	want := RangeSummary{From: "2024-01-02", To: "2024-01-05", Days: 4, ActiveDays: 2, Keystrokes: 400, Words: 80}
+	if got != want {
+		t.Errorf("Summary = %+v, want %+v", got, want)
+	}
+	if got.DailyAverage() != 100 {
+		t.Errorf("DailyAverage = %d, want 100", got.DailyAverage())
+	}
+	if got := c.Summary(-5, 100); got.Days != 10 || got.Keystrokes != 1400 {
+		t.Errorf("Clamped summary = %+v", got)
+	}
+}
+
+func TestHeatmapRows(t *testing.T) {
+	if rows := heatmapRows(map[string][]storage.HourlyStats{}, nil, nil); len(rows) != 0 {
+		t.Errorf("Empty heatmap has %d rows, want 0", len(rows))
+	}
+
+	rows := heatmapRows(map[string][]storage.HourlyStats{
+		"2024-01-03": {{Hour: 9, Keystrokes: 100}},
+		"2024-01-01": {{Hour: 9, Keystrokes: 1000}, {Hour: 10, Keystrokes: 100}},
+		"2024-01-02": {{Hour: 9, Keystrokes: 0}},
+	}, []storage.HourlySpeed{
+		{Date: "2024-01-03", Hour: 9, Speed: storage.Speed{Bursts: 2, MedianWPM: 64.6, P90WPM: 80}},
+	}, []storage.HourlyCorrections{
+		{Date: "2024-01-03", Hour: 9, Counts: corrections.Counts{Chars: 80, Corrections: 4}},
+		{Date: "2024-01-02", Hour: 9, Counts: corrections.Counts{Corrections: 2}},
+	})
+
+	var labels []string
+	for _, row := range rows {
+		labels = append(labels, row.Label)
+	}
+	if got := strings.Join(labels, ","); got != "Mon Jan 1,Tue Jan 2,Wed Jan 3" {
+		t.Errorf("Row labels = %s, want dates in order", got)
+	}
+
+	// The busiest hour gets the brightest colour
+	busiest := rows[0].Cells[0]
+	if busiest.Color != "#7bc96f" {
+		t.Errorf("Busiest cell colour = %s, want #7bc96f", busiest.Color)
+	}
+	if busiest.Title != "Mon Jan 1 9:00 - 1000 keystrokes" {
+		t.Errorf("Busiest cell title = %q", busiest.Title)
+	}
+	if got := rows[2].Cells[0].Title; got != "Wed Jan 3 9:00 - 100 keystrokes, 65 WPM, 5.0% corrected" {
+		t.Errorf("Cell title with typing speed and corrections = %q", got)
+	}
+	if got := rows[1].Cells[0].Title; got != "Tue Jan 2 9:00 - 0 keystrokes" {
+		t.Errorf("Cell title without characters typed = %q", got)
+	}
+}
+
+func TestHourLabels(t *testing.T) {
+	labels := hourLabels()
+	if len(labels) != 24 {
+		t.Fatalf("Got %d hour labels, want 24", len(labels))
+	}
+	for h, want := range map[int]string{0: "0", 1: "", 6: "6", 12: "12", 20: "", 21: "21"} {
+		if labels[h] != want {
+			t.Errorf("Hour %d label = %q, want %q", h, labels[h], want)
+		}
+	}
+}
+
+func TestNewBadges(t *testing.T) {
+	at := time.Date(2024, 5, 2, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		status achievements.Status
+		want   string
+	}{
+		{"recorded", achievements.Status{Unlocked: true, UnlockedAt: &at, Progress: 1}, "Unlocked May 2, 2024"},
+		{"met", achievements.Status{Unlocked: true, Progress: 1}, "Unlocked"},
+		{"locked", achievements.Status{Progress: 0.256}, "26% there"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBadges([]achievements.Status{tt.status})[0]
+			if b.State != tt.want {
+				t.Errorf("State = %q, want %q", b.State, tt.want)
+			}
+		})
+	}
+}
+
+func testCharts() *Charts {
+	return &Charts{
+		Selected: "weekly",
+		Periods: []Period{
+			{
+				Key:              "weekly",
+				Title:            "Weekly (7 days)",
+				Days:             2,
+				Dates:            []string{"2024-01-01", "2024-01-02"},
+				Labels:           []string{"Jan 1", "Jan 2"},
+				Keystrokes:       []int64{1200, 3400},
+				Words:            []int64{200, 560},
+				MouseFeet:        []float64{12.5, 80},
+				WPMMedian:        []float64{0, 71.5},
+				WPMP90:           []float64{0, 88},
+				Chars:            []int64{900, 2800},
+				Corrections:      []int64{45, 98},
+				CorrectionBursts: []int64{2, 5},
+				TotalKeystrokes:  4600,
+				TotalWords:       760,
+				TotalMouseFeet:   92.5,
+				MedianWPM:        71.5,
+				CorrectionRate:   3.9,
+				Heatmap: heatmapRows(map[string][]storage.HourlyStats{
+					"2024-01-01": {{Hour: 0, Keystrokes: 0}, {Hour: 1, Keystrokes: 1200}},
+					"2024-01-02": {{Hour: 0, Keystrokes: 3000}, {Hour: 1, Keystrokes: 400}},
+				}, []storage.HourlySpeed{
+					{Date: "2024-01-02", Hour: 0, Speed: storage.Speed{Bursts: 12, MedianWPM: 71.5, P90WPM: 88}},
+				}, []storage.HourlyCorrections{
+					{Date: "2024-01-02", Hour: 0, Counts: corrections.Counts{Chars: 2500, Corrections: 90, Bursts: 4}},
+				}),
+				Keyboard: testKeyboard(),
+			},
+			{
+				Key:              PeriodYearly,
+				Title:            "Yearly (365 days)",
+				Days:             1,
+				Dates:            []string{"2024-01-02"},
+				Labels:           []string{"Jan 2"},
+				Keystrokes:       []int64{3400},
+				Words:            []int64{560},
+				MouseFeet:        []float64{80},
+				WPMMedian:        []float64{71.5},
+				WPMP90:           []float64{88},
+				Chars:            []int64{2800},
+				Corrections:      []int64{98},
+				CorrectionBursts: []int64{5},
+				MedianWPM:        71.5,
+				CorrectionRate:   3.5,
+			},
+		},
+		Calendar: NewCalendar("2023-12-28", "2024-01-02", []storage.DailyStats{
+			{Date: "2024-01-01", Keystrokes: 1200, Words: 200},
+			{Date: "2024-01-02", Keystrokes: 3400, Words: 560},
+		}),
+		Badges: []Badge{
+			{Icon: "⚡", Name: "Triple Digits", Description: "Reach 100 WPM in a typing test", Unlocked: true, State: "Unlocked Jan 2, 2024", Percent: 100},
+			{Icon: "🔥", Name: "On Fire", Description: "Meet your daily goals 30 days in a row", State: "40% there", Percent: 40},
+		},
+	}
+}
+
+func testKeyboard() *Keyboard {
+	kb, _ := BuildKeyboard([]storage.KeyFrequency{
+		{Keycode: keyboard.KeySpace, Count: 900},
+		{Keycode: keyboard.KeyE, Count: 500},
+		{Keycode: keyboard.KeyT, Count: 300},
+		{Keycode: keyboard.KeyDelete, Count: 120},
+		{Keycode: keyboard.KeyReturn, Count: 80},
+	}, keyboard.ISO)
+	return kb
+}
+
+func TestWriteChartsGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCharts(&buf, testCharts()); err != nil {
+		t.Fatalf("WriteCharts failed: %v", err)
+	}
+	checkGolden(t, "charts.golden.html", buf.Bytes())
+}
+
+func TestEntryLabel(t *testing.T) {
+	hour := 9
+	start := time.Date(2024, 5, 2, 0, 30, 0, 0, time.FixedZone("", 3600))
+	tests := []struct {
+		name  string
+		entry storage.LeaderboardEntry
+		want  string
+	}{
+		{"day", storage.LeaderboardEntry{Date: "2024-05-02"}, "Thursday, May 2, 2024"},
+		{"hour", storage.LeaderboardEntry{Date: "2024-05-02", Hour: &hour}, "Thursday, May 2, 2024, 09:00"},
+		{"session", storage.LeaderboardEntry{Date: "2024-05-02", Start: &start}, "Thursday, May 2, 2024, 00:30"},
+		{"test", storage.LeaderboardEntry{Date: "2024-05-02", Start: &start, Mode: &storage.TypingTestMode{WordCount: 50, Punctuation: true}}, "Thursday, May 2, 2024, 50 words with punctuation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EntryLabel(tt.entry); got != tt.want {
+				t.Errorf("EntryLabel = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatLeaderboardValue(t *testing.T) {
+	tests := []struct {
+		metric leaderboard.Metric
+		value  float64
+		want   string
+	}{
+		{leaderboard.Keystrokes, 12345, "12,345 keystrokes"},
+		{leaderboard.Clicks, 0, "0 clicks"},
+		{leaderboard.TestWPM, 104.6, "105 WPM"},
+		{leaderboard.SessionMinutes, 42.4, "42m"},
+		{leaderboard.SessionMinutes, 95, "1h 35m"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.metric), func(t *testing.T) {
+			if got := (Units{}).FormatLeaderboardValue(tt.metric, tt.value); got != tt.want {
+				t.Errorf("FormatLeaderboardValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLeaderboardGolden(t *testing.T) {
+	lb := &Leaderboard{Entries: []LeaderboardEntry{
+		{Medal: Medal(1), Date: "Monday, Jan 1, 2024", Distance: "12ft"},
+		{Medal: Medal(2), Date: "Tuesday, Jan 2, 2024", Distance: "40ft"},
+		{Medal: Medal(4), Date: "Wednesday, Jan 3, 2024", Distance: "1.2mi"},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteLeaderboard(&buf, lb); err != nil {
+		t.Fatalf("WriteLeaderboard failed: %v", err)
+	}
+	checkGolden(t, "leaderboard.golden.html", buf.Bytes())
+}
+
+func TestTemplatesEscape(t *testing.T) {
+	c := testCharts()
+	c.Periods[0].Labels[0] = "</script><b>"
+	c.Periods[0].Heatmap[0].Label = "<img>"
+
+	var buf bytes.Buffer
+	if err := WriteCharts(&buf, c); err != nil {
+		t.Fatalf("WriteCharts failed: %v", err)
+	}
+	html := buf.String()
+	if strings.Contains(html, "</script><b>") || strings.Contains(html, "<img>") {
+		t.Error("Data should be escaped in the rendered page")
+	}
+}
+
+func TestSaveCharts(t *testing.T) {
+	t.Setenv(paths.EnvDataDir, t.TempDir())
+
+	store, err := storage.NewWithPath(filepath.Join(t.TempDir(), "typtel.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	store.RecordKeystroke(0)
+
+	htmlPath, err := SaveCharts(store, Units{}, ChartOptions{})
+	if err != nil {
+		t.Fatalf("SaveCharts failed: %v", err)
+	}
+	data, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	html := string(data)
+	if strings.Contains(html, "<script src=") || strings.Contains(html, "https://") {
+		t.Error("Chart page should not load anything from the network")
+	}
+	if !strings.Contains(html, charts.Script) {
+		t.Error("Chart page should inline the chart renderer")
+	}
+	if strings.Count(html, `class="heatmap" data-period=`) != len(chartPeriods)+1 {
+		t.Errorf("Expected one heatmap per period and one for the page's date picker")
+	}
+	if !strings.Contains(html, `class="calendar-cell"`) {
+		t.Error("Chart page should include the calendar")
+	}
+
+	if _, err := SaveLeaderboard(store, Units{}); err != nil {
+		t.Errorf("SaveLeaderboard failed: %v", err)
+	}
+}
+
+func TestBuildChartsCustomRange(t *testing.T) {
+	store, err := storage.NewWithPath(filepath.Join(t.TempDir(), "typtel.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	store.AddDailyTotals("2023-03-01", 100, 20)
+	store.AddDailyTotals("2023-06-30", 50, 10)
+	store.AddHourlyCount("2023-03-01", 9, 100)
+	noon := time.Date(2023, 3, 2, 12, 0, 0, 0, time.Local)
+	store.RecordTypingBurst(speed.Burst{Start: noon, End: noon.Add(12 * time.Second), Chars: 60})
+	for _, code := range []int{keyboard.KeyA, keyboard.KeyA, keyboard.KeyA, keyboard.KeyA, keyboard.KeyDelete} {
+		store.RecordKeystrokeAt(code, noon)
+	}
+
+	c, err := BuildCharts(store, Units{}, ChartOptions{From: "2023-03-01", To: "2023-03-05"})
+	if err != nil {
+		t.Fatalf("BuildCharts failed: %v", err)
+	}
+	if c.Selected != PeriodCustom {
+		t.Errorf("Selected = %q, want the custom range", c.Selected)
+	}
+
+	custom := c.Periods[len(c.Periods)-1]
+	if custom.Key != PeriodCustom || custom.Days != 5 || custom.TotalKeystrokes != 105 {
+		t.Errorf("Custom period = %+v", custom)
+	}
+	if custom.MedianWPM != 60 || custom.WPMMedian[1] != 60 || custom.WPMP90[1] != 60 || custom.WPMMedian[0] != 0 {
+		t.Errorf("Custom typing speed = %v median, daily %v", custom.MedianWPM, custom.WPMMedian)
+	}
+	if custom.CorrectionRate != 25 || custom.Chars[1] != 4 || custom.Corrections[1] != 1 || custom.Chars[0] != 0 {
+		t.Errorf("Custom corrections = %v%%, daily %v of %v", custom.CorrectionRate, custom.Corrections, custom.Chars)
+	}
+	if len(custom.Heatmap) != 5 || custom.Heatmap[0].Cells[9].Color != "#7bc96f" {
+		t.Errorf("Custom heatmap = %+v", custom.Heatmap)
+	}
+	if len(c.Calendar.Days) != CalendarDays || c.Calendar.To != store.Today() {
+		t.Errorf("Calendar = %s..%s with %d days", c.Calendar.From, c.Calendar.To, len(c.Calendar.Days))
+	}
+
+	// Long ranges skip the hourly heatmap
+	c, err = BuildCharts(store, Units{}, ChartOptions{From: "2023-01-01", To: "2023-12-31"})
+	if err != nil {
+		t.Fatalf("BuildCharts failed: %v", err)
+	}
+	if custom := c.Periods[len(c.Periods)-1]; custom.Heatmap != nil || custom.TotalKeystrokes != 155 {
+		t.Errorf("Long custom period = %d keystrokes, %d heatmap rows", custom.TotalKeystrokes, len(custom.Heatmap))
+	}
+
+	for _, opts := range []ChartOptions{
+		{From: "2023-03-05", To: "2023-03-01"},
+		{From: "March"},
+		{From: "2023-03-01", To: "2023-02-30"},
+	} {
+		if _, err := BuildCharts(store, Units{}, opts); err == nil {
+			t.Errorf("BuildCharts(%+v) should fail", opts)
+		}
+	}
+}
+
+func TestBuildKeyboard(t *testing.T) {
+	kb := testKeyboard()
+	if kb.Layout != keyboard.ISO || kb.Width != 15 || kb.Rows != 6 {
+		t.Errorf("keyboard is %s %vx%d, want iso 15x6", kb.Layout, kb.Width, kb.Rows)
+	}
+	if kb.Total != 1900 {
+		t.Errorf("Total = %d, want 1900", kb.Total)
+	}
+
+	levels := make(map[int]int)
+	for _, k := range kb.Keys {
+		if _, seen := levels[k.Code]; !seen || k.Level > levels[k.Code] {
+			levels[k.Code] = k.Level
+		}
+	}
+	if levels[keyboard.KeySpace] != Levels-1 {
+		t.Errorf("Space level = %d, want the busiest", levels[keyboard.KeySpace])
+	}
+	if levels[keyboard.KeyReturn] != 1 || levels[keyboard.KeyA] != 0 {
+		t.Errorf("Return/A levels = %d/%d, want 1/0", levels[keyboard.KeyReturn], levels[keyboard.KeyA])
+	}
+
+	// Both halves of the ISO Return share its colour
+	var returnColors []string
+	for _, k := range kb.Keys {
+		if k.Code == keyboard.KeyReturn {
+			returnColors = append(returnColors, k.Color)
+		}
+	}
+	if len(returnColors) != 2 || returnColors[0] != returnColors[1] {
+		t.Errorf("Return caps = %v, want two of the same colour", returnColors)
+	}
+
+	if len(kb.Top) != 5 || kb.Top[0].Name != "Space" || kb.Top[0].Percent < 47.3 || kb.Top[0].Percent > 47.4 {
+		t.Errorf("Top = %+v, want Space first at 47.4%%", kb.Top)
+	}
+}
+
+func TestBuildKeyboardOffLayout(t *testing.T) {
+	// Keys the layout doesn't draw still count towards the total and top keys
+	kb, err := BuildKeyboard([]storage.KeyFrequency{{Keycode: 200, Count: 10}, {Keycode: keyboard.KeyA, Count: 10}}, keyboard.ANSI)
+	if err != nil {
+		t.Fatalf("BuildKeyboard failed: %v", err)
+	}
+	if kb.Total != 20 || len(kb.Top) != 2 || kb.Top[0].Name != "key 200" {
+		t.Errorf("Total = %d, Top = %+v", kb.Total, kb.Top)
+	}
+
+	if _, err := BuildKeyboard(nil, "colemak"); err == nil {
+		t.Error("expected an error for an unknown layout")
+	}
+	empty, err := BuildKeyboard(nil, "")
+	if err != nil || len(empty.Top) != 0 || empty.Total != 0 {
+		t.Errorf("empty keyboard = %+v, %v", empty, err)
+	}
+}
@@ -0,0 +1,58 @@
+package report
+
+import "sort"
+
+// Heatmap colours from no activity (level 0) to the busiest (level 4)
+var heatmapPalette = []string{"#1a1a2e", "#2d4a3e", "#3d6b4f", "#5a9a6f", "#7bc96f"}
+
+// Levels is the number of heatmap levels, including the empty level 0
+const Levels = 5
+
+// Scale buckets counts into heatmap levels by the quartiles of the non-zero
+// counts it was built from, so one unusually busy hour or day doesn't wash
+// out everything else. Zero is always level 0.
+type Scale struct {
+	bounds [3]int64 // 25th, 50th and 75th percentiles
+}
+
+// NewScale builds a scale from the counts being displayed
+func NewScale(values []int64) Scale {
+	var nonZero []int64
+	for _, v := range values {
+		if v > 0 {
+			nonZero = append(nonZero, v)
+		}
+	}
+	if len(nonZero) == 0 {
+		return Scale{}
+	}
+	sort.Slice(nonZero, func(i, j int) bool { return nonZero[i] < nonZero[j] })
+
+	var s Scale
+	for i, q := range []float64{0.25, 0.5, 0.75} {
+		s.bounds[i] = nonZero[int(q*float64(len(nonZero)-1)+0.5)]
+	}
+	return s
+}
+
+// Level returns 0 for no activity and 1-4 by quartile otherwise
+func (s Scale) Level(v int64) int {
+	if v <= 0 {
+		return 0
+	}
+	level := 1
+	for _, b := range s.bounds {
+		if v >= b {
+			level++
+		}
+	}
+	if level >= Levels {
+		level = Levels - 1
+	}
+	return level
+}
+
+// Color returns the heatmap colour for v
+func (s Scale) Color(v int64) string {
+	return heatmapPalette[s.Level(v)]
+}
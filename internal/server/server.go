@@ -0,0 +1,197 @@
+// Package server serves a live dashboard and a read-only JSON API over HTTP,
+// so browsers and scripts can read telemetry without opening SQLite.
+//
+// Every /api endpoint except /api/today takes the same date range query
+// parameters:
+//
+//	from=YYYY-MM-DD  first day, inclusive
+//	to=YYYY-MM-DD    last day, inclusive (default: today)
+//	days=N           N days ending at to, used when from is not given (default: 30)
+//
+// and responds with {"from": ..., "to": ..., "data": [...]}, where each data
+// row has the same fields as the matching table in 'typtel export'.
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/charts"
+	"github.com/aayushbajaj/typing-telemetry/internal/export"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+// DefaultAddr is where 'typtel serve' listens unless told otherwise. It is
+// loopback-only: the API has no authentication.
+const DefaultAddr = "127.0.0.1:7777"
+
+// DefaultDays is the range served when a request gives no dates
+const DefaultDays = 30
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// Server handles dashboard and API requests
+type Server struct {
+	store *storage.Store
+	mux   *http.ServeMux
+}
+
+// New creates a server reading from store
+func New(store *storage.Store) *Server {
+	s := &Server{store: store, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("GET /{$}", s.handleDashboard)
+	s.mux.HandleFunc("GET /assets/chart.js", s.handleChartScript)
+	s.mux.HandleFunc("GET /api/today", s.handleToday)
+	s.mux.HandleFunc("GET /api/daily", s.handleTable(export.TableDaily))
+	s.mux.HandleFunc("GET /api/hourly", s.handleTable(export.TableHourly))
+	s.mux.HandleFunc("GET /api/mouse", s.handleTable(export.TableMouse))
+	s.mux.HandleFunc("GET /api/keys", s.handleTable(export.TableKeys))
+	s.mux.HandleFunc("GET /api/tests", s.handleTable(export.TableTests))
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// rangeResponse is the body of every date-range endpoint
+type rangeResponse struct {
+	From string      `json:"from"`
+	To   string      `json:"to"`
+	Data interface{} `json:"data"`
+}
+
+// todayResponse is the body of /api/today
+type todayResponse struct {
+	Date       string  `json:"date"`
+	Keystrokes int64   `json:"keystrokes"`
+	Words      int64   `json:"words"`
+	Clicks     int64   `json:"clicks"`
+	DistancePx float64 `json:"distance_px"`
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(dashboardHTML)
+}
+
+func (s *Server) handleChartScript(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Write([]byte(charts.Script))
+}
+
+func (s *Server) handleToday(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.store.GetTodayStats()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	mouse, err := s.store.GetTodayMouseStats()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, todayResponse{
+		Date:       stats.Date,
+		Keystrokes: stats.Keystrokes,
+		Words:      stats.Words,
+		Clicks:     mouse.ClickCount,
+		DistancePx: mouse.TotalDistance,
+	})
+}
+
+// handleTable serves one export table over the requested date range
+func (s *Server) handleTable(table string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := s.dateRange(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		ds, err := export.Collect(s.store, export.Options{From: from, To: to, Tables: []string{table}})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// Empty ranges encode as [] rather than null
+		var data interface{}
+		switch table {
+		case export.TableDaily:
+			data = nonNil(ds.Daily)
+		case export.TableHourly:
+			data = nonNil(ds.Hourly)
+		case export.TableMouse:
+			data = nonNil(ds.Mouse)
+		case export.TableKeys:
+			data = nonNil(ds.Keys)
+		case export.TableTests:
+			data = nonNil(ds.Tests)
+		}
+
+		writeJSON(w, http.StatusOK, rangeResponse{From: from, To: to, Data: data})
+	}
+}
+
+// dateRange reads the from, to and days query parameters
+func (s *Server) dateRange(r *http.Request) (string, string, error) {
+	q := r.URL.Query()
+	from, to := q.Get("from"), q.Get("to")
+	if err := export.ValidateDate(from); err != nil {
+		return "", "", err
+	}
+	if err := export.ValidateDate(to); err != nil {
+		return "", "", err
+	}
+	if from != "" && to != "" && from > to {
+		return "", "", fmt.Errorf("from %s is after to %s", from, to)
+	}
+
+	// An explicit start wins; otherwise count days back from the end
+	if from != "" {
+		return from, to, nil
+	}
+
+	days := DefaultDays
+	if daysParam := q.Get("days"); daysParam != "" {
+		n, err := strconv.Atoi(daysParam)
+		if err != nil || n < 1 {
+			return "", "", fmt.Errorf("invalid days %q, expected a positive number", daysParam)
+		}
+		days = n
+	}
+
+	if to == "" {
+		to = s.store.Today()
+	}
+	end, _ := time.Parse("2006-01-02", to)
+	return end.AddDate(0, 0, -(days - 1)).Format("2006-01-02"), to, nil
+}
+
+func nonNil[T any](rows []T) []T {
+	if rows == nil {
+		return []T{}
+	}
+	return rows
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/charts"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *storage.Store) {
+	t.Helper()
+	store, err := storage.NewWithPath(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	ts := httptest.NewServer(New(store))
+	t.Cleanup(ts.Close)
+	return ts, store
+}
+
+func getJSON(t *testing.T, url string, wantStatus int, v interface{}) {
+	t.Helper()
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != wantStatus {
+		t.Fatalf("GET %s status = %d, want %d", url, res.StatusCode, wantStatus)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET %s Content-Type = %q, want application/json", url, ct)
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		t.Fatalf("Decoding %s failed: %v", url, err)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	res, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("GET / status = %d, want 200", res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("GET / Content-Type = %q, want text/html", ct)
+	}
+
+	res, err = http.Get(ts.URL + "/nope")
+	if err != nil {
+		t.Fatalf("GET /nope failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /nope status = %d, want 404", res.StatusCode)
+	}
+}
+
+func TestDashboardIsOffline(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	if strings.Contains(string(dashboardHTML), "https://") {
+		t.Error("Dashboard should not load anything from the network")
+	}
+
+	res, err := http.Get(ts.URL + "/assets/chart.js")
+	if err != nil {
+		t.Fatalf("GET /assets/chart.js failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("GET /assets/chart.js status = %d, want 200", res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/javascript") {
+		t.Errorf("GET /assets/chart.js Content-Type = %q, want text/javascript", ct)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if string(body) != charts.Script {
+		t.Error("GET /assets/chart.js should serve the embedded renderer")
+	}
+}
+
+func TestToday(t *testing.T) {
+	ts, store := newTestServer(t)
+	store.RecordKeystroke(0)
+	store.RecordKeystroke(1)
+	store.IncrementWordCount(store.Today())
+	store.RecordMouseClick()
+
+	var body todayResponse
+	getJSON(t, ts.URL+"/api/today", http.StatusOK, &body)
+
+	want := todayResponse{Date: store.Today(), Keystrokes: 2, Words: 1, Clicks: 1}
+	if body != want {
+		t.Errorf("/api/today = %+v, want %+v", body, want)
+	}
+}
+
+func TestRangeEndpoints(t *testing.T) {
+	ts, store := newTestServer(t)
+
+	at := time.Date(2024, 1, 15, 10, 0, 0, 0, time.Local)
+	store.RecordKeystrokeAt(0, at)
+	store.RecordKeystrokeAt(0, at)
+	store.RecordKeystrokeAt(1, at.Add(24*time.Hour))
+	store.AddMouseTotals("2024-01-15", 500, 3, 20)
+	store.RecordTypingTest(storage.TypingTestResult{Timestamp: at, WPM: 85, WordCount: 25})
+
+	tests := []struct {
+		path     string
+		wantRows int
+	}{
+		{"/api/daily?from=2024-01-01&to=2024-01-31", 2},
+		{"/api/daily?from=2024-01-16", 1},
+		{"/api/daily?to=2024-01-15&days=1", 1},
+		{"/api/hourly?from=2024-01-15&to=2024-01-15", 1},
+		{"/api/mouse?from=2024-01-01&to=2024-01-31", 1},
+		{"/api/keys?from=2024-01-01&to=2024-01-31", 2},
+		{"/api/tests?from=2024-01-01&to=2024-01-31", 1},
+		{"/api/daily?from=2023-01-01&to=2023-01-31", 0},
+	}
+
+	for _, tt := range tests {
+		var body struct {
+			From string            `json:"from"`
+			To   string            `json:"to"`
+			Data []json.RawMessage `json:"data"`
+		}
+		getJSON(t, ts.URL+tt.path, http.StatusOK, &body)
+		if body.Data == nil {
+			t.Errorf("%s: data is null, want an array", tt.path)
+		}
+		if len(body.Data) != tt.wantRows {
+			t.Errorf("%s: got %d rows, want %d", tt.path, len(body.Data), tt.wantRows)
+		}
+	}
+
+	// Rows use the export schema
+	var daily struct {
+		Data []struct {
+			Date       string `json:"date"`
+			Keystrokes int64  `json:"keystrokes"`
+		} `json:"data"`
+	}
+	getJSON(t, ts.URL+"/api/daily?from=2024-01-15&to=2024-01-15", http.StatusOK, &daily)
+	if len(daily.Data) != 1 || daily.Data[0].Keystrokes != 2 {
+		t.Errorf("/api/daily = %+v, want 2 keystrokes on 2024-01-15", daily.Data)
+	}
+}
+
+func TestDefaultRange(t *testing.T) {
+	ts, store := newTestServer(t)
+
+	var body rangeResponse
+	getJSON(t, ts.URL+"/api/daily", http.StatusOK, &body)
+
+	today := store.Today()
+	end, _ := time.Parse("2006-01-02", today)
+	wantFrom := end.AddDate(0, 0, -(DefaultDays - 1)).Format("2006-01-02")
+	if body.From != wantFrom || body.To != today {
+		t.Errorf("Default range = %s..%s, want %s..%s", body.From, body.To, wantFrom, today)
+	}
+
+	getJSON(t, ts.URL+"/api/daily?days=7&to=2024-01-10", http.StatusOK, &body)
+	if body.From != "2024-01-04" || body.To != "2024-01-10" {
+		t.Errorf("days=7 range = %s..%s, want 2024-01-04..2024-01-10", body.From, body.To)
+	}
+}
+
+func TestBadRequests(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	for _, path := range []string{
+		"/api/daily?from=yesterday",
+		"/api/hourly?to=2024-13-01",
+		"/api/keys?days=0",
+		"/api/tests?days=many",
+		"/api/mouse?from=2024-02-01&to=2024-01-01",
+	} {
+		var body map[string]string
+		getJSON(t, ts.URL+path, http.StatusBadRequest, &body)
+		if body["error"] == "" {
+			t.Errorf("%s: expected an error message", path)
+		}
+	}
+
+	res, err := http.Post(ts.URL+"/api/daily", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /api/daily status = %d, want 405", res.StatusCode)
+	}
+}
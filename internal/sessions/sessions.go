@@ -0,0 +1,89 @@
+// Package sessions splits activity into sessions of focused work.
+//
+// Every keystroke, mouse movement and click is activity. A session runs from
+// its first activity to its last and ends once nothing happens for the idle
+// gap; the next activity starts a new session. The time between two sessions
+// is a break, so stepping away for longer than the gap (AFK) never counts as
+// active time.
+package sessions
+
+import "time"
+
+// DefaultIdleGap is how long without activity ends a session
+const DefaultIdleGap = 5 * time.Minute
+
+// Session is one stretch of activity
+type Session struct {
+	Start         time.Time
+	End           time.Time // the last activity
+	Keystrokes    int64
+	MouseDistance float64 // pixels
+}
+
+// Duration returns the active time from the first activity to the last
+func (s Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Tracker follows the session in progress. The zero value has none.
+type Tracker struct {
+	current Session
+	open    bool
+}
+
+// Add records activity at t: keystrokes typed and pixels the mouse moved.
+// It reports whether t started a new session because it's more than gap
+// away from the session in progress. Activity within the session, e.g.
+// delivered late, is added to it.
+func (tr *Tracker) Add(t time.Time, gap time.Duration, keystrokes int64, distance float64) bool {
+	started := !tr.open || t.Sub(tr.current.End) > gap || tr.current.Start.Sub(t) > gap
+	if started {
+		tr.current = Session{Start: t, End: t}
+		tr.open = true
+	}
+	if t.After(tr.current.End) {
+		tr.current.End = t
+	}
+	if t.Before(tr.current.Start) {
+		tr.current.Start = t
+	}
+	tr.current.Keystrokes += keystrokes
+	tr.current.MouseDistance += distance
+	return started
+}
+
+// Resume continues s, e.g. the last session stored before a restart, if
+// activity follows within the idle gap
+func (tr *Tracker) Resume(s Session) {
+	tr.current, tr.open = s, true
+}
+
+// Current returns the session in progress, if any
+func (tr *Tracker) Current() (Session, bool) {
+	return tr.current, tr.open
+}
+
+// Summary describes the sessions of a day
+type Summary struct {
+	Sessions int
+	Active   time.Duration // total time in sessions
+	Longest  time.Duration // longest focus session
+	Breaks   int           // gaps between sessions
+}
+
+// Summarize sums up sessions
+func Summarize(sessions []Session) Summary {
+	var s Summary
+	for _, session := range sessions {
+		d := session.Duration()
+		s.Active += d
+		if d > s.Longest {
+			s.Longest = d
+		}
+	}
+	s.Sessions = len(sessions)
+	if s.Sessions > 1 {
+		s.Breaks = s.Sessions - 1
+	}
+	return s
+}
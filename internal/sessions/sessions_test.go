@@ -0,0 +1,109 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	base := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return base.Add(d) }
+
+	type activity struct {
+		at       time.Duration
+		keys     int64
+		distance float64
+	}
+	tests := []struct {
+		name     string
+		activity []activity
+		started  []bool
+		want     Session
+	}{
+		{
+			name:     "first activity starts a session",
+			activity: []activity{{0, 1, 0}},
+			started:  []bool{true},
+			want:     Session{Start: at(0), End: at(0), Keystrokes: 1},
+		},
+		{
+			name:     "activity within the gap extends it",
+			activity: []activity{{0, 1, 0}, {4 * time.Minute, 0, 120}, {8 * time.Minute, 1, 0}},
+			started:  []bool{true, false, false},
+			want:     Session{Start: at(0), End: at(8 * time.Minute), Keystrokes: 2, MouseDistance: 120},
+		},
+		{
+			name:     "exactly the gap still continues",
+			activity: []activity{{0, 1, 0}, {5 * time.Minute, 1, 0}},
+			started:  []bool{true, false},
+			want:     Session{Start: at(0), End: at(5 * time.Minute), Keystrokes: 2},
+		},
+		{
+			name:     "a longer pause starts a new session",
+			activity: []activity{{0, 1, 0}, {10 * time.Minute, 1, 0}, {11 * time.Minute, 0, 50}},
+			started:  []bool{true, true, false},
+			want:     Session{Start: at(10 * time.Minute), End: at(11 * time.Minute), Keystrokes: 1, MouseDistance: 50},
+		},
+		{
+			name:     "late activity doesn't move the end back",
+			activity: []activity{{0, 1, 0}, {2 * time.Minute, 1, 0}, {time.Minute, 1, 0}},
+			started:  []bool{true, false, false},
+			want:     Session{Start: at(0), End: at(2 * time.Minute), Keystrokes: 3},
+		},
+		{
+			name:     "much older activity starts its own session",
+			activity: []activity{{time.Hour, 1, 0}, {0, 1, 0}},
+			started:  []bool{true, true},
+			want:     Session{Start: at(0), End: at(0), Keystrokes: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tr Tracker
+			for i, a := range tt.activity {
+				if got := tr.Add(at(a.at), DefaultIdleGap, a.keys, a.distance); got != tt.started[i] {
+					t.Errorf("Add #%d started = %v, want %v", i, got, tt.started[i])
+				}
+			}
+			got, open := tr.Current()
+			if !open || got != tt.want {
+				t.Errorf("Current() = %+v, %v; want %+v", got, open, tt.want)
+			}
+		})
+	}
+}
+
+func TestResume(t *testing.T) {
+	end := time.Date(2024, 5, 2, 9, 30, 0, 0, time.UTC)
+	last := Session{Start: end.Add(-time.Hour), End: end, Keystrokes: 900}
+
+	var tr Tracker
+	if _, open := tr.Current(); open {
+		t.Fatal("A new tracker shouldn't have a session")
+	}
+	tr.Resume(last)
+	if tr.Add(end.Add(time.Minute), DefaultIdleGap, 1, 0) {
+		t.Error("Activity soon after a resumed session should continue it")
+	}
+	if got, _ := tr.Current(); got.Start != last.Start || got.Keystrokes != 901 {
+		t.Errorf("Current() = %+v, want the resumed session extended", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	base := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	session := func(start, minutes int) Session {
+		s := base.Add(time.Duration(start) * time.Minute)
+		return Session{Start: s, End: s.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	if got := Summarize(nil); got != (Summary{}) {
+		t.Errorf("Summarize(nil) = %+v, want zero", got)
+	}
+	got := Summarize([]Session{session(0, 50), session(60, 25), session(120, 0)})
+	want := Summary{Sessions: 3, Active: 75 * time.Minute, Longest: 50 * time.Minute, Breaks: 2}
+	if got != want {
+		t.Errorf("Summarize = %+v, want %+v", got, want)
+	}
+}
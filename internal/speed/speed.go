@@ -0,0 +1,146 @@
+// Package speed estimates typing speed from everyday typing.
+//
+// Keystrokes are grouped into bursts of continuous typing: a burst lasts as
+// long as text keys (letters, digits, punctuation and whitespace) and
+// Backspace follow each other within Pause. Anything else, such as arrows,
+// shortcuts or a longer pause, ends it. Only the time inside bursts counts,
+// so thinking, reading and mousing never drag the speed down. Speeds are in
+// words per minute with the usual five characters to a word; Backspace keeps
+// a burst going but types nothing, so corrections lower the speed.
+package speed
+
+import (
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+const (
+	// Pause is the longest gap between keys inside a burst
+	Pause = 2 * time.Second
+	// MinBurstChars is how many characters a burst needs to be measured;
+	// shorter ones are too noisy to tell a speed from
+	MinBurstChars = 10
+	// Window is how much recent typing time Current averages over
+	Window = time.Minute
+	// CharsPerWord converts characters to words
+	CharsPerWord = 5
+)
+
+// Burst is a stretch of continuous typing
+type Burst struct {
+	Start time.Time // the first key
+	End   time.Time // the last key
+	Chars int       // characters typed after the first key
+}
+
+// Duration returns how long the burst lasted
+func (b Burst) Duration() time.Duration {
+	return b.End.Sub(b.Start)
+}
+
+// WPM returns the burst's typing speed. Chars counts the keys after the
+// first, which started the clock, so it matches the time they took.
+func (b Burst) WPM() float64 {
+	return wpm(b.Chars, b.Duration())
+}
+
+func wpm(chars int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(chars) / CharsPerWord / d.Minutes()
+}
+
+// step is the gap before a key inside a burst and what it typed
+type step struct {
+	gap   time.Duration
+	chars int
+}
+
+// Estimator splits keystrokes into bursts and keeps a rolling speed over the
+// most recent typing. The zero value is ready to use; it isn't safe for
+// concurrent use.
+type Estimator struct {
+	burst  Burst
+	typing bool      // a burst is open
+	last   time.Time // the burst's last key
+
+	recent  []step // the last Window of typing, oldest first
+	elapsed time.Duration
+	chars   int
+}
+
+// Press feeds a key pressed at t with mods held. When the key ends a burst
+// long enough to measure, that burst is returned with ok set.
+func (e *Estimator) Press(t time.Time, code int, mods keyboard.Modifiers) (b Burst, ok bool) {
+	if keyboard.IsModifier(code) {
+		return Burst{}, false
+	}
+	text := keyboard.IsText(code, mods)
+	backspace := code == keyboard.KeyDelete && mods&(keyboard.ModCommand|keyboard.ModControl) == 0
+	if !text && !backspace {
+		return e.end()
+	}
+
+	if e.typing && !t.Before(e.last) && t.Sub(e.last) <= Pause {
+		s := step{gap: t.Sub(e.last)}
+		if text {
+			s.chars = 1
+		}
+		e.burst.End = t
+		e.burst.Chars += s.chars
+		e.last = t
+		e.push(s)
+		return Burst{}, false
+	}
+
+	b, ok = e.end()
+	e.burst = Burst{Start: t, End: t}
+	e.typing = true
+	e.last = t
+	return b, ok
+}
+
+// Flush ends the open burst if nothing has been typed for longer than Pause
+// by now, returning it if it's long enough to measure
+func (e *Estimator) Flush(now time.Time) (Burst, bool) {
+	if !e.typing || now.Sub(e.last) <= Pause {
+		return Burst{}, false
+	}
+	return e.end()
+}
+
+// Current returns the typing speed over the last Window of typing, pauses
+// left out, or 0 until enough has been typed to tell
+func (e *Estimator) Current() float64 {
+	if e.chars < MinBurstChars {
+		return 0
+	}
+	return wpm(e.chars, e.elapsed)
+}
+
+// end closes the open burst
+func (e *Estimator) end() (Burst, bool) {
+	if !e.typing {
+		return Burst{}, false
+	}
+	e.typing = false
+	if e.burst.Chars < MinBurstChars {
+		return Burst{}, false
+	}
+	return e.burst, true
+}
+
+// push adds a step to the rolling window, dropping the oldest steps beyond
+// Window
+func (e *Estimator) push(s step) {
+	e.recent = append(e.recent, s)
+	e.elapsed += s.gap
+	e.chars += s.chars
+	for len(e.recent) > 1 && e.elapsed-e.recent[0].gap >= Window {
+		e.elapsed -= e.recent[0].gap
+		e.chars -= e.recent[0].chars
+		e.recent = e.recent[1:]
+	}
+}
@@ -0,0 +1,169 @@
+package speed
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+var start = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+// press is a key pressed gap after the previous one
+type press struct {
+	gap  time.Duration
+	code int
+	mods keyboard.Modifiers
+}
+
+// typing returns n letter presses gap apart
+func typing(n int, gap time.Duration) []press {
+	p := make([]press, n)
+	for i := range p {
+		p[i] = press{gap, keyboard.KeyA, 0}
+	}
+	return p
+}
+
+func seq(parts ...[]press) []press {
+	var p []press
+	for _, part := range parts {
+		p = append(p, part...)
+	}
+	return p
+}
+
+func key(gap time.Duration, code int, mods keyboard.Modifiers) []press {
+	return []press{{gap, code, mods}}
+}
+
+// feed presses keys from start, returning the bursts ended along the way and
+// the time of the last key
+func feed(e *Estimator, presses []press) ([]Burst, time.Time) {
+	var bursts []Burst
+	t := start
+	for _, p := range presses {
+		t = t.Add(p.gap)
+		if b, ok := e.Press(t, p.code, p.mods); ok {
+			bursts = append(bursts, b)
+		}
+	}
+	return bursts, t
+}
+
+func TestBurstWPM(t *testing.T) {
+	b := Burst{Start: start, End: start.Add(12 * time.Second), Chars: 60}
+	if got := b.WPM(); got != 60 {
+		t.Errorf("WPM() = %v, want 60", got)
+	}
+	if got := (Burst{Start: start, End: start, Chars: 10}).WPM(); got != 0 {
+		t.Errorf("WPM() of an instant = %v, want 0", got)
+	}
+}
+
+func TestEstimatorBursts(t *testing.T) {
+	ms := time.Millisecond
+
+	tests := []struct {
+		name    string
+		presses []press
+		want    []Burst // Start and End as offsets from start
+	}{
+		{
+			"pause ends burst",
+			seq(typing(21, 200*ms), typing(1, 3*time.Second)),
+			[]Burst{{start.Add(200 * ms), start.Add(4200 * ms), 20}},
+		},
+		{
+			"pause within limit",
+			seq(typing(11, 200*ms), typing(10, Pause), key(Pause+ms, keyboard.KeyA, 0)),
+			[]Burst{{start.Add(200 * ms), start.Add(2200*ms + 10*Pause), 20}},
+		},
+		{
+			"too short to measure",
+			seq(typing(5, 200*ms), typing(1, 5*time.Second)),
+			nil,
+		},
+		{
+			"arrow ends burst",
+			seq(typing(15, 100*ms), key(100*ms, keyboard.KeyLeft, 0)),
+			[]Burst{{start.Add(100 * ms), start.Add(1500 * ms), 14}},
+		},
+		{
+			"shortcut ends burst",
+			seq(typing(15, 100*ms), key(100*ms, keyboard.KeyS, keyboard.ModCommand)),
+			[]Burst{{start.Add(100 * ms), start.Add(1500 * ms), 14}},
+		},
+		{
+			"backspace keeps burst",
+			seq(typing(6, 100*ms), key(100*ms, keyboard.KeyDelete, 0), typing(6, 100*ms), key(100*ms, keyboard.KeyEscape, 0)),
+			[]Burst{{start.Add(100 * ms), start.Add(1300 * ms), 11}},
+		},
+		{
+			"modifiers ignored",
+			seq(typing(6, 100*ms), key(100*ms, keyboard.KeyShift, 0), typing(6, 100*ms), key(100*ms, keyboard.KeyEscape, 0)),
+			[]Burst{{start.Add(100 * ms), start.Add(1300 * ms), 11}},
+		},
+		{
+			"whitespace and punctuation count",
+			seq(typing(6, 100*ms), key(100*ms, keyboard.KeySpace, 0), key(100*ms, keyboard.KeyComma, 0),
+				key(100*ms, keyboard.KeyReturn, keyboard.ModShift), typing(4, 100*ms), key(100*ms, keyboard.KeyEscape, 0)),
+			[]Burst{{start.Add(100 * ms), start.Add(1300 * ms), 12}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e Estimator
+			got, _ := feed(&e, tt.presses)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d bursts %v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) || got[i].Chars != tt.want[i].Chars {
+					t.Errorf("burst %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEstimatorFlush(t *testing.T) {
+	var e Estimator
+	_, last := feed(&e, typing(21, 100*time.Millisecond))
+
+	if _, ok := e.Flush(last.Add(Pause)); ok {
+		t.Error("Flush ended the burst before the pause was over")
+	}
+	b, ok := e.Flush(last.Add(Pause + time.Millisecond))
+	if !ok {
+		t.Fatal("Flush didn't end the burst after a pause")
+	}
+	if b.Chars != 20 || !b.End.Equal(last) {
+		t.Errorf("burst = %+v, want 20 chars ending at the last key", b)
+	}
+	if _, ok := e.Flush(last.Add(time.Hour)); ok {
+		t.Error("Flush returned the same burst twice")
+	}
+}
+
+func TestEstimatorCurrent(t *testing.T) {
+	var e Estimator
+	if got := e.Current(); got != 0 {
+		t.Errorf("Current() before typing = %v, want 0", got)
+	}
+
+	// 100ms a key is 120 WPM; pauses between bursts don't count
+	feed(&e, seq(typing(20, 100*time.Millisecond), typing(20, time.Minute), typing(20, 100*time.Millisecond)))
+	if got := e.Current(); math.Abs(got-120) > 1e-9 {
+		t.Errorf("Current() = %v, want 120", got)
+	}
+
+	// A minute at 200ms a key (60 WPM) pushes the earlier typing out
+	e = Estimator{}
+	feed(&e, seq(typing(100, 100*time.Millisecond), typing(400, 200*time.Millisecond)))
+	if got := e.Current(); math.Abs(got-60) > 1e-9 {
+		t.Errorf("Current() after slowing down = %v, want 60", got)
+	}
+}
@@ -0,0 +1,235 @@
+// Package statusbar formats today's totals for status bars: the macOS menu
+// bar title, and waybar, polybar, i3blocks and tmux through 'typtel today
+// --format'.
+package statusbar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/goals"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	"github.com/aayushbajaj/typing-telemetry/pkg/stats"
+)
+
+// Activity classes, for styling in waybar
+const (
+	ClassIdle   = "idle"   // nothing typed yet today
+	ClassActive = "active" // something typed today
+)
+
+// Status is today's totals as handed to format templates
+type Status struct {
+	Date           string
+	Keystrokes     int64
+	Words          int64
+	Clicks         int64
+	DistancePixels float64
+	Distance       string  // in the configured unit, e.g. "1.2mi" or "34 cars"
+	WPM            float64 // typing speed; 0 until something has been measured
+	KeystrokeGoal  int64   // 0 without a goal
+	WordGoal       int64   // 0 without a goal
+	Streak         int     // days in a row goals were met (see internal/goals)
+	Title          string  // what the macOS menu bar shows
+	Tooltip        string  // today's totals spelled out
+	Class          string  // ClassIdle or ClassActive
+}
+
+// Load reads today's totals and formats them the way the menu bar is set up
+func Load(store *storage.Store, units report.Units) (*Status, error) {
+	today, err := store.GetTodayStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get today's stats: %w", err)
+	}
+	mouse, err := store.GetTodayMouseStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get today's mouse stats: %w", err)
+	}
+	speed, err := store.GetSpeed(today.Date, today.Date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get today's typing speed: %w", err)
+	}
+	goals, err := store.GetGoalStatus(units.Feet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+	show := store.GetMenubarSettings()
+	s := New(today, mouse, speed.MedianWPM, units, show)
+	s.SetGoals(goals, show)
+	return s, nil
+}
+
+// New builds a status from today's stats; mouse may be nil. wpm is the
+// typing speed to show: today's median, or the menu bar's live estimate.
+func New(today *storage.DailyStats, mouse *storage.MouseDailyStats, wpm float64, units report.Units, show storage.MenubarSettings) *Status {
+	s := &Status{
+		Date:       today.Date,
+		Keystrokes: today.Keystrokes,
+		Words:      today.Words,
+		WPM:        wpm,
+		Class:      ClassIdle,
+	}
+	if mouse != nil {
+		s.Clicks = mouse.ClickCount
+		s.DistancePixels = mouse.TotalDistance
+	}
+	s.Distance = units.FormatDistance(s.DistancePixels)
+	if s.Keystrokes > 0 {
+		s.Class = ClassActive
+	}
+	s.render(show)
+	return s
+}
+
+// SetGoals adds progress towards today's keystroke and word goals and the
+// streak, re-rendering the title and tooltip
+func (s *Status) SetGoals(g *storage.GoalStatus, show storage.MenubarSettings) {
+	s.KeystrokeGoal, s.WordGoal = 0, 0
+	for _, t := range g.Targets {
+		switch t.Metric {
+		case goals.Keystrokes:
+			s.KeystrokeGoal = int64(t.Goal)
+		case goals.Words:
+			s.WordGoal = int64(t.Goal)
+		}
+	}
+	s.Streak = g.Streak.Current
+	s.render(show)
+}
+
+// render fills in the title and tooltip
+func (s *Status) render(show storage.MenubarSettings) {
+	s.Title = Title(s, show)
+	s.Tooltip = fmt.Sprintf("Today: %s keystrokes (%s words)\nMouse: %s clicks, %s",
+		report.FormatAbsolute(s.Keystrokes), report.FormatAbsolute(s.Words),
+		report.FormatAbsolute(s.Clicks), s.Distance)
+	if s.WPM > 0 {
+		s.Tooltip += fmt.Sprintf("\nTyping speed: %.0f WPM", s.WPM)
+	}
+	if s.Streak > 0 {
+		s.Tooltip += fmt.Sprintf("\nGoals met %s in a row", days(s.Streak))
+	}
+}
+
+// Title joins the totals the menu bar settings ask for, e.g.
+// "⌨️12,345 | 2,100w". With goals shown, totals with a goal show progress
+// towards it and the streak follows, e.g. "7,450 / 10,000 ⌨️ · 🔥 12-day
+// streak".
+func Title(s *Status, show storage.MenubarSettings) string {
+	var parts []string
+	if show.ShowKeystrokes {
+		if show.ShowGoals && s.KeystrokeGoal > 0 {
+			parts = append(parts, fmt.Sprintf("%s / %s ⌨️", report.FormatAbsolute(s.Keystrokes), report.FormatAbsolute(s.KeystrokeGoal)))
+		} else {
+			parts = append(parts, fmt.Sprintf("⌨️%s", report.FormatAbsolute(s.Keystrokes)))
+		}
+	}
+	if show.ShowWords {
+		if show.ShowGoals && s.WordGoal > 0 {
+			parts = append(parts, fmt.Sprintf("%s / %sw", report.FormatAbsolute(s.Words), report.FormatAbsolute(s.WordGoal)))
+		} else {
+			parts = append(parts, fmt.Sprintf("%sw", report.FormatAbsolute(s.Words)))
+		}
+	}
+	if show.ShowClicks {
+		parts = append(parts, fmt.Sprintf("🖱️%s", report.FormatAbsolute(s.Clicks)))
+	}
+	if show.ShowDistance && s.DistancePixels > 0 {
+		parts = append(parts, s.Distance)
+	}
+	if show.ShowWPM && s.WPM > 0 {
+		parts = append(parts, fmt.Sprintf("%.0fwpm", s.WPM))
+	}
+
+	title := "⌨️"
+	if len(parts) > 0 {
+		title = strings.Join(parts, " | ")
+	}
+	if show.ShowGoals && s.Streak > 0 {
+		title += fmt.Sprintf(" · 🔥 %d-day streak", s.Streak)
+	}
+	return title
+}
+
+// days formats a number of days, e.g. "1 day" or "12 days"
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// Presets are the built-in formats, by name
+var Presets = map[string]string{
+	// The bare keystroke count, for scripts
+	"plain": "{{.Keystrokes}}\n",
+	// waybar custom module with "return-type": "json"
+	"waybar": `{"text":{{json .Title}},"tooltip":{{json .Tooltip}},"class":{{json .Class}}}` + "\n",
+	// polybar custom/script module
+	"polybar": "{{.Title}}\n",
+	// i3blocks: full text, then short text
+	"i3blocks": "{{.Title}}\n{{short .Keystrokes}}\n",
+	// tmux status-left or status-right via #(typtel today --format tmux)
+	"tmux": "{{tmux .Title}}\n",
+}
+
+// DefaultPreset is used when no format is given
+const DefaultPreset = "plain"
+
+// PresetNames lists the presets alphabetically
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var funcs = template.FuncMap{
+	// json quotes a value as JSON
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// abs formats a count in full, e.g. 12,345
+	"abs": report.FormatAbsolute,
+	// short formats a count compactly, e.g. 12.3K
+	"short": stats.FormatKeystrokeCount,
+	// tmux escapes text for a tmux status line
+	"tmux": func(s string) string { return strings.ReplaceAll(s, "#", "##") },
+}
+
+// Parse compiles a preset name or a Go template over Status. Templates get a
+// trailing newline if they lack one.
+func Parse(format string) (*template.Template, error) {
+	if format == "" {
+		format = DefaultPreset
+	}
+	text, ok := Presets[format]
+	if !ok {
+		text = format
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+	}
+	tmpl, err := template.New("status").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Write renders status with a preset name or Go template
+func Write(w io.Writer, format string, s *Status) error {
+	tmpl, err := Parse(format)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, s)
+}
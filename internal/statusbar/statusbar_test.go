@@ -0,0 +1,188 @@
+package statusbar
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/goals"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/speed"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+)
+
+func testStatus(show storage.MenubarSettings) *Status {
+	return New(
+		&storage.DailyStats{Date: "2024-03-01", Keystrokes: 12345, Words: 2100},
+		&storage.MouseDailyStats{Date: "2024-03-01", ClickCount: 456, TotalDistance: 1200 * 100 * 12},
+		72.4,
+		report.Units{Distance: storage.DistanceUnitFeet},
+		show,
+	)
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		show storage.MenubarSettings
+		want string
+	}{
+		{"defaults", storage.MenubarSettings{ShowKeystrokes: true, ShowWords: true}, "⌨️12,345 | 2,100w"},
+		{"without speed", storage.MenubarSettings{ShowKeystrokes: true, ShowWords: true, ShowClicks: true, ShowDistance: true}, "⌨️12,345 | 2,100w | 🖱️456 | 1200ft"},
+		{"everything", storage.MenubarSettings{ShowKeystrokes: true, ShowWords: true, ShowClicks: true, ShowDistance: true, ShowWPM: true}, "⌨️12,345 | 2,100w | 🖱️456 | 1200ft | 72wpm"},
+		{"clicks only", storage.MenubarSettings{ShowClicks: true}, "🖱️456"},
+		{"nothing", storage.MenubarSettings{}, "⌨️"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testStatus(tt.show).Title; got != tt.want {
+				t.Errorf("Title = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitleWithGoals(t *testing.T) {
+	g := &storage.GoalStatus{
+		Targets: []goals.Target{
+			{Metric: goals.Keystrokes, Goal: 10000, Value: 12345, Met: true},
+			{Metric: goals.TypingTests, Goal: 1},
+		},
+		Streak: goals.Streak{Current: 12, Longest: 30, TodayMet: true},
+	}
+	tests := []struct {
+		name string
+		show storage.MenubarSettings
+		want string
+	}{
+		{"keystrokes", storage.MenubarSettings{ShowKeystrokes: true, ShowGoals: true}, "12,345 / 10,000 ⌨️ · 🔥 12-day streak"},
+		{"words without a goal", storage.MenubarSettings{ShowKeystrokes: true, ShowWords: true, ShowGoals: true}, "12,345 / 10,000 ⌨️ | 2,100w · 🔥 12-day streak"},
+		{"goals hidden", storage.MenubarSettings{ShowKeystrokes: true}, "⌨️12,345"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testStatus(tt.show)
+			s.SetGoals(g, tt.show)
+			if s.Title != tt.want {
+				t.Errorf("Title = %q, want %q", s.Title, tt.want)
+			}
+		})
+	}
+
+	s := testStatus(storage.MenubarSettings{})
+	s.SetGoals(g, storage.MenubarSettings{})
+	if !strings.HasSuffix(s.Tooltip, "\nGoals met 12 days in a row") {
+		t.Errorf("Tooltip = %q, want the streak", s.Tooltip)
+	}
+}
+
+func TestTitleHidesZeroDistanceAndSpeed(t *testing.T) {
+	s := New(&storage.DailyStats{}, nil, 0, report.Units{}, storage.MenubarSettings{ShowDistance: true, ShowWPM: true})
+	if s.Title != "⌨️" {
+		t.Errorf("Title = %q, want bare keyboard with no distance or speed", s.Title)
+	}
+	if s.Class != ClassIdle {
+		t.Errorf("Class = %q, want %q", s.Class, ClassIdle)
+	}
+}
+
+func TestPresets(t *testing.T) {
+	s := testStatus(storage.MenubarSettings{ShowKeystrokes: true, ShowWords: true})
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", "12345\n"},
+		{"plain", "12345\n"},
+		{"polybar", "⌨️12,345 | 2,100w\n"},
+		{"i3blocks", "⌨️12,345 | 2,100w\n12.3K\n"},
+		{"tmux", "⌨️12,345 | 2,100w\n"},
+		{"{{abs .Keystrokes}} keys, {{.Distance}}", "12,345 keys, 1200ft\n"},
+		{"{{.Words}}\n", "2100\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b strings.Builder
+			if err := Write(&b, tt.format, s); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("Write(%q) = %q, want %q", tt.format, b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWaybar(t *testing.T) {
+	s := testStatus(storage.MenubarSettings{ShowKeystrokes: true})
+	var b strings.Builder
+	if err := Write(&b, "waybar", s); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var out struct {
+		Text    string `json:"text"`
+		Tooltip string `json:"tooltip"`
+		Class   string `json:"class"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &out); err != nil {
+		t.Fatalf("waybar output is not JSON: %v\n%s", err, b.String())
+	}
+	if out.Text != "⌨️12,345" {
+		t.Errorf("text = %q", out.Text)
+	}
+	if out.Tooltip != "Today: 12,345 keystrokes (2,100 words)\nMouse: 456 clicks, 1200ft\nTyping speed: 72 WPM" {
+		t.Errorf("tooltip = %q", out.Tooltip)
+	}
+	if out.Class != ClassActive {
+		t.Errorf("class = %q, want %q", out.Class, ClassActive)
+	}
+}
+
+func TestTmuxEscapes(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, "{{tmux .Date}}", &Status{Date: "#1"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if b.String() != "##1\n" {
+		t.Errorf("tmux escape = %q, want %q", b.String(), "##1\n")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse("{{"); err == nil {
+		t.Error("Expected an error for an unclosed action")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	store, err := storage.NewWithPath(filepath.Join(t.TempDir(), "typtel.db"))
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.AddDailyTotals(store.Today(), 100, 20); err != nil {
+		t.Fatalf("AddDailyTotals failed: %v", err)
+	}
+	now := time.Now()
+	if err := store.RecordTypingBurst(speed.Burst{Start: now, End: now.Add(12 * time.Second), Chars: 60}); err != nil {
+		t.Fatalf("RecordTypingBurst failed: %v", err)
+	}
+
+	s, err := Load(store, report.Units{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if s.Keystrokes != 100 || s.Words != 20 || s.Date != store.Today() {
+		t.Errorf("Load() = %+v", s)
+	}
+	if s.Title != "⌨️100 | 20w" {
+		t.Errorf("Title = %q, want the default menu bar title", s.Title)
+	}
+	if s.WPM != 60 {
+		t.Errorf("WPM = %v, want today's median of 60", s.WPM)
+	}
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+)
+
+// stillWeekDays is how many tracked days in a row make a week of mouse travel
+const stillWeekDays = 7
+
+// nightEndHour ends the hours a night owl session begins in, from midnight
+const nightEndHour = 4
+
+// GetAchievementStats measures what achievements are judged by. feet
+// converts mouse distance from pixels.
+func (s *Store) GetAchievementStats(feet func(pixels float64) float64) (achievements.Stats, error) {
+	stats := achievements.Stats{}
+
+	var keystrokes int64
+	if err := s.db.QueryRow("SELECT COALESCE(SUM(keystrokes), 0) FROM daily_summary").Scan(&keystrokes); err != nil {
+		return nil, fmt.Errorf("failed to count keystrokes: %w", err)
+	}
+	stats[achievements.TotalKeystrokes] = float64(keystrokes)
+
+	var wpm sql.NullFloat64
+	if err := s.db.QueryRow("SELECT MAX(wpm) FROM typing_tests").Scan(&wpm); err != nil {
+		return nil, fmt.Errorf("failed to get best typing test: %w", err)
+	}
+	if wpm.Valid {
+		stats[achievements.BestTestWPM] = wpm.Float64
+	}
+
+	if pixels, ok, err := s.stillestWeek(); err != nil {
+		return nil, err
+	} else if ok {
+		stats[achievements.StillestWeekFeet] = feet(pixels)
+	}
+
+	goals, err := s.GetGoalStatus(feet)
+	if err != nil {
+		return nil, err
+	}
+	stats[achievements.LongestStreak] = float64(goals.Streak.Longest)
+
+	// Sessions are bucketed by their start in the timezone they began in
+	var night sql.NullInt64
+	err = s.db.QueryRow(`
+		SELECT MAX(end_ts - start_ts) FROM sessions
+		WHERE ((start_ts / 1000 + COALESCE(tz_offset, 0)) % 86400) < ?
+	`, nightEndHour*3600).Scan(&night)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get night sessions: %w", err)
+	}
+	if night.Valid {
+		stats[achievements.LongestNightSession] = minutes(time.Duration(night.Int64) * time.Millisecond)
+	}
+
+	return stats, nil
+}
+
+// stillestWeek returns the least mouse travel in pixels over stillWeekDays
+// days in a row with mouse tracking on, and whether there were that many
+func (s *Store) stillestWeek() (float64, bool, error) {
+	rows, err := s.db.Query("SELECT date, total_distance FROM mouse_daily WHERE movement_count > 0 ORDER BY date")
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get mouse days: %w", err)
+	}
+	defer rows.Close()
+
+	var window []float64 // distances of the run of consecutive days so far
+	var prev time.Time
+	best, found := 0.0, false
+	for rows.Next() {
+		var date string
+		var distance float64
+		if err := rows.Scan(&date, &distance); err != nil {
+			return 0, false, err
+		}
+		day, err := time.Parse(dateLayout, date)
+		if err != nil {
+			continue
+		}
+		if prev.IsZero() || !day.Equal(prev.AddDate(0, 0, 1)) {
+			window = window[:0]
+		}
+		prev = day
+
+		window = append(window, distance)
+		if len(window) > stillWeekDays {
+			window = window[1:]
+		}
+		if len(window) == stillWeekDays {
+			var sum float64
+			for _, d := range window {
+				sum += d
+			}
+			if !found || sum < best {
+				best, found = sum, true
+			}
+		}
+	}
+	return best, found, rows.Err()
+}
+
+// getUnlocked returns when each unlocked achievement was unlocked, by ID
+func (s *Store) getUnlocked() (map[string]time.Time, error) {
+	rows, err := s.db.Query("SELECT id, unlocked_ts FROM achievements")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get achievements: %w", err)
+	}
+	defer rows.Close()
+
+	unlocked := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var ts int64
+		if err := rows.Scan(&id, &ts); err != nil {
+			return nil, err
+		}
+		unlocked[id] = time.UnixMilli(ts).UTC()
+	}
+	return unlocked, rows.Err()
+}
+
+// GetAchievements returns every achievement and whether it's unlocked.
+// Achievements met since the last UnlockAchievements show as unlocked
+// without a time.
+func (s *Store) GetAchievements(feet func(pixels float64) float64) ([]achievements.Status, error) {
+	stats, err := s.GetAchievementStats(feet)
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := s.getUnlocked()
+	if err != nil {
+		return nil, err
+	}
+	return achievements.Evaluate(stats, unlocked), nil
+}
+
+// UnlockAchievements records the achievements met but not yet unlocked as
+// unlocked at now, and returns them
+func (s *Store) UnlockAchievements(feet func(pixels float64) float64, now time.Time) ([]achievements.Achievement, error) {
+	stats, err := s.GetAchievementStats(feet)
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := s.getUnlocked()
+	if err != nil {
+		return nil, err
+	}
+
+	newly := achievements.Newly(stats, unlocked)
+	for _, a := range newly {
+		if _, err := s.db.Exec("INSERT OR IGNORE INTO achievements (id, unlocked_ts) VALUES (?, ?)", a.ID, now.UnixMilli()); err != nil {
+			return nil, fmt.Errorf("failed to unlock %s: %w", a.ID, err)
+		}
+	}
+	return newly, nil
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+)
+
+func TestAchievements(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	pixels := func(p float64) float64 { return p }
+
+	// Nothing is measured in an empty database but keystrokes and streaks
+	stats, err := store.GetAchievementStats(pixels)
+	if err != nil {
+		t.Fatalf("GetAchievementStats failed: %v", err)
+	}
+	if len(stats) != 2 || stats[achievements.TotalKeystrokes] != 0 {
+		t.Errorf("Empty stats = %v", stats)
+	}
+
+	if err := store.AddDailyTotals("2024-05-01", 999999, 0); err != nil {
+		t.Fatalf("AddDailyTotals failed: %v", err)
+	}
+	if err := store.RecordTypingTest(TypingTestResult{Timestamp: time.Now(), WPM: 101.5}); err != nil {
+		t.Fatalf("RecordTypingTest failed: %v", err)
+	}
+	// Six quiet days, a gap, then seven days of 20px a day
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 14; i++ {
+		if i == 6 {
+			continue
+		}
+		distance := 20.0
+		if i < 6 {
+			distance = 1
+		}
+		if err := store.AddMouseTotals(day.AddDate(0, 0, i).Format(dateLayout), distance, 0, 10); err != nil {
+			t.Fatalf("AddMouseTotals failed: %v", err)
+		}
+	}
+	// 40 minutes from 00:30 local time, 23:30 UTC
+	start := time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)
+	if _, err := store.db.Exec(
+		"INSERT INTO sessions (start_ts, end_ts, tz_offset, date, keystrokes) VALUES (?, ?, 3600, '2024-05-02', 500)",
+		start.UnixMilli(), start.Add(40*time.Minute).UnixMilli(),
+	); err != nil {
+		t.Fatalf("Inserting a session failed: %v", err)
+	}
+
+	stats, err = store.GetAchievementStats(pixels)
+	if err != nil {
+		t.Fatalf("GetAchievementStats failed: %v", err)
+	}
+	want := achievements.Stats{
+		achievements.TotalKeystrokes:     999999,
+		achievements.BestTestWPM:         101.5,
+		achievements.StillestWeekFeet:    140,
+		achievements.LongestStreak:       0,
+		achievements.LongestNightSession: 40,
+	}
+	if len(stats) != len(want) {
+		t.Errorf("GetAchievementStats = %v, want %v", stats, want)
+	}
+	for stat, v := range want {
+		if stats[stat] != v {
+			t.Errorf("%s = %v, want %v", stat, stats[stat], v)
+		}
+	}
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	newly, err := store.UnlockAchievements(pixels, now)
+	if err != nil {
+		t.Fatalf("UnlockAchievements failed: %v", err)
+	}
+	if len(newly) != 2 || newly[0].ID != "test-100wpm" || newly[1].ID != "night-owl" {
+		t.Errorf("UnlockAchievements = %+v, want the typing test and night owl", newly)
+	}
+
+	// One more keystroke makes a million, unlocked later
+	if err := store.AddDailyTotals("2024-05-02", 1, 0); err != nil {
+		t.Fatalf("AddDailyTotals failed: %v", err)
+	}
+	statuses, err := store.GetAchievements(pixels)
+	if err != nil {
+		t.Fatalf("GetAchievements failed: %v", err)
+	}
+	million := statuses[0]
+	if million.ID != "keystrokes-1m" || !million.Unlocked || million.UnlockedAt != nil {
+		t.Errorf("Before unlocking, %+v should be met without a time", million)
+	}
+	if s := statuses[1]; !s.Unlocked || s.UnlockedAt == nil || !s.UnlockedAt.Equal(now) {
+		t.Errorf("%s unlocked at %v, want %v", s.ID, s.UnlockedAt, now)
+	}
+	if s := statuses[2]; s.Unlocked || s.Progress < 0.71 || s.Progress > 0.72 {
+		t.Errorf("%s = %+v, want 100/140 of the way", s.ID, s)
+	}
+
+	newly, err = store.UnlockAchievements(pixels, now.Add(time.Hour))
+	if err != nil || len(newly) != 1 || newly[0].ID != "keystrokes-1m" {
+		t.Errorf("UnlockAchievements = %+v, %v; want only the million keystrokes", newly, err)
+	}
+}
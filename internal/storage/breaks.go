@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/breaks"
+)
+
+// BreakStats counts the reminders of one kind of break and how they went
+type BreakStats struct {
+	Kind    breaks.Kind `json:"kind"`
+	Taken   int         `json:"taken"`
+	Skipped int         `json:"skipped"`
+}
+
+// TakenRate returns the share of reminders heeded, or 0 without any
+func (b BreakStats) TakenRate() float64 {
+	if b.Taken+b.Skipped == 0 {
+		return 0
+	}
+	return float64(b.Taken) / float64(b.Taken+b.Skipped)
+}
+
+// RecordBreakOutcome stores a break reminder that was taken or skipped,
+// credited to the day it was due on
+func (s *Store) RecordBreakOutcome(e breaks.Event) error {
+	if e.Type != breaks.Taken && e.Type != breaks.Skipped {
+		return fmt.Errorf("break event %d is not an outcome", e.Type)
+	}
+	bounds := s.dayBounds()
+	wall := e.At.In(bounds.loc)
+	date, _ := bucket(wall, bounds.dayStart)
+	_, offset := wall.Zone()
+
+	_, err := s.db.Exec(
+		"INSERT INTO break_outcomes (ts, tz_offset, date, kind, taken) VALUES (?, ?, ?, ?, ?)",
+		e.At.UnixMilli(), offset, date, e.Rule.Kind, e.Type == breaks.Taken,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record break: %w", err)
+	}
+	return nil
+}
+
+// GetBreakStats returns how many breaks of each kind were taken and skipped
+// between from and to (see the range query conventions in ranges.go)
+func (s *Store) GetBreakStats(from, to string) ([]BreakStats, error) {
+	rows, err := s.db.Query(`
+		SELECT kind, SUM(taken), SUM(1 - taken)
+		FROM break_outcomes
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		GROUP BY kind
+		ORDER BY kind
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []BreakStats
+	for rows.Next() {
+		var b BreakStats
+		if err := rows.Scan(&b.Kind, &b.Taken, &b.Skipped); err != nil {
+			return nil, err
+		}
+		stats = append(stats, b)
+	}
+	return stats, rows.Err()
+}
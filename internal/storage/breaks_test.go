@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/breaks"
+)
+
+func TestBreakOutcomes(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	brk := breaks.Rule{Kind: breaks.KindBreak, Active: 50 * time.Minute, Length: 5 * time.Minute}
+	micro := breaks.Rule{Kind: breaks.KindMicroPause, Keystrokes: 300, Length: 10 * time.Second}
+	at := time.Date(2024, 5, 2, 9, 50, 0, 0, time.UTC)
+	for _, e := range []breaks.Event{
+		{Rule: brk, Type: breaks.Taken, At: at},
+		{Rule: brk, Type: breaks.Skipped, At: at.Add(time.Hour)},
+		{Rule: brk, Type: breaks.Taken, At: at.Add(2 * time.Hour)},
+		{Rule: micro, Type: breaks.Skipped, At: at},
+		{Rule: brk, Type: breaks.Skipped, At: at.AddDate(0, 0, 1)},
+	} {
+		if err := store.RecordBreakOutcome(e); err != nil {
+			t.Fatalf("RecordBreakOutcome failed: %v", err)
+		}
+	}
+	if err := store.RecordBreakOutcome(breaks.Event{Rule: brk, Type: breaks.Due, At: at}); err == nil {
+		t.Error("A due reminder isn't an outcome and shouldn't be recorded")
+	}
+
+	got, err := store.GetBreakStats("2024-05-02", "2024-05-02")
+	if err != nil {
+		t.Fatalf("GetBreakStats failed: %v", err)
+	}
+	want := []BreakStats{
+		{Kind: breaks.KindBreak, Taken: 2, Skipped: 1},
+		{Kind: breaks.KindMicroPause, Taken: 0, Skipped: 1},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("GetBreakStats = %+v, want %+v", got, want)
+	}
+	if rate := got[0].TakenRate(); rate < 0.66 || rate > 0.67 {
+		t.Errorf("TakenRate = %v, want 2/3", rate)
+	}
+
+	// Moving the day boundary past 9:50 puts the morning on the day before
+	if err := store.SetDayStartHour(10); err != nil {
+		t.Fatalf("SetDayStartHour failed: %v", err)
+	}
+	if got, _ := store.GetBreakStats("2024-05-01", "2024-05-01"); len(got) != 2 || got[0].Taken != 1 {
+		t.Errorf("After moving the day start got %+v on 2024-05-01", got)
+	}
+}
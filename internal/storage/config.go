@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+)
+
+// LoadConfigFile reads the config file at path as the layer beneath the
+// settings table (see internal/config). Without one the built-in defaults
+// apply.
+func (s *Store) LoadConfigFile(path string) error {
+	f, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	s.cfgMu.Lock()
+	s.cfgFile = f
+	s.cfgMu.Unlock()
+	s.settingsChanged()
+	return nil
+}
+
+// ConfigFile returns the loaded config file, or nil if none was loaded
+func (s *Store) ConfigFile() *config.File {
+	s.cfgMu.Lock()
+	defer s.cfgMu.Unlock()
+	return s.cfgFile
+}
+
+// ConfigOverrides returns the settings table values that override the config
+// file, by key name. Values are as stored and may be invalid; Config skips
+// those.
+func (s *Store) ConfigOverrides() (map[string]string, error) {
+	keys := make([]interface{}, len(config.Keys))
+	names := make(map[string]string, len(config.Keys))
+	for i, k := range config.Keys {
+		keys[i] = k.Setting
+		names[k.Setting] = k.Name
+	}
+
+	rows, err := s.db.Query(
+		"SELECT key, value FROM settings WHERE key IN (?"+strings.Repeat(", ?", len(keys)-1)+")",
+		keys...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		if value != "" {
+			overrides[names[key]] = value
+		}
+	}
+	return overrides, rows.Err()
+}
+
+// Config returns the effective settings: the defaults, then the config file,
+// then valid overrides from the settings table
+func (s *Store) Config() config.Config {
+	cfg := config.Default()
+	if f := s.ConfigFile(); f != nil {
+		cfg = f.Config
+	}
+
+	overrides, _ := s.ConfigOverrides()
+	for _, k := range config.Keys {
+		if val, ok := overrides[k.Name]; ok {
+			_ = k.Set(&cfg, val) // an invalid override leaves the file's value
+		}
+	}
+	return cfg
+}
+
+// hotSettings are the settings read on every keystroke or mouse event
+type hotSettings struct {
+	bounds  dayBounds
+	idleGap time.Duration
+}
+
+// hotSettings returns the hot path settings, resolving the config only after
+// a setting has changed
+func (s *Store) hotSettings() hotSettings {
+	s.hotMu.Lock()
+	defer s.hotMu.Unlock()
+	if s.hot != nil {
+		return *s.hot
+	}
+
+	cfg := s.Config()
+	s.hot = &hotSettings{
+		bounds:  newDayBounds(cfg.Days),
+		idleGap: time.Duration(cfg.Sessions.IdleMinutes) * time.Minute,
+	}
+	return *s.hot
+}
+
+// settingsChanged drops the cached hot path settings, so they're resolved
+// again when next needed
+func (s *Store) settingsChanged() {
+	s.hotMu.Lock()
+	s.hot = nil
+	s.hotMu.Unlock()
+}
+
+// DeleteSetting removes a setting, e.g. to drop a config override
+func (s *Store) DeleteSetting(key string) error {
+	_, err := s.db.Exec("DELETE FROM settings WHERE key = ?", key)
+	s.settingsChanged()
+	return err
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+func TestSettingKeysMatchConfig(t *testing.T) {
+	settings := map[string]string{
+		"menubar.show_keystrokes":      SettingShowKeystrokes,
+		"menubar.show_words":           SettingShowWords,
+		"menubar.show_clicks":          SettingShowClicks,
+		"menubar.show_distance":        SettingShowDistance,
+		"menubar.show_wpm":             SettingShowWPM,
+		"menubar.show_goals":           SettingShowGoals,
+		"mouse.tracking":               SettingMouseTrackingEnabled,
+		"mouse.distance_unit":          SettingDistanceUnit,
+		"inertia.enabled":              SettingInertiaEnabled,
+		"inertia.max_speed":            SettingInertiaMaxSpeed,
+		"inertia.threshold_ms":         SettingInertiaThreshold,
+		"inertia.accel_rate":           SettingInertiaAccelRate,
+		"keyboard.layout":              SettingKeyboardLayout,
+		"typing_test.theme":            SettingTypingTestTheme,
+		"days.timezone":                SettingTimezone,
+		"days.start_hour":              SettingDayStartHour,
+		"retention.keystroke_days":     SettingRetentionDays,
+		"sessions.idle_minutes":        SettingSessionIdleMinutes,
+		"breaks.enabled":               SettingBreaksEnabled,
+		"breaks.break_after_minutes":   SettingBreakAfterMinutes,
+		"breaks.break_minutes":         SettingBreakMinutes,
+		"breaks.micropause_keystrokes": SettingMicroPauseKeystrokes,
+		"breaks.micropause_seconds":    SettingMicroPauseSeconds,
+		"breaks.notifier":              SettingBreakNotifier,
+		"breaks.webhook_url":           SettingBreakWebhookURL,
+		"goals.keystrokes":             SettingGoalKeystrokes,
+		"goals.words":                  SettingGoalWords,
+		"goals.typing_tests":           SettingGoalTypingTests,
+		"goals.max_mouse_feet":         SettingGoalMaxMouseFeet,
+		"goals.notify":                 SettingGoalNotify,
+		"metrics.addr":                 SettingMetricsAddr,
+	}
+
+	if len(settings) != len(config.Keys) {
+		t.Errorf("%d config keys, want %d", len(config.Keys), len(settings))
+	}
+	for _, k := range config.Keys {
+		if settings[k.Name] != k.Setting {
+			t.Errorf("%s is stored as %q, want %q", k.Name, k.Setting, settings[k.Name])
+		}
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	// Defaults
+	if got := store.GetDistanceUnit(); got != DistanceUnitFeet {
+		t.Errorf("default distance unit = %q, want %q", got, DistanceUnitFeet)
+	}
+
+	// The file overrides the defaults
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[mouse]\ndistance_unit = \"cars\"\n\n[inertia]\nthreshold_ms = 300\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := store.LoadConfigFile(path); err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if got := store.GetDistanceUnit(); got != DistanceUnitCars {
+		t.Errorf("distance unit from file = %q, want %q", got, DistanceUnitCars)
+	}
+
+	// The settings table overrides the file
+	if err := store.SetDistanceUnit(DistanceUnitFrisbee); err != nil {
+		t.Fatalf("SetDistanceUnit failed: %v", err)
+	}
+	if got := store.GetDistanceUnit(); got != DistanceUnitFrisbee {
+		t.Errorf("overridden distance unit = %q, want %q", got, DistanceUnitFrisbee)
+	}
+
+	// Invalid overrides are ignored
+	if err := store.SetSetting(SettingInertiaThreshold, "soon"); err != nil {
+		t.Fatalf("SetSetting failed: %v", err)
+	}
+	if got := store.GetInertiaSettings().Threshold; got != 300 {
+		t.Errorf("threshold with invalid override = %d, want 300 from the file", got)
+	}
+
+	overrides, err := store.ConfigOverrides()
+	if err != nil {
+		t.Fatalf("ConfigOverrides failed: %v", err)
+	}
+	if overrides["mouse.distance_unit"] != DistanceUnitFrisbee || overrides["inertia.threshold_ms"] != "soon" {
+		t.Errorf("ConfigOverrides = %v", overrides)
+	}
+
+	// Removing the override brings back the file's value
+	if err := store.DeleteSetting(SettingDistanceUnit); err != nil {
+		t.Fatalf("DeleteSetting failed: %v", err)
+	}
+	if got := store.GetDistanceUnit(); got != DistanceUnitCars {
+		t.Errorf("distance unit after unset = %q, want %q", got, DistanceUnitCars)
+	}
+}
+
+func TestMetricsAddrFromConfigFile(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[metrics]\naddr = \"127.0.0.1:9100\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := store.LoadConfigFile(path); err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if got := store.MetricsAddr(); got != "127.0.0.1:9100" {
+		t.Errorf("MetricsAddr() = %q, want the file's 127.0.0.1:9100", got)
+	}
+	if err := store.SetMetricsAddr("127.0.0.1:9200"); err != nil {
+		t.Fatalf("SetMetricsAddr failed: %v", err)
+	}
+	if got := store.MetricsAddr(); got != "127.0.0.1:9200" {
+		t.Errorf("MetricsAddr() = %q, want the saved 127.0.0.1:9200", got)
+	}
+}
+
+func TestLoadConfigFileInvalid(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[mouse]\ndistance_unit = \"parsecs\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := store.LoadConfigFile(path); err == nil {
+		t.Fatal("expected an error for an invalid config file")
+	}
+	if got := store.GetDistanceUnit(); got != DistanceUnitFeet {
+		t.Errorf("distance unit = %q, want the default", got)
+	}
+}
+
+func TestPruneKeystrokes(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	today, _ := time.ParseInLocation(dateLayout, store.Today(), time.Local)
+	old := today.AddDate(0, 0, -10).Add(9 * time.Hour)
+	recent := today.AddDate(0, 0, -1).Add(9 * time.Hour)
+	for i := 0; i < 3; i++ {
+		store.RecordKeystrokeAt(4, old)
+	}
+	store.RecordChordAt(5, keyboard.ModCommand, old.Add(time.Hour))
+	store.RecordKeystrokeAt(4, recent)
+
+	// Retention off keeps everything
+	if n, err := store.PruneKeystrokes(); err != nil || n != 0 {
+		t.Fatalf("PruneKeystrokes with retention off = %d, %v", n, err)
+	}
+
+	if err := store.SetSetting(SettingRetentionDays, "7"); err != nil {
+		t.Fatalf("SetSetting failed: %v", err)
+	}
+	n, err := store.PruneKeystrokes()
+	if err != nil {
+		t.Fatalf("PruneKeystrokes failed: %v", err)
+	}
+	if n != 4 {
+		t.Errorf("pruned %d keystrokes, want 4", n)
+	}
+
+	var rows int
+	store.db.QueryRow("SELECT COUNT(*) FROM keystrokes").Scan(&rows)
+	if rows != 1 {
+		t.Errorf("%d keystroke rows left, want 1", rows)
+	}
+
+	// Totals are unchanged
+	date := old.Format(dateLayout)
+	day, _ := store.GetDayStats(date)
+	if day.Keystrokes != 4 {
+		t.Errorf("daily total = %d, want 4", day.Keystrokes)
+	}
+	hourly, _ := store.GetHourlyStats(date)
+	if hourly[9].Keystrokes != 3 || hourly[10].Keystrokes != 1 {
+		t.Errorf("hourly = %d at 9, %d at 10, want 3 and 1", hourly[9].Keystrokes, hourly[10].Keystrokes)
+	}
+	keys, err := store.GetKeyCountRange(date, date)
+	if err != nil {
+		t.Fatalf("GetKeyCountRange failed: %v", err)
+	}
+	counts := map[int]int64{}
+	for _, k := range keys {
+		counts[k.Keycode] += k.Count
+	}
+	if counts[4] != 3 || counts[5] != 1 {
+		t.Errorf("key counts = %v, want 3 of 4 and 1 of 5", counts)
+	}
+
+	shortcuts, err := store.GetShortcuts(date, date)
+	if err != nil {
+		t.Fatalf("GetShortcuts failed: %v", err)
+	}
+	if len(shortcuts) != 1 || shortcuts[0] != (ShortcutCount{5, keyboard.ModCommand, 1}) {
+		t.Errorf("shortcuts = %+v, want one Cmd+G", shortcuts)
+	}
+
+	// Pruning again finds nothing
+	if n, _ := store.PruneKeystrokes(); n != 0 {
+		t.Errorf("second prune removed %d rows", n)
+	}
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/corrections"
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+// HourlyCorrections is the typing and correcting in one clock hour of a day
+type HourlyCorrections struct {
+	Date string `json:"date"`
+	Hour int    `json:"hour"`
+	corrections.Counts
+}
+
+// DailyCorrections is the typing and correcting on a day
+type DailyCorrections struct {
+	Date string `json:"date"`
+	corrections.Counts
+}
+
+// addCorrections adds counts to an hour of hourly_corrections
+func addCorrections(tx *sql.Tx, date string, hour int, c corrections.Counts) error {
+	_, err := tx.Exec(`
+		INSERT INTO hourly_corrections (date, hour, chars, corrections, bursts) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(date, hour) DO UPDATE SET
+			chars = chars + excluded.chars,
+			corrections = corrections + excluded.corrections,
+			bursts = bursts + excluded.bursts
+	`, date, hour, c.Chars, c.Corrections, c.Bursts)
+	if err != nil {
+		return fmt.Errorf("failed to update corrections: %w", err)
+	}
+	return nil
+}
+
+// recountCorrections replays the keystrokes of dates through a correction
+// detector and rewrites their hourly_corrections, including dates that may
+// have lost all their keystrokes. Nil dates rewrite every date that still
+// has keystrokes. Days whose keystrokes were pruned keep their counts.
+func recountCorrections(db *sql.DB, dates []string) error {
+	type slot struct {
+		date string
+		hour int
+	}
+
+	rows, err := replayKeystrokes(db, "keycode, modifiers, date, hour", dates)
+	if err != nil {
+		return fmt.Errorf("failed to read keystrokes: %w", err)
+	}
+	var detector corrections.Detector
+	counts := make(map[slot]corrections.Counts)
+	recount := make(map[string]bool)
+	for _, date := range dates {
+		recount[date] = true
+	}
+	for rows.Next() {
+		var code, hour int
+		var mods keyboard.Modifiers
+		var date string
+		if err := rows.Scan(&code, &mods, &date, &hour); err != nil {
+			rows.Close()
+			return err
+		}
+		recount[date] = true
+		if c := detector.Press(code, mods); c != (corrections.Counts{}) {
+			sum := counts[slot{date, hour}]
+			sum.Add(c)
+			counts[slot{date, hour}] = sum
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for date := range recount {
+		if _, err := tx.Exec("DELETE FROM hourly_corrections WHERE date = ?", date); err != nil {
+			return fmt.Errorf("failed to clear corrections for %s: %w", date, err)
+		}
+	}
+	for sl, c := range counts {
+		if err := addCorrections(tx, sl.date, sl.hour, c); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetHourlyCorrectionsRange returns characters typed, corrections and
+// correction bursts per date and hour (see internal/corrections)
+func (s *Store) GetHourlyCorrectionsRange(from, to string) ([]HourlyCorrections, error) {
+	rows, err := s.db.Query(`
+		SELECT date, hour, chars, corrections, bursts
+		FROM hourly_corrections
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date, hour
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hours []HourlyCorrections
+	for rows.Next() {
+		var h HourlyCorrections
+		if err := rows.Scan(&h.Date, &h.Hour, &h.Chars, &h.Corrections, &h.Bursts); err != nil {
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+	return hours, rows.Err()
+}
+
+// GetDailyCorrectionsRange returns characters typed, corrections and
+// correction bursts per day
+func (s *Store) GetDailyCorrectionsRange(from, to string) ([]DailyCorrections, error) {
+	rows, err := s.db.Query(`
+		SELECT date, SUM(chars), SUM(corrections), SUM(bursts)
+		FROM hourly_corrections
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		GROUP BY date
+		ORDER BY date
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []DailyCorrections
+	for rows.Next() {
+		var d DailyCorrections
+		if err := rows.Scan(&d.Date, &d.Chars, &d.Corrections, &d.Bursts); err != nil {
+			return nil, err
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// GetCorrections returns the characters typed, corrections and correction
+// bursts between from and to
+func (s *Store) GetCorrections(from, to string) (corrections.Counts, error) {
+	var c corrections.Counts
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(chars), 0), COALESCE(SUM(corrections), 0), COALESCE(SUM(bursts), 0)
+		FROM hourly_corrections
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+	`, from, from, to, to).Scan(&c.Chars, &c.Corrections, &c.Bursts)
+	return c, err
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/corrections"
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+func TestCorrections(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	a, del := keyboard.KeyA, keyboard.KeyDelete
+	nine := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	typeKeys(t, store, nine, a, a, a, a, del, a, a, a)
+	typeKeys(t, store, nine.Add(time.Hour), a, a, del, del, del, keyboard.KeyShift, a)
+	typeKeys(t, store, nine.AddDate(0, 0, 1), a, keyboard.KeyS)
+	store.RecordChordAt(keyboard.KeyS, keyboard.ModCommand, nine.AddDate(0, 0, 1).Add(time.Minute))
+
+	hourly, err := store.GetHourlyCorrectionsRange("2024-05-02", "2024-05-02")
+	if err != nil {
+		t.Fatalf("GetHourlyCorrectionsRange failed: %v", err)
+	}
+	want := []HourlyCorrections{
+		{"2024-05-02", 9, corrections.Counts{Chars: 7, Corrections: 1}},
+		{"2024-05-02", 10, corrections.Counts{Chars: 3, Corrections: 3, Bursts: 1}},
+	}
+	if len(hourly) != len(want) || hourly[0] != want[0] || hourly[1] != want[1] {
+		t.Errorf("GetHourlyCorrectionsRange = %+v, want %+v", hourly, want)
+	}
+
+	daily, err := store.GetDailyCorrectionsRange("", "")
+	if err != nil {
+		t.Fatalf("GetDailyCorrectionsRange failed: %v", err)
+	}
+	wantDaily := []DailyCorrections{
+		{"2024-05-02", corrections.Counts{Chars: 10, Corrections: 4, Bursts: 1}},
+		{"2024-05-03", corrections.Counts{Chars: 2}},
+	}
+	if len(daily) != len(wantDaily) || daily[0] != wantDaily[0] || daily[1] != wantDaily[1] {
+		t.Errorf("GetDailyCorrectionsRange = %+v, want %+v", daily, wantDaily)
+	}
+
+	total, err := store.GetCorrections("", "2024-05-03")
+	if err != nil {
+		t.Fatalf("GetCorrections failed: %v", err)
+	}
+	if total != (corrections.Counts{Chars: 12, Corrections: 4, Bursts: 1}) {
+		t.Errorf("GetCorrections = %+v", total)
+	}
+	if none, _ := store.GetCorrections("2025-01-01", ""); none != (corrections.Counts{}) {
+		t.Errorf("GetCorrections with no typing = %+v, want zero", none)
+	}
+}
+
+func TestCorrectionsBackfill(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	at := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	typeKeys(t, store, at, keyboard.KeyA, keyboard.KeyDelete, keyboard.KeyDelete, keyboard.KeyDelete)
+
+	// A database from before corrections were counted
+	if _, err := store.db.Exec("DELETE FROM hourly_corrections"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.db.Exec("PRAGMA user_version = 7"); err != nil {
+		t.Fatal(err)
+	}
+	if err := initSchema(store.db); err != nil {
+		t.Fatalf("initSchema failed: %v", err)
+	}
+
+	got, _ := store.GetCorrections("", "")
+	if got != (corrections.Counts{Chars: 1, Corrections: 3, Bursts: 1}) {
+		t.Errorf("Backfilled corrections = %+v", got)
+	}
+}
+
+func TestRebuildMovesCorrections(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	// 02:15 UTC moves to the previous day once days start at 4am
+	at := time.Date(2024, 5, 2, 2, 15, 0, 0, time.UTC)
+	typeKeys(t, store, at, keyboard.KeyA, keyboard.KeyA, keyboard.KeyDelete)
+	if err := store.SetDayStartHour(4); err != nil {
+		t.Fatalf("SetDayStartHour failed: %v", err)
+	}
+
+	daily, _ := store.GetDailyCorrectionsRange("", "")
+	want := DailyCorrections{"2024-05-01", corrections.Counts{Chars: 2, Corrections: 1}}
+	if len(daily) != 1 || daily[0] != want {
+		t.Errorf("GetDailyCorrectionsRange = %+v, want only %+v", daily, want)
+	}
+}
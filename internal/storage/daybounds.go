@@ -0,0 +1,325 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+)
+
+// Day boundary settings
+const (
+	SettingTimezone         = "timezone"           // IANA zone name; empty follows the system clock
+	SettingDayStartHour     = "day_start_hour"     // hour (0-23) at which a new "day" begins
+	settingDayBoundsApplied = "day_bounds_applied" // day bounds keystrokes were last bucketed with
+	settingDayBoundsAt      = "day_bounds_at"      // when they were, in UTC epoch milliseconds
+)
+
+// dayBoundsSlack widens the rows ApplyDayBounds checks back from when the day
+// bounds were last applied, as typing bursts are stored after they start
+const dayBoundsSlack = time.Hour
+
+// DefaultDayStartHour keeps the day boundary at midnight
+const DefaultDayStartHour = 0
+
+const dateLayout = "2006-01-02"
+
+// dayBounds are the timezone and day start hour keystrokes are bucketed by
+type dayBounds struct {
+	timezone string         // empty follows the system clock
+	loc      *time.Location // the pinned zone, or time.Local
+	dayStart int
+}
+
+// newDayBounds resolves the days section of the config
+func newDayBounds(days config.Days) dayBounds {
+	b := dayBounds{loc: time.Local, dayStart: days.StartHour}
+	if days.Timezone != "" {
+		if loc, err := time.LoadLocation(days.Timezone); err == nil {
+			b.timezone, b.loc = days.Timezone, loc
+		}
+	}
+	return b
+}
+
+// dayBounds returns the cached day bounds (days.timezone and
+// days.start_hour)
+func (s *Store) dayBounds() dayBounds {
+	return s.hotSettings().bounds
+}
+
+// dayBoundsKey identifies a timezone and day start hour in
+// settingDayBoundsApplied
+func dayBoundsKey(timezone string, dayStart int) string {
+	return fmt.Sprintf("%s|%d", timezone, dayStart)
+}
+
+// ApplyDayBounds re-buckets keystrokes if the timezone or day start hour has
+// changed since they were last bucketed, e.g. in the config file or with
+// 'typtel config set'. Otherwise rows recorded since then are still checked:
+// a process that hadn't seen a change, such as the menu bar app, may have
+// kept recording with the old bounds.
+func (s *Store) ApplyDayBounds() error {
+	applied, err := s.GetSetting(settingDayBoundsApplied)
+	if err != nil {
+		return err
+	}
+	at, err := s.GetSetting(settingDayBoundsAt)
+	if err != nil {
+		return err
+	}
+	var since int64
+	if b := s.dayBounds(); applied == dayBoundsKey(b.timezone, b.dayStart) {
+		if ms, err := parseInt(at); err == nil {
+			since = int64(ms) - dayBoundsSlack.Milliseconds()
+		}
+	}
+	return s.rebuildDates(since)
+}
+
+// markDayBoundsApplied notes that keystrokes are bucketed with b as of now.
+// It leaves the cached settings alone, as it isn't one of them.
+func (s *Store) markDayBoundsApplied(b dayBounds) error {
+	_, err := s.db.Exec(`
+		INSERT INTO settings (key, value) VALUES (?, ?), (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`, settingDayBoundsApplied, dayBoundsKey(b.timezone, b.dayStart),
+		settingDayBoundsAt, strconv.FormatInt(time.Now().UnixMilli(), 10))
+	return err
+}
+
+// recordDayBounds notes the day bounds keystrokes were bucketed with when
+// the database predates settingDayBoundsApplied: those saved in the settings
+// table, which were all that applied then
+func recordDayBounds(db *sql.DB) error {
+	var timezone, start string
+	db.QueryRow("SELECT value FROM settings WHERE key = ?", SettingTimezone).Scan(&timezone)
+	db.QueryRow("SELECT value FROM settings WHERE key = ?", SettingDayStartHour).Scan(&start)
+	if _, err := time.LoadLocation(timezone); err != nil {
+		timezone = ""
+	}
+	dayStart, err := parseInt(start)
+	if err != nil || dayStart < 0 || dayStart > 23 {
+		dayStart = DefaultDayStartHour
+	}
+	_, err = db.Exec(
+		"INSERT OR IGNORE INTO settings (key, value) VALUES (?, ?)",
+		settingDayBoundsApplied, dayBoundsKey(timezone, dayStart),
+	)
+	return err
+}
+
+// Location returns the configured timezone, or the system local zone if unset
+// or invalid.
+func (s *Store) Location() *time.Location {
+	return s.dayBounds().loc
+}
+
+// SetTimezone pins aggregation to an IANA zone (e.g. "Europe/London").
+// An empty name removes the override, so the config file's zone or the
+// system clock applies. Existing keystrokes are re-bucketed into the new
+// days and hours.
+func (s *Store) SetTimezone(name string) error {
+	if name != "" {
+		if _, err := time.LoadLocation(name); err != nil {
+			return fmt.Errorf("unknown timezone %q: %w", name, err)
+		}
+	}
+	if err := s.SetSetting(SettingTimezone, name); err != nil {
+		return err
+	}
+	return s.RebuildKeystrokeDates()
+}
+
+// DayStartHour returns the hour at which a new day begins (default: midnight)
+func (s *Store) DayStartHour() int {
+	return s.dayBounds().dayStart
+}
+
+// SetDayStartHour moves the day boundary, e.g. 4 so typing until 3:59am still
+// counts towards the previous day. Existing keystrokes are re-bucketed.
+func (s *Store) SetDayStartHour(hour int) error {
+	if hour < 0 || hour > 23 {
+		return fmt.Errorf("day start hour must be between 0 and 23, got %d", hour)
+	}
+	if err := s.SetSetting(SettingDayStartHour, intToString(hour)); err != nil {
+		return err
+	}
+	return s.RebuildKeystrokeDates()
+}
+
+// bucket returns the logical date and clock hour that t falls into, given the
+// wall clock it was observed on and the configured day start hour
+func bucket(wall time.Time, dayStart int) (string, int) {
+	date := wall.Add(-time.Duration(dayStart) * time.Hour).Format(dateLayout)
+	return date, wall.Hour()
+}
+
+// rebucket returns the logical date, clock hour and UTC offset of a row
+// recorded at ts (UTC epoch milliseconds) with offset, in the pinned zone loc
+// or, when loc is nil, at the offset it was recorded with
+func rebucket(ts int64, offset int, loc *time.Location, dayStart int) (string, int, int) {
+	var wall time.Time
+	if loc != nil {
+		wall = time.UnixMilli(ts).In(loc)
+		_, offset = wall.Zone()
+	} else {
+		wall = time.UnixMilli(ts).In(time.FixedZone("", offset))
+	}
+	date, hour := bucket(wall, dayStart)
+	return date, hour, offset
+}
+
+// DateFor returns the logical date that t falls into
+func (s *Store) DateFor(t time.Time) string {
+	b := s.dayBounds()
+	date, _ := bucket(t.In(b.loc), b.dayStart)
+	return date
+}
+
+// Today returns the current logical date
+func (s *Store) Today() string {
+	return s.DateFor(time.Now())
+}
+
+// lastNDates returns the last n logical dates, oldest first, ending today
+func (s *Store) lastNDates(n int) []string {
+	today, _ := time.Parse(dateLayout, s.Today())
+	dates := make([]string, n)
+	for i := n - 1; i >= 0; i-- {
+		dates[n-1-i] = today.AddDate(0, 0, -i).Format(dateLayout)
+	}
+	return dates
+}
+
+// RebuildKeystrokeDates recomputes the date and hour of every keystroke from
+// its UTC timestamp using the current timezone and day start settings, and
+// moves the matching counts in daily_summary. With no pinned timezone each
+// keystroke keeps the UTC offset it was recorded with. Words are recounted
+// (see RecountWords) and corrections recounted so they follow their
+// keystrokes, and typing bursts, sessions and break outcomes move too; mouse
+// totals are kept on the day they were recorded.
+func (s *Store) RebuildKeystrokeDates() error {
+	return s.rebuildDates(0)
+}
+
+// rebuildDates re-buckets the rows recorded at or after since (UTC epoch
+// milliseconds), as RebuildKeystrokeDates does every row
+func (s *Store) rebuildDates(since int64) error {
+	b := s.dayBounds()
+	var loc *time.Location
+	if b.timezone != "" {
+		loc = b.loc
+	}
+	dayStart := b.dayStart
+
+	if err := s.rebuildBurstDates(loc, dayStart, since); err != nil {
+		return err
+	}
+	if err := s.rebuildRowDates("sessions", "start_ts", loc, dayStart, since); err != nil {
+		return err
+	}
+	if err := s.rebuildRowDates("break_outcomes", "ts", loc, dayStart, since); err != nil {
+		return err
+	}
+
+	type move struct {
+		id       int64
+		keycode  int
+		oldDate  string
+		oldHour  int
+		newDate  string
+		newHour  int
+		tzOffset int
+	}
+
+	rows, err := s.db.Query("SELECT id, keycode, ts, COALESCE(tz_offset, 0), date, hour FROM keystrokes WHERE ts >= ?", since)
+	if err != nil {
+		return err
+	}
+	var moves []move
+	for rows.Next() {
+		var id, ts int64
+		var keycode, offset, hour int
+		var date string
+		if err := rows.Scan(&id, &keycode, &ts, &offset, &date, &hour); err != nil {
+			rows.Close()
+			return err
+		}
+		newDate, newHour, newOffset := rebucket(ts, offset, loc, dayStart)
+		if newDate != date || newHour != hour {
+			moves = append(moves, move{id, keycode, date, hour, newDate, newHour, newOffset})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(moves) == 0 {
+		return s.markDayBoundsApplied(b)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	synced, err := syncedKeystrokeID(tx)
+	if err != nil {
+		return err
+	}
+
+	delta := make(map[string]int64)
+	for _, m := range moves {
+		if _, err := tx.Exec(
+			"UPDATE keystrokes SET date = ?, hour = ?, tz_offset = ? WHERE id = ?",
+			m.newDate, m.newHour, m.tzOffset, m.id,
+		); err != nil {
+			return err
+		}
+		delta[m.oldDate]--
+		delta[m.newDate]++
+
+		// Other devices already have this keystroke at its old date and hour
+		if m.id <= synced {
+			if _, err := tx.Exec(
+				"INSERT INTO sync_adjustments (date, hour, keycode, keystrokes) VALUES (?, ?, ?, -1), (?, ?, ?, 1)",
+				m.oldDate, m.oldHour, m.keycode, m.newDate, m.newHour, m.keycode,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	for date, d := range delta {
+		if d == 0 {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO daily_summary (date, keystrokes) VALUES (?, MAX(?, 0))
+			ON CONFLICT(date) DO UPDATE SET
+				keystrokes = MAX(keystrokes + ?, 0),
+				updated_at = CURRENT_TIMESTAMP
+		`, date, d, d); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	moved := make([]string, 0, len(delta))
+	for date := range delta {
+		moved = append(moved, date)
+	}
+	if _, err := s.recountWords(moved); err != nil {
+		return err
+	}
+	if err := recountCorrections(s.db, moved); err != nil {
+		return err
+	}
+	return s.markDayBoundsApplied(b)
+}
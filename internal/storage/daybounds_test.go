@@ -0,0 +1,341 @@
+package storage
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDayStartHourSetting(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if h := store.DayStartHour(); h != DefaultDayStartHour {
+		t.Errorf("Expected default day start %d, got %d", DefaultDayStartHour, h)
+	}
+
+	if err := store.SetDayStartHour(4); err != nil {
+		t.Fatalf("SetDayStartHour failed: %v", err)
+	}
+	if h := store.DayStartHour(); h != 4 {
+		t.Errorf("Expected day start 4, got %d", h)
+	}
+
+	for _, bad := range []int{-1, 24, 100} {
+		if err := store.SetDayStartHour(bad); err == nil {
+			t.Errorf("Expected error for day start hour %d", bad)
+		}
+	}
+
+	// Garbage in the settings table falls back to the default
+	store.SetSetting(SettingDayStartHour, "not a number")
+	if h := store.DayStartHour(); h != DefaultDayStartHour {
+		t.Errorf("Expected fallback day start %d, got %d", DefaultDayStartHour, h)
+	}
+}
+
+func TestDayBoundsCached(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	// Rows written behind the store's back aren't read on every keystroke
+	store.db.Exec("UPDATE settings SET value = 'Asia/Tokyo' WHERE key = ?", SettingTimezone)
+	store.db.Exec("INSERT INTO settings (key, value) VALUES (?, '6')", SettingDayStartHour)
+	if loc, h := store.Location(), store.DayStartHour(); loc.String() != "UTC" || h != 0 {
+		t.Errorf("Expected the cached UTC and midnight, got %s and %d", loc, h)
+	}
+
+	// Changing any setting through the store reads them again
+	store.SetSetting("unrelated", "x")
+	if loc, h := store.Location(), store.DayStartHour(); loc.String() != "Asia/Tokyo" || h != 6 {
+		t.Errorf("Expected Asia/Tokyo and 6am after a settings change, got %s and %d", loc, h)
+	}
+	store.DeleteSetting(SettingDayStartHour)
+	if h := store.DayStartHour(); h != DefaultDayStartHour {
+		t.Errorf("Expected the default day start once deleted, got %d", h)
+	}
+}
+
+func TestDayBoundsFromConfigFile(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	// 2:30am UTC on the 2nd is still the 1st in New York
+	at := time.Date(2024, 3, 2, 2, 30, 0, 0, time.UTC)
+	store.SetTimezone("UTC")
+	store.RecordKeystrokeAt(4, at)
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[days]\ntimezone = \"America/New_York\"\nstart_hour = 4\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := store.LoadConfigFile(path); err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+
+	// The saved timezone overrides the file's; the file's day start applies
+	if loc, h := store.Location(), store.DayStartHour(); loc.String() != "UTC" || h != 4 {
+		t.Errorf("Expected UTC and 4am, got %s and %d", loc, h)
+	}
+	if err := store.DeleteSetting(SettingTimezone); err != nil {
+		t.Fatalf("DeleteSetting failed: %v", err)
+	}
+	if loc := store.Location(); loc.String() != "America/New_York" {
+		t.Errorf("Expected the file's America/New_York, got %s", loc)
+	}
+
+	// Keystrokes follow once the new bounds are applied
+	if err := store.ApplyDayBounds(); err != nil {
+		t.Fatalf("ApplyDayBounds failed: %v", err)
+	}
+	var date string
+	var hour int
+	store.db.QueryRow("SELECT date, hour FROM keystrokes").Scan(&date, &hour)
+	if date != "2024-03-01" || hour != 21 {
+		t.Errorf("Keystroke bucketed at %s %02d:00, want 2024-03-01 21:00", date, hour)
+	}
+}
+
+func TestTimezoneSetting(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if loc := store.Location(); loc != time.Local {
+		t.Errorf("Expected system local zone by default, got %v", loc)
+	}
+
+	if err := store.SetTimezone("Asia/Tokyo"); err != nil {
+		t.Fatalf("SetTimezone failed: %v", err)
+	}
+	if loc := store.Location(); loc.String() != "Asia/Tokyo" {
+		t.Errorf("Expected Asia/Tokyo, got %v", loc)
+	}
+
+	if err := store.SetTimezone("Not/AZone"); err == nil {
+		t.Error("Expected error for unknown timezone")
+	}
+
+	if err := store.SetTimezone(""); err != nil {
+		t.Fatalf("SetTimezone(\"\") failed: %v", err)
+	}
+	if loc := store.Location(); loc != time.Local {
+		t.Errorf("Expected system local zone after reset, got %v", loc)
+	}
+}
+
+func TestRecordKeystrokeAtStoresEpochAndOffset(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	store.SetTimezone("Asia/Tokyo") // UTC+9, no DST
+	at := time.Date(2024, 3, 10, 20, 30, 0, 0, time.UTC)
+
+	if err := store.RecordKeystrokeAt(42, at); err != nil {
+		t.Fatalf("RecordKeystrokeAt failed: %v", err)
+	}
+
+	var ts int64
+	var offset, hour int
+	var date string
+	err := store.db.QueryRow("SELECT ts, tz_offset, date, hour FROM keystrokes").Scan(&ts, &offset, &date, &hour)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if ts != at.UnixMilli() {
+		t.Errorf("Expected ts %d, got %d", at.UnixMilli(), ts)
+	}
+	if offset != 9*3600 {
+		t.Errorf("Expected tz_offset %d, got %d", 9*3600, offset)
+	}
+	// 20:30 UTC is 05:30 the next morning in Tokyo
+	if date != "2024-03-11" || hour != 5 {
+		t.Errorf("Expected 2024-03-11 hour 5, got %s hour %d", date, hour)
+	}
+}
+
+func TestDayStartHourBucketing(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	store.SetTimezone("UTC")
+	store.SetDayStartHour(4)
+
+	late := time.Date(2024, 5, 2, 3, 59, 0, 0, time.UTC)
+	early := time.Date(2024, 5, 2, 4, 0, 0, 0, time.UTC)
+	store.RecordKeystrokeAt(1, late)
+	store.RecordKeystrokeAt(2, early)
+
+	if got := store.DateFor(late); got != "2024-05-01" {
+		t.Errorf("DateFor(03:59) = %s, want 2024-05-01", got)
+	}
+	if got := store.DateFor(early); got != "2024-05-02" {
+		t.Errorf("DateFor(04:00) = %s, want 2024-05-02", got)
+	}
+
+	prev, _ := store.GetDayStats("2024-05-01")
+	next, _ := store.GetDayStats("2024-05-02")
+	if prev.Keystrokes != 1 || next.Keystrokes != 1 {
+		t.Errorf("Expected 1 keystroke on each day, got %d and %d", prev.Keystrokes, next.Keystrokes)
+	}
+
+	// The 03:59 keystroke keeps its clock hour within the previous day
+	hourly, _ := store.GetHourlyStats("2024-05-01")
+	if hourly[3].Keystrokes != 1 {
+		t.Errorf("Expected 1 keystroke at hour 3 of 2024-05-01, got %d", hourly[3].Keystrokes)
+	}
+}
+
+func TestRebuildKeystrokeDatesMovesCounts(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	store.SetTimezone("UTC")
+	at := time.Date(2024, 5, 2, 2, 15, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		store.RecordKeystrokeAt(i, at)
+	}
+
+	day, _ := store.GetDayStats("2024-05-02")
+	if day.Keystrokes != 3 {
+		t.Fatalf("Expected 3 keystrokes on 2024-05-02, got %d", day.Keystrokes)
+	}
+
+	// Moving the boundary to 4am re-buckets the 02:15 keystrokes
+	if err := store.SetDayStartHour(4); err != nil {
+		t.Fatalf("SetDayStartHour failed: %v", err)
+	}
+	prev, _ := store.GetDayStats("2024-05-01")
+	day, _ = store.GetDayStats("2024-05-02")
+	if prev.Keystrokes != 3 || day.Keystrokes != 0 {
+		t.Errorf("Expected 3/0 keystrokes after rebuild, got %d/%d", prev.Keystrokes, day.Keystrokes)
+	}
+
+	// Pinning a different zone moves them again: 02:15 UTC is 11:15 in Tokyo
+	if err := store.SetTimezone("Asia/Tokyo"); err != nil {
+		t.Fatalf("SetTimezone failed: %v", err)
+	}
+	day, _ = store.GetDayStats("2024-05-02")
+	if day.Keystrokes != 3 {
+		t.Errorf("Expected 3 keystrokes on 2024-05-02 in Tokyo, got %d", day.Keystrokes)
+	}
+	hourly, _ := store.GetHourlyStats("2024-05-02")
+	if hourly[11].Keystrokes != 3 {
+		t.Errorf("Expected 3 keystrokes at hour 11, got %d", hourly[11].Keystrokes)
+	}
+}
+
+func TestBackfillLegacyKeystrokes(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	// Schema and rows as written by earlier releases: UTC timestamp, local date/hour
+	_, err = db.Exec(`
+		CREATE TABLE keystrokes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			keycode INTEGER,
+			date TEXT,
+			hour INTEGER
+		);
+		INSERT INTO keystrokes (timestamp, keycode, date, hour) VALUES
+			('2024-01-15 08:20:00', 1, '2024-01-15', 10),
+			('2024-01-15 23:45:00', 2, '2024-01-15', 18);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+	db.Close()
+
+	store, err := NewWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	defer store.Close()
+
+	rows, err := store.db.Query("SELECT ts, tz_offset FROM keystrokes ORDER BY id")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	defer rows.Close()
+
+	want := []struct {
+		ts     int64
+		offset int
+	}{
+		{time.Date(2024, 1, 15, 8, 20, 0, 0, time.UTC).UnixMilli(), 2 * 3600},
+		{time.Date(2024, 1, 15, 23, 45, 0, 0, time.UTC).UnixMilli(), -5 * 3600},
+	}
+	i := 0
+	for rows.Next() {
+		var ts int64
+		var offset int
+		if err := rows.Scan(&ts, &offset); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		if ts != want[i].ts || offset != want[i].offset {
+			t.Errorf("Row %d: got ts=%d offset=%d, want ts=%d offset=%d", i, ts, offset, want[i].ts, want[i].offset)
+		}
+		i++
+	}
+	if i != len(want) {
+		t.Errorf("Expected %d rows, got %d", len(want), i)
+	}
+
+	// Legacy keystrokes count as unmodified
+	var modified int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM keystrokes WHERE modifiers != 0").Scan(&modified); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if modified != 0 {
+		t.Errorf("Expected no modified keystrokes, got %d", modified)
+	}
+}
+
+func TestApplyDayBoundsChecksRowsSinceApplied(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daybounds.db")
+	daemon, err := NewWithPath(path)
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	daemon.SetTimezone("UTC")
+
+	// Another process moves the day start to 4am and applies it...
+	cli, err := NewWithPath(path)
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	if err := cli.SetDayStartHour(4); err != nil {
+		t.Fatalf("SetDayStartHour failed: %v", err)
+	}
+	cli.Close()
+
+	// ...while the running daemon keeps recording with midnight
+	at := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1).Add(2*time.Hour + 31*time.Minute)
+	if err := daemon.RecordKeystrokeAt(4, at); err != nil {
+		t.Fatalf("RecordKeystrokeAt failed: %v", err)
+	}
+	daemon.Close()
+
+	// The marker matches on restart, but the keystroke is still moved
+	restarted, err := NewWithPath(path)
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	defer restarted.Close()
+	if err := restarted.ApplyDayBounds(); err != nil {
+		t.Fatalf("ApplyDayBounds failed: %v", err)
+	}
+	var date string
+	restarted.db.QueryRow("SELECT date FROM keystrokes").Scan(&date)
+	if want := at.AddDate(0, 0, -1).Format(dateLayout); date != want {
+		t.Errorf("Keystroke on %s, want %s", date, want)
+	}
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+	"github.com/aayushbajaj/typing-telemetry/internal/goals"
+)
+
+// GoalStatus is a day's progress towards its goals and the streak so far
+type GoalStatus struct {
+	Date    string         `json:"date"`
+	Targets []goals.Target `json:"targets"` // goals set for the day
+	Streak  goals.Streak   `json:"streak"`
+}
+
+// RecordGoals notes that g are the goals from date on, unless they already
+// are. Earlier days keep the goals recorded for them.
+func (s *Store) RecordGoals(date string, g config.Goals) error {
+	history, err := s.GetGoalHistory()
+	if err != nil {
+		return err
+	}
+	if len(history) > 0 && sameGoals(history.On(date, g), g) {
+		return nil
+	}
+
+	_, err = s.db.Exec(`
+		INSERT OR REPLACE INTO goal_history (date, keystrokes, words, typing_tests, max_mouse_feet)
+		VALUES (?, ?, ?, ?, ?)
+	`, date, g.Keystrokes, g.Words, g.TypingTests, g.MaxMouseFeet)
+	if err != nil {
+		return fmt.Errorf("failed to record goals: %w", err)
+	}
+	return nil
+}
+
+// RecordGoalChange records that the goals changed from before to after on
+// date. Without any history yet, before is recorded as the goals until then
+// so the change doesn't apply to earlier days.
+func (s *Store) RecordGoalChange(date string, before, after config.Goals) error {
+	history, err := s.GetGoalHistory()
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 && !sameGoals(before, after) {
+		day, err := time.Parse(dateLayout, date)
+		if err != nil {
+			return fmt.Errorf("invalid date %q: %w", date, err)
+		}
+		if err := s.RecordGoals(day.AddDate(0, 0, -1).Format(dateLayout), before); err != nil {
+			return err
+		}
+	}
+	return s.RecordGoals(date, after)
+}
+
+// sameGoals reports whether a and b set the same targets
+func sameGoals(a, b config.Goals) bool {
+	return a.Keystrokes == b.Keystrokes && a.Words == b.Words &&
+		a.TypingTests == b.TypingTests && a.MaxMouseFeet == b.MaxMouseFeet
+}
+
+// GetGoalHistory returns every recorded change of goals, oldest first
+func (s *Store) GetGoalHistory() (goals.History, error) {
+	rows, err := s.db.Query("SELECT date, keystrokes, words, typing_tests, max_mouse_feet FROM goal_history ORDER BY date")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goal history: %w", err)
+	}
+	defer rows.Close()
+
+	var history goals.History
+	for rows.Next() {
+		var c goals.Change
+		if err := rows.Scan(&c.Date, &c.Goals.Keystrokes, &c.Goals.Words, &c.Goals.TypingTests, &c.Goals.MaxMouseFeet); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
+
+// GetGoalDays returns the totals goals are measured against for each day
+// with any activity between from and to (see the range query conventions in
+// ranges.go), oldest first. feet converts mouse distance from pixels.
+func (s *Store) GetGoalDays(from, to string, feet func(pixels float64) float64) ([]goals.Day, error) {
+	rows, err := s.db.Query(`
+		SELECT date, SUM(keystrokes), SUM(words), SUM(tests), SUM(distance) FROM (
+			SELECT date, keystrokes, words, 0 AS tests, 0 AS distance FROM daily_summary
+			UNION ALL
+			SELECT date, 0, 0, 0, total_distance FROM mouse_daily
+			UNION ALL
+			SELECT date, 0, 0, COUNT(*), 0 FROM typing_tests GROUP BY date
+		)
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		GROUP BY date
+		ORDER BY date
+	`, from, from, to, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goal days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []goals.Day
+	for rows.Next() {
+		var d goals.Day
+		var pixels sql.NullFloat64
+		if err := rows.Scan(&d.Date, &d.Keystrokes, &d.Words, &d.TypingTests, &pixels); err != nil {
+			return nil, err
+		}
+		d.MouseFeet = feet(pixels.Float64)
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// GetGoalStatus returns today's progress towards the configured goals and
+// the streak of days, each judged by the goals recorded for it, that met
+// them. feet converts mouse distance from pixels.
+func (s *Store) GetGoalStatus(feet func(pixels float64) float64) (*GoalStatus, error) {
+	current := s.Config().Goals
+	history, err := s.GetGoalHistory()
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.GetGoalDays("", "", feet)
+	if err != nil {
+		return nil, err
+	}
+
+	today := s.Today()
+	goalsOn := func(date string) config.Goals {
+		if date == today {
+			return current
+		}
+		return history.On(date, current)
+	}
+
+	status := &GoalStatus{Date: today, Targets: []goals.Target{}}
+	todayTotals := goals.Day{Date: today}
+	if len(days) > 0 && days[len(days)-1].Date == today {
+		todayTotals = days[len(days)-1]
+	}
+	status.Targets = append(status.Targets, goals.Targets(current, todayTotals)...)
+	status.Streak = goals.Streaks(days, goalsOn, today)
+	return status, nil
+}
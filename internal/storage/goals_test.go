@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+	"github.com/aayushbajaj/typing-telemetry/internal/goals"
+)
+
+func TestGoalHistory(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	ten := config.Goals{Keystrokes: 10000}
+	twelve := config.Goals{Keystrokes: 12000, Notify: true}
+
+	// Changing from no goals records them as the goals until the change
+	if err := store.RecordGoalChange("2024-05-10", config.Goals{}, ten); err != nil {
+		t.Fatalf("RecordGoalChange failed: %v", err)
+	}
+	if err := store.RecordGoals("2024-05-12", ten); err != nil {
+		t.Fatalf("RecordGoals failed: %v", err)
+	}
+	if err := store.RecordGoals("2024-05-20", twelve); err != nil {
+		t.Fatalf("RecordGoals failed: %v", err)
+	}
+	// Recording twice on one day keeps the last goals
+	if err := store.RecordGoals("2024-05-20", twelve); err != nil {
+		t.Fatalf("RecordGoals failed: %v", err)
+	}
+
+	history, err := store.GetGoalHistory()
+	if err != nil {
+		t.Fatalf("GetGoalHistory failed: %v", err)
+	}
+	want := goals.History{
+		{Date: "2024-05-09", Goals: config.Goals{}},
+		{Date: "2024-05-10", Goals: ten},
+		{Date: "2024-05-20", Goals: config.Goals{Keystrokes: 12000}},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("GetGoalHistory = %+v, want %+v", history, want)
+	}
+	for i := range want {
+		if history[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, history[i], want[i])
+		}
+	}
+}
+
+func TestGoalStatus(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	today, _ := time.Parse(dateLayout, store.Today())
+	date := func(daysAgo int) string { return today.AddDate(0, 0, -daysAgo).Format(dateLayout) }
+	for daysAgo, keys := range []int64{7450, 12000, 10500, 8000, 11000} {
+		if err := store.AddDailyTotals(date(daysAgo), keys, keys/5); err != nil {
+			t.Fatalf("AddDailyTotals failed: %v", err)
+		}
+	}
+	if err := store.AddMouseTotals(date(0), 1200, 3, 10); err != nil {
+		t.Fatalf("AddMouseTotals failed: %v", err)
+	}
+	if err := store.RecordTypingTest(TypingTestResult{Timestamp: time.Now(), WPM: 80}); err != nil {
+		t.Fatalf("RecordTypingTest failed: %v", err)
+	}
+
+	days, err := store.GetGoalDays(date(0), date(0), func(pixels float64) float64 { return pixels / 12 })
+	if err != nil {
+		t.Fatalf("GetGoalDays failed: %v", err)
+	}
+	wantDay := goals.Day{Date: date(0), Keystrokes: 7450, Words: 1490, TypingTests: 1, MouseFeet: 100}
+	if len(days) != 1 || days[0] != wantDay {
+		t.Errorf("GetGoalDays = %+v, want %+v", days, wantDay)
+	}
+
+	// 9,000 keystrokes a day from four days ago, then 10,000 from two days
+	// ago, and 7,000 today
+	for _, c := range []struct {
+		date string
+		keys int
+	}{{date(4), 9000}, {date(2), 10000}} {
+		if err := store.RecordGoals(c.date, config.Goals{Keystrokes: c.keys}); err != nil {
+			t.Fatalf("RecordGoals failed: %v", err)
+		}
+	}
+	if err := store.SetSetting(SettingGoalKeystrokes, "7000"); err != nil {
+		t.Fatalf("SetSetting failed: %v", err)
+	}
+
+	status, err := store.GetGoalStatus(func(pixels float64) float64 { return pixels })
+	if err != nil {
+		t.Fatalf("GetGoalStatus failed: %v", err)
+	}
+	wantTarget := goals.Target{Metric: goals.Keystrokes, Goal: 7000, Value: 7450, Met: true}
+	if status.Date != date(0) || len(status.Targets) != 1 || status.Targets[0] != wantTarget {
+		t.Errorf("GetGoalStatus = %+v, want today's keystrokes against the current goal", status)
+	}
+	// 8,000 three days ago fell short of the 9,000 then, even though it
+	// would meet today's goal
+	if want := (goals.Streak{Current: 3, Longest: 3, TodayMet: true}); status.Streak != want {
+		t.Errorf("Streak = %+v, want %+v", status.Streak, want)
+	}
+}
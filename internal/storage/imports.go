@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"time"
+)
+
+// Data sources. Rows recorded by the daemon or the typing test are tagged
+// SourceTyptel; everything else records which importer brought it in.
+const (
+	SourceTyptel     = "typtel"
+	SourceExport     = "export"     // a 'typtel export' file
+	SourceTyptelDB   = "typtel-db"  // another machine's typtel.db
+	SourceMonkeytype = "monkeytype" // Monkeytype results export
+	SourceWhatPulse  = "whatpulse"  // WhatPulse keys-per-day export
+)
+
+// ImportRecord is one entry in the import log
+type ImportRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	Origin    string    `json:"origin"` // File the data was read from
+	Rows      int       `json:"rows"`
+}
+
+// AddImportedDailyTotals adds imported keystrokes and words to a day and
+// remembers how much of the day's total came from source. Existing totals are
+// summed, never overwritten.
+func (s *Store) AddImportedDailyTotals(date, source string, keystrokes, words int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO daily_summary (date, keystrokes, words) VALUES (?, ?, ?)
+		ON CONFLICT(date) DO UPDATE SET
+			keystrokes = keystrokes + excluded.keystrokes,
+			words = words + excluded.words,
+			updated_at = CURRENT_TIMESTAMP
+	`, date, keystrokes, words); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO imported_daily (date, source, keystrokes, words) VALUES (?, ?, ?, ?)
+		ON CONFLICT(date, source) DO UPDATE SET
+			keystrokes = keystrokes + excluded.keystrokes,
+			words = words + excluded.words
+	`, date, source, keystrokes, words); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetDailySources breaks a day's totals down by source. Whatever the imports
+// do not account for was recorded by typtel itself.
+func (s *Store) GetDailySources(date string) (map[string]DailyStats, error) {
+	sources := make(map[string]DailyStats)
+
+	total, err := s.GetDayStats(date)
+	if err != nil {
+		return nil, err
+	}
+	own := *total
+
+	rows, err := s.db.Query("SELECT source, keystrokes, words FROM imported_daily WHERE date = ?", date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d := DailyStats{Date: date}
+		var source string
+		if err := rows.Scan(&source, &d.Keystrokes, &d.Words); err != nil {
+			return nil, err
+		}
+		sources[source] = d
+		own.Keystrokes -= d.Keystrokes
+		own.Words -= d.Words
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if own.Keystrokes > 0 || own.Words > 0 {
+		sources[SourceTyptel] = own
+	}
+	return sources, nil
+}
+
+// LogImport records that rows were imported from origin
+func (s *Store) LogImport(source, origin string, rows int) error {
+	_, err := s.db.Exec(
+		"INSERT INTO import_log (ts, source, origin, rows) VALUES (?, ?, ?, ?)",
+		time.Now().UnixMilli(), source, origin, rows,
+	)
+	return err
+}
+
+// GetImportLog returns every recorded import, oldest first
+func (s *Store) GetImportLog() ([]ImportRecord, error) {
+	rows, err := s.db.Query("SELECT ts, source, COALESCE(origin, ''), COALESCE(rows, 0) FROM import_log ORDER BY ts, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var log []ImportRecord
+	for rows.Next() {
+		var r ImportRecord
+		var ts int64
+		if err := rows.Scan(&ts, &r.Source, &r.Origin, &r.Rows); err != nil {
+			return nil, err
+		}
+		r.Timestamp = time.UnixMilli(ts)
+		log = append(log, r)
+	}
+
+	return log, rows.Err()
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/leaderboard"
+)
+
+// LeaderboardEntry is one ranked day, hour, session or typing test
+type LeaderboardEntry struct {
+	Rank  int             `json:"rank"`
+	Date  string          `json:"date"`
+	Hour  *int            `json:"hour,omitempty"`  // hourly boards
+	Start *time.Time      `json:"start,omitempty"` // sessions and typing tests
+	Mode  *TypingTestMode `json:"mode,omitempty"`  // typing tests
+	Value float64         `json:"value"`           // in the board's metric; mouse distance in pixels
+}
+
+// LeaderboardOptions narrows a leaderboard
+type LeaderboardOptions struct {
+	Limit int             // entries to return; 0 for all
+	Mode  *TypingTestMode // typing test boards only list tests of this mode, if set
+}
+
+// leaderboardSources select the entries of each metric and period as
+// date, hour, ts, tz_offset, word_count, punctuation and value
+var leaderboardSources = map[leaderboard.Metric]map[leaderboard.Period]string{
+	leaderboard.Keystrokes: {
+		leaderboard.Day: "SELECT date, NULL, NULL, NULL, NULL, NULL, keystrokes FROM daily_summary WHERE keystrokes > 0",
+	},
+	leaderboard.Words: {
+		leaderboard.Day:  "SELECT date, NULL, NULL, NULL, NULL, NULL, words FROM daily_summary WHERE words > 0",
+		leaderboard.Hour: "SELECT date, hour, NULL, NULL, NULL, NULL, words FROM hourly_words WHERE words > 0",
+	},
+	leaderboard.MouseDistance: {
+		leaderboard.Day: "SELECT date, NULL, NULL, NULL, NULL, NULL, total_distance FROM mouse_daily WHERE movement_count > 0",
+	},
+	leaderboard.Clicks: {
+		// Days with mouse tracking on, so a day away doesn't count as no clicks
+		leaderboard.Day: "SELECT date, NULL, NULL, NULL, NULL, NULL, click_count FROM mouse_daily WHERE movement_count > 0",
+	},
+	leaderboard.TestWPM: {
+		leaderboard.Test: `SELECT date, NULL, ts, NULL, COALESCE(word_count, 0), COALESCE(punctuation, 0), wpm FROM typing_tests
+			WHERE (? = 0 OR (word_count = ? AND punctuation = ?))`,
+	},
+	leaderboard.SessionMinutes: {
+		leaderboard.Session: "SELECT date, NULL, start_ts, tz_offset, NULL, NULL, (end_ts - start_ts) / 60000.0 FROM sessions",
+	},
+}
+
+// GetLeaderboard ranks the entries of board, best first. Ties go to the
+// earlier entry.
+func (s *Store) GetLeaderboard(board leaderboard.Board, opts LeaderboardOptions) ([]LeaderboardEntry, error) {
+	source, ok := leaderboardSources[board.Metric][board.Period]
+	if !ok {
+		return nil, fmt.Errorf("no leaderboard of %s per %s", board.Metric, board.Period)
+	}
+
+	var args []interface{}
+	if board.Metric == leaderboard.TestWPM {
+		var mode TypingTestMode
+		filter := 0
+		if opts.Mode != nil {
+			mode, filter = *opts.Mode, 1
+		}
+		args = append(args, filter, mode.WordCount, mode.Punctuation)
+	}
+	order := "DESC"
+	if board.Direction == leaderboard.Least {
+		order = "ASC"
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		WITH entries (date, hour, ts, tz_offset, word_count, punctuation, value) AS (%s)
+		SELECT * FROM entries
+		ORDER BY value %s, date, hour, ts
+		LIMIT ?
+	`, source, order), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s leaderboard: %w", board.ID, err)
+	}
+	defer rows.Close()
+
+	var entries []LeaderboardEntry
+	for rows.Next() {
+		var e LeaderboardEntry
+		var hour, ts, tz, wordCount sql.NullInt64
+		var punct sql.NullBool
+		if err := rows.Scan(&e.Date, &hour, &ts, &tz, &wordCount, &punct, &e.Value); err != nil {
+			return nil, err
+		}
+		if hour.Valid {
+			h := int(hour.Int64)
+			e.Hour = &h
+		}
+		if ts.Valid {
+			// In the timezone the entry happened in, if it was recorded
+			start := time.UnixMilli(ts.Int64).Local()
+			if tz.Valid {
+				start = start.In(time.FixedZone("", int(tz.Int64)))
+			}
+			e.Start = &start
+		}
+		if wordCount.Valid {
+			e.Mode = &TypingTestMode{WordCount: int(wordCount.Int64), Punctuation: punct.Bool}
+		}
+		e.Rank = len(entries) + 1
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
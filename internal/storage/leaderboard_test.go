@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/leaderboard"
+)
+
+func TestGetLeaderboard(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	store.AddDailyTotals("2024-05-01", 500, 80)
+	store.AddDailyTotals("2024-05-02", 900, 100)
+
+	// Two words at 9am and one at 10am, each recorded as its space is typed
+	h, i, space := keyboard.KeyH, keyboard.KeyI, keyboard.KeySpace
+	nine, ten := time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC), time.Date(2024, 5, 3, 10, 0, 0, 0, time.UTC)
+	typeKeys(t, store, nine, h, i, space, h, i, space)
+	typeKeys(t, store, ten, h, i, space)
+	for _, at := range []time.Time{nine.Add(2 * time.Second), nine.Add(5 * time.Second), ten.Add(2 * time.Second)} {
+		if err := store.IncrementWordCountAt(at); err != nil {
+			t.Fatalf("IncrementWordCountAt failed: %v", err)
+		}
+	}
+
+	store.AddMouseTotals("2024-05-01", 300, 12, 10)
+	store.AddMouseTotals("2024-05-02", 100, 40, 10)
+	store.AddMouseTotals("2024-05-04", 0, 0, 0) // mouse tracking off
+
+	for _, r := range []TypingTestResult{
+		{WPM: 90, WordCount: 25},
+		{WPM: 110, WordCount: 50, Punctuation: true},
+		{WPM: 95, WordCount: 25},
+	} {
+		if err := store.RecordTypingTest(r); err != nil {
+			t.Fatalf("RecordTypingTest failed: %v", err)
+		}
+	}
+
+	// 40 minutes from 00:30 at UTC+1
+	start := time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)
+	if _, err := store.db.Exec(
+		"INSERT INTO sessions (start_ts, end_ts, tz_offset, date, keystrokes) VALUES (?, ?, 3600, '2024-05-02', 500)",
+		start.UnixMilli(), start.Add(40*time.Minute).UnixMilli(),
+	); err != nil {
+		t.Fatalf("Inserting a session failed: %v", err)
+	}
+
+	board := func(id string) leaderboard.Board {
+		b, err := leaderboard.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	tests := []struct {
+		board     string
+		opts      LeaderboardOptions
+		wantLen   int
+		wantDate  string
+		wantValue float64
+	}{
+		{"stillness", LeaderboardOptions{}, 2, "2024-05-02", 100},
+		{"keystrokes", LeaderboardOptions{}, 3, "2024-05-02", 900},
+		{"keystrokes", LeaderboardOptions{Limit: 1}, 1, "2024-05-02", 900},
+		{"words-hour", LeaderboardOptions{}, 2, "2024-05-03", 2},
+		{"test-wpm", LeaderboardOptions{}, 3, store.Today(), 110},
+		{"test-wpm", LeaderboardOptions{Mode: &TypingTestMode{WordCount: 25}}, 2, store.Today(), 95},
+		{"focus", LeaderboardOptions{}, 3, "2024-05-02", 40},
+		{"fewest-clicks", LeaderboardOptions{}, 2, "2024-05-01", 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.board, func(t *testing.T) {
+			entries, err := store.GetLeaderboard(board(tt.board), tt.opts)
+			if err != nil {
+				t.Fatalf("GetLeaderboard failed: %v", err)
+			}
+			if len(entries) != tt.wantLen {
+				t.Fatalf("Got %d entries, want %d: %+v", len(entries), tt.wantLen, entries)
+			}
+			first := entries[0]
+			if first.Rank != 1 || first.Date != tt.wantDate || first.Value != tt.wantValue {
+				t.Errorf("First entry = %+v, want %s with %v", first, tt.wantDate, tt.wantValue)
+			}
+			for n, e := range entries {
+				if e.Rank != n+1 {
+					t.Errorf("Entry %d has rank %d", n, e.Rank)
+				}
+			}
+		})
+	}
+
+	entries, _ := store.GetLeaderboard(board("words-hour"), LeaderboardOptions{})
+	if len(entries) > 0 && (entries[0].Hour == nil || *entries[0].Hour != 9) {
+		t.Errorf("Busiest hour = %+v, want 9am", entries[0])
+	}
+	entries, _ = store.GetLeaderboard(board("test-wpm"), LeaderboardOptions{})
+	if len(entries) > 0 && (entries[0].Mode == nil || *entries[0].Mode != (TypingTestMode{WordCount: 50, Punctuation: true})) {
+		t.Errorf("Fastest test = %+v, want the 50 word test with punctuation", entries[0])
+	}
+	entries, _ = store.GetLeaderboard(board("focus"), LeaderboardOptions{})
+	if len(entries) > 0 && (entries[0].Start == nil || entries[0].Start.Format("15:04") != "00:30") {
+		t.Errorf("Longest session = %+v, want it to start at 00:30 local time", entries[0])
+	}
+
+	if _, err := store.GetLeaderboard(leaderboard.Board{Metric: leaderboard.Clicks, Period: leaderboard.Hour}, LeaderboardOptions{}); err == nil {
+		t.Error("GetLeaderboard should reject a metric and period it can't measure")
+	}
+}
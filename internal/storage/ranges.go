@@ -0,0 +1,262 @@
+package storage
+
+import "github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+
+// Range queries return every stored row between from and to, which are
+// inclusive logical dates ("2006-01-02"). An empty bound is unbounded.
+// Rows come back in date order; days without data are omitted.
+
+// HourlyCount is the number of keystrokes in one clock hour of a day
+type HourlyCount struct {
+	Date       string
+	Hour       int
+	Keystrokes int64
+}
+
+// KeyCount is how often a keycode was pressed on a day
+type KeyCount struct {
+	Date    string
+	Keycode int
+	Count   int64
+}
+
+// KeyFrequency is how often a keycode was pressed over a range of days
+type KeyFrequency struct {
+	Keycode int   `json:"keycode"`
+	Count   int64 `json:"count"`
+}
+
+// ShortcutCount is how often a key was pressed with the same modifiers held
+// over a range of days
+type ShortcutCount struct {
+	Keycode   int                `json:"keycode"`
+	Modifiers keyboard.Modifiers `json:"modifiers"`
+	Count     int64              `json:"count"`
+}
+
+// GetDailyRange returns daily keystroke and word totals
+func (s *Store) GetDailyRange(from, to string) ([]DailyStats, error) {
+	rows, err := s.db.Query(`
+		SELECT date, COALESCE(keystrokes, 0), COALESCE(words, 0)
+		FROM daily_summary
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []DailyStats
+	for rows.Next() {
+		var d DailyStats
+		if err := rows.Scan(&d.Date, &d.Keystrokes, &d.Words); err != nil {
+			return nil, err
+		}
+		stats = append(stats, d)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetHourlyRange returns keystroke counts per date and hour, including
+// imported hourly counts
+func (s *Store) GetHourlyRange(from, to string) ([]HourlyCount, error) {
+	rows, err := s.db.Query(`
+		SELECT date, hour, SUM(n) FROM (
+			SELECT date, hour, COUNT(*) AS n FROM keystrokes
+			WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+			GROUP BY date, hour
+			UNION ALL
+			SELECT date, hour, keystrokes AS n FROM imported_hourly
+			WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		)
+		GROUP BY date, hour
+		ORDER BY date, hour
+	`, from, from, to, to, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []HourlyCount
+	for rows.Next() {
+		var c HourlyCount
+		if err := rows.Scan(&c.Date, &c.Hour, &c.Keystrokes); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+// GetKeyCountRange returns per-key press counts per date, including imported
+// key counts
+func (s *Store) GetKeyCountRange(from, to string) ([]KeyCount, error) {
+	rows, err := s.db.Query(`
+		SELECT date, keycode, SUM(n) FROM (
+			SELECT date, keycode, COUNT(*) AS n FROM keystrokes
+			WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+			GROUP BY date, keycode
+			UNION ALL
+			SELECT date, keycode, count AS n FROM imported_keys
+			WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		)
+		GROUP BY date, keycode
+		ORDER BY date, keycode
+	`, from, from, to, to, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []KeyCount
+	for rows.Next() {
+		var c KeyCount
+		if err := rows.Scan(&c.Date, &c.Keycode, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+// GetKeyFrequencies returns how often each keycode was pressed between from
+// and to, including imported key counts, most pressed first
+func (s *Store) GetKeyFrequencies(from, to string) ([]KeyFrequency, error) {
+	rows, err := s.db.Query(`
+		SELECT keycode, SUM(n) AS total FROM (
+			SELECT keycode, COUNT(*) AS n FROM keystrokes
+			WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+			GROUP BY keycode
+			UNION ALL
+			SELECT keycode, count AS n FROM imported_keys
+			WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		)
+		GROUP BY keycode
+		ORDER BY total DESC, keycode
+	`, from, from, to, to, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var freqs []KeyFrequency
+	for rows.Next() {
+		var f KeyFrequency
+		if err := rows.Scan(&f.Keycode, &f.Count); err != nil {
+			return nil, err
+		}
+		freqs = append(freqs, f)
+	}
+
+	return freqs, rows.Err()
+}
+
+// GetShortcuts returns how often each shortcut (see keyboard.IsShortcut) was
+// pressed between from and to, most pressed first
+func (s *Store) GetShortcuts(from, to string) ([]ShortcutCount, error) {
+	rows, err := s.db.Query(`
+		SELECT keycode, modifiers, SUM(n) AS total FROM (
+			SELECT keycode, modifiers, COUNT(*) AS n FROM keystrokes
+			WHERE modifiers & ? != 0 AND (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+			GROUP BY keycode, modifiers
+			UNION ALL
+			SELECT keycode, modifiers, count AS n FROM imported_shortcuts
+			WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		)
+		GROUP BY keycode, modifiers
+		ORDER BY total DESC, keycode, modifiers
+	`, keyboard.ShortcutModifiers, from, from, to, to, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shortcuts []ShortcutCount
+	for rows.Next() {
+		var sc ShortcutCount
+		if err := rows.Scan(&sc.Keycode, &sc.Modifiers, &sc.Count); err != nil {
+			return nil, err
+		}
+		if keyboard.IsShortcut(sc.Keycode, sc.Modifiers) {
+			shortcuts = append(shortcuts, sc)
+		}
+	}
+
+	return shortcuts, rows.Err()
+}
+
+// GetMouseRange returns mouse totals per day
+func (s *Store) GetMouseRange(from, to string) ([]MouseDailyStats, error) {
+	rows, err := s.db.Query(`
+		SELECT date, COALESCE(total_distance, 0), COALESCE(movement_count, 0), COALESCE(click_count, 0)
+		FROM mouse_daily
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []MouseDailyStats
+	for rows.Next() {
+		var m MouseDailyStats
+		if err := rows.Scan(&m.Date, &m.TotalDistance, &m.MovementCount, &m.ClickCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, m)
+	}
+
+	return stats, rows.Err()
+}
+
+// AddDailyTotals adds keystrokes and words to a day, creating it if needed.
+// Existing totals are summed, never overwritten.
+func (s *Store) AddDailyTotals(date string, keystrokes, words int64) error {
+	_, err := s.db.Exec(`
+		INSERT INTO daily_summary (date, keystrokes, words) VALUES (?, ?, ?)
+		ON CONFLICT(date) DO UPDATE SET
+			keystrokes = keystrokes + excluded.keystrokes,
+			words = words + excluded.words,
+			updated_at = CURRENT_TIMESTAMP
+	`, date, keystrokes, words)
+	return err
+}
+
+// AddHourlyCount adds keystrokes to an hour of a day without individual
+// keystroke rows (used when importing aggregated data). It does not change
+// daily_summary; add the day's total with AddDailyTotals.
+func (s *Store) AddHourlyCount(date string, hour int, keystrokes int64) error {
+	_, err := s.db.Exec(`
+		INSERT INTO imported_hourly (date, hour, keystrokes) VALUES (?, ?, ?)
+		ON CONFLICT(date, hour) DO UPDATE SET keystrokes = keystrokes + excluded.keystrokes
+	`, date, hour, keystrokes)
+	return err
+}
+
+// AddKeyCount adds presses of a keycode on a day without individual
+// keystroke rows (used when importing aggregated data)
+func (s *Store) AddKeyCount(date string, keycode int, count int64) error {
+	_, err := s.db.Exec(`
+		INSERT INTO imported_keys (date, keycode, count) VALUES (?, ?, ?)
+		ON CONFLICT(date, keycode) DO UPDATE SET count = count + excluded.count
+	`, date, keycode, count)
+	return err
+}
+
+// AddMouseTotals adds distance, clicks and movement events to a day
+func (s *Store) AddMouseTotals(date string, distance float64, clicks, movements int64) error {
+	_, err := s.db.Exec(`
+		INSERT INTO mouse_daily (date, total_distance, click_count, movement_count) VALUES (?, ?, ?, ?)
+		ON CONFLICT(date) DO UPDATE SET
+			total_distance = total_distance + excluded.total_distance,
+			click_count = click_count + excluded.click_count,
+			movement_count = movement_count + excluded.movement_count,
+			updated_at = CURRENT_TIMESTAMP
+	`, date, distance, clicks, movements)
+	return err
+}
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+func TestGetDailyRange(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	for _, date := range []string{"2024-01-01", "2024-01-02", "2024-01-03"} {
+		if err := store.AddDailyTotals(date, 100, 20); err != nil {
+			t.Fatalf("AddDailyTotals failed: %v", err)
+		}
+	}
+	// Adding to an existing day sums
+	if err := store.AddDailyTotals("2024-01-02", 5, 1); err != nil {
+		t.Fatalf("AddDailyTotals failed: %v", err)
+	}
+
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		{"", "", []string{"2024-01-01", "2024-01-02", "2024-01-03"}},
+		{"2024-01-02", "", []string{"2024-01-02", "2024-01-03"}},
+		{"", "2024-01-01", []string{"2024-01-01"}},
+		{"2024-01-02", "2024-01-02", []string{"2024-01-02"}},
+		{"2025-01-01", "", nil},
+	}
+
+	for _, tt := range tests {
+		stats, err := store.GetDailyRange(tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("GetDailyRange(%q, %q) failed: %v", tt.from, tt.to, err)
+		}
+		var dates []string
+		for _, d := range stats {
+			dates = append(dates, d.Date)
+			if d.Date == "2024-01-02" && (d.Keystrokes != 105 || d.Words != 21) {
+				t.Errorf("2024-01-02 = %d keystrokes, %d words; want 105, 21", d.Keystrokes, d.Words)
+			}
+		}
+		if len(dates) != len(tt.want) {
+			t.Errorf("GetDailyRange(%q, %q) = %v, want %v", tt.from, tt.to, dates, tt.want)
+			continue
+		}
+		for i := range dates {
+			if dates[i] != tt.want[i] {
+				t.Errorf("GetDailyRange(%q, %q) = %v, want %v", tt.from, tt.to, dates, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestHourlyAndKeyRangesIncludeImported(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	at := time.Date(2024, 1, 1, 10, 30, 0, 0, time.Local)
+	store.RecordKeystrokeAt(0, at)
+	store.RecordKeystrokeAt(0, at)
+	store.AddHourlyCount("2024-01-01", 10, 3)
+	store.AddHourlyCount("2024-01-01", 11, 4)
+	store.AddKeyCount("2024-01-01", 0, 5)
+	store.AddKeyCount("2024-01-01", 0, 1)
+
+	hours, err := store.GetHourlyRange("", "")
+	if err != nil {
+		t.Fatalf("GetHourlyRange failed: %v", err)
+	}
+	want := []HourlyCount{{"2024-01-01", 10, 5}, {"2024-01-01", 11, 4}}
+	if len(hours) != len(want) || hours[0] != want[0] || hours[1] != want[1] {
+		t.Errorf("GetHourlyRange = %+v, want %+v", hours, want)
+	}
+
+	keys, err := store.GetKeyCountRange("", "")
+	if err != nil {
+		t.Fatalf("GetKeyCountRange failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != (KeyCount{"2024-01-01", 0, 8}) {
+		t.Errorf("GetKeyCountRange = %+v, want one row with count 8", keys)
+	}
+
+	// The per-day hourly stats used by the charts see imported hours too
+	stats, err := store.GetHourlyStats("2024-01-01")
+	if err != nil {
+		t.Fatalf("GetHourlyStats failed: %v", err)
+	}
+	if stats[10].Keystrokes != 5 || stats[11].Keystrokes != 4 {
+		t.Errorf("GetHourlyStats hours 10/11 = %d/%d, want 5/4", stats[10].Keystrokes, stats[11].Keystrokes)
+	}
+}
+
+func TestGetKeyFrequencies(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	day1 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	day2 := time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local)
+	for i := 0; i < 3; i++ {
+		store.RecordKeystrokeAt(14, day1) // E
+	}
+	store.RecordKeystrokeAt(0, day1) // A
+	store.RecordKeystrokeAt(49, day2)
+	store.RecordKeystrokeAt(49, day2)
+	store.AddKeyCount("2024-01-02", 0, 4)
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []KeyFrequency
+	}{
+		{"all", "", "", []KeyFrequency{{0, 5}, {14, 3}, {49, 2}}},
+		{"first day", "2024-01-01", "2024-01-01", []KeyFrequency{{14, 3}, {0, 1}}},
+		{"second day", "2024-01-02", "", []KeyFrequency{{0, 4}, {49, 2}}},
+		{"empty", "2024-02-01", "2024-02-28", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.GetKeyFrequencies(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GetKeyFrequencies failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetKeyFrequencies = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("GetKeyFrequencies = %+v, want %+v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestGetShortcuts(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	cmd, ctrlShift := keyboard.ModCommand, keyboard.ModControl|keyboard.ModShift
+	day1 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	day2 := time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local)
+	for i := 0; i < 3; i++ {
+		store.RecordChordAt(keyboard.KeyS, cmd, day1)
+	}
+	store.RecordChordAt(keyboard.KeyTab, ctrlShift, day1)
+	store.RecordChordAt(keyboard.KeyS, cmd, day2)
+	store.RecordChordAt(keyboard.KeyTab, ctrlShift, day2)
+	store.RecordChordAt(keyboard.KeyTab, ctrlShift, day2)
+
+	// Not shortcuts: plain keys, capitals and modifier-only presses
+	store.RecordKeystrokeAt(keyboard.KeyS, day1)
+	store.RecordChordAt(keyboard.KeyS, keyboard.ModShift, day1)
+	store.RecordChordAt(keyboard.KeyShift, cmd, day1)
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []ShortcutCount
+	}{
+		{"all", "", "", []ShortcutCount{{keyboard.KeyS, cmd, 4}, {keyboard.KeyTab, ctrlShift, 3}}},
+		{"first day", "2024-01-01", "2024-01-01", []ShortcutCount{{keyboard.KeyS, cmd, 3}, {keyboard.KeyTab, ctrlShift, 1}}},
+		{"second day", "2024-01-02", "", []ShortcutCount{{keyboard.KeyTab, ctrlShift, 2}, {keyboard.KeyS, cmd, 1}}},
+		{"empty", "2024-02-01", "2024-02-28", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.GetShortcuts(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GetShortcuts failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetShortcuts = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("GetShortcuts = %+v, want %+v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	// Shortcuts are ordinary keystrokes in the key counts
+	freqs, _ := store.GetKeyFrequencies("", "")
+	if freqs[0] != (KeyFrequency{keyboard.KeyS, 6}) {
+		t.Errorf("GetKeyFrequencies()[0] = %+v, want 6 presses of S", freqs[0])
+	}
+}
+
+func TestGetMouseRange(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	store.AddMouseTotals("2024-01-01", 100.5, 2, 10)
+	store.AddMouseTotals("2024-01-01", 50, 1, 5)
+	store.AddMouseTotals("2024-02-01", 1, 1, 1)
+
+	stats, err := store.GetMouseRange("2024-01-01", "2024-01-31")
+	if err != nil {
+		t.Fatalf("GetMouseRange failed: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("Expected 1 day, got %d", len(stats))
+	}
+	m := stats[0]
+	if m.TotalDistance != 150.5 || m.ClickCount != 3 || m.MovementCount != 15 {
+		t.Errorf("Mouse totals = %+v, want 150.5 distance, 3 clicks, 15 movements", m)
+	}
+}
+
+func TestTypingTestHistory(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	first := TypingTestResult{
+		Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Date:      "2024-01-01",
+		WPM:       80,
+		Accuracy:  95,
+		Duration:  30 * time.Second,
+		WordCount: 25,
+		Source:    SourceTyptel,
+	}
+	second := first
+	second.Timestamp = first.Timestamp.Add(48 * time.Hour)
+	second.Date = "2024-01-03"
+	second.WPM = 90
+	second.Punctuation = true
+	second.Source = SourceMonkeytype
+
+	for _, r := range []TypingTestResult{second, first} {
+		if err := store.RecordTypingTest(r); err != nil {
+			t.Fatalf("RecordTypingTest failed: %v", err)
+		}
+	}
+
+	all, err := store.GetTypingTestHistory("", "")
+	if err != nil {
+		t.Fatalf("GetTypingTestHistory failed: %v", err)
+	}
+	if len(all) != 2 || all[0] != first || all[1] != second {
+		t.Errorf("GetTypingTestHistory = %+v, want [%+v %+v]", all, first, second)
+	}
+
+	later, err := store.GetTypingTestHistory("2024-01-02", "")
+	if err != nil {
+		t.Fatalf("GetTypingTestHistory failed: %v", err)
+	}
+	if len(later) != 1 || later[0].Mode() != (TypingTestMode{WordCount: 25, Punctuation: true}) {
+		t.Errorf("Expected only the punctuation test, got %+v", later)
+	}
+
+	// History is separate from the personal best settings
+	if stats := store.GetTypingTestStats(); stats.TestCount != 0 {
+		t.Errorf("Expected no personal best stats, got %d tests", stats.TestCount)
+	}
+}
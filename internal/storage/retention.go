@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+// PruneKeystrokes applies the retention.keystroke_days setting: individual
+// keystrokes on days before the retention window are folded into hourly,
+// per-key and shortcut totals and deleted. Those counts and the daily totals
+// are unchanged; only the raw rows (and the ability to re-bucket them with
+// RebuildKeystrokeDates) are lost. Returns the number of rows deleted.
+func (s *Store) PruneKeystrokes() (int64, error) {
+	days := s.Config().Retention.KeystrokeDays
+	if days <= 0 {
+		return 0, nil
+	}
+	today, _ := time.Parse(dateLayout, s.Today())
+	return s.PruneKeystrokesBefore(today.AddDate(0, 0, -days+1).Format(dateLayout))
+}
+
+// PruneKeystrokesBefore folds the individual keystrokes of days before date
+// into hourly, per-key and shortcut totals and deletes them. With sync on,
+// keystrokes not yet in a changeset are kept until they are, since folded
+// totals aren't synced.
+func (s *Store) PruneKeystrokesBefore(date string) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	maxID := int64(math.MaxInt64)
+	if s.SyncDir() != "" {
+		if maxID, err = syncedKeystrokeID(tx); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO imported_hourly (date, hour, keystrokes)
+		SELECT date, hour, COUNT(*) FROM keystrokes WHERE date < ? AND id <= ? GROUP BY date, hour
+		ON CONFLICT(date, hour) DO UPDATE SET keystrokes = keystrokes + excluded.keystrokes
+	`, date, maxID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fold hourly counts: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO imported_keys (date, keycode, count)
+		SELECT date, keycode, COUNT(*) FROM keystrokes WHERE date < ? AND id <= ? GROUP BY date, keycode
+		ON CONFLICT(date, keycode) DO UPDATE SET count = count + excluded.count
+	`, date, maxID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fold key counts: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO imported_shortcuts (date, keycode, modifiers, count)
+		SELECT date, keycode, modifiers, COUNT(*) FROM keystrokes
+		WHERE date < ? AND id <= ? AND modifiers & ? != 0 GROUP BY date, keycode, modifiers
+		ON CONFLICT(date, keycode, modifiers) DO UPDATE SET count = count + excluded.count
+	`, date, maxID, keyboard.ShortcutModifiers)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fold shortcut counts: %w", err)
+	}
+
+	res, err := tx.Exec("DELETE FROM keystrokes WHERE date < ? AND id <= ?", date, maxID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete keystrokes: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
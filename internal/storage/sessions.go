@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/sessions"
+)
+
+// StoredSession is a session of activity, credited to the day it started on
+type StoredSession struct {
+	ID   int64
+	Date string
+	sessions.Session
+}
+
+// DailySessions sums up the sessions of a day
+type DailySessions struct {
+	Date           string  `json:"date"`
+	Sessions       int     `json:"sessions"`
+	ActiveMinutes  float64 `json:"active_minutes"`
+	LongestMinutes float64 `json:"longest_minutes"` // longest focus session
+	Breaks         int     `json:"breaks"`          // gaps of at least the idle gap between sessions
+}
+
+// SessionIdleGap returns how long without activity ends a session
+// (sessions.idle_minutes), cached until a setting changes
+func (s *Store) SessionIdleGap() time.Duration {
+	return s.hotSettings().idleGap
+}
+
+// trackActivity adds activity at t to the session in progress, or starts a
+// new one after the idle gap. The session's row is kept up to date as it
+// grows, so a restart within the gap carries on where it left off.
+//
+// Callers pass their write transaction, so the database lock is always taken
+// before sessMu; taking them the other way round deadlocks until the busy
+// timeout.
+func (s *Store) trackActivity(tx *sql.Tx, t time.Time, keystrokes int64, distance float64) error {
+	gap := s.SessionIdleGap()
+
+	s.sessMu.Lock()
+	defer s.sessMu.Unlock()
+
+	if s.sessionID == 0 {
+		if err := s.resumeSession(tx); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if s.session.Add(t, gap, keystrokes, distance) {
+		err = s.insertSession(tx)
+	} else {
+		cur, _ := s.session.Current()
+		_, err = tx.Exec(
+			"UPDATE sessions SET start_ts = ?, end_ts = ?, keystrokes = ?, mouse_distance = ? WHERE id = ?",
+			cur.Start.UnixMilli(), cur.End.UnixMilli(), cur.Keystrokes, cur.MouseDistance, s.sessionID,
+		)
+	}
+	if err != nil {
+		// Reload from the database next time, in case the write is rolled back
+		s.sessionID = 0
+		s.session = sessions.Tracker{}
+		return fmt.Errorf("failed to record session: %w", err)
+	}
+	return nil
+}
+
+// resumeSession picks up the most recent stored session
+func (s *Store) resumeSession(tx *sql.Tx) error {
+	var id, start, end int64
+	var last sessions.Session
+	err := tx.QueryRow(
+		"SELECT id, start_ts, end_ts, keystrokes, mouse_distance FROM sessions ORDER BY end_ts DESC LIMIT 1",
+	).Scan(&id, &start, &end, &last.Keystrokes, &last.MouseDistance)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read last session: %w", err)
+	}
+	last.Start, last.End = time.UnixMilli(start), time.UnixMilli(end)
+	s.session.Resume(last)
+	s.sessionID = id
+	return nil
+}
+
+// insertSession stores the session that just started
+func (s *Store) insertSession(tx *sql.Tx) error {
+	cur, _ := s.session.Current()
+	bounds := s.dayBounds()
+	wall := cur.Start.In(bounds.loc)
+	date, _ := bucket(wall, bounds.dayStart)
+	_, offset := wall.Zone()
+
+	res, err := tx.Exec(`
+		INSERT INTO sessions (start_ts, end_ts, tz_offset, date, keystrokes, mouse_distance)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, cur.Start.UnixMilli(), cur.End.UnixMilli(), offset, date, cur.Keystrokes, cur.MouseDistance)
+	if err != nil {
+		return err
+	}
+	s.sessionID, err = res.LastInsertId()
+	return err
+}
+
+// backfillSessions splits the stored keystrokes into sessions with the
+// default idle gap, for databases from before sessions were recorded. Each
+// session is credited to the date of its first keystroke. Mouse activity
+// wasn't timestamped, so these sessions have no mouse distance.
+func backfillSessions(db *sql.DB) error {
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sessions").Scan(&n); err != nil || n > 0 {
+		return err
+	}
+
+	type row struct {
+		date   string
+		offset int
+	}
+	rows, err := db.Query("SELECT ts, COALESCE(tz_offset, 0), date FROM keystrokes WHERE ts IS NOT NULL ORDER BY ts")
+	if err != nil {
+		return fmt.Errorf("failed to read keystrokes: %w", err)
+	}
+	var tracker sessions.Tracker
+	var found []sessions.Session
+	var firsts []row
+	for rows.Next() {
+		var ts int64
+		var r row
+		if err := rows.Scan(&ts, &r.offset, &r.date); err != nil {
+			rows.Close()
+			return err
+		}
+		if cur, open := tracker.Current(); tracker.Add(time.UnixMilli(ts), sessions.DefaultIdleGap, 1, 0) {
+			if open {
+				found = append(found, cur)
+			}
+			firsts = append(firsts, r)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if cur, open := tracker.Current(); open {
+		found = append(found, cur)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, session := range found {
+		if _, err := tx.Exec(`
+			INSERT INTO sessions (start_ts, end_ts, tz_offset, date, keystrokes, mouse_distance)
+			VALUES (?, ?, ?, ?, ?, 0)
+		`, session.Start.UnixMilli(), session.End.UnixMilli(), firsts[i].offset, firsts[i].date, session.Keystrokes); err != nil {
+			return fmt.Errorf("failed to backfill sessions: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// rebuildRowDates moves the rows of table whose tsColumn is at or after since
+// (UTC epoch milliseconds) to the day it falls into under the current
+// timezone and day start settings (see RebuildKeystrokeDates). The table
+// needs id, tz_offset and date columns.
+func (s *Store) rebuildRowDates(table, tsColumn string, loc *time.Location, dayStart int, since int64) error {
+	type move struct {
+		id       int64
+		newDate  string
+		tzOffset int
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf("SELECT id, %s, COALESCE(tz_offset, 0), date FROM %s WHERE %s >= ?", tsColumn, table, tsColumn), since)
+	if err != nil {
+		return err
+	}
+	var moves []move
+	for rows.Next() {
+		var id, ts int64
+		var offset int
+		var date string
+		if err := rows.Scan(&id, &ts, &offset, &date); err != nil {
+			rows.Close()
+			return err
+		}
+		if newDate, _, newOffset := rebucket(ts, offset, loc, dayStart); newDate != date {
+			moves = append(moves, move{id, newDate, newOffset})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(moves) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, m := range moves {
+		if _, err := tx.Exec("UPDATE "+table+" SET date = ?, tz_offset = ? WHERE id = ?", m.newDate, m.tzOffset, m.id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSessionsRange returns the sessions that started between from and to,
+// oldest first (see the range query conventions in ranges.go)
+func (s *Store) GetSessionsRange(from, to string) ([]StoredSession, error) {
+	rows, err := s.db.Query(`
+		SELECT id, date, start_ts, end_ts, keystrokes, mouse_distance
+		FROM sessions
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY start_ts
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []StoredSession
+	for rows.Next() {
+		var ss StoredSession
+		var start, end int64
+		if err := rows.Scan(&ss.ID, &ss.Date, &start, &end, &ss.Keystrokes, &ss.MouseDistance); err != nil {
+			return nil, err
+		}
+		ss.Start, ss.End = time.UnixMilli(start), time.UnixMilli(end)
+		found = append(found, ss)
+	}
+	return found, rows.Err()
+}
+
+// GetDailySessionsRange sums up the sessions of each day between from and
+// to that had any
+func (s *Store) GetDailySessionsRange(from, to string) ([]DailySessions, error) {
+	stored, err := s.GetSessionsRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []string
+	byDate := make(map[string][]sessions.Session)
+	for _, ss := range stored {
+		if _, ok := byDate[ss.Date]; !ok {
+			dates = append(dates, ss.Date)
+		}
+		byDate[ss.Date] = append(byDate[ss.Date], ss.Session)
+	}
+	sort.Strings(dates)
+
+	days := make([]DailySessions, len(dates))
+	for i, date := range dates {
+		sum := sessions.Summarize(byDate[date])
+		days[i] = DailySessions{
+			Date:           date,
+			Sessions:       sum.Sessions,
+			ActiveMinutes:  minutes(sum.Active),
+			LongestMinutes: minutes(sum.Longest),
+			Breaks:         sum.Breaks,
+		}
+	}
+	return days, nil
+}
+
+// minutes converts d to minutes with one decimal
+func minutes(d time.Duration) float64 {
+	return float64(int64(d.Minutes()*10+0.5)) / 10
+}
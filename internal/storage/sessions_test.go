@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/sessions"
+)
+
+func TestSessions(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	a := keyboard.KeyA
+	nine := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	typeKeys(t, store, nine, a, a, a)                     // 9:00:00-9:00:02
+	typeKeys(t, store, nine.Add(4*time.Minute), a, a)     // within the gap
+	typeKeys(t, store, nine.Add(30*time.Minute), a)       // after a break
+	typeKeys(t, store, nine.Add(2*time.Hour), a, a, a, a) // after another
+	typeKeys(t, store, nine.AddDate(0, 0, 1), a, a)       // the next day
+
+	stored, err := store.GetSessionsRange("2024-05-02", "2024-05-02")
+	if err != nil {
+		t.Fatalf("GetSessionsRange failed: %v", err)
+	}
+	want := []sessions.Session{
+		{Start: nine, End: nine.Add(4*time.Minute + time.Second), Keystrokes: 5},
+		{Start: nine.Add(30 * time.Minute), End: nine.Add(30 * time.Minute), Keystrokes: 1},
+	}
+	if len(stored) != 3 {
+		t.Fatalf("Got %d sessions on 2024-05-02, want 3: %+v", len(stored), stored)
+	}
+	for i, w := range want {
+		if !sameSession(stored[i].Session, w) || stored[i].Date != "2024-05-02" {
+			t.Errorf("session %d = %+v, want %+v", i, stored[i], w)
+		}
+	}
+
+	daily, err := store.GetDailySessionsRange("", "")
+	if err != nil {
+		t.Fatalf("GetDailySessionsRange failed: %v", err)
+	}
+	wantDaily := []DailySessions{
+		{Date: "2024-05-02", Sessions: 3, ActiveMinutes: 4.1, LongestMinutes: 4, Breaks: 2},
+		{Date: "2024-05-03", Sessions: 1, ActiveMinutes: 0, LongestMinutes: 0, Breaks: 0},
+	}
+	if len(daily) != len(wantDaily) || daily[0] != wantDaily[0] || daily[1] != wantDaily[1] {
+		t.Errorf("GetDailySessionsRange = %+v, want %+v", daily, wantDaily)
+	}
+}
+
+func TestSessionsIdleGapSetting(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	if got := store.SessionIdleGap(); got != sessions.DefaultIdleGap {
+		t.Errorf("Default idle gap = %v, want %v", got, sessions.DefaultIdleGap)
+	}
+	if err := store.SetSetting(SettingSessionIdleMinutes, "15"); err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	typeKeys(t, store, at, keyboard.KeyA)
+	typeKeys(t, store, at.Add(10*time.Minute), keyboard.KeyA)
+
+	daily, _ := store.GetDailySessionsRange("", "")
+	if len(daily) != 1 || daily[0].Sessions != 1 || daily[0].ActiveMinutes != 10 {
+		t.Errorf("With a 15 minute gap got %+v, want one 10 minute session", daily)
+	}
+}
+
+func TestSessionIdleGapCached(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SessionIdleGap()
+
+	// A row written behind the store's back isn't read on every event
+	store.db.Exec("INSERT INTO settings (key, value) VALUES (?, '20')", SettingSessionIdleMinutes)
+	if got := store.SessionIdleGap(); got != sessions.DefaultIdleGap {
+		t.Errorf("Idle gap = %v, want the cached %v", got, sessions.DefaultIdleGap)
+	}
+
+	// Changing a setting through the store reads it again
+	store.SetSetting("unrelated", "x")
+	if got := store.SessionIdleGap(); got != 20*time.Minute {
+		t.Errorf("Idle gap = %v after a settings change, want 20m", got)
+	}
+}
+
+func TestSessionsMouseAndRestart(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if err := store.RecordKeystroke(keyboard.KeyA); err != nil {
+		t.Fatalf("RecordKeystroke failed: %v", err)
+	}
+	if err := store.RecordMouseMovement(10, 10, 250); err != nil {
+		t.Fatalf("RecordMouseMovement failed: %v", err)
+	}
+
+	// A restarted daemon carries on with the last session
+	store.session, store.sessionID = sessions.Tracker{}, 0
+	if err := store.RecordMouseClick(); err != nil {
+		t.Fatalf("RecordMouseClick failed: %v", err)
+	}
+	if err := store.RecordMouseMovement(20, 20, 50); err != nil {
+		t.Fatalf("RecordMouseMovement failed: %v", err)
+	}
+
+	stored, err := store.GetSessionsRange("", "")
+	if err != nil {
+		t.Fatalf("GetSessionsRange failed: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Keystrokes != 1 || stored[0].MouseDistance != 300 || stored[0].Date != store.Today() {
+		t.Errorf("Sessions = %+v, want one with a keystroke and 300px of mouse movement today", stored)
+	}
+}
+
+func TestSessionsBackfill(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	at := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	typeKeys(t, store, at, keyboard.KeyA, keyboard.KeyA)
+	typeKeys(t, store, at.Add(time.Hour), keyboard.KeyA)
+
+	// A database from before sessions were recorded
+	if _, err := store.db.Exec("DELETE FROM sessions"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.db.Exec("PRAGMA user_version = 8"); err != nil {
+		t.Fatal(err)
+	}
+	if err := initSchema(store.db); err != nil {
+		t.Fatalf("initSchema failed: %v", err)
+	}
+
+	stored, _ := store.GetSessionsRange("", "")
+	if len(stored) != 2 || stored[0].Keystrokes != 2 || stored[0].Duration() != time.Second || stored[1].Keystrokes != 1 {
+		t.Errorf("Backfilled sessions = %+v", stored)
+	}
+}
+
+func TestRebuildMovesSessions(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	// 02:15 UTC moves to the previous day once days start at 4am
+	typeKeys(t, store, time.Date(2024, 5, 2, 2, 15, 0, 0, time.UTC), keyboard.KeyA)
+	if err := store.SetDayStartHour(4); err != nil {
+		t.Fatalf("SetDayStartHour failed: %v", err)
+	}
+
+	daily, _ := store.GetDailySessionsRange("", "")
+	if len(daily) != 1 || daily[0].Date != "2024-05-01" {
+		t.Errorf("Expected the session on 2024-05-01, got %+v", daily)
+	}
+}
+
+func sameSession(a, b sessions.Session) bool {
+	return a.Start.Equal(b.Start) && a.End.Equal(b.End) && a.Keystrokes == b.Keystrokes && a.MouseDistance == b.MouseDistance
+}
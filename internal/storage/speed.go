@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/speed"
+	"github.com/aayushbajaj/typing-telemetry/pkg/stats"
+)
+
+// Speed summarises the typing bursts measured over some span (see
+// internal/speed)
+type Speed struct {
+	Bursts    int     `json:"bursts"`
+	MedianWPM float64 `json:"median_wpm"`
+	P90WPM    float64 `json:"p90_wpm"`
+}
+
+// HourlySpeed is the typing speed in one clock hour of a day
+type HourlySpeed struct {
+	Date string `json:"date"`
+	Hour int    `json:"hour"`
+	Speed
+}
+
+// DailySpeed is the typing speed on a day
+type DailySpeed struct {
+	Date string `json:"date"`
+	Speed
+}
+
+// speedOf summarises burst speeds
+func speedOf(wpms []float64) Speed {
+	return Speed{
+		Bursts:    len(wpms),
+		MedianWPM: stats.Percentile(wpms, 50),
+		P90WPM:    stats.Percentile(wpms, 90),
+	}
+}
+
+// RecordTypingBurst stores a burst of continuous typing, credited to the
+// hour it started in, and updates that hour's median and p90 speed
+func (s *Store) RecordTypingBurst(b speed.Burst) error {
+	bounds := s.dayBounds()
+	wall := b.Start.In(bounds.loc)
+	date, hour := bucket(wall, bounds.dayStart)
+	_, offset := wall.Zone()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO typing_bursts (ts, tz_offset, date, hour, duration_ms, chars, wpm)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, b.Start.UnixMilli(), offset, date, hour, b.Duration().Milliseconds(), b.Chars, b.WPM()); err != nil {
+		return fmt.Errorf("failed to record typing burst: %w", err)
+	}
+	if err := updateHourlySpeed(tx, date, hour); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateHourlySpeed recomputes an hour's row in hourly_speed from its bursts
+func updateHourlySpeed(tx *sql.Tx, date string, hour int) error {
+	wpms, err := burstSpeeds(tx, "SELECT wpm FROM typing_bursts WHERE date = ? AND hour = ?", date, hour)
+	if err != nil {
+		return fmt.Errorf("failed to read typing bursts: %w", err)
+	}
+	if len(wpms) == 0 {
+		_, err = tx.Exec("DELETE FROM hourly_speed WHERE date = ? AND hour = ?", date, hour)
+		return err
+	}
+
+	sp := speedOf(wpms)
+	_, err = tx.Exec(`
+		INSERT INTO hourly_speed (date, hour, bursts, median_wpm, p90_wpm) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(date, hour) DO UPDATE SET
+			bursts = excluded.bursts,
+			median_wpm = excluded.median_wpm,
+			p90_wpm = excluded.p90_wpm
+	`, date, hour, sp.Bursts, sp.MedianWPM, sp.P90WPM)
+	if err != nil {
+		return fmt.Errorf("failed to update hourly speed: %w", err)
+	}
+	return nil
+}
+
+// querier is what burstSpeeds needs from a *sql.DB or *sql.Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// burstSpeeds runs a query selecting burst speeds
+func burstSpeeds(q querier, query string, args ...interface{}) ([]float64, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wpms []float64
+	for rows.Next() {
+		var wpm float64
+		if err := rows.Scan(&wpm); err != nil {
+			return nil, err
+		}
+		wpms = append(wpms, wpm)
+	}
+	return wpms, rows.Err()
+}
+
+// GetHourlySpeedRange returns the median and p90 typing speed per date and
+// hour (see the range query conventions in ranges.go)
+func (s *Store) GetHourlySpeedRange(from, to string) ([]HourlySpeed, error) {
+	rows, err := s.db.Query(`
+		SELECT date, hour, bursts, median_wpm, p90_wpm
+		FROM hourly_speed
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date, hour
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var speeds []HourlySpeed
+	for rows.Next() {
+		var h HourlySpeed
+		if err := rows.Scan(&h.Date, &h.Hour, &h.Bursts, &h.MedianWPM, &h.P90WPM); err != nil {
+			return nil, err
+		}
+		speeds = append(speeds, h)
+	}
+	return speeds, rows.Err()
+}
+
+// GetDailySpeedRange returns the median and p90 typing speed of each day's
+// bursts
+func (s *Store) GetDailySpeedRange(from, to string) ([]DailySpeed, error) {
+	rows, err := s.db.Query(`
+		SELECT date, wpm FROM typing_bursts
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var speeds []DailySpeed
+	var date string
+	var wpms []float64
+	flush := func() {
+		if len(wpms) > 0 {
+			speeds = append(speeds, DailySpeed{Date: date, Speed: speedOf(wpms)})
+		}
+	}
+	for rows.Next() {
+		var d string
+		var wpm float64
+		if err := rows.Scan(&d, &wpm); err != nil {
+			return nil, err
+		}
+		if d != date {
+			flush()
+			date, wpms = d, nil
+		}
+		wpms = append(wpms, wpm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return speeds, nil
+}
+
+// GetSpeed returns the median and p90 typing speed over all bursts between
+// from and to
+func (s *Store) GetSpeed(from, to string) (Speed, error) {
+	wpms, err := burstSpeeds(s.db, `
+		SELECT wpm FROM typing_bursts
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+	`, from, from, to, to)
+	if err != nil {
+		return Speed{}, err
+	}
+	return speedOf(wpms), nil
+}
+
+// rebuildBurstDates re-buckets typing bursts from since (UTC epoch
+// milliseconds) like RebuildKeystrokeDates does keystrokes, recomputing the
+// speed of every hour a burst left or joined
+func (s *Store) rebuildBurstDates(loc *time.Location, dayStart int, since int64) error {
+	type move struct {
+		id       int64
+		newDate  string
+		newHour  int
+		tzOffset int
+	}
+	type slot struct {
+		date string
+		hour int
+	}
+
+	rows, err := s.db.Query("SELECT id, ts, COALESCE(tz_offset, 0), date, hour FROM typing_bursts WHERE ts >= ?", since)
+	if err != nil {
+		return err
+	}
+	var moves []move
+	touched := make(map[slot]bool)
+	for rows.Next() {
+		var id, ts int64
+		var offset, hour int
+		var date string
+		if err := rows.Scan(&id, &ts, &offset, &date, &hour); err != nil {
+			rows.Close()
+			return err
+		}
+		newDate, newHour, newOffset := rebucket(ts, offset, loc, dayStart)
+		if newDate != date || newHour != hour {
+			moves = append(moves, move{id, newDate, newHour, newOffset})
+			touched[slot{date, hour}] = true
+			touched[slot{newDate, newHour}] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(moves) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, m := range moves {
+		if _, err := tx.Exec(
+			"UPDATE typing_bursts SET date = ?, hour = ?, tz_offset = ? WHERE id = ?",
+			m.newDate, m.newHour, m.tzOffset, m.id,
+		); err != nil {
+			return err
+		}
+	}
+	for sl := range touched {
+		if err := updateHourlySpeed(tx, sl.date, sl.hour); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
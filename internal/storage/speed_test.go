@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/speed"
+)
+
+// burstAt is a burst starting at t typing wpm over 30 seconds
+func burstAt(t time.Time, wpm float64) speed.Burst {
+	return speed.Burst{Start: t, End: t.Add(30 * time.Second), Chars: int(wpm * speed.CharsPerWord / 2)}
+}
+
+func TestTypingSpeed(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	nine := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	for i, wpm := range []float64{40, 60, 80, 100, 50} {
+		if err := store.RecordTypingBurst(burstAt(nine.Add(time.Duration(i)*time.Minute), wpm)); err != nil {
+			t.Fatalf("RecordTypingBurst failed: %v", err)
+		}
+	}
+	store.RecordTypingBurst(burstAt(nine.Add(time.Hour), 70))
+	store.RecordTypingBurst(burstAt(nine.AddDate(0, 0, 1), 90))
+
+	hourly, err := store.GetHourlySpeedRange("2024-05-02", "2024-05-02")
+	if err != nil {
+		t.Fatalf("GetHourlySpeedRange failed: %v", err)
+	}
+	want := []HourlySpeed{
+		{"2024-05-02", 9, Speed{5, 60, 92}},
+		{"2024-05-02", 10, Speed{1, 70, 70}},
+	}
+	if len(hourly) != len(want) {
+		t.Fatalf("Expected %d hours, got %+v", len(want), hourly)
+	}
+	for i := range want {
+		if !sameSpeed(hourly[i].Speed, want[i].Speed) || hourly[i].Date != want[i].Date || hourly[i].Hour != want[i].Hour {
+			t.Errorf("hour %d = %+v, want %+v", i, hourly[i], want[i])
+		}
+	}
+
+	daily, err := store.GetDailySpeedRange("", "")
+	if err != nil {
+		t.Fatalf("GetDailySpeedRange failed: %v", err)
+	}
+	if len(daily) != 2 || daily[0].Date != "2024-05-02" || !sameSpeed(daily[0].Speed, Speed{6, 65, 90}) ||
+		daily[1].Date != "2024-05-03" || !sameSpeed(daily[1].Speed, Speed{1, 90, 90}) {
+		t.Errorf("GetDailySpeedRange = %+v", daily)
+	}
+
+	overall, err := store.GetSpeed("2024-05-03", "")
+	if err != nil {
+		t.Fatalf("GetSpeed failed: %v", err)
+	}
+	if !sameSpeed(overall, Speed{1, 90, 90}) {
+		t.Errorf("GetSpeed = %+v, want one 90 WPM burst", overall)
+	}
+
+	none, _ := store.GetSpeed("2024-06-01", "2024-06-30")
+	if none != (Speed{}) {
+		t.Errorf("GetSpeed with no bursts = %+v, want zero", none)
+	}
+}
+
+func TestRebuildMovesTypingSpeed(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	at := time.Date(2024, 5, 2, 2, 15, 0, 0, time.UTC)
+	store.RecordTypingBurst(burstAt(at, 60))
+
+	// 02:15 UTC is 11:15 in Tokyo
+	if err := store.SetTimezone("Asia/Tokyo"); err != nil {
+		t.Fatalf("SetTimezone failed: %v", err)
+	}
+	hourly, _ := store.GetHourlySpeedRange("", "")
+	if len(hourly) != 1 || hourly[0].Date != "2024-05-02" || hourly[0].Hour != 11 || hourly[0].Bursts != 1 {
+		t.Errorf("Expected the burst at 11:00 in Tokyo, got %+v", hourly)
+	}
+
+	// Moving the boundary to noon puts it on the previous day
+	if err := store.SetDayStartHour(12); err != nil {
+		t.Fatalf("SetDayStartHour failed: %v", err)
+	}
+	daily, _ := store.GetDailySpeedRange("", "")
+	if len(daily) != 1 || daily[0].Date != "2024-05-01" {
+		t.Errorf("Expected the burst on 2024-05-01, got %+v", daily)
+	}
+}
+
+func sameSpeed(a, b Speed) bool {
+	return a.Bursts == b.Bursts && math.Abs(a.MedianWPM-b.MedianWPM) < 1e-9 && math.Abs(a.P90WPM-b.P90WPM) < 1e-9
+}
@@ -3,85 +3,187 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
-	"os/user"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/aayushbajaj/typing-telemetry/internal/config"
+	"github.com/aayushbajaj/typing-telemetry/internal/corrections"
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/leaderboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/paths"
+	"github.com/aayushbajaj/typing-telemetry/internal/sessions"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type Store struct {
 	db *sql.DB
+
+	// Settings read on every keystroke or mouse event, cached until a
+	// setting changes (see config.go)
+	hotMu sync.Mutex
+	hot   *hotSettings
+
+	// Config file beneath the settings table (see config.go)
+	cfgMu   sync.Mutex
+	cfgFile *config.File
+
+	// Correction run of the keystrokes recorded by this process (see
+	// corrections.go)
+	corrMu      sync.Mutex
+	corrections corrections.Detector
+
+	// Session in progress and its row, or 0 until loaded (see sessions.go)
+	sessMu    sync.Mutex
+	session   sessions.Tracker
+	sessionID int64
 }
 
 type DailyStats struct {
-	Date       string
-	Keystrokes int64
-	Words      int64
+	Date       string `json:"date"`
+	Keystrokes int64  `json:"keystrokes"`
+	Words      int64  `json:"words"`
 }
 
 type HourlyStats struct {
-	Hour       int
-	Keystrokes int64
+	Hour       int   `json:"hour"`
+	Keystrokes int64 `json:"keystrokes"`
 }
 
 // MouseDailyStats represents mouse movement statistics for a day
 type MouseDailyStats struct {
-	Date          string
-	TotalDistance float64 // Total Euclidean distance traveled in pixels
-	MidnightX     float64 // Mouse X position at midnight (or start of tracking)
-	MidnightY     float64 // Mouse Y position at midnight (or start of tracking)
-	CurrentX      float64 // Current mouse X position
-	CurrentY      float64 // Current mouse Y position
-	MAEFromOrigin float64 // Mean Absolute Error from midnight position
-	MovementCount int64   // Number of movement events recorded
-	ClickCount    int64   // Number of mouse clicks
+	Date          string  `json:"date"`
+	TotalDistance float64 `json:"total_distance_px"` // Total Euclidean distance traveled in pixels
+	MidnightX     float64 `json:"midnight_x"`        // Mouse X position at midnight (or start of tracking)
+	MidnightY     float64 `json:"midnight_y"`        // Mouse Y position at midnight (or start of tracking)
+	CurrentX      float64 `json:"current_x"`         // Current mouse X position
+	CurrentY      float64 `json:"current_y"`         // Current mouse Y position
+	MAEFromOrigin float64 `json:"mae_from_origin"`   // Mean Absolute Error from midnight position
+	MovementCount int64   `json:"movement_count"`    // Number of movement events recorded
+	ClickCount    int64   `json:"click_count"`       // Number of mouse clicks
 }
 
 // MouseLeaderboardEntry represents a day in the "least mouse movement" leaderboard
 type MouseLeaderboardEntry struct {
-	Date          string
-	TotalDistance float64
-	Rank          int
+	Date          string  `json:"date"`
+	TotalDistance float64 `json:"total_distance_px"`
+	Rank          int     `json:"rank"`
 }
 
-func getDataDir() (string, error) {
-	home, err := os.UserHomeDir()
+// schemaVersion is recorded in PRAGMA user_version once initSchema has run,
+// so read-only opens can tell whether migrations are still pending.
+const schemaVersion = 14
+
+// busyTimeoutMS is how long a connection waits for a competing lock before
+// giving up with "database is locked".
+const busyTimeoutMS = 5000
+
+// New opens the database at the default location (see internal/paths)
+func New() (*Store, error) {
+	dbPath, err := paths.DBPath()
 	if err != nil {
-		// Fallback: get home dir from user.Current()
-		if u, userErr := user.Current(); userErr == nil {
-			home = u.HomeDir
-		} else {
-			return "", err
+		return nil, err
+	}
+	return NewWithPath(dbPath)
+}
+
+// NewWithPath opens (creating if needed) the database at dbPath
+func NewWithPath(dbPath string) (*Store, error) {
+	if dir := filepath.Dir(dbPath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
 		}
 	}
-	dataDir := filepath.Join(home, ".local", "share", "typtel")
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return "", err
+
+	db, err := sql.Open("sqlite3", dsn(dbPath, false))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := initSchema(db); err != nil {
+		db.Close()
+		return nil, err
 	}
-	return dataDir, nil
+
+	return &Store{db: db}, nil
 }
 
-func New() (*Store, error) {
-	dataDir, err := getDataDir()
+// NewReadOnly opens the default database for reporting
+func NewReadOnly() (*Store, error) {
+	dbPath, err := paths.DBPath()
 	if err != nil {
 		return nil, err
 	}
+	return OpenReadOnly(dbPath)
+}
 
-	dbPath := filepath.Join(dataDir, "typtel.db")
-	db, err := sql.Open("sqlite3", dbPath)
+// OpenReadOnly opens the database at dbPath without write access. Reporting
+// commands use this so they never contend with the daemon for the write lock.
+// If the file is missing or its schema is out of date it is first opened
+// read-write once to create or migrate it.
+func OpenReadOnly(dbPath string) (*Store, error) {
+	current, err := schemaIsCurrent(dbPath)
 	if err != nil {
 		return nil, err
 	}
+	if !current {
+		store, err := NewWithPath(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		store.Close()
+	}
 
-	if err := initSchema(db); err != nil {
+	db, err := sql.Open("sqlite3", dsn(dbPath, true))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return &Store{db: db}, nil
 }
 
+// dsn builds the go-sqlite3 connection string. Writers run in WAL mode so
+// readers never block them, and take the write lock when a transaction
+// begins instead of upgrading later (which can deadlock under contention).
+// The path is escaped so '?', '#' and '%' in it aren't read as URI syntax.
+func dsn(dbPath string, readOnly bool) string {
+	u := url.URL{Scheme: "file", Opaque: (&url.URL{Path: dbPath}).EscapedPath()}
+	if readOnly {
+		u.RawQuery = fmt.Sprintf("mode=ro&_busy_timeout=%d", busyTimeoutMS)
+	} else {
+		u.RawQuery = fmt.Sprintf("_journal_mode=WAL&_busy_timeout=%d&_txlock=immediate", busyTimeoutMS)
+	}
+	return u.String()
+}
+
+// schemaIsCurrent reports whether dbPath exists and has been migrated to schemaVersion
+func schemaIsCurrent(dbPath string) (bool, error) {
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	db, err := sql.Open("sqlite3", dsn(dbPath, true))
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return false, err
+	}
+	return version >= schemaVersion, nil
+}
+
 func initSchema(db *sql.DB) error {
 	schema := `
 	CREATE TABLE IF NOT EXISTS keystrokes (
@@ -89,7 +191,10 @@ func initSchema(db *sql.DB) error {
 		timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
 		keycode INTEGER,
 		date TEXT,
-		hour INTEGER
+		hour INTEGER,
+		ts INTEGER,        -- UTC epoch milliseconds
+		tz_offset INTEGER, -- seconds east of UTC used to derive date/hour
+		modifiers INTEGER NOT NULL DEFAULT 0 -- keyboard.Modifiers held
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_keystrokes_date ON keystrokes(date);
@@ -121,6 +226,163 @@ func initSchema(db *sql.DB) error {
 		key TEXT PRIMARY KEY,
 		value TEXT
 	);
+
+	CREATE TABLE IF NOT EXISTS typing_tests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		ts INTEGER NOT NULL, -- UTC epoch milliseconds
+		date TEXT,
+		wpm REAL,
+		accuracy REAL,
+		duration_ms INTEGER,
+		word_count INTEGER,
+		punctuation INTEGER DEFAULT 0,
+		source TEXT NOT NULL DEFAULT 'typtel'
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_typing_tests_date ON typing_tests(date);
+
+	-- Aggregated counts without individual keystroke rows (imports, and
+	-- keystrokes folded away by retention.keystroke_days)
+	CREATE TABLE IF NOT EXISTS imported_hourly (
+		date TEXT,
+		hour INTEGER,
+		keystrokes INTEGER DEFAULT 0,
+		PRIMARY KEY (date, hour)
+	);
+
+	CREATE TABLE IF NOT EXISTS imported_keys (
+		date TEXT,
+		keycode INTEGER,
+		count INTEGER DEFAULT 0,
+		PRIMARY KEY (date, keycode)
+	);
+
+	-- Shortcut counts of keystrokes folded away by retention.keystroke_days
+	CREATE TABLE IF NOT EXISTS imported_shortcuts (
+		date TEXT,
+		keycode INTEGER,
+		modifiers INTEGER,
+		count INTEGER DEFAULT 0,
+		PRIMARY KEY (date, keycode, modifiers)
+	);
+
+	-- Per-source share of daily_summary totals that came from imports
+	CREATE TABLE IF NOT EXISTS imported_daily (
+		date TEXT,
+		source TEXT,
+		keystrokes INTEGER DEFAULT 0,
+		words INTEGER DEFAULT 0,
+		PRIMARY KEY (date, source)
+	);
+
+	CREATE TABLE IF NOT EXISTS import_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		ts INTEGER NOT NULL, -- UTC epoch milliseconds
+		source TEXT NOT NULL,
+		origin TEXT,
+		rows INTEGER DEFAULT 0
+	);
+
+	-- Bursts of continuous typing measured by the daemon (see internal/speed)
+	CREATE TABLE IF NOT EXISTS typing_bursts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		ts INTEGER NOT NULL, -- UTC epoch milliseconds of the first key
+		tz_offset INTEGER,   -- seconds east of UTC used to derive date/hour
+		date TEXT,
+		hour INTEGER,
+		duration_ms INTEGER,
+		chars INTEGER,
+		wpm REAL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_typing_bursts_date ON typing_bursts(date, hour);
+
+	-- Median and 90th percentile burst speed per hour, kept up to date as
+	-- bursts are recorded
+	CREATE TABLE IF NOT EXISTS hourly_speed (
+		date TEXT,
+		hour INTEGER,
+		bursts INTEGER DEFAULT 0,
+		median_wpm REAL DEFAULT 0,
+		p90_wpm REAL DEFAULT 0,
+		PRIMARY KEY (date, hour)
+	);
+
+	-- Characters typed and corrections per hour (see internal/corrections),
+	-- kept up to date as keystrokes are recorded
+	CREATE TABLE IF NOT EXISTS hourly_corrections (
+		date TEXT,
+		hour INTEGER,
+		chars INTEGER DEFAULT 0,
+		corrections INTEGER DEFAULT 0,
+		bursts INTEGER DEFAULT 0,
+		PRIMARY KEY (date, hour)
+	);
+
+	-- Words typed per hour, kept up to date as words are recorded. Imported
+	-- and synced words only count towards their day.
+	CREATE TABLE IF NOT EXISTS hourly_words (
+		date TEXT,
+		hour INTEGER,
+		words INTEGER DEFAULT 0,
+		PRIMARY KEY (date, hour)
+	);
+
+	-- Sessions of activity split by the idle gap (see internal/sessions)
+	CREATE TABLE IF NOT EXISTS sessions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		start_ts INTEGER NOT NULL, -- UTC epoch milliseconds of the first activity
+		end_ts INTEGER NOT NULL,   -- UTC epoch milliseconds of the last activity
+		tz_offset INTEGER,         -- seconds east of UTC used to derive date
+		date TEXT,                 -- the day the session started on
+		keystrokes INTEGER DEFAULT 0,
+		mouse_distance REAL DEFAULT 0 -- pixels
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sessions_date ON sessions(date);
+
+	-- Break reminders and whether they were heeded (see internal/breaks)
+	CREATE TABLE IF NOT EXISTS break_outcomes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		ts INTEGER NOT NULL, -- UTC epoch milliseconds the reminder was due
+		tz_offset INTEGER,   -- seconds east of UTC used to derive date
+		date TEXT,
+		kind TEXT NOT NULL,  -- breaks.Kind
+		taken INTEGER NOT NULL
+	);
+
+	-- Daily goals in effect from each date on (see internal/goals), so
+	-- changing a goal doesn't rewrite past streaks
+	CREATE TABLE IF NOT EXISTS goal_history (
+		date TEXT PRIMARY KEY,
+		keystrokes INTEGER DEFAULT 0,
+		words INTEGER DEFAULT 0,
+		typing_tests INTEGER DEFAULT 0,
+		max_mouse_feet REAL DEFAULT 0
+	);
+
+	-- When each achievement was unlocked (see internal/achievements)
+	CREATE TABLE IF NOT EXISTS achievements (
+		id TEXT PRIMARY KEY,
+		unlocked_ts INTEGER NOT NULL -- UTC epoch milliseconds
+	);
+
+	-- Keystrokes already in a sync changeset that RebuildKeystrokeDates
+	-- moved: -1 at the old date, hour and key and +1 at the new, for the next
+	-- changeset to carry (see sync.go)
+	CREATE TABLE IF NOT EXISTS sync_adjustments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		date TEXT,
+		hour INTEGER,
+		keycode INTEGER,
+		keystrokes INTEGER
+	);
+
+	-- Word counts already written to this device's sync changesets
+	CREATE TABLE IF NOT EXISTS sync_pushed (
+		date TEXT PRIMARY KEY,
+		words INTEGER DEFAULT 0
+	);
 	`
 	_, err := db.Exec(schema)
 	if err != nil {
@@ -130,13 +392,89 @@ func initSchema(db *sql.DB) error {
 	// Add click_count column if it doesn't exist (migration for existing DBs)
 	_, _ = db.Exec("ALTER TABLE mouse_daily ADD COLUMN click_count INTEGER DEFAULT 0")
 
-	return nil
+	// Add epoch timestamp columns (migration for existing DBs)
+	_, _ = db.Exec("ALTER TABLE keystrokes ADD COLUMN ts INTEGER")
+	_, _ = db.Exec("ALTER TABLE keystrokes ADD COLUMN tz_offset INTEGER")
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_keystrokes_ts ON keystrokes(ts)"); err != nil {
+		return err
+	}
+
+	// Tag typing tests with where they came from (migration for existing DBs)
+	_, _ = db.Exec("ALTER TABLE typing_tests ADD COLUMN source TEXT NOT NULL DEFAULT 'typtel'")
+
+	// Modifiers held with each keystroke (migration for existing DBs; older
+	// keystrokes count as unmodified)
+	_, _ = db.Exec("ALTER TABLE keystrokes ADD COLUMN modifiers INTEGER NOT NULL DEFAULT 0")
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version < 2 {
+		if err := backfillTimestamps(db); err != nil {
+			return err
+		}
+	}
+	if version < 8 {
+		if err := recountCorrections(db, nil); err != nil {
+			return err
+		}
+	}
+	if version < 9 {
+		if err := backfillSessions(db); err != nil {
+			return err
+		}
+	}
+	if version < 13 {
+		if err := recordDayBounds(db); err != nil {
+			return err
+		}
+	}
+	if version < 14 {
+		if err := recountHourlyWords(db, nil); err != nil {
+			return err
+		}
+	}
+
+	_, err = db.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion))
+	return err
+}
+
+// backfillTimestamps fills ts and tz_offset for rows recorded before they
+// existed. The old timestamp column holds SQLite's UTC CURRENT_TIMESTAMP and
+// date/hour hold local wall-clock time, so the offset is their difference
+// (to whole-hour precision, since only the local hour was kept).
+func backfillTimestamps(db *sql.DB) error {
+	_, err := db.Exec(`
+		UPDATE keystrokes SET
+			ts = CAST(strftime('%s', timestamp) AS INTEGER) * 1000,
+			tz_offset = CAST(strftime('%s', date || ' ' || printf('%02d:00:00', hour)) AS INTEGER)
+			          - CAST(strftime('%s', strftime('%Y-%m-%d %H:00:00', timestamp)) AS INTEGER)
+		WHERE ts IS NULL AND timestamp IS NOT NULL
+	`)
+	return err
 }
 
 func (s *Store) RecordKeystroke(keycode int) error {
-	now := time.Now()
-	date := now.Format("2006-01-02")
-	hour := now.Hour()
+	return s.RecordKeystrokeAt(keycode, time.Now())
+}
+
+// RecordChord records a key pressed now with mods held
+func (s *Store) RecordChord(keycode int, mods keyboard.Modifiers) error {
+	return s.RecordChordAt(keycode, mods, time.Now())
+}
+
+// RecordKeystrokeAt records an unmodified keystroke observed at t
+func (s *Store) RecordKeystrokeAt(keycode int, t time.Time) error {
+	return s.RecordChordAt(keycode, 0, t)
+}
+
+// RecordChordAt records a key pressed at t with mods held
+func (s *Store) RecordChordAt(keycode int, mods keyboard.Modifiers, t time.Time) error {
+	bounds := s.dayBounds()
+	wall := t.In(bounds.loc)
+	date, hour := bucket(wall, bounds.dayStart)
+	_, offset := wall.Zone()
 
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -145,8 +483,8 @@ func (s *Store) RecordKeystroke(keycode int) error {
 	defer tx.Rollback()
 
 	_, err = tx.Exec(
-		"INSERT INTO keystrokes (keycode, date, hour) VALUES (?, ?, ?)",
-		keycode, date, hour,
+		"INSERT INTO keystrokes (keycode, date, hour, ts, tz_offset, modifiers) VALUES (?, ?, ?, ?, ?, ?)",
+		keycode, date, hour, t.UnixMilli(), offset, mods,
 	)
 	if err != nil {
 		return err
@@ -162,9 +500,22 @@ func (s *Store) RecordKeystroke(keycode int) error {
 		return err
 	}
 
+	s.corrMu.Lock()
+	c := s.corrections.Press(keycode, mods)
+	s.corrMu.Unlock()
+	if c != (corrections.Counts{}) {
+		if err := addCorrections(tx, date, hour, c); err != nil {
+			return err
+		}
+	}
+	if err := s.trackActivity(tx, t, 1, 0); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// IncrementWordCount adds a word to date without crediting it to an hour
 func (s *Store) IncrementWordCount(date string) error {
 	_, err := s.db.Exec(`
 		INSERT INTO daily_summary (date, words) VALUES (?, 1)
@@ -175,9 +526,39 @@ func (s *Store) IncrementWordCount(date string) error {
 	return err
 }
 
+// IncrementWordCountAt adds a word completed at t to its day and hour
+func (s *Store) IncrementWordCountAt(t time.Time) error {
+	bounds := s.dayBounds()
+	date, hour := bucket(t.In(bounds.loc), bounds.dayStart)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO daily_summary (date, words) VALUES (?, 1)
+		ON CONFLICT(date) DO UPDATE SET
+			words = words + 1,
+			updated_at = CURRENT_TIMESTAMP
+	`, date)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO hourly_words (date, hour, words) VALUES (?, ?, 1)
+		ON CONFLICT(date, hour) DO UPDATE SET words = words + 1
+	`, date, hour)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (s *Store) GetTodayStats() (*DailyStats, error) {
-	date := time.Now().Format("2006-01-02")
-	return s.GetDayStats(date)
+	return s.GetDayStats(s.Today())
 }
 
 func (s *Store) GetDayStats(date string) (*DailyStats, error) {
@@ -200,19 +581,7 @@ func (s *Store) GetDayStats(date string) (*DailyStats, error) {
 }
 
 func (s *Store) GetWeekStats() ([]DailyStats, error) {
-	now := time.Now()
-	stats := make([]DailyStats, 7)
-
-	for i := 6; i >= 0; i-- {
-		date := now.AddDate(0, 0, -i).Format("2006-01-02")
-		dayStat, err := s.GetDayStats(date)
-		if err != nil {
-			return nil, err
-		}
-		stats[6-i] = *dayStat
-	}
-
-	return stats, nil
+	return s.GetHistoricalStats(7)
 }
 
 func (s *Store) GetHourlyStats(date string) ([]HourlyStats, error) {
@@ -221,10 +590,14 @@ func (s *Store) GetHourlyStats(date string) ([]HourlyStats, error) {
 		stats[i].Hour = i
 	}
 
-	rows, err := s.db.Query(
-		"SELECT hour, COUNT(*) FROM keystrokes WHERE date = ? GROUP BY hour",
-		date,
-	)
+	rows, err := s.db.Query(`
+		SELECT hour, SUM(n) FROM (
+			SELECT hour, COUNT(*) AS n FROM keystrokes WHERE date = ? GROUP BY hour
+			UNION ALL
+			SELECT hour, keystrokes AS n FROM imported_hourly WHERE date = ?
+		)
+		GROUP BY hour
+	`, date, date)
 	if err != nil {
 		return nil, err
 	}
@@ -246,16 +619,14 @@ func (s *Store) GetHourlyStats(date string) ([]HourlyStats, error) {
 
 // GetHistoricalStats returns stats for the last N days
 func (s *Store) GetHistoricalStats(days int) ([]DailyStats, error) {
-	now := time.Now()
 	stats := make([]DailyStats, days)
 
-	for i := days - 1; i >= 0; i-- {
-		date := now.AddDate(0, 0, -i).Format("2006-01-02")
+	for i, date := range s.lastNDates(days) {
 		dayStat, err := s.GetDayStats(date)
 		if err != nil {
 			return nil, err
 		}
-		stats[days-1-i] = *dayStat
+		stats[i] = *dayStat
 	}
 
 	return stats, nil
@@ -264,10 +635,8 @@ func (s *Store) GetHistoricalStats(days int) ([]DailyStats, error) {
 // GetAllHourlyStatsForDays returns hourly stats for multiple days (for heatmap)
 func (s *Store) GetAllHourlyStatsForDays(days int) (map[string][]HourlyStats, error) {
 	result := make(map[string][]HourlyStats)
-	now := time.Now()
 
-	for i := days - 1; i >= 0; i-- {
-		date := now.AddDate(0, 0, -i).Format("2006-01-02")
+	for _, date := range s.lastNDates(days) {
 		hourlyStats, err := s.GetHourlyStats(date)
 		if err != nil {
 			return nil, err
@@ -284,15 +653,20 @@ func (s *Store) Close() error {
 
 // RecordMouseMovement records a mouse movement event with distance traveled
 func (s *Store) RecordMouseMovement(x, y, distance float64) error {
-	now := time.Now()
-	date := now.Format("2006-01-02")
+	date := s.Today()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
 	// Calculate absolute error from midnight position
 	// We'll need to get the midnight position first
 	var midnightX, midnightY float64
 	var exists bool
 
-	err := s.db.QueryRow(
+	err = tx.QueryRow(
 		"SELECT midnight_x, midnight_y FROM mouse_daily WHERE date = ?",
 		date,
 	).Scan(&midnightX, &midnightY)
@@ -313,13 +687,13 @@ func (s *Store) RecordMouseMovement(x, y, distance float64) error {
 
 	if !exists {
 		// Insert new record for the day
-		_, err = s.db.Exec(`
+		_, err = tx.Exec(`
 			INSERT INTO mouse_daily (date, total_distance, midnight_x, midnight_y, current_x, current_y, sum_abs_error, movement_count)
 			VALUES (?, ?, ?, ?, ?, ?, ?, 1)
 		`, date, distance, midnightX, midnightY, x, y, absError)
 	} else {
 		// Update existing record
-		_, err = s.db.Exec(`
+		_, err = tx.Exec(`
 			UPDATE mouse_daily SET
 				total_distance = total_distance + ?,
 				current_x = ?,
@@ -330,8 +704,14 @@ func (s *Store) RecordMouseMovement(x, y, distance float64) error {
 			WHERE date = ?
 		`, distance, x, y, absError, date)
 	}
+	if err != nil {
+		return err
+	}
+	if err := s.trackActivity(tx, time.Now(), 0, distance); err != nil {
+		return err
+	}
 
-	return err
+	return tx.Commit()
 }
 
 // SetMidnightPosition sets the starting mouse position for a new day
@@ -377,65 +757,60 @@ func (s *Store) GetMouseDailyStats(date string) (*MouseDailyStats, error) {
 
 // RecordMouseClick records a mouse click event
 func (s *Store) RecordMouseClick() error {
-	now := time.Now()
-	date := now.Format("2006-01-02")
+	date := s.Today()
 
-	_, err := s.db.Exec(`
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		INSERT INTO mouse_daily (date, click_count) VALUES (?, 1)
 		ON CONFLICT(date) DO UPDATE SET
 			click_count = click_count + 1,
 			updated_at = CURRENT_TIMESTAMP
 	`, date)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := s.trackActivity(tx, time.Now(), 0, 0); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // GetTodayMouseStats returns today's mouse movement stats
 func (s *Store) GetTodayMouseStats() (*MouseDailyStats, error) {
-	date := time.Now().Format("2006-01-02")
-	return s.GetMouseDailyStats(date)
+	return s.GetMouseDailyStats(s.Today())
 }
 
-// GetMouseLeaderboard returns days with the least mouse movement (stillness leaderboard)
+// GetMouseLeaderboard returns days with the least mouse movement (stillness
+// leaderboard), in the form `typtel leaderboard --json` has always printed
 func (s *Store) GetMouseLeaderboard(limit int) ([]MouseLeaderboardEntry, error) {
-	rows, err := s.db.Query(`
-		SELECT date, total_distance
-		FROM mouse_daily
-		WHERE movement_count > 0
-		ORDER BY total_distance ASC
-		LIMIT ?
-	`, limit)
+	ranked, err := s.GetLeaderboard(leaderboard.Stillness, LeaderboardOptions{Limit: limit})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
 	var entries []MouseLeaderboardEntry
-	rank := 1
-	for rows.Next() {
-		var entry MouseLeaderboardEntry
-		if err := rows.Scan(&entry.Date, &entry.TotalDistance); err != nil {
-			return nil, err
-		}
-		entry.Rank = rank
-		entries = append(entries, entry)
-		rank++
+	for _, e := range ranked {
+		entries = append(entries, MouseLeaderboardEntry{Date: e.Date, TotalDistance: e.Value, Rank: e.Rank})
 	}
-
 	return entries, nil
 }
 
 // GetMouseHistoricalStats returns mouse stats for the last N days
 func (s *Store) GetMouseHistoricalStats(days int) ([]MouseDailyStats, error) {
-	now := time.Now()
 	stats := make([]MouseDailyStats, days)
 
-	for i := days - 1; i >= 0; i-- {
-		date := now.AddDate(0, 0, -i).Format("2006-01-02")
+	for i, date := range s.lastNDates(days) {
 		dayStat, err := s.GetMouseDailyStats(date)
 		if err != nil {
 			return nil, err
 		}
-		stats[days-1-i] = *dayStat
+		stats[i] = *dayStat
 	}
 
 	return stats, nil
@@ -460,8 +835,28 @@ const (
 	SettingShowWords            = "menubar_show_words"
 	SettingShowClicks           = "menubar_show_clicks"
 	SettingShowDistance         = "menubar_show_distance"
+	SettingShowWPM              = "menubar_show_wpm"
+	SettingShowGoals            = "menubar_show_goals"
 	SettingMouseTrackingEnabled = "mouse_tracking_enabled"
 	SettingDistanceUnit         = "distance_unit"
+	SettingKeyboardLayout       = "keyboard_layout"
+	SettingRetentionDays        = "retention_keystroke_days" // days of individual keystrokes kept; 0 keeps them forever
+	SettingMetricsAddr          = "metrics_addr"             // where the daemon serves Prometheus metrics; empty is off
+	SettingSessionIdleMinutes   = "sessions_idle_minutes"    // minutes without activity that end a session
+	// Break reminder settings (see internal/breaks)
+	SettingBreaksEnabled        = "breaks_enabled"
+	SettingBreakAfterMinutes    = "breaks_break_after_minutes"
+	SettingBreakMinutes         = "breaks_break_minutes"
+	SettingMicroPauseKeystrokes = "breaks_micropause_keystrokes"
+	SettingMicroPauseSeconds    = "breaks_micropause_seconds"
+	SettingBreakNotifier        = "breaks_notifier"
+	SettingBreakWebhookURL      = "breaks_webhook_url"
+	// Daily goal settings (see internal/goals)
+	SettingGoalKeystrokes   = "goals_keystrokes"
+	SettingGoalWords        = "goals_words"
+	SettingGoalTypingTests  = "goals_typing_tests"
+	SettingGoalMaxMouseFeet = "goals_max_mouse_feet"
+	SettingGoalNotify       = "goals_notify"
 	// Inertia settings
 	SettingInertiaEnabled   = "inertia_enabled"
 	SettingInertiaMaxSpeed  = "inertia_max_speed"
@@ -477,27 +872,22 @@ const (
 
 // Distance unit options
 const (
-	DistanceUnitFeet    = "feet"    // feet/miles (default)
-	DistanceUnitCars    = "cars"    // average car length ~15ft
-	DistanceUnitFrisbee = "frisbee" // ultimate frisbee field ~330ft
+	DistanceUnitFeet    = config.DistanceUnitFeet
+	DistanceUnitCars    = config.DistanceUnitCars
+	DistanceUnitFrisbee = config.DistanceUnitFrisbee
 )
 
-// Inertia max speed options (capped at what terminals/editors can handle)
+// Inertia max speed options
 const (
-	InertiaSpeedUltraFast = "ultra_fast" // Cap at ~140 keys/sec (pushing limits)
-	InertiaSpeedVeryFast  = "very_fast"  // Cap at ~125 keys/sec
-	InertiaSpeedFast      = "fast"       // Cap at ~83 keys/sec
-	InertiaSpeedMedium    = "medium"     // Cap at ~50 keys/sec
-	InertiaSpeedSlow      = "slow"       // Cap at ~20 keys/sec
+	InertiaSpeedUltraFast = config.InertiaSpeedUltraFast
+	InertiaSpeedVeryFast  = config.InertiaSpeedVeryFast
+	InertiaSpeedFast      = config.InertiaSpeedFast
+	InertiaSpeedMedium    = config.InertiaSpeedMedium
+	InertiaSpeedSlow      = config.InertiaSpeedSlow
 )
 
 // MenubarSettings represents what to show in the menubar
-type MenubarSettings struct {
-	ShowKeystrokes bool
-	ShowWords      bool
-	ShowClicks     bool
-	ShowDistance   bool
-}
+type MenubarSettings = config.Menubar
 
 // GetSetting retrieves a setting value
 func (s *Store) GetSetting(key string) (string, error) {
@@ -515,39 +905,13 @@ func (s *Store) SetSetting(key, value string) error {
 		INSERT INTO settings (key, value) VALUES (?, ?)
 		ON CONFLICT(key) DO UPDATE SET value = ?
 	`, key, value, value)
+	s.settingsChanged()
 	return err
 }
 
 // GetMenubarSettings returns the current menubar display settings
 func (s *Store) GetMenubarSettings() MenubarSettings {
-	settings := MenubarSettings{
-		ShowKeystrokes: true,  // default on
-		ShowWords:      true,  // default on
-		ShowClicks:     false, // default off
-		ShowDistance:   false, // default off (per user request)
-	}
-
-	if val, _ := s.GetSetting(SettingShowKeystrokes); val == "false" {
-		settings.ShowKeystrokes = false
-	} else if val == "true" {
-		settings.ShowKeystrokes = true
-	}
-
-	if val, _ := s.GetSetting(SettingShowWords); val == "false" {
-		settings.ShowWords = false
-	} else if val == "true" {
-		settings.ShowWords = true
-	}
-
-	if val, _ := s.GetSetting(SettingShowClicks); val == "true" {
-		settings.ShowClicks = true
-	}
-
-	if val, _ := s.GetSetting(SettingShowDistance); val == "true" {
-		settings.ShowDistance = true
-	}
-
-	return settings
+	return s.Config().Menubar
 }
 
 // SaveMenubarSettings saves the menubar display settings
@@ -561,7 +925,13 @@ func (s *Store) SaveMenubarSettings(settings MenubarSettings) error {
 	if err := s.SetSetting(SettingShowClicks, boolToString(settings.ShowClicks)); err != nil {
 		return err
 	}
-	return s.SetSetting(SettingShowDistance, boolToString(settings.ShowDistance))
+	if err := s.SetSetting(SettingShowDistance, boolToString(settings.ShowDistance)); err != nil {
+		return err
+	}
+	if err := s.SetSetting(SettingShowWPM, boolToString(settings.ShowWPM)); err != nil {
+		return err
+	}
+	return s.SetSetting(SettingShowGoals, boolToString(settings.ShowGoals))
 }
 
 func boolToString(b bool) string {
@@ -573,9 +943,7 @@ func boolToString(b bool) string {
 
 // IsMouseTrackingEnabled returns whether mouse tracking is enabled (default: true)
 func (s *Store) IsMouseTrackingEnabled() bool {
-	val, _ := s.GetSetting(SettingMouseTrackingEnabled)
-	// Default to enabled if not set
-	return val != "false"
+	return s.Config().Mouse.Tracking
 }
 
 // SetMouseTrackingEnabled sets whether mouse tracking is enabled
@@ -585,11 +953,7 @@ func (s *Store) SetMouseTrackingEnabled(enabled bool) error {
 
 // GetDistanceUnit returns the current distance unit (default: feet)
 func (s *Store) GetDistanceUnit() string {
-	val, _ := s.GetSetting(SettingDistanceUnit)
-	if val == "" {
-		return DistanceUnitFeet
-	}
-	return val
+	return s.Config().Mouse.DistanceUnit
 }
 
 // SetDistanceUnit sets the distance unit
@@ -597,44 +961,24 @@ func (s *Store) SetDistanceUnit(unit string) error {
 	return s.SetSetting(SettingDistanceUnit, unit)
 }
 
-// InertiaSettings represents inertia configuration
-type InertiaSettings struct {
-	Enabled   bool
-	MaxSpeed  string  // "very_fast", "fast", "medium"
-	Threshold int     // ms before acceleration starts (default 200)
-	AccelRate float64 // acceleration multiplier (default 1.0)
+// MetricsAddr returns the address the daemon serves /metrics on, or "" if
+// the metrics listener is off
+func (s *Store) MetricsAddr() string {
+	return s.Config().Metrics.Addr
 }
 
-// GetInertiaSettings returns the current inertia settings
-func (s *Store) GetInertiaSettings() InertiaSettings {
-	settings := InertiaSettings{
-		Enabled:   false,
-		MaxSpeed:  InertiaSpeedFast,
-		Threshold: 200,
-		AccelRate: 1.0,
-	}
-
-	if val, _ := s.GetSetting(SettingInertiaEnabled); val == "true" {
-		settings.Enabled = true
-	}
-
-	if val, _ := s.GetSetting(SettingInertiaMaxSpeed); val != "" {
-		settings.MaxSpeed = val
-	}
-
-	if val, _ := s.GetSetting(SettingInertiaThreshold); val != "" {
-		if v, err := parseInt(val); err == nil && v > 0 {
-			settings.Threshold = v
-		}
-	}
+// SetMetricsAddr sets the metrics listen address. "" removes the override,
+// which turns metrics off unless the config file sets metrics.addr.
+func (s *Store) SetMetricsAddr(addr string) error {
+	return s.SetSetting(SettingMetricsAddr, addr)
+}
 
-	if val, _ := s.GetSetting(SettingInertiaAccelRate); val != "" {
-		if v, err := parseFloat(val); err == nil && v > 0 {
-			settings.AccelRate = v
-		}
-	}
+// InertiaSettings represents inertia configuration
+type InertiaSettings = config.Inertia
 
-	return settings
+// GetInertiaSettings returns the current inertia settings
+func (s *Store) GetInertiaSettings() InertiaSettings {
+	return s.Config().Inertia
 }
 
 // SetInertiaEnabled sets whether inertia is enabled
@@ -659,15 +1003,11 @@ func (s *Store) SetInertiaAccelRate(rate float64) error {
 
 // Helper functions for parsing
 func parseInt(s string) (int, error) {
-	var v int
-	_, err := fmt.Sscanf(s, "%d", &v)
-	return v, err
+	return strconv.Atoi(s)
 }
 
 func parseFloat(s string) (float64, error) {
-	var v float64
-	_, err := fmt.Sscanf(s, "%f", &v)
-	return v, err
+	return strconv.ParseFloat(s, 64)
 }
 
 func intToString(i int) string {
@@ -680,15 +1020,15 @@ func floatToString(f float64) string {
 
 // TypingTestStats holds typing test performance data
 type TypingTestStats struct {
-	PersonalBest float64 // Best WPM
-	AverageWPM   float64 // Running average WPM
-	TestCount    int     // Number of tests completed
+	PersonalBest float64 `json:"personal_best_wpm"` // Best WPM
+	AverageWPM   float64 `json:"average_wpm"`       // Running average WPM
+	TestCount    int     `json:"test_count"`        // Number of tests completed
 }
 
 // TypingTestMode represents a specific configuration for typing tests
 type TypingTestMode struct {
-	WordCount   int
-	Punctuation bool
+	WordCount   int  `json:"word_count"`
+	Punctuation bool `json:"punctuation"`
 }
 
 // ModeKey generates a unique key for a typing test mode
@@ -811,11 +1151,7 @@ func (s *Store) SaveTypingTestResultForMode(wpm float64, mode TypingTestMode) er
 
 // GetTypingTestTheme retrieves the saved theme preference
 func (s *Store) GetTypingTestTheme() string {
-	val, _ := s.GetSetting(SettingTypingTestTheme)
-	if val == "" {
-		return "default"
-	}
-	return val
+	return s.Config().TypingTest.Theme
 }
 
 // SetTypingTestTheme saves the theme preference
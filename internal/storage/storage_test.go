@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -250,6 +251,7 @@ func TestMenubarSettings(t *testing.T) {
 	// Modify and save settings
 	settings.ShowKeystrokes = false
 	settings.ShowClicks = true
+	settings.ShowWPM = true
 	err := store.SaveMenubarSettings(settings)
 	if err != nil {
 		t.Fatalf("SaveMenubarSettings failed: %v", err)
@@ -263,6 +265,9 @@ func TestMenubarSettings(t *testing.T) {
 	if settings.ShowClicks != true {
 		t.Error("Expected ShowClicks to be true after save")
 	}
+	if !settings.ShowWPM {
+		t.Error("Expected ShowWPM to be true after save")
+	}
 }
 
 func TestInertiaSettings(t *testing.T) {
@@ -615,6 +620,11 @@ func TestParseIntEdgeCases(t *testing.T) {
 		t.Error("parseInt('') should return an error")
 	}
 
+	// Test trailing garbage
+	if v, err := parseInt("12abc"); err == nil {
+		t.Errorf("parseInt('12abc') = %d, want an error", v)
+	}
+
 	// Test negative number
 	v, err := parseInt("-42")
 	if err != nil || v != -42 {
@@ -641,6 +651,11 @@ func TestParseFloatEdgeCases(t *testing.T) {
 		t.Error("parseFloat('') should return an error")
 	}
 
+	// Test trailing garbage
+	if f, err := parseFloat("1.5x"); err == nil {
+		t.Errorf("parseFloat('1.5x') = %f, want an error", f)
+	}
+
 	// Test negative number
 	f, err := parseFloat("-3.14")
 	if err != nil || f != -3.14 {
@@ -880,3 +895,279 @@ func TestFloatToStringEdgeCases(t *testing.T) {
 		}
 	}
 }
+
+func TestNewWithPath(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "nested", "profile.db")
+
+	store, err := NewWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	if err := store.RecordKeystroke(1); err != nil {
+		t.Fatalf("RecordKeystroke failed: %v", err)
+	}
+	store.Close()
+
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Fatalf("Expected database file at %s: %v", dbPath, err)
+	}
+
+	// Reopening the same path sees the same data
+	store, err = NewWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewWithPath (reopen) failed: %v", err)
+	}
+	defer store.Close()
+
+	stats, _ := store.GetTodayStats()
+	if stats.Keystrokes != 1 {
+		t.Errorf("Expected 1 keystroke after reopen, got %d", stats.Keystrokes)
+	}
+}
+
+func TestNewWithPathEnablesWAL(t *testing.T) {
+	store, err := NewWithPath(filepath.Join(t.TempDir(), "wal.db"))
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	defer store.Close()
+
+	var mode string
+	if err := store.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("PRAGMA journal_mode failed: %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("Expected journal_mode wal, got %q", mode)
+	}
+
+	var timeout int
+	if err := store.db.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("PRAGMA busy_timeout failed: %v", err)
+	}
+	if timeout != busyTimeoutMS {
+		t.Errorf("Expected busy_timeout %d, got %d", busyTimeoutMS, timeout)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "ro.db")
+
+	// A missing database is created so reporting works on a fresh install
+	ro, err := OpenReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("OpenReadOnly on missing file failed: %v", err)
+	}
+	stats, err := ro.GetTodayStats()
+	if err != nil {
+		t.Fatalf("GetTodayStats failed: %v", err)
+	}
+	if stats.Keystrokes != 0 {
+		t.Errorf("Expected 0 keystrokes, got %d", stats.Keystrokes)
+	}
+
+	// Writes are rejected
+	if err := ro.RecordKeystroke(1); err == nil {
+		t.Error("Expected RecordKeystroke to fail on a read-only store")
+	}
+	ro.Close()
+
+	// Data written by a read-write store is visible
+	rw, err := NewWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	defer rw.Close()
+	rw.RecordKeystroke(1)
+	rw.RecordKeystroke(2)
+
+	ro, err = OpenReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("OpenReadOnly failed: %v", err)
+	}
+	defer ro.Close()
+
+	stats, _ = ro.GetTodayStats()
+	if stats.Keystrokes != 2 {
+		t.Errorf("Expected 2 keystrokes, got %d", stats.Keystrokes)
+	}
+}
+
+func TestOpenPathNeedingEscapes(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"what?.db", "a#b.db", "100%.db", "sp ace.db"}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			dbPath := filepath.Join(dir, name)
+			rw, err := NewWithPath(dbPath)
+			if err != nil {
+				t.Fatalf("NewWithPath failed: %v", err)
+			}
+			rw.RecordKeystroke(1)
+			rw.Close()
+
+			if _, err := os.Stat(dbPath); err != nil {
+				t.Fatalf("Database not created at %s: %v", dbPath, err)
+			}
+
+			ro, err := OpenReadOnly(dbPath)
+			if err != nil {
+				t.Fatalf("OpenReadOnly failed: %v", err)
+			}
+			defer ro.Close()
+			if stats, _ := ro.GetTodayStats(); stats.Keystrokes != 1 {
+				t.Errorf("Expected 1 keystroke, got %d", stats.Keystrokes)
+			}
+		})
+	}
+
+	// Nothing was opened under a truncated or unescaped name
+	if dbs, _ := filepath.Glob(filepath.Join(dir, "*.db")); len(dbs) != len(names) {
+		t.Errorf("Expected only %v, got %v", names, dbs)
+	}
+}
+
+func TestConcurrentWriterAndReaders(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "concurrent.db")
+
+	writer, err := NewWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	defer writer.Close()
+
+	const (
+		keystrokes = 300
+		readers    = 4
+	)
+
+	errs := make(chan error, keystrokes+readers*keystrokes)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// Writer: keystrokes, words and mouse events, like the daemon
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		date := time.Now().Format("2006-01-02")
+		for i := 0; i < keystrokes; i++ {
+			if err := writer.RecordKeystroke(i % 50); err != nil {
+				errs <- err
+				return
+			}
+			if i%5 == 0 {
+				if err := writer.IncrementWordCount(date); err != nil {
+					errs <- err
+					return
+				}
+			}
+			if err := writer.RecordMouseMovement(float64(i), float64(i), 1); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	// Readers: separate read-only connections polling like `typtel stats`
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reader, err := OpenReadOnly(dbPath)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer reader.Close()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if _, err := reader.GetTodayStats(); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := reader.GetWeekStats(); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := reader.GetHourlyStats(time.Now().Format("2006-01-02")); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := reader.GetTodayMouseStats(); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent access failed: %v", err)
+	}
+
+	stats, err := writer.GetTodayStats()
+	if err != nil {
+		t.Fatalf("GetTodayStats failed: %v", err)
+	}
+	if stats.Keystrokes != keystrokes {
+		t.Errorf("Expected %d keystrokes, got %d", keystrokes, stats.Keystrokes)
+	}
+	if stats.Words != keystrokes/5 {
+		t.Errorf("Expected %d words, got %d", keystrokes/5, stats.Words)
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	store, err := NewWithPath(filepath.Join(t.TempDir(), "writers.db"))
+	if err != nil {
+		t.Fatalf("NewWithPath failed: %v", err)
+	}
+	defer store.Close()
+
+	const events = 200
+
+	// The daemon records keystrokes, mouse movements and clicks from
+	// separate goroutines
+	errs := make(chan error, 3)
+	var wg sync.WaitGroup
+	writers := []func(i int) error{
+		func(i int) error { return store.RecordKeystroke(i % 50) },
+		func(i int) error { return store.RecordMouseMovement(float64(i), float64(i), 1) },
+		func(int) error { return store.RecordMouseClick() },
+	}
+	start := time.Now()
+	for _, write := range writers {
+		wg.Add(1)
+		go func(write func(int) error) {
+			defer wg.Done()
+			for i := 0; i < events; i++ {
+				if err := write(i); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(write)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent write failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= busyTimeoutMS*time.Millisecond {
+		t.Errorf("Writers took %v, waiting out the busy timeout", elapsed)
+	}
+
+	stats, _ := store.GetTodayStats()
+	mouse, _ := store.GetTodayMouseStats()
+	if stats.Keystrokes != events || mouse.MovementCount != events || mouse.ClickCount != events {
+		t.Errorf("Recorded %d keystrokes, %d movements and %d clicks, want %d of each",
+			stats.Keystrokes, mouse.MovementCount, mouse.ClickCount, events)
+	}
+}
@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Sync settings
+const (
+	SettingDeviceID       = "device_id"   // random ID naming this device's changeset file
+	SettingDeviceName     = "device_name" // human-readable name shown by other devices
+	SettingSyncDir        = "sync_dir"    // shared folder changesets are written to
+	settingSyncSeq        = "sync_seq"    // sequence number of the last changeset written
+	settingSyncLastID     = "sync_last_keystroke_id"
+	settingSyncLastAdjust = "sync_last_adjustment_id"
+	settingSyncPushed     = "sync_last_push" // UTC epoch milliseconds
+)
+
+// SyncDay is the change in a day's word count since the last changeset
+type SyncDay struct {
+	Date  string
+	Words int64
+}
+
+// SyncDelta is everything this device recorded since its last changeset
+type SyncDelta struct {
+	Seq      int64     // Sequence number to write the changeset under
+	LastPush time.Time // When the previous changeset was written; zero if never
+	Hours    []HourlyCount
+	Keys     []KeyCount
+	Days     []SyncDay
+
+	// Watermarks before and after this delta (see MarkSynced)
+	prevKeystrokeID, keystrokeID int64
+	prevAdjustID, adjustID       int64
+	prevPush                     int64
+}
+
+// Empty reports whether the delta carries no counts
+func (d *SyncDelta) Empty() bool {
+	return len(d.Hours) == 0 && len(d.Keys) == 0 && len(d.Days) == 0
+}
+
+// DeviceID returns this device's sync ID, generating one on first use
+func (s *Store) DeviceID() (string, error) {
+	id, err := s.GetSetting(SettingDeviceID)
+	if err != nil || id != "" {
+		return id, err
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id = hex.EncodeToString(b)
+	if err := s.SetSetting(SettingDeviceID, id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// DeviceName returns the name other devices show for this one (default: hostname)
+func (s *Store) DeviceName() string {
+	if name, _ := s.GetSetting(SettingDeviceName); name != "" {
+		return name
+	}
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return "unknown"
+}
+
+// SetDeviceName renames this device
+func (s *Store) SetDeviceName(name string) error {
+	return s.SetSetting(SettingDeviceName, name)
+}
+
+// SyncDir returns the shared sync folder, or "" if sync is off
+func (s *Store) SyncDir() string {
+	dir, _ := s.GetSetting(SettingSyncDir)
+	return dir
+}
+
+// SetSyncDir sets the shared sync folder; "" turns sync off
+func (s *Store) SetSyncDir(dir string) error {
+	return s.SetSetting(SettingSyncDir, dir)
+}
+
+// LastSyncPush returns when this device last wrote a changeset
+func (s *Store) LastSyncPush() time.Time {
+	val, _ := s.GetSetting(settingSyncPushed)
+	ms, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// errSyncRace is returned when another push moved the watermark first
+var errSyncRace = errors.New("another sync push ran at the same time; try again")
+
+// PendingSync collects everything recorded locally since the last changeset:
+// new keystrokes, keystrokes already pushed that RebuildKeystrokeDates has
+// moved since (as a count off the old hour and key and onto the new), and
+// changes to daily word counts. Imported data is not included; it belongs
+// to the device it came from.
+func (s *Store) PendingSync() (*SyncDelta, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	seq, err := txSettingInt(tx, settingSyncSeq)
+	if err != nil {
+		return nil, err
+	}
+	lastPush, err := txSettingInt(tx, settingSyncPushed)
+	if err != nil {
+		return nil, err
+	}
+	delta := &SyncDelta{Seq: seq + 1, prevPush: lastPush}
+	if lastPush > 0 {
+		delta.LastPush = time.UnixMilli(lastPush)
+	}
+	if delta.prevKeystrokeID, err = txSettingInt(tx, settingSyncLastID); err != nil {
+		return nil, err
+	}
+	if delta.prevAdjustID, err = txSettingInt(tx, settingSyncLastAdjust); err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRow("SELECT COALESCE(MAX(id), 0) FROM keystrokes").Scan(&delta.keystrokeID); err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRow("SELECT COALESCE(MAX(id), 0) FROM sync_adjustments").Scan(&delta.adjustID); err != nil {
+		return nil, err
+	}
+	ids := []interface{}{delta.prevKeystrokeID, delta.keystrokeID, delta.prevAdjustID, delta.adjustID}
+
+	rows, err := tx.Query(`
+		SELECT date, hour, SUM(n) FROM (
+			SELECT date, hour, COUNT(*) AS n FROM keystrokes WHERE id > ? AND id <= ? GROUP BY date, hour
+			UNION ALL
+			SELECT date, hour, SUM(keystrokes) AS n FROM sync_adjustments WHERE id > ? AND id <= ? GROUP BY date, hour
+		)
+		GROUP BY date, hour HAVING SUM(n) != 0 ORDER BY date, hour
+	`, ids...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var c HourlyCount
+		if err := rows.Scan(&c.Date, &c.Hour, &c.Keystrokes); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		delta.Hours = append(delta.Hours, c)
+	}
+	rows.Close()
+
+	rows, err = tx.Query(`
+		SELECT date, keycode, SUM(n) FROM (
+			SELECT date, keycode, COUNT(*) AS n FROM keystrokes WHERE id > ? AND id <= ? GROUP BY date, keycode
+			UNION ALL
+			SELECT date, keycode, SUM(keystrokes) AS n FROM sync_adjustments WHERE id > ? AND id <= ? GROUP BY date, keycode
+		)
+		GROUP BY date, keycode HAVING SUM(n) != 0 ORDER BY date, keycode
+	`, ids...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var c KeyCount
+		if err := rows.Scan(&c.Date, &c.Keycode, &c.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		delta.Keys = append(delta.Keys, c)
+	}
+	rows.Close()
+
+	// Words are a per-day counter, so diff them against what was last sent
+	rows, err = tx.Query(`
+		SELECT d.date, COALESCE(d.words, 0) - COALESCE(i.words, 0) - COALESCE(p.words, 0)
+		FROM daily_summary d
+		LEFT JOIN (SELECT date, SUM(words) AS words FROM imported_daily GROUP BY date) i ON i.date = d.date
+		LEFT JOIN sync_pushed p ON p.date = d.date
+		WHERE COALESCE(d.words, 0) - COALESCE(i.words, 0) - COALESCE(p.words, 0) != 0
+		ORDER BY d.date
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var d SyncDay
+		if err := rows.Scan(&d.Date, &d.Words); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		delta.Days = append(delta.Days, d)
+	}
+	rows.Close()
+
+	return delta, rows.Err()
+}
+
+// MarkSynced advances the watermarks past delta and claims its sequence
+// number, before its changeset is written. Committing first means a
+// changeset is never written twice under one sequence number; if writing it
+// fails, UnmarkSynced gives the counts back to the next push. It fails if
+// another push claimed the sequence number first.
+func (s *Store) MarkSynced(delta *SyncDelta) error {
+	return s.moveSyncWatermarks(delta, delta.Seq-1, delta.Seq, delta.keystrokeID, delta.adjustID, time.Now().UnixMilli(), 1)
+}
+
+// UnmarkSynced undoes MarkSynced when delta's changeset couldn't be written
+func (s *Store) UnmarkSynced(delta *SyncDelta) error {
+	return s.moveSyncWatermarks(delta, delta.Seq, delta.Seq-1, delta.prevKeystrokeID, delta.prevAdjustID, delta.prevPush, -1)
+}
+
+// moveSyncWatermarks sets the sync sequence number from fromSeq to toSeq
+// along with the other watermarks, and adds sign times delta's word counts
+// to what has been pushed
+func (s *Store) moveSyncWatermarks(delta *SyncDelta, fromSeq, toSeq, keystrokeID, adjustID, pushed int64, sign int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seq, err := txSettingInt(tx, settingSyncSeq)
+	if err != nil {
+		return err
+	}
+	if seq != fromSeq {
+		return errSyncRace
+	}
+
+	for _, d := range delta.Days {
+		if _, err := tx.Exec(`
+			INSERT INTO sync_pushed (date, words) VALUES (?, ?)
+			ON CONFLICT(date) DO UPDATE SET words = words + excluded.words
+		`, d.Date, sign*d.Words); err != nil {
+			return err
+		}
+	}
+	for key, value := range map[string]int64{
+		settingSyncSeq:        toSeq,
+		settingSyncLastID:     keystrokeID,
+		settingSyncLastAdjust: adjustID,
+		settingSyncPushed:     pushed,
+	} {
+		if err := txSetSetting(tx, key, strconv.FormatInt(value, 10)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// syncedKeystrokeID returns the newest keystroke already in a changeset
+func syncedKeystrokeID(tx *sql.Tx) (int64, error) {
+	return txSettingInt(tx, settingSyncLastID)
+}
+
+func txSettingInt(tx *sql.Tx, key string) (int64, error) {
+	var value string
+	err := tx.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func txSetSetting(tx *sql.Tx, key, value string) error {
+	_, err := tx.Exec(`
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`, key, value)
+	return err
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"time"
+)
+
+// TypingTestResult is a single completed typing test
+type TypingTestResult struct {
+	Timestamp   time.Time
+	Date        string // Logical date the test was taken on
+	WPM         float64
+	Accuracy    float64 // Percentage, 0-100
+	Duration    time.Duration
+	WordCount   int
+	Punctuation bool
+	Source      string // Where the result came from; empty means SourceTyptel
+}
+
+// Mode returns the typing test mode the result was recorded in
+func (r TypingTestResult) Mode() TypingTestMode {
+	return TypingTestMode{WordCount: r.WordCount, Punctuation: r.Punctuation}
+}
+
+// RecordTypingTest appends a result to the typing test history. It does not
+// touch the personal best/average settings; see SaveTypingTestResultForMode.
+func (s *Store) RecordTypingTest(r TypingTestResult) error {
+	if r.Timestamp.IsZero() {
+		r.Timestamp = time.Now()
+	}
+	if r.Date == "" {
+		r.Date = s.DateFor(r.Timestamp)
+	}
+	if r.Source == "" {
+		r.Source = SourceTyptel
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO typing_tests (ts, date, wpm, accuracy, duration_ms, word_count, punctuation, source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.Timestamp.UnixMilli(), r.Date, r.WPM, r.Accuracy, r.Duration.Milliseconds(), r.WordCount, r.Punctuation, r.Source)
+	return err
+}
+
+// GetTypingTestHistory returns typing tests taken between from and to
+// (inclusive logical dates, empty for unbounded), oldest first
+func (s *Store) GetTypingTestHistory(from, to string) ([]TypingTestResult, error) {
+	rows, err := s.db.Query(`
+		SELECT ts, date, wpm, COALESCE(accuracy, 0), COALESCE(duration_ms, 0),
+		       COALESCE(word_count, 0), COALESCE(punctuation, 0), source
+		FROM typing_tests
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY ts, id
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []TypingTestResult
+	for rows.Next() {
+		var r TypingTestResult
+		var ts, durationMS int64
+		if err := rows.Scan(&ts, &r.Date, &r.WPM, &r.Accuracy, &durationMS, &r.WordCount, &r.Punctuation, &r.Source); err != nil {
+			return nil, err
+		}
+		r.Timestamp = time.UnixMilli(ts).UTC()
+		r.Duration = time.Duration(durationMS) * time.Millisecond
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/words"
+)
+
+// WordRecount summarises a RecountWords run
+type WordRecount struct {
+	Days    int   `json:"days"`    // days with recorded keystrokes
+	Changed int   `json:"changed"` // days whose word count changed
+	Before  int64 `json:"before"`  // words on those days before the recount
+	After   int64 `json:"after"`
+}
+
+// RecountWords replays the recorded keystrokes through the same word
+// detector the daemon uses and rewrites the word count of every day, and
+// hour, that still has its keystrokes. Words are credited to the day and
+// hour of the key that completed them. Imported words are kept; days whose
+// keystrokes were pruned or never recorded here keep their totals.
+func (s *Store) RecountWords() (*WordRecount, error) {
+	return s.recountWords(nil)
+}
+
+// recountWords recounts only dates, including any that may have lost all
+// their keystrokes, such as the days keystrokes were moved away from. Their
+// keystrokes are replayed on their own, so a word typed across the edge of
+// one is credited as if it began there. Nil dates recount every day with
+// keystrokes, as RecountWords does.
+func (s *Store) recountWords(dates []string) (*WordRecount, error) {
+	if err := recountHourlyWords(s.db, dates); err != nil {
+		return nil, err
+	}
+
+	rows, err := replayKeystrokes(s.db, "keycode, modifiers, date", dates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystrokes: %w", err)
+	}
+	var detector words.Detector
+	counts := make(map[string]int64)
+	for _, date := range dates {
+		counts[date] = 0
+	}
+	for rows.Next() {
+		var code int
+		var mods keyboard.Modifiers
+		var date string
+		if err := rows.Scan(&code, &mods, &date); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := counts[date]; !ok {
+			counts[date] = 0
+		}
+		if detector.Press(code, mods) {
+			counts[date]++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &WordRecount{Days: len(counts)}
+	for date, n := range counts {
+		var before, imported int64
+		err := tx.QueryRow(`
+			SELECT COALESCE((SELECT words FROM daily_summary WHERE date = ?), 0),
+			       COALESCE((SELECT SUM(words) FROM imported_daily WHERE date = ?), 0)
+		`, date, date).Scan(&before, &imported)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read words for %s: %w", date, err)
+		}
+
+		after := n + imported
+		if after == before {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO daily_summary (date, words) VALUES (?, ?)
+			ON CONFLICT(date) DO UPDATE SET
+				words = excluded.words,
+				updated_at = CURRENT_TIMESTAMP
+		`, date, after); err != nil {
+			return nil, fmt.Errorf("failed to update words for %s: %w", date, err)
+		}
+		result.Changed++
+		result.Before += before
+		result.After += after
+	}
+
+	return result, tx.Commit()
+}
+
+// recountHourlyWords replays the keystrokes of dates through a word detector
+// and rewrites their hourly_words, including dates that may have lost all
+// their keystrokes. Nil dates rewrite every date that still has keystrokes.
+// Days whose keystrokes were pruned keep their counts.
+func recountHourlyWords(db *sql.DB, dates []string) error {
+	type slot struct {
+		date string
+		hour int
+	}
+
+	rows, err := replayKeystrokes(db, "keycode, modifiers, date, hour", dates)
+	if err != nil {
+		return fmt.Errorf("failed to read keystrokes: %w", err)
+	}
+	var detector words.Detector
+	counts := make(map[slot]int64)
+	recount := make(map[string]bool)
+	for _, date := range dates {
+		recount[date] = true
+	}
+	for rows.Next() {
+		var code, hour int
+		var mods keyboard.Modifiers
+		var date string
+		if err := rows.Scan(&code, &mods, &date, &hour); err != nil {
+			rows.Close()
+			return err
+		}
+		recount[date] = true
+		if detector.Press(code, mods) {
+			counts[slot{date, hour}]++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for date := range recount {
+		if _, err := tx.Exec("DELETE FROM hourly_words WHERE date = ?", date); err != nil {
+			return fmt.Errorf("failed to clear hourly words for %s: %w", date, err)
+		}
+	}
+	for sl, n := range counts {
+		if _, err := tx.Exec(
+			"INSERT INTO hourly_words (date, hour, words) VALUES (?, ?, ?)",
+			sl.date, sl.hour, n,
+		); err != nil {
+			return fmt.Errorf("failed to update hourly words: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// replayKeystrokes selects cols of the keystrokes on dates in the order they
+// were typed, or of every keystroke if dates is nil
+func replayKeystrokes(db *sql.DB, cols string, dates []string) (*sql.Rows, error) {
+	if dates == nil {
+		return db.Query("SELECT " + cols + " FROM keystrokes ORDER BY id")
+	}
+	args := make([]interface{}, len(dates))
+	for i, date := range dates {
+		args[i] = date
+	}
+	placeholders := strings.TrimPrefix(strings.Repeat(", ?", len(dates)), ", ")
+	return db.Query("SELECT "+cols+" FROM keystrokes WHERE date IN ("+placeholders+") ORDER BY id", args...)
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+// typeKeys records presses a second apart starting at t and returns the time
+// after the last one
+func typeKeys(t *testing.T, store *Store, at time.Time, codes ...int) time.Time {
+	t.Helper()
+	for _, code := range codes {
+		if err := store.RecordKeystrokeAt(code, at); err != nil {
+			t.Fatalf("RecordKeystrokeAt failed: %v", err)
+		}
+		at = at.Add(time.Second)
+	}
+	return at
+}
+
+func TestRecountWords(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	const (
+		h, i, space, ret = keyboard.KeyH, keyboard.KeyI, keyboard.KeySpace, keyboard.KeyReturn
+	)
+
+	// "hi   hi\n\n" counted by boundary keys gave 5 words; it is 2
+	day1 := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	typeKeys(t, store, day1, h, i, space, space, space, h, i, ret, ret)
+	for n := 0; n < 5; n++ {
+		store.IncrementWordCount("2024-03-01")
+	}
+	store.AddImportedDailyTotals("2024-03-01", SourceWhatPulse, 0, 10)
+
+	// A word typed across midnight counts on the day it was finished
+	late := time.Date(2024, 3, 1, 23, 59, 58, 0, time.UTC)
+	typeKeys(t, store, late, h, i, space)
+
+	// Days without keystrokes keep their words
+	store.AddDailyTotals("2024-02-01", 100, 20)
+
+	result, err := store.RecountWords()
+	if err != nil {
+		t.Fatalf("RecountWords failed: %v", err)
+	}
+	if result.Days != 2 || result.Changed != 2 || result.Before != 15 || result.After != 13 {
+		t.Errorf("RecountWords = %+v, want 2 days changed from 15 to 13 words", result)
+	}
+
+	for date, want := range map[string]int64{"2024-03-01": 12, "2024-03-02": 1, "2024-02-01": 20} {
+		day, _ := store.GetDayStats(date)
+		if day.Words != want {
+			t.Errorf("%s has %d words, want %d", date, day.Words, want)
+		}
+	}
+
+	// Each word is credited to the hour of the key that completed it
+	for _, tt := range []struct {
+		date  string
+		hour  int
+		words int64
+	}{{"2024-03-01", 9, 2}, {"2024-03-01", 23, 0}, {"2024-03-02", 0, 1}} {
+		var n int64
+		store.db.QueryRow("SELECT words FROM hourly_words WHERE date = ? AND hour = ?", tt.date, tt.hour).Scan(&n)
+		if n != tt.words {
+			t.Errorf("%s %02d:00 has %d words, want %d", tt.date, tt.hour, n, tt.words)
+		}
+	}
+
+	// Recounting again changes nothing
+	result, err = store.RecountWords()
+	if err != nil {
+		t.Fatalf("RecountWords failed: %v", err)
+	}
+	if result.Changed != 0 {
+		t.Errorf("second recount changed %d days", result.Changed)
+	}
+}
+
+func TestHourlyWordsBackfill(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	at := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	typeKeys(t, store, at, keyboard.KeyO, keyboard.KeyK, keyboard.KeySpace, keyboard.KeyO, keyboard.KeyK, keyboard.KeySpace)
+
+	// A database from before words were counted per hour
+	if _, err := store.db.Exec("PRAGMA user_version = 13"); err != nil {
+		t.Fatal(err)
+	}
+	if err := initSchema(store.db); err != nil {
+		t.Fatalf("initSchema failed: %v", err)
+	}
+
+	var n int64
+	store.db.QueryRow("SELECT words FROM hourly_words WHERE date = '2024-05-02' AND hour = 9").Scan(&n)
+	if n != 2 {
+		t.Errorf("Backfilled %d words at 9am, want 2", n)
+	}
+}
+
+func TestRebuildKeystrokeDatesRecountsWords(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.SetTimezone("UTC")
+
+	at := time.Date(2024, 5, 2, 2, 15, 0, 0, time.UTC)
+	typeKeys(t, store, at, keyboard.KeyO, keyboard.KeyK, keyboard.KeySpace)
+	store.IncrementWordCount("2024-05-02")
+
+	// A day nothing moves from or to isn't recounted, even when it's off
+	typeKeys(t, store, time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC), keyboard.KeyO, keyboard.KeyK, keyboard.KeySpace)
+	for i := 0; i < 3; i++ {
+		store.IncrementWordCount("2024-05-10")
+	}
+	store.db.Exec("UPDATE hourly_corrections SET chars = 99 WHERE date = '2024-05-10'")
+
+	// Moving the boundary to 4am takes the word back a day with its keystrokes
+	if err := store.SetDayStartHour(4); err != nil {
+		t.Fatalf("SetDayStartHour failed: %v", err)
+	}
+	prev, _ := store.GetDayStats("2024-05-01")
+	day, _ := store.GetDayStats("2024-05-02")
+	if prev.Words != 1 || day.Words != 0 {
+		t.Errorf("Expected 1/0 words after rebuild, got %d/%d", prev.Words, day.Words)
+	}
+	if untouched, _ := store.GetDayStats("2024-05-10"); untouched.Words != 3 {
+		t.Errorf("Expected the unmoved day to keep 3 words, got %d", untouched.Words)
+	}
+	var chars int64
+	store.db.QueryRow("SELECT chars FROM hourly_corrections WHERE date = '2024-05-10'").Scan(&chars)
+	if chars != 99 {
+		t.Errorf("Expected the unmoved day's corrections kept, got %d chars", chars)
+	}
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressWidth is the width of a locked achievement's progress bar
+const progressWidth = 20
+
+// updateAchievements handles keys while the achievements are shown
+func (m Model) updateAchievements(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "a", "esc":
+		m.showAchievements = false
+	case "r":
+		return m, m.fetchStats
+	}
+	return m, nil
+}
+
+func (m Model) achievementsView() string {
+	var b strings.Builder
+
+	unlocked := 0
+	for _, s := range m.achievements {
+		if s.Unlocked {
+			unlocked++
+		}
+	}
+	b.WriteString(titleStyle.Render(":: Achievements"))
+	b.WriteString("\n\n")
+	b.WriteString(statLabelStyle.Render(fmt.Sprintf("%d of %d unlocked", unlocked, len(m.achievements))))
+	b.WriteString("\n\n")
+
+	for _, s := range m.achievements {
+		b.WriteString(renderAchievement(s))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(helpStyle.Render("a: back • r: refresh • q: quit"))
+
+	return b.String()
+}
+
+// renderAchievement shows an achievement with when it was unlocked, or a
+// progress bar towards it
+func renderAchievement(s achievements.Status) string {
+	if s.Unlocked {
+		when := "unlocked"
+		if s.UnlockedAt != nil {
+			when = "unlocked " + s.UnlockedAt.Local().Format("Jan 2, 2006")
+		}
+		return fmt.Sprintf("%s %s  %s\n   %s",
+			s.Icon, statValueStyle.Render(s.Name), statLabelStyle.Render(when), s.Description)
+	}
+
+	filled := int(s.Progress * progressWidth)
+	bar := graphStyle.Render(strings.Repeat("█", filled)) + strings.Repeat("░", progressWidth-filled)
+	return fmt.Sprintf("🔒 %s  %s %s\n   %s",
+		statLabelStyle.Render(s.Name), bar, statLabelStyle.Render(fmt.Sprintf("%3.0f%%", s.Progress*100)), s.Description)
+}
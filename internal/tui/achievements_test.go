@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAchievementsToggle(t *testing.T) {
+	at := time.Date(2024, 3, 30, 12, 0, 0, 0, time.Local)
+	stats := achievements.Stats{achievements.TotalKeystrokes: 250000, achievements.BestTestWPM: 104}
+	newModel, _ := New(nil).Update(statsMsg{
+		today:  &storage.DailyStats{Date: "2024-03-31"},
+		badges: achievements.Evaluate(stats, map[string]time.Time{"test-100wpm": at}),
+	})
+
+	m := pressKey(newModel.(Model), "a")
+	if !m.showAchievements {
+		t.Fatal("Expected a to open the achievements")
+	}
+	view := m.View()
+	for _, want := range []string{"1 of 5 unlocked", "Triple Digits", "unlocked Mar 30, 2024", "Millionaire", " 25%"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in achievements view:\n%s", want, view)
+		}
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+	if m.showAchievements || cmd != nil {
+		t.Error("Expected esc to close the achievements without quitting")
+	}
+}
+
+func TestAchievementsToggleBeforeLoad(t *testing.T) {
+	if m := pressKey(New(nil), "a"); m.showAchievements {
+		t.Error("Expected achievements to stay closed until stats have loaded")
+	}
+}
@@ -0,0 +1,204 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// calendarLevels draws report.Levels heatmap levels, empty first
+var calendarLevels = []string{"·", "░", "▒", "▓", "█"}
+
+// calendarWeekdays labels every other row, Sunday first
+var calendarWeekdays = [7]string{"", "Mon", "", "Wed", "", "Fri", ""}
+
+// setCalendar swaps in freshly loaded calendar data, keeping the cursor and
+// range on the same days where they still exist
+func (m *Model) setCalendar(c *report.Calendar) {
+	cursorDate, anchorDate := "", ""
+	if m.calendar != nil {
+		if m.calCursor >= 0 && m.calCursor < len(m.calendar.Days) {
+			cursorDate = m.calendar.Days[m.calCursor].Date
+		}
+		if m.calAnchor >= 0 && m.calAnchor < len(m.calendar.Days) {
+			anchorDate = m.calendar.Days[m.calAnchor].Date
+		}
+	}
+
+	m.calendar = c
+	m.calCursor, m.calAnchor = -1, -1
+	if c == nil || len(c.Days) == 0 {
+		return
+	}
+	if cursorDate != "" {
+		m.calCursor = c.Index(cursorDate)
+	}
+	if m.calCursor < 0 {
+		m.calCursor = len(c.Days) - 1
+	}
+	if anchorDate != "" {
+		m.calAnchor = c.Index(anchorDate)
+	}
+}
+
+// updateCalendar handles keys while the calendar is shown
+func (m Model) updateCalendar(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "c", "esc":
+		m.showCalendar = false
+	case "r":
+		return m, m.fetchStats
+	case "left", "h":
+		m.moveCalendarCursor(-7)
+	case "right", "l":
+		m.moveCalendarCursor(7)
+	case "up", "k":
+		m.moveCalendarCursor(-1)
+	case "down", "j":
+		m.moveCalendarCursor(1)
+	case " ":
+		if m.calAnchor >= 0 {
+			m.calAnchor = -1
+		} else {
+			m.calAnchor = m.calCursor
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) moveCalendarCursor(days int) {
+	if m.calendar == nil || len(m.calendar.Days) == 0 {
+		return
+	}
+	m.calCursor += days
+	if m.calCursor < 0 {
+		m.calCursor = 0
+	}
+	if m.calCursor >= len(m.calendar.Days) {
+		m.calCursor = len(m.calendar.Days) - 1
+	}
+}
+
+// selection returns the selected range of calendar days, which is just the
+// cursor's day when no range is being marked
+func (m Model) selection() (int, int) {
+	if m.calAnchor < 0 {
+		return m.calCursor, m.calCursor
+	}
+	if m.calAnchor < m.calCursor {
+		return m.calAnchor, m.calCursor
+	}
+	return m.calCursor, m.calAnchor
+}
+
+func (m Model) calendarView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(":: Typing Calendar"))
+	b.WriteString("\n\n")
+	b.WriteString(m.renderCalendar())
+	b.WriteString("\n\n")
+
+	from, to := m.selection()
+	summary := m.calendar.Summary(from, to)
+	if summary.Days == 1 {
+		day := m.calendar.Days[from]
+		t, _ := time.Parse("2006-01-02", day.Date)
+		b.WriteString(fmt.Sprintf(
+			"%s  %s %s  %s %s",
+			statValueStyle.Render(t.Format("Mon Jan 2, 2006")),
+			statLabelStyle.Render("Keystrokes:"),
+			statValueStyle.Render(report.FormatAbsolute(day.Keystrokes)),
+			statLabelStyle.Render("Words:"),
+			statValueStyle.Render(report.FormatAbsolute(day.Words)),
+		))
+	} else {
+		start, _ := time.Parse("2006-01-02", summary.From)
+		end, _ := time.Parse("2006-01-02", summary.To)
+		b.WriteString(fmt.Sprintf(
+			"%s  %s\n%s %s  %s %s  %s %s",
+			statValueStyle.Render(start.Format("Jan 2, 2006")+" – "+end.Format("Jan 2, 2006")),
+			statLabelStyle.Render(fmt.Sprintf("%d days, %d active", summary.Days, summary.ActiveDays)),
+			statLabelStyle.Render("Keystrokes:"),
+			statValueStyle.Render(report.FormatAbsolute(summary.Keystrokes)),
+			statLabelStyle.Render("Words:"),
+			statValueStyle.Render(report.FormatAbsolute(summary.Words)),
+			statLabelStyle.Render("Daily Avg:"),
+			statValueStyle.Render(report.FormatAbsolute(summary.DailyAverage())),
+		))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(helpStyle.Render("←/→: week • ↑/↓: day • space: mark range • c: back • q: quit"))
+
+	return b.String()
+}
+
+// renderCalendar draws the calendar grid: month labels, one row per weekday
+// and one column per week, then a legend
+func (m Model) renderCalendar() string {
+	c := m.calendar
+	from, to := m.selection()
+	var b strings.Builder
+
+	// Month labels, skipping any that would run into the previous one
+	months := []rune(strings.Repeat(" ", len(c.Weeks)))
+	next := 0
+	for w, week := range c.Weeks {
+		if week.Month == "" || w < next || w+len(week.Month) > len(months) {
+			continue
+		}
+		copy(months[w:], []rune(week.Month))
+		next = w + len(week.Month) + 1
+	}
+	b.WriteString("    ")
+	b.WriteString(statLabelStyle.Render(string(months)))
+	b.WriteString("\n")
+
+	index := make(map[string]int, len(c.Days))
+	for i, day := range c.Days {
+		index[day.Date] = i
+	}
+	for wd := 0; wd < 7; wd++ {
+		b.WriteString(statLabelStyle.Render(fmt.Sprintf("%-4s", calendarWeekdays[wd])))
+		for _, week := range c.Weeks {
+			day := week.Days[wd]
+			if day == nil {
+				b.WriteString(" ")
+				continue
+			}
+			cell := calendarLevels[day.Level]
+			i := index[day.Date]
+			switch {
+			case i == m.calCursor:
+				b.WriteString(calendarCursorStyle.Render(cell))
+			case m.calAnchor >= 0 && i >= from && i <= to:
+				b.WriteString(calendarRangeStyle.Render(cell))
+			case day.Level == 0:
+				b.WriteString(statLabelStyle.Render(cell))
+			default:
+				b.WriteString(graphStyle.Render(cell))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n    ")
+	b.WriteString(statLabelStyle.Render("Less "))
+	for level, cell := range calendarLevels {
+		if level == 0 {
+			b.WriteString(statLabelStyle.Render(cell))
+		} else {
+			b.WriteString(graphStyle.Render(cell))
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString(statLabelStyle.Render("More"))
+
+	return b.String()
+}
@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// calendarModel returns a loaded model with a calendar from Jan 1 to Mar 31
+// 2024 (91 days)
+func calendarModel() Model {
+	model := New(nil)
+	calendar := report.NewCalendar("2024-01-01", "2024-03-31", []storage.DailyStats{
+		{Date: "2024-01-02", Keystrokes: 1200, Words: 240},
+		{Date: "2024-02-14", Keystrokes: 5000, Words: 1000},
+		{Date: "2024-03-31", Keystrokes: 300, Words: 60},
+	})
+	newModel, _ := model.Update(statsMsg{today: &storage.DailyStats{}, calendar: calendar})
+	return newModel.(Model)
+}
+
+func pressKey(m Model, key string) Model {
+	var msg tea.KeyMsg
+	switch key {
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	case "left":
+		msg = tea.KeyMsg{Type: tea.KeyLeft}
+	case "right":
+		msg = tea.KeyMsg{Type: tea.KeyRight}
+	case "up":
+		msg = tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	case " ":
+		msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	newModel, _ := m.Update(msg)
+	return newModel.(Model)
+}
+
+func TestCalendarCursorStartsOnLastDay(t *testing.T) {
+	m := calendarModel()
+	if m.calCursor != len(m.calendar.Days)-1 {
+		t.Errorf("calCursor = %d, want %d", m.calCursor, len(m.calendar.Days)-1)
+	}
+	if m.calAnchor != -1 {
+		t.Errorf("calAnchor = %d, want -1", m.calAnchor)
+	}
+}
+
+func TestCalendarToggle(t *testing.T) {
+	m := pressKey(calendarModel(), "c")
+	if !m.showCalendar {
+		t.Fatal("Expected c to open the calendar")
+	}
+	if !strings.Contains(m.View(), "Typing Calendar") {
+		t.Error("Expected calendar view")
+	}
+
+	// esc leaves the calendar instead of quitting
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+	if m.showCalendar {
+		t.Error("Expected esc to close the calendar")
+	}
+	if cmd != nil {
+		t.Error("Expected esc in the calendar not to quit")
+	}
+}
+
+func TestCalendarToggleBeforeLoad(t *testing.T) {
+	m := pressKey(New(nil), "c")
+	if m.showCalendar {
+		t.Error("Expected calendar to stay closed until stats have loaded")
+	}
+}
+
+func TestCalendarNavigation(t *testing.T) {
+	last := 90
+	tests := []struct {
+		name string
+		keys []string
+		want int
+	}{
+		{"previous day", []string{"up"}, last - 1},
+		{"previous week", []string{"left"}, last - 7},
+		{"vim keys", []string{"k", "h"}, last - 8},
+		{"clamped at end", []string{"down", "right", "j", "l"}, last},
+		{"back and forth", []string{"left", "left", "right", "down"}, last - 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := pressKey(calendarModel(), "c")
+			for _, key := range tt.keys {
+				m = pressKey(m, key)
+			}
+			if m.calCursor != tt.want {
+				t.Errorf("calCursor = %d, want %d", m.calCursor, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarCursorClampedAtStart(t *testing.T) {
+	m := pressKey(calendarModel(), "c")
+	for i := 0; i < 20; i++ {
+		m = pressKey(m, "left")
+	}
+	if m.calCursor != 0 {
+		t.Errorf("calCursor = %d, want 0", m.calCursor)
+	}
+}
+
+func TestCalendarSelectedDay(t *testing.T) {
+	m := pressKey(calendarModel(), "c")
+	view := m.View()
+	if !strings.Contains(view, "Sun Mar 31, 2024") {
+		t.Error("Expected selected day in view")
+	}
+	if !strings.Contains(view, "300") {
+		t.Error("Expected selected day's keystrokes in view")
+	}
+}
+
+func TestCalendarRangeSelection(t *testing.T) {
+	m := pressKey(calendarModel(), "c")
+	m = pressKey(m, " ")
+	for i := 0; i < 7; i++ {
+		m = pressKey(m, "left")
+	}
+
+	from, to := m.selection()
+	if from != 41 || to != 90 {
+		t.Fatalf("selection() = %d, %d, want 41, 90", from, to)
+	}
+
+	view := m.View()
+	for _, want := range []string{"Feb 11, 2024 – Mar 31, 2024", "50 days, 2 active", "5,300", "106"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in range summary:\n%s", want, view)
+		}
+	}
+
+	// space again clears the range
+	m = pressKey(m, " ")
+	if from, to := m.selection(); from != to {
+		t.Errorf("Expected single day after clearing range, got %d-%d", from, to)
+	}
+}
+
+func TestCalendarKeepsCursorOnRefresh(t *testing.T) {
+	m := pressKey(calendarModel(), "c")
+	m = pressKey(m, "left")
+	date := m.calendar.Days[m.calCursor].Date
+
+	// The next day's calendar drops Jan 1 and adds Apr 1
+	calendar := report.NewCalendar("2024-01-02", "2024-04-01", nil)
+	newModel, _ := m.Update(statsMsg{today: &storage.DailyStats{}, calendar: calendar})
+	m = newModel.(Model)
+
+	if got := m.calendar.Days[m.calCursor].Date; got != date {
+		t.Errorf("cursor moved to %s after refresh, want %s", got, date)
+	}
+}
+
+func TestRenderCalendar(t *testing.T) {
+	m := calendarModel()
+	grid := m.renderCalendar()
+	lines := strings.Split(grid, "\n")
+
+	// Month labels, 7 weekday rows, a blank line and the legend
+	if len(lines) != 10 {
+		t.Fatalf("Expected 10 lines, got %d:\n%s", len(lines), grid)
+	}
+	for _, month := range []string{"Jan", "Feb", "Mar"} {
+		if !strings.Contains(lines[0], month) {
+			t.Errorf("Expected %s label in %q", month, lines[0])
+		}
+	}
+	for _, label := range []string{"Mon", "Wed", "Fri"} {
+		found := false
+		for _, line := range lines[1:8] {
+			if strings.HasPrefix(line, label) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a row labelled %s", label)
+		}
+	}
+	if !strings.Contains(lines[9], "Less") || !strings.Contains(lines[9], "More") {
+		t.Errorf("Expected legend, got %q", lines[9])
+	}
+}
@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// KeyboardDays is how many days of key presses the keyboard heatmap covers,
+// ending today
+const KeyboardDays = 30
+
+// keyColumns is the number of terminal columns per key unit; a letter key is
+// four columns of cap and one of gap
+const keyColumns = 5
+
+// keyboardFrom returns the first day of the keyboard heatmap's range
+func keyboardFrom(today string) string {
+	t, err := time.Parse("2006-01-02", today)
+	if err != nil {
+		return today
+	}
+	return t.AddDate(0, 0, -(KeyboardDays - 1)).Format("2006-01-02")
+}
+
+// setKeyboard lays out the loaded key frequencies on the current layout
+func (m *Model) setKeyboard() {
+	kb, err := report.BuildKeyboard(m.keyFreqs, m.keyboardLayout)
+	if err != nil {
+		kb, _ = report.BuildKeyboard(m.keyFreqs, keyboard.DefaultLayout)
+	}
+	m.keyboard = kb
+	m.keyboardLayout = kb.Layout
+}
+
+// updateKeyboard handles keys while the keyboard heatmap is shown
+func (m Model) updateKeyboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "k", "esc":
+		m.showKeyboard = false
+	case "r":
+		return m, m.fetchStats
+	case "l":
+		// Cycle through the physical layouts
+		next := keyboard.Layouts[0]
+		for i, name := range keyboard.Layouts {
+			if name == m.keyboardLayout && i+1 < len(keyboard.Layouts) {
+				next = keyboard.Layouts[i+1]
+			}
+		}
+		m.keyboardLayout = next
+		m.setKeyboard()
+	}
+	return m, nil
+}
+
+func (m Model) keyboardView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(":: Key Frequency"))
+	b.WriteString("\n\n")
+	b.WriteString(statLabelStyle.Render(fmt.Sprintf("Last %d days • %s layout • %s presses",
+		KeyboardDays, strings.ToUpper(m.keyboard.Layout), report.FormatAbsolute(m.keyboard.Total))))
+	b.WriteString("\n\n")
+	b.WriteString(renderKeyboard(m.keyboard))
+	b.WriteString("\n\n")
+
+	if len(m.keyboard.Top) == 0 {
+		b.WriteString(statLabelStyle.Render("No keystrokes yet"))
+		b.WriteString("\n")
+	}
+	for i, k := range m.keyboard.Top {
+		b.WriteString(fmt.Sprintf("%s %s %s\n",
+			statLabelStyle.Render(fmt.Sprintf("%2d.", i+1)),
+			statValueStyle.Render(fmt.Sprintf("%-7s", k.Name)),
+			statLabelStyle.Render(fmt.Sprintf("%10s  %5.1f%%", report.FormatAbsolute(k.Count), k.Percent)),
+		))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(helpStyle.Render("l: switch layout • k: back • r: refresh • q: quit"))
+
+	return b.String()
+}
+
+// renderKeyboard draws each key row as one line of caps shaded with the
+// heatmap colours
+func renderKeyboard(kb *report.Keyboard) string {
+	rows := make([]strings.Builder, kb.Rows)
+	ends := make([]int, kb.Rows)
+	for _, k := range kb.Keys {
+		y := int(k.Y)
+		start := int(math.Round(k.X * keyColumns))
+		end := int(math.Round((k.X + k.W) * keyColumns))
+		if start > ends[y] {
+			rows[y].WriteString(strings.Repeat(" ", start-ends[y]))
+		}
+		ends[y] = end
+
+		width := end - start - 1
+		label := []rune(k.Label)
+		if len(label) > width {
+			label = label[:width]
+		}
+		pad := width - len(label)
+		text := strings.Repeat(" ", pad/2) + string(label) + strings.Repeat(" ", pad-pad/2)
+
+		style := lipgloss.NewStyle().Background(lipgloss.Color(k.Color)).Foreground(lipgloss.Color("#e0e0e0"))
+		if k.Level >= report.Levels-2 {
+			style = style.Foreground(lipgloss.Color("#1a1a2e"))
+		}
+		rows[y].WriteString(style.Render(text))
+		rows[y].WriteString(" ")
+	}
+
+	lines := make([]string, len(rows))
+	for i := range rows {
+		lines[i] = strings.TrimRight(rows[i].String(), " ")
+	}
+	return strings.Join(lines, "\n")
+}
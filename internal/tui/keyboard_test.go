@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+	"github.com/aayushbajaj/typing-telemetry/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyboardModel returns a loaded model with a few keys pressed
+func keyboardModel(layout string) Model {
+	newModel, _ := New(nil).Update(statsMsg{
+		today: &storage.DailyStats{Date: "2024-03-31"},
+		keys: []storage.KeyFrequency{
+			{Keycode: keyboard.KeySpace, Count: 900},
+			{Keycode: keyboard.KeyE, Count: 600},
+			{Keycode: keyboard.KeyDelete, Count: 120},
+		},
+		layout: layout,
+	})
+	return newModel.(Model)
+}
+
+func TestKeyboardFrom(t *testing.T) {
+	if got := keyboardFrom("2024-03-31"); got != "2024-03-02" {
+		t.Errorf("keyboardFrom = %s, want 2024-03-02", got)
+	}
+}
+
+func TestKeyboardToggle(t *testing.T) {
+	m := pressKey(keyboardModel(keyboard.ISO), "k")
+	if !m.showKeyboard {
+		t.Fatal("Expected k to open the keyboard")
+	}
+	view := m.View()
+	for _, want := range []string{"Key Frequency", "ISO layout", "1,620 presses", "Space", "55.6%"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in keyboard view:\n%s", want, view)
+		}
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+	if m.showKeyboard || cmd != nil {
+		t.Error("Expected esc to close the keyboard without quitting")
+	}
+}
+
+func TestKeyboardToggleBeforeLoad(t *testing.T) {
+	if m := pressKey(New(nil), "k"); m.showKeyboard {
+		t.Error("Expected keyboard to stay closed until stats have loaded")
+	}
+}
+
+func TestKeyboardLayoutSwitch(t *testing.T) {
+	m := pressKey(keyboardModel(""), "k")
+	if m.keyboardLayout != keyboard.ANSI {
+		t.Fatalf("layout = %q, want the default", m.keyboardLayout)
+	}
+	m = pressKey(m, "l")
+	if m.keyboard.Layout != keyboard.ISO {
+		t.Errorf("layout after l = %q, want iso", m.keyboard.Layout)
+	}
+	m = pressKey(m, "l")
+	if m.keyboard.Layout != keyboard.ANSI {
+		t.Errorf("layout after l twice = %q, want ansi", m.keyboard.Layout)
+	}
+
+	// A refresh keeps the chosen layout
+	m = pressKey(m, "l")
+	newModel, _ := m.Update(statsMsg{today: &storage.DailyStats{}, layout: keyboard.ANSI})
+	if got := newModel.(Model).keyboard.Layout; got != keyboard.ISO {
+		t.Errorf("layout after refresh = %q, want iso", got)
+	}
+}
+
+func TestRenderKeyboard(t *testing.T) {
+	tests := []struct {
+		layout string
+		row    int
+		want   string
+	}{
+		{keyboard.ANSI, 1, "`    1    2"},
+		{keyboard.ANSI, 3, "Caps    A    S"},
+		{keyboard.ISO, 1, "§    1    2"},
+		{keyboard.ISO, 4, "Shift  `    Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			m := keyboardModel(tt.layout)
+			lines := strings.Split(renderKeyboard(m.keyboard), "\n")
+			if len(lines) != 6 {
+				t.Fatalf("Expected 6 rows, got %d", len(lines))
+			}
+			if !strings.Contains(lines[tt.row], tt.want) {
+				t.Errorf("row %d = %q, want it to contain %q", tt.row, lines[tt.row], tt.want)
+			}
+			for i, line := range lines {
+				if w := len([]rune(line)); w > 15*keyColumns {
+					t.Errorf("row %d is %d columns wide", i, w)
+				}
+			}
+		})
+	}
+}
@@ -170,6 +170,14 @@ func regenerateStyles() {
 	helpStyle = lipgloss.NewStyle().
 		Foreground(lipgloss.Color(CurrentTheme.LabelText)).
 		MarginTop(1)
+
+	calendarCursorStyle = lipgloss.NewStyle().
+		Background(lipgloss.Color(CurrentTheme.PrimaryAccent)).
+		Foreground(lipgloss.Color("#000000"))
+
+	calendarRangeStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(CurrentTheme.SecondaryAccent)).
+		Background(lipgloss.Color(CurrentTheme.SelectedBg))
 }
 
 // Initialize styles with default theme
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/aayushbajaj/typing-telemetry/internal/achievements"
+	"github.com/aayushbajaj/typing-telemetry/internal/report"
 	"github.com/aayushbajaj/typing-telemetry/internal/storage"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -17,6 +19,10 @@ var (
 	boxStyle       lipgloss.Style
 	graphStyle     lipgloss.Style
 	helpStyle      lipgloss.Style
+
+	// Calendar styles - initialized by themes.go
+	calendarCursorStyle lipgloss.Style
+	calendarRangeStyle  lipgloss.Style
 )
 
 type Model struct {
@@ -24,6 +30,16 @@ type Model struct {
 	todayStats         *storage.DailyStats
 	weekStats          []storage.DailyStats
 	hourlyStats        []storage.HourlyStats
+	calendar           *report.Calendar
+	showCalendar       bool
+	calCursor          int // index into calendar.Days, -1 until loaded
+	calAnchor          int // other end of the selected range, -1 when none
+	keyFreqs           []storage.KeyFrequency
+	keyboard           *report.Keyboard
+	keyboardLayout     string
+	showKeyboard       bool
+	achievements       []achievements.Status
+	showAchievements   bool
 	width              int
 	height             int
 	err                error
@@ -31,14 +47,18 @@ type Model struct {
 }
 
 type statsMsg struct {
-	today  *storage.DailyStats
-	week   []storage.DailyStats
-	hourly []storage.HourlyStats
-	err    error
+	today    *storage.DailyStats
+	week     []storage.DailyStats
+	hourly   []storage.HourlyStats
+	calendar *report.Calendar
+	keys     []storage.KeyFrequency
+	layout   string
+	badges   []achievements.Status
+	err      error
 }
 
 func New(store *storage.Store) Model {
-	return Model{store: store}
+	return Model{store: store, calCursor: -1, calAnchor: -1}
 }
 
 func (m Model) Init() tea.Cmd {
@@ -61,17 +81,61 @@ func (m Model) fetchStats() tea.Msg {
 		return statsMsg{err: err}
 	}
 
-	return statsMsg{today: today, week: week, hourly: hourly}
+	calendar, err := report.BuildCalendar(m.store, today.Date, report.CalendarDays)
+	if err != nil {
+		return statsMsg{err: err}
+	}
+
+	keys, err := m.store.GetKeyFrequencies(keyboardFrom(today.Date), today.Date)
+	if err != nil {
+		return statsMsg{err: err}
+	}
+
+	badges, err := m.store.GetAchievements(report.Units{}.Feet)
+	if err != nil {
+		return statsMsg{err: err}
+	}
+
+	return statsMsg{
+		today:    today,
+		week:     week,
+		hourly:   hourly,
+		calendar: calendar,
+		keys:     keys,
+		layout:   m.store.Config().Keyboard.Layout,
+		badges:   badges,
+	}
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.showCalendar {
+			return m.updateCalendar(msg)
+		}
+		if m.showKeyboard {
+			return m.updateKeyboard(msg)
+		}
+		if m.showAchievements {
+			return m.updateAchievements(msg)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c", "esc":
 			return m, tea.Quit
 		case "r":
 			return m, m.fetchStats
+		case "c":
+			if m.calendar != nil {
+				m.showCalendar = true
+			}
+		case "k":
+			if m.keyboard != nil {
+				m.showKeyboard = true
+			}
+		case "a":
+			if m.achievements != nil {
+				m.showAchievements = true
+			}
 		case "t":
 			m.SwitchToTypingTest = true
 			return m, tea.Quit
@@ -88,6 +152,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.todayStats = msg.today
 			m.weekStats = msg.week
 			m.hourlyStats = msg.hourly
+			m.setCalendar(msg.calendar)
+			m.keyFreqs = msg.keys
+			if m.keyboardLayout == "" {
+				m.keyboardLayout = msg.layout
+			}
+			m.setKeyboard()
+			m.achievements = msg.badges
 		}
 	}
 
@@ -103,6 +174,18 @@ func (m Model) View() string {
 		return "Loading..."
 	}
 
+	if m.showCalendar {
+		return m.calendarView()
+	}
+
+	if m.showKeyboard {
+		return m.keyboardView()
+	}
+
+	if m.showAchievements {
+		return m.achievementsView()
+	}
+
 	var b strings.Builder
 
 	// Title
@@ -148,7 +231,7 @@ func (m Model) View() string {
 	b.WriteString("\n")
 
 	// Help
-	b.WriteString(helpStyle.Render("t: typing test • r: refresh • q: quit"))
+	b.WriteString(helpStyle.Render("t: typing test • c: calendar • k: keyboard • a: achievements • r: refresh • q: quit"))
 
 	return b.String()
 }
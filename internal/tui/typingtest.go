@@ -450,6 +450,19 @@ func (m *TypingTestModel) recordTestResult() {
 			Punctuation: m.options.Punctuation,
 		}
 		m.store.SaveTypingTestResultForMode(wpm, mode)
+
+		accuracy := 0.0
+		if len(m.targetText) > 0 {
+			accuracy = float64(len(m.targetText)-m.errors) / float64(len(m.targetText)) * 100
+		}
+		m.store.RecordTypingTest(storage.TypingTestResult{
+			Timestamp:   m.endTime,
+			WPM:         wpm,
+			Accuracy:    accuracy,
+			Duration:    m.endTime.Sub(m.startTime),
+			WordCount:   mode.WordCount,
+			Punctuation: mode.Punctuation,
+		})
 	}
 
 	m.lastWPM = wpm
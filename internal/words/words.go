@@ -0,0 +1,127 @@
+// Package words counts words typed from a stream of keystrokes.
+//
+// A word is counted when a boundary key (Space, Return, Enter or Tab) or a
+// key that moves on from the text (arrows, Home, End, Page Up/Down, Escape
+// or a Cmd/Ctrl shortcut) ends a run of character keys containing at least
+// one letter or digit. Boundaries with nothing typed before them, such as
+// repeated spaces, indentation or Return on an empty prompt, count nothing.
+// Backspace removes the last character of the current word; Opt- or
+// Cmd-Backspace, Ctrl+W and Ctrl+U discard it.
+package words
+
+import "github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+
+// class is what a key does to the word being typed
+type class int
+
+const (
+	ignored    class = iota // modifiers, function keys, unknown keys
+	letter                  // letters and digits
+	symbol                  // punctuation, which only counts alongside letters
+	boundary                // ends the word
+	navigation              // leaves the word, which counts if typed
+	backspace               // removes the last character
+	discard                 // deletes the whole word
+)
+
+var navigationKeys = map[int]bool{
+	keyboard.KeyLeft:     true,
+	keyboard.KeyRight:    true,
+	keyboard.KeyUp:       true,
+	keyboard.KeyDown:     true,
+	keyboard.KeyHome:     true,
+	keyboard.KeyEnd:      true,
+	keyboard.KeyPageUp:   true,
+	keyboard.KeyPageDown: true,
+	keyboard.KeyEscape:   true,
+}
+
+func classify(code int, mods keyboard.Modifiers) class {
+	shortcut := mods&(keyboard.ModCommand|keyboard.ModControl) != 0
+	switch {
+	case keyboard.IsModifier(code):
+		return ignored
+	case code == keyboard.KeyDelete:
+		if mods&(keyboard.ModOption|keyboard.ModCommand) != 0 {
+			return discard
+		}
+		return backspace
+	case mods&keyboard.ModControl != 0 && (code == keyboard.KeyW || code == keyboard.KeyU):
+		return discard
+	case keyboard.IsWhitespace(code):
+		return boundary
+	case navigationKeys[code] || shortcut:
+		return navigation
+	case keyboard.IsLetter(code):
+		return letter
+	case keyboard.IsSymbol(code):
+		return symbol
+	}
+	return ignored
+}
+
+// run is a stretch of the current word's characters of one kind
+type run struct {
+	letters bool
+	n       int
+}
+
+// Detector is a word-counting state machine. The zero value is ready to use.
+type Detector struct {
+	word []run // characters typed since the last boundary, as runs
+}
+
+// Press feeds a key press with the modifiers held and reports whether it
+// completed a word
+func (d *Detector) Press(keycode int, mods keyboard.Modifiers) bool {
+	switch classify(keycode, mods) {
+	case letter:
+		d.push(true)
+	case symbol:
+		d.push(false)
+	case backspace:
+		if last := len(d.word) - 1; last >= 0 {
+			if d.word[last].n--; d.word[last].n == 0 {
+				d.word = d.word[:last]
+			}
+		}
+	case discard:
+		d.Reset()
+	case boundary, navigation:
+		complete := d.hasLetters()
+		d.Reset()
+		return complete
+	}
+	return false
+}
+
+// Reset forgets the word being typed
+func (d *Detector) Reset() {
+	d.word = d.word[:0]
+}
+
+// Pending returns how many characters of the current word have been typed
+func (d *Detector) Pending() int {
+	n := 0
+	for _, r := range d.word {
+		n += r.n
+	}
+	return n
+}
+
+func (d *Detector) push(letters bool) {
+	if last := len(d.word) - 1; last >= 0 && d.word[last].letters == letters {
+		d.word[last].n++
+		return
+	}
+	d.word = append(d.word, run{letters: letters, n: 1})
+}
+
+func (d *Detector) hasLetters() bool {
+	for _, r := range d.word {
+		if r.letters {
+			return true
+		}
+	}
+	return false
+}
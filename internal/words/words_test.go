@@ -0,0 +1,126 @@
+package words
+
+import (
+	"testing"
+
+	"github.com/aayushbajaj/typing-telemetry/internal/keyboard"
+)
+
+type press struct {
+	code int
+	mods keyboard.Modifiers
+}
+
+var charKeys = map[rune]int{
+	' ': keyboard.KeySpace, '\n': keyboard.KeyReturn, '\t': keyboard.KeyTab, '\b': keyboard.KeyDelete,
+	'.': keyboard.KeyPeriod, ',': keyboard.KeyComma, '-': keyboard.KeyMinus, '/': keyboard.KeySlash,
+	';': keyboard.KeySemicolon, '\'': keyboard.KeyQuote, '=': keyboard.KeyEqual,
+	'a': keyboard.KeyA, 'b': keyboard.KeyB, 'c': keyboard.KeyC, 'd': keyboard.KeyD, 'e': keyboard.KeyE,
+	'f': keyboard.KeyF, 'g': keyboard.KeyG, 'h': keyboard.KeyH, 'i': keyboard.KeyI, 'j': keyboard.KeyJ,
+	'k': keyboard.KeyK, 'l': keyboard.KeyL, 'm': keyboard.KeyM, 'n': keyboard.KeyN, 'o': keyboard.KeyO,
+	'p': keyboard.KeyP, 'q': keyboard.KeyQ, 'r': keyboard.KeyR, 's': keyboard.KeyS, 't': keyboard.KeyT,
+	'u': keyboard.KeyU, 'v': keyboard.KeyV, 'w': keyboard.KeyW, 'x': keyboard.KeyX, 'y': keyboard.KeyY,
+	'z': keyboard.KeyZ, '0': keyboard.Key0, '1': keyboard.Key1, '2': keyboard.Key2, '3': keyboard.Key3,
+	'4': keyboard.Key4, '5': keyboard.Key5, '6': keyboard.Key6, '7': keyboard.Key7, '8': keyboard.Key8,
+	'9': keyboard.Key9,
+}
+
+// typed returns the unmodified presses that type s; \b is Backspace
+func typed(s string) []press {
+	var p []press
+	for _, r := range s {
+		code, ok := charKeys[r]
+		if !ok {
+			panic("no key for " + string(r))
+		}
+		p = append(p, press{code: code})
+	}
+	return p
+}
+
+// seq joins runs of presses
+func seq(parts ...[]press) []press {
+	var p []press
+	for _, part := range parts {
+		p = append(p, part...)
+	}
+	return p
+}
+
+// key is a single press with modifiers held
+func key(code int, mods keyboard.Modifiers) []press {
+	return []press{{code, mods}}
+}
+
+func TestDetector(t *testing.T) {
+	shift, cmd, ctrl, opt := keyboard.ModShift, keyboard.ModCommand, keyboard.ModControl, keyboard.ModOption
+
+	tests := []struct {
+		name    string
+		presses []press
+		want    int
+		pending int
+	}{
+		{"single word", typed("hello "), 1, 0},
+		{"sentence", typed("the quick brown fox\n"), 4, 0},
+		{"word without boundary", typed("hello"), 0, 5},
+		{"mashing space", typed("     "), 0, 0},
+		{"double spaces", typed("one  two   three "), 3, 0},
+		{"indenting", typed("\t\t\tif x\n"), 2, 0},
+		{"enter on empty prompt", typed("\n\n\n"), 0, 0},
+		{"keypad enter", seq(typed("ls"), key(keyboard.KeyKeypadEnter, 0)), 1, 0},
+		{"digits", typed("42 "), 1, 0},
+		{"punctuation alone", typed("- -- ... "), 0, 0},
+		{"punctuation in words", typed("don't stop. "), 2, 0},
+		{"capitals", seq(key(keyboard.KeyShift, 0), key(keyboard.KeyH, shift), typed("i ")), 1, 0},
+		{"backspace within word", typed("helo\b\blo "), 1, 0},
+		{"backspace whole word", typed("hi\b\b "), 0, 0},
+		{"backspace past word", typed("hi\b\b\b\b "), 0, 0},
+		{"backspace leaves punctuation", typed("a.\b\b. "), 0, 0},
+		{"backspace after boundary", typed("hi \b\bo "), 2, 0},
+		{"option backspace", seq(typed("oops"), key(keyboard.KeyDelete, opt), typed(" ")), 0, 0},
+		{"command backspace", seq(typed("oops"), key(keyboard.KeyDelete, cmd), typed(" ")), 0, 0},
+		{"ctrl w", seq(typed("rm -rf"), key(keyboard.KeyW, ctrl), typed("\n")), 1, 0},
+		{"ctrl u", seq(typed("oops"), key(keyboard.KeyU, ctrl), typed("\n")), 0, 0},
+		{"shortcut ends word", seq(typed("save"), key(keyboard.KeyS, cmd)), 1, 0},
+		{"shortcut alone", seq(key(keyboard.KeyS, cmd), typed(" ")), 0, 0},
+		{"shortcut letter isn't typed", seq(key(keyboard.KeyC, cmd), key(keyboard.KeyV, cmd), typed("\n")), 0, 0},
+		{"escape ends word", seq(typed("iword"), key(keyboard.KeyEscape, 0), typed(";wq\n")), 2, 0},
+		{"arrows after word", seq(typed("hi"), key(keyboard.KeyLeft, 0), key(keyboard.KeyRight, 0)), 1, 0},
+		{"arrows alone", seq(key(keyboard.KeyUp, 0), key(keyboard.KeyUp, 0), typed("\n")), 0, 0},
+		{"option letters are typed", seq(key(keyboard.KeyE, opt), typed("t ")), 1, 0},
+		{"modifier presses ignored", seq(typed("a"), key(keyboard.KeyCommand, 0), key(keyboard.KeyShift, cmd), typed("b ")), 1, 0},
+		{"function keys ignored", seq(typed("ab"), key(keyboard.KeyF5, 0), typed("c ")), 1, 0},
+		{"forward delete ignored", seq(typed("ab"), key(keyboard.KeyForwardDel, 0), typed(" ")), 1, 0},
+		{"pending punctuation", typed("ab.-"), 0, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Detector
+			got := 0
+			for _, p := range tt.presses {
+				if d.Press(p.code, p.mods) {
+					got++
+				}
+			}
+			if got != tt.want {
+				t.Errorf("counted %d words, want %d", got, tt.want)
+			}
+			if d.Pending() != tt.pending {
+				t.Errorf("Pending() = %d, want %d", d.Pending(), tt.pending)
+			}
+		})
+	}
+}
+
+func TestDetectorReset(t *testing.T) {
+	var d Detector
+	for _, p := range typed("abc") {
+		d.Press(p.code, p.mods)
+	}
+	d.Reset()
+	if d.Press(keyboard.KeySpace, 0) {
+		t.Error("Expected no word after Reset")
+	}
+}
@@ -0,0 +1,167 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Comparison periods
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodYear  = "year"
+)
+
+// NotableT is the Welch's t statistic beyond which a change counts as
+// notable, roughly a 95% confidence level for all but the shortest periods
+const NotableT = 2.0
+
+// Range is an inclusive span of days
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// Days is the number of days in the range
+func (r Range) Days() int {
+	return int(r.To.Sub(r.From).Hours()/24+0.5) + 1
+}
+
+// PeriodRanges returns the current period up to and including today and the
+// same stretch of the previous period, e.g. Monday to Wednesday of this week
+// and of last week, or March 1-15 and February 1-15. Weeks start on Monday;
+// days past the end of a shorter previous month stop at its last day.
+func PeriodRanges(period string, today time.Time) (current, previous Range, err error) {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+
+	loc := today.Location()
+	var start, prevStart, prevEnd time.Time
+	switch period {
+	case PeriodWeek:
+		offset := (int(today.Weekday()) + 6) % 7
+		start = today.AddDate(0, 0, -offset)
+		prevStart = start.AddDate(0, 0, -7)
+		prevEnd = today.AddDate(0, 0, -7)
+	case PeriodMonth:
+		start = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+		prevStart = start.AddDate(0, -1, 0)
+		prevEnd = clampedDate(prevStart.Year(), prevStart.Month(), today.Day(), loc)
+	case PeriodYear:
+		start = time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		prevStart = start.AddDate(-1, 0, 0)
+		prevEnd = clampedDate(prevStart.Year(), today.Month(), today.Day(), loc)
+	default:
+		return Range{}, Range{}, fmt.Errorf("unknown period %q (want week, month or year)", period)
+	}
+
+	return Range{From: start, To: today}, Range{From: prevStart, To: prevEnd}, nil
+}
+
+// clampedDate is the given day, or the month's last day if it is shorter
+func clampedDate(year int, month time.Month, day int, loc *time.Location) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// Comparison is one metric compared across two periods
+type Comparison struct {
+	Metric     string  `json:"metric"`
+	Previous   float64 `json:"previous"`    // total over the previous period
+	Current    float64 `json:"current"`     // total over the current period
+	Change     float64 `json:"change"`      // Current - Previous
+	Percent    float64 `json:"percent"`     // only meaningful when HasPercent is set
+	HasPercent bool    `json:"has_percent"` // false when Previous is zero
+	T          float64 `json:"-"`           // Welch's t statistic of the daily values; may be ±Inf
+	Notable    bool    `json:"notable"`
+}
+
+// Compare totals two series of daily values and tests whether the days of
+// the current period differ from those of the previous one by more than
+// day-to-day noise
+func Compare(metric string, previous, current []float64) Comparison {
+	c := Comparison{Metric: metric, Previous: sum(previous), Current: sum(current)}
+	c.Change = c.Current - c.Previous
+	c.Percent, c.HasPercent = PercentChange(c.Previous, c.Current)
+	c.T = WelchT(previous, current)
+	c.Notable = math.Abs(c.T) >= NotableT
+	return c
+}
+
+// PercentChange returns the change from previous to current in percent, or
+// false when previous is zero
+func PercentChange(previous, current float64) (float64, bool) {
+	if previous == 0 {
+		return 0, false
+	}
+	return (current - previous) / math.Abs(previous) * 100, true
+}
+
+// MeanStdDev returns the mean and sample standard deviation of values
+func MeanStdDev(values []float64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	mean = sum(values) / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)-1))
+}
+
+// Percentile returns the p-th percentile (0-100) of values, interpolating
+// between the nearest ranks. It is 0 for no values.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	if lo < 0 {
+		return sorted[0]
+	}
+	return sorted[lo] + (sorted[lo+1]-sorted[lo])*(rank-float64(lo))
+}
+
+// WelchT returns Welch's t statistic for the difference in means from a to
+// b, positive when b is larger. It is 0 when either sample has fewer than
+// two values, and ±Inf when both samples are constant but differ.
+func WelchT(a, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+	meanA, sdA := MeanStdDev(a)
+	meanB, sdB := MeanStdDev(b)
+	se := math.Sqrt(sdA*sdA/float64(len(a)) + sdB*sdB/float64(len(b)))
+	if se == 0 {
+		switch {
+		case meanB > meanA:
+			return math.Inf(1)
+		case meanB < meanA:
+			return math.Inf(-1)
+		}
+		return 0
+	}
+	return (meanB - meanA) / se
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
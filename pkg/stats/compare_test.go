@@ -0,0 +1,225 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestPeriodRanges(t *testing.T) {
+	tests := []struct {
+		period    string
+		today     string
+		current   [2]string
+		previous  [2]string
+		wantError bool
+	}{
+		{PeriodWeek, "2024-03-13", [2]string{"2024-03-11", "2024-03-13"}, [2]string{"2024-03-04", "2024-03-06"}, false},
+		{PeriodWeek, "2024-03-11", [2]string{"2024-03-11", "2024-03-11"}, [2]string{"2024-03-04", "2024-03-04"}, false},
+		{PeriodWeek, "2024-03-17", [2]string{"2024-03-11", "2024-03-17"}, [2]string{"2024-03-04", "2024-03-10"}, false},
+		{PeriodMonth, "2024-03-15", [2]string{"2024-03-01", "2024-03-15"}, [2]string{"2024-02-01", "2024-02-15"}, false},
+		{PeriodMonth, "2024-03-31", [2]string{"2024-03-01", "2024-03-31"}, [2]string{"2024-02-01", "2024-02-29"}, false},
+		{PeriodMonth, "2024-01-10", [2]string{"2024-01-01", "2024-01-10"}, [2]string{"2023-12-01", "2023-12-10"}, false},
+		{PeriodYear, "2024-03-01", [2]string{"2024-01-01", "2024-03-01"}, [2]string{"2023-01-01", "2023-03-01"}, false},
+		{PeriodYear, "2025-12-31", [2]string{"2025-01-01", "2025-12-31"}, [2]string{"2024-01-01", "2024-12-31"}, false},
+		{PeriodYear, "2024-02-29", [2]string{"2024-01-01", "2024-02-29"}, [2]string{"2023-01-01", "2023-02-28"}, false},
+		{"fortnight", "2024-03-13", [2]string{}, [2]string{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period+" "+tt.today, func(t *testing.T) {
+			current, previous, err := PeriodRanges(tt.period, date(tt.today))
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PeriodRanges() error: %v", err)
+			}
+			got := [4]string{
+				current.From.Format("2006-01-02"), current.To.Format("2006-01-02"),
+				previous.From.Format("2006-01-02"), previous.To.Format("2006-01-02"),
+			}
+			want := [4]string{tt.current[0], tt.current[1], tt.previous[0], tt.previous[1]}
+			if got != want {
+				t.Errorf("PeriodRanges() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRangeDays(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     int
+	}{
+		{"2024-03-01", "2024-03-01", 1},
+		{"2024-03-01", "2024-03-07", 7},
+		{"2024-01-01", "2024-12-31", 366},
+	}
+	for _, tt := range tests {
+		r := Range{From: date(tt.from), To: date(tt.to)}
+		if got := r.Days(); got != tt.want {
+			t.Errorf("Range{%s, %s}.Days() = %d, want %d", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestPercentChange(t *testing.T) {
+	tests := []struct {
+		previous, current float64
+		want              float64
+		ok                bool
+	}{
+		{100, 150, 50, true},
+		{200, 100, -50, true},
+		{100, 100, 0, true},
+		{0, 100, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := PercentChange(tt.previous, tt.current)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("PercentChange(%v, %v) = %v, %v, want %v, %v", tt.previous, tt.current, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMeanStdDev(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []float64
+		mean, sdev float64
+	}{
+		{"empty", nil, 0, 0},
+		{"single", []float64{5}, 5, 0},
+		{"constant", []float64{3, 3, 3}, 3, 0},
+		{"spread", []float64{2, 4, 4, 4, 5, 5, 7, 9}, 5, 2.138089935299395},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, sdev := MeanStdDev(tt.values)
+			if mean != tt.mean || math.Abs(sdev-tt.sdev) > 1e-9 {
+				t.Errorf("MeanStdDev() = %v, %v, want %v, %v", mean, sdev, tt.mean, tt.sdev)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{"empty", nil, 50, 0},
+		{"single", []float64{42}, 90, 42},
+		{"odd median", []float64{5, 1, 3}, 50, 3},
+		{"even median", []float64{4, 1, 3, 2}, 50, 2.5},
+		{"p90", []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}, 90, 91},
+		{"min", []float64{3, 1, 2}, 0, 1},
+		{"max", []float64{3, 1, 2}, 100, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Percentile(tt.values, tt.p); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Percentile(%v, %v) = %v, want %v", tt.values, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWelchT(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{"too few days", []float64{1}, []float64{5, 6}, 0},
+		{"identical", []float64{1, 2, 3}, []float64{1, 2, 3}, 0},
+		{"constant increase", []float64{2, 2}, []float64{3, 3}, math.Inf(1)},
+		{"constant decrease", []float64{3, 3}, []float64{2, 2}, math.Inf(-1)},
+		{"increase", []float64{1, 2, 3}, []float64{4, 5, 6}, 3.6742346141747673},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WelchT(tt.a, tt.b)
+			if got != tt.want && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("WelchT() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name        string
+		previous    []float64
+		current     []float64
+		change      float64
+		percent     float64
+		hasPercent  bool
+		wantNotable bool
+	}{
+		{
+			name:        "steady",
+			previous:    []float64{1000, 1200, 900, 1100},
+			current:     []float64{1100, 1000, 1200, 950},
+			change:      50,
+			percent:     1.1904761904761905,
+			hasPercent:  true,
+			wantNotable: false,
+		},
+		{
+			name:        "one outlier day",
+			previous:    []float64{1000, 1000, 1000, 1000},
+			current:     []float64{1000, 1000, 1000, 5000},
+			change:      4000,
+			percent:     100,
+			hasPercent:  true,
+			wantNotable: false,
+		},
+		{
+			name:        "doubled every day",
+			previous:    []float64{1000, 1100, 900, 1000},
+			current:     []float64{2000, 2100, 1900, 2000},
+			change:      4000,
+			percent:     100,
+			hasPercent:  true,
+			wantNotable: true,
+		},
+		{
+			name:        "started from nothing",
+			previous:    []float64{0, 0, 0},
+			current:     []float64{10, 20, 30},
+			change:      60,
+			hasPercent:  false,
+			wantNotable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compare("Keystrokes", tt.previous, tt.current)
+			if c.Metric != "Keystrokes" {
+				t.Errorf("Metric = %q", c.Metric)
+			}
+			if c.Change != tt.change {
+				t.Errorf("Change = %v, want %v", c.Change, tt.change)
+			}
+			if c.HasPercent != tt.hasPercent || math.Abs(c.Percent-tt.percent) > 1e-9 {
+				t.Errorf("Percent = %v, %v, want %v, %v", c.Percent, c.HasPercent, tt.percent, tt.hasPercent)
+			}
+			if c.Notable != tt.wantNotable {
+				t.Errorf("Notable = %v (t = %v), want %v", c.Notable, c.T, tt.wantNotable)
+			}
+		})
+	}
+}